@@ -0,0 +1,211 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Config holds user preferences loaded from config.toml. Zero values mean
+// "use the built-in default", so an empty or missing file changes nothing.
+type Config struct {
+	// PageSize is the number of rows fetched per page. 0 sizes pages to
+	// fit the visible table height.
+	PageSize int `toml:"page_size"`
+
+	// Theme selects a built-in color theme by name (auto, dark, light,
+	// solarized). Empty means auto, which adapts to the terminal background.
+	Theme string `toml:"theme"`
+
+	// ReadOnly opens every database with query_only enabled, so no
+	// statement can modify it.
+	ReadOnly bool `toml:"read_only"`
+
+	// SSHSyncOnRefresh makes refreshing a database opened from an scp://
+	// URL copy it from the host again first.
+	SSHSyncOnRefresh bool `toml:"ssh_sync_on_refresh"`
+
+	// Attach lists databases to attach to every database opened, each as
+	// NAME=PATH, or PATH to name it after the file.
+	Attach []string `toml:"attach"`
+
+	// MinColWidth and MaxColWidth bound the measured width of grid columns.
+	MinColWidth int `toml:"min_col_width"`
+	MaxColWidth int `toml:"max_col_width"`
+
+	// Timezone is an IANA name (e.g. "Europe/Berlin", "UTC") used when
+	// showing timestamps. Empty means the system's local timezone.
+	Timezone string `toml:"timezone"`
+
+	// WeekStart is the first day of the week (e.g. "monday", "sun") used
+	// when deciding whether a timestamp falls in the current week.
+	WeekStart string `toml:"week_start"`
+
+	// ScanWarnRows makes the query popup check EXPLAIN QUERY PLAN before
+	// running a query and warn when it would scan a table with more rows
+	// than this. 0 disables the check.
+	ScanWarnRows int `toml:"scan_warn_rows"`
+
+	// ConfirmWrites makes the query popup count the rows an UPDATE or
+	// DELETE without a LIMIT would change and ask before running it.
+	ConfirmWrites bool `toml:"confirm_writes"`
+
+	// QueryTimeout cancels a query from the query popup that runs longer
+	// than this, e.g. "30s" or "2m". 0 lets queries run until cancelled.
+	QueryTimeout time.Duration `toml:"query_timeout"`
+
+	// MaxValueLength cuts TEXT values longer than this many characters
+	// when rows are read for the grid, so huge values don't slow it down.
+	// The row detail reads them in full. 0 keeps every value whole.
+	MaxValueLength int `toml:"max_value_length"`
+
+	// FilterHistory is how many filters are remembered per table, for
+	// recalling with up and down in the filter input. 0 remembers none.
+	FilterHistory int `toml:"filter_history"`
+
+	// SaveFilterHistory keeps the filter history across runs instead of
+	// only for the session.
+	SaveFilterHistory bool `toml:"save_filter_history"`
+
+	// SQLFunctions names the groups of helper SQL functions added to every
+	// connection for use in queries: uuid, base64, and time. regexp() is
+	// always available.
+	SQLFunctions []string `toml:"sql_functions"`
+
+	// FormatTimestamps shows integer columns holding unix timestamps (in
+	// seconds or milliseconds) and REAL columns holding Julian days as
+	// dates in the grid and row detail.
+	FormatTimestamps bool `toml:"format_timestamps"`
+
+	// RowNumbers starts the grid with a column numbering each row by its
+	// position in the whole table.
+	RowNumbers bool `toml:"row_numbers"`
+
+	// RowIDs starts the grid with a column showing each table row's rowid.
+	RowIDs bool `toml:"show_rowids"`
+
+	// ImagePreview picks how PNG and JPEG BLOBs are drawn in the row
+	// detail: auto (detect the terminal), kitty, iterm2, sixel, or off.
+	ImagePreview string `toml:"image_preview"`
+
+	// ShowClipped shows the widest value of the grid's selected row that
+	// is cut off at its column's width in full in the status bar.
+	ShowClipped bool `toml:"show_clipped"`
+
+	// Align sets how grid cells line up in their columns.
+	Align AlignConfig `toml:"align"`
+
+	// VimKeys adds vim's motions to the data pane: gg and G for the first
+	// and last page, ctrl+d and ctrl+u for half a page, and n and N for the
+	// next and previous match of a / search, in place of ctrl+n and ctrl+p.
+	// The other actions' default keys these take are dropped.
+	VimKeys bool `toml:"vim_keys"`
+
+	// Keys rebinds actions to different keys, e.g. next_page = ["n"].
+	// Action names match the fields of ui.KeyMap in snake_case.
+	Keys map[string][]string `toml:"keys"`
+}
+
+// AlignConfig sets cell alignment (left, right, or center) per kind of
+// column, detected from its values. Empty fields keep the defaults:
+// numbers right, text left, dates centered.
+type AlignConfig struct {
+	Numbers string `toml:"numbers"`
+	Text    string `toml:"text"`
+	Dates   string `toml:"dates"`
+
+	// Columns overrides individual columns, keyed by "column" or
+	// "table.column", e.g. "orders.zip" = "left".
+	Columns map[string]string `toml:"columns"`
+}
+
+// Default returns the built-in configuration.
+func Default() Config {
+	return Config{
+		MinColWidth:    10,
+		MaxColWidth:    40,
+		FilterHistory:  20,
+		MaxValueLength: 10000,
+		SQLFunctions:   []string{"uuid", "base64", "time"},
+		ConfirmWrites:  true,
+
+		SSHSyncOnRefresh: true,
+	}
+}
+
+// Dir returns the sqlitui config directory: $XDG_CONFIG_HOME/sqlitui,
+// falling back to ~/.config/sqlitui.
+func Dir() (string, error) {
+	if d := os.Getenv("XDG_CONFIG_HOME"); d != "" {
+		return filepath.Join(d, "sqlitui"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "sqlitui"), nil
+}
+
+// Path returns the default location of config.toml.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load reads the config file at path on top of Default(). An empty path
+// means the default location. A missing file is not an error; a malformed
+// one is, so typos don't get silently ignored.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path == "" {
+		p, err := Path()
+		if err != nil {
+			return cfg, nil
+		}
+		path = p
+	}
+
+	md, err := toml.DecodeFile(path, &cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Default(), fmt.Errorf("config %s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return Default(), fmt.Errorf("config %s: unknown option %q", path, undecoded[0].String())
+	}
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	if c.PageSize < 0 {
+		return fmt.Errorf("page_size must not be negative (got %d)", c.PageSize)
+	}
+	if c.ScanWarnRows < 0 {
+		return fmt.Errorf("scan_warn_rows must not be negative (got %d)", c.ScanWarnRows)
+	}
+	if c.QueryTimeout < 0 {
+		return fmt.Errorf("query_timeout must not be negative (got %s)", c.QueryTimeout)
+	}
+	if c.MaxValueLength < 0 {
+		return fmt.Errorf("max_value_length must not be negative (got %d)", c.MaxValueLength)
+	}
+	if c.FilterHistory < 0 {
+		return fmt.Errorf("filter_history must not be negative (got %d)", c.FilterHistory)
+	}
+	if c.MinColWidth < 1 {
+		return fmt.Errorf("min_col_width must be at least 1 (got %d)", c.MinColWidth)
+	}
+	if c.MaxColWidth < c.MinColWidth {
+		return fmt.Errorf("max_col_width (%d) must not be less than min_col_width (%d)", c.MaxColWidth, c.MinColWidth)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Snippet is a named piece of SQL offered by the query popup's snippet
+// picker.
+type Snippet struct {
+	Name string
+	SQL  string
+}
+
+// SnippetsDir returns the directory holding the user's snippets, one .sql
+// file each: the snippets directory under Dir().
+func SnippetsDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snippets"), nil
+}
+
+// LoadSnippets reads the user's snippets, named after their files with the
+// .sql left off and sorted by name. A missing directory holds none.
+func LoadSnippets() ([]Snippet, error) {
+	dir, err := SnippetsDir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var snippets []Snippet
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return snippets, err
+		}
+		snippets = append(snippets, Snippet{
+			Name: strings.TrimSuffix(filepath.Base(p), ".sql"),
+			SQL:  strings.TrimSpace(string(data)),
+		})
+	}
+	return snippets, nil
+}
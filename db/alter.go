@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// AddColumnSQL is the ALTER TABLE statement adding the column def, a
+// column definition like "age INTEGER NOT NULL DEFAULT 0", to table.
+func AddColumnSQL(table, def string) string {
+	return "ALTER TABLE " + quoteTable(table) + " ADD COLUMN " + def
+}
+
+// RenameColumnSQL is the ALTER TABLE statement renaming a column of table.
+func RenameColumnSQL(table, from, to string) string {
+	return "ALTER TABLE " + quoteTable(table) + " RENAME COLUMN " + quoteIdent(from) + " TO " + quoteIdent(to)
+}
+
+// RenameTableSQL is the ALTER TABLE statement renaming table to name, which
+// stays in the table's schema.
+func RenameTableSQL(table, name string) string {
+	return "ALTER TABLE " + quoteTable(table) + " RENAME TO " + quoteIdent(name)
+}
+
+// AddColumn adds the column def to table. SQLite's ADD COLUMN refuses
+// some columns, like a UNIQUE one, one NOT NULL without a default, or one
+// whose default isn't constant; those are added by rebuilding the table
+// around them instead, as RebuildWith does. rebuilt reports which it took.
+func AddColumn(ctx context.Context, db *sql.DB, table, def string) (rebuilt bool, err error) {
+	_, err = on(db).ExecContext(ctx, AddColumnSQL(table, def))
+	if err == nil || !strings.Contains(strings.ToLower(err.Error()), "cannot add a") {
+		return false, err
+	}
+	return true, RebuildWith(ctx, db, table, def)
+}
+
+// RenameColumn renames a column of table. SQLite rewrites the indexes,
+// triggers, and views using it to match.
+func RenameColumn(ctx context.Context, db *sql.DB, table, from, to string) error {
+	_, err := on(db).ExecContext(ctx, RenameColumnSQL(table, from, to))
+	return err
+}
+
+// RenameTable renames table to name, keeping it in its schema; the
+// foreign keys, triggers, and views referring to it follow the rename.
+func RenameTable(ctx context.Context, db *sql.DB, table, name string) error {
+	_, err := on(db).ExecContext(ctx, RenameTableSQL(table, name))
+	return err
+}
+
+// RebuildWith adds the column def to table the way SQLite's documentation
+// describes for changes ALTER TABLE can't make: it creates the table anew
+// with the column, copies the rows over with their rowids, drops the old
+// table, renames the new one into its place, and recreates its indexes and
+// triggers, all in one savepoint. Foreign keys are turned off for it and
+// checked afterwards, so dropping the old table doesn't delete the rows
+// referring to it; inside a transaction they can't be, so a rebuild there
+// needs them off to begin with.
+func RebuildWith(ctx context.Context, db *sql.DB, table, def string) error {
+	schema, name := splitTable(table)
+	master, _ := schemaMaster(table)
+	var stmt string
+	if err := on(db).QueryRowContext(ctx,
+		"SELECT sql FROM "+master+" WHERE type = 'table' AND name = ?", name,
+	).Scan(&stmt); err != nil {
+		return fmt.Errorf("reading the definition of %s: %w", table, err)
+	}
+	columns, err := GetColumns(ctx, db, table)
+	if err != nil {
+		return err
+	}
+
+	if InTransaction(db) {
+		// An interrupt would roll back the transaction, not just the rebuild.
+		ctx = context.WithoutCancel(ctx)
+	}
+	conn, release, err := reserve(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	var fkOn bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&fkOn); err != nil {
+		return err
+	}
+	if fkOn {
+		if InTransaction(db) {
+			return errors.New("rebuilding a table inside a transaction needs PRAGMA foreign_keys off; end the transaction first")
+		}
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+	}
+	// Keep the rename from rewriting the views and triggers that refer to
+	// the table, which is briefly missing.
+	if _, err := conn.ExecContext(ctx, "PRAGMA legacy_alter_table = ON"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA legacy_alter_table = OFF")
+
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT sqlitui_rebuild"); err != nil {
+		return err
+	}
+	if err := rebuild(ctx, conn, schema, name, stmt, def, columns, fkOn); err != nil {
+		conn.ExecContext(context.Background(), "ROLLBACK TO sqlitui_rebuild")
+		conn.ExecContext(context.Background(), "RELEASE sqlitui_rebuild")
+		checkTx(db, err)
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "RELEASE sqlitui_rebuild")
+	return err
+}
+
+// rebuild runs the steps of RebuildWith inside its savepoint.
+func rebuild(ctx context.Context, conn *sql.Conn, schema, name, stmt, def string, columns []string, fkOn bool) error {
+	master, prefix := "sqlite_master", ""
+	if schema != "" {
+		master, prefix = quoteIdent(schema)+".sqlite_master", quoteIdent(schema)+"."
+	}
+	create, rowid, err := withColumn(stmt, def)
+	if err != nil {
+		return err
+	}
+
+	rows, err := conn.QueryContext(ctx,
+		"SELECT sql FROM "+master+" WHERE tbl_name = ? AND type IN ('index', 'trigger') AND sql IS NOT NULL ORDER BY type, name",
+		name)
+	if err != nil {
+		return err
+	}
+	var dependents []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			rows.Close()
+			return err
+		}
+		dependents = append(dependents, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tmp := "sqlitui_new_" + name
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	cols := strings.Join(quoted, ", ")
+	if rowid {
+		cols = "rowid, " + cols
+	}
+	stmts := []string{
+		"CREATE TABLE " + prefix + quoteIdent(tmp) + " " + create,
+		"INSERT INTO " + prefix + quoteIdent(tmp) + " (" + cols + ") SELECT " + cols + " FROM " + prefix + quoteIdent(name),
+		"DROP TABLE " + prefix + quoteIdent(name),
+		"ALTER TABLE " + prefix + quoteIdent(tmp) + " RENAME TO " + quoteIdent(name),
+	}
+	for _, s := range dependents {
+		stmts = append(stmts, inSchema(s, schema))
+	}
+	for _, s := range stmts {
+		if _, err := conn.ExecContext(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	if fkOn {
+		var table string
+		err := conn.QueryRowContext(ctx, "PRAGMA "+prefix+"foreign_key_check").Scan(&table)
+		switch {
+		case err == nil:
+			return fmt.Errorf("the rebuilt table breaks a foreign key of %s", table)
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+	}
+	return nil
+}
+
+// withColumn returns what follows the name in the CREATE TABLE statement
+// stmt with the column def added after its last column, ahead of any table
+// constraints, and whether the table has rowids to carry over.
+func withColumn(stmt, def string) (string, bool, error) {
+	tokens := topLevelTokens(stmt)
+	var body sqlToken
+	at := -1
+	for i, t := range tokens {
+		if strings.HasPrefix(t.text, "(") {
+			body, at = t, i
+			break
+		}
+	}
+	if at < 0 {
+		return "", false, errors.New("can't rebuild a table created with AS SELECT")
+	}
+	rowid := true
+	for i, t := range tokens[at+1:] {
+		if t.word() == "WITHOUT" && at+2+i < len(tokens) && tokens[at+2+i].word() == "ROWID" {
+			rowid = false
+		}
+	}
+
+	inner := body.text[1 : len(body.text)-1]
+	var parts []string
+	from := 0
+	for _, t := range append(topLevelTokens(inner), sqlToken{len(inner), len(inner), ","}) {
+		if t.text == "," {
+			parts = append(parts, strings.TrimSpace(inner[from:t.start]))
+			from = t.end
+		}
+	}
+	// Table constraints follow the columns.
+	i := len(parts)
+	for j, p := range parts {
+		first := lexSQL(p)
+		if len(first) == 0 {
+			continue
+		}
+		switch first[0].word() {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			i = min(i, j)
+		}
+	}
+	parts = append(parts[:i:i], append([]string{strings.TrimSpace(def)}, parts[i:]...)...)
+	return "(\n  " + strings.Join(parts, ",\n  ") + "\n)" + stmt[body.end:], rowid, nil
+}
+
+// inSchema qualifies the name an index's or trigger's CREATE statement
+// gives it with schema, so it is created in an attached database rather
+// than main.
+func inSchema(stmt, schema string) string {
+	if schema == "" {
+		return stmt
+	}
+	tokens := lexSQL(stmt)
+	for i, t := range tokens {
+		switch t.word() {
+		case "CREATE", "UNIQUE", "INDEX", "TRIGGER", "IF", "NOT", "EXISTS", "TEMP", "TEMPORARY":
+			continue
+		}
+		if i > 0 {
+			return stmt[:t.start] + quoteIdent(schema) + "." + stmt[t.start:]
+		}
+		break
+	}
+	return stmt
+}
+
+// RenamedName is how the table list names table once RenameTable renamed
+// it to name: in its attached database's schema still, if it is in one.
+func RenamedName(table, name string) string {
+	if schema, _ := splitTable(table); schema != "" {
+		return schema + "." + name
+	}
+	return name
+}
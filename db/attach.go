@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// Attachment is a database file attached to another under a schema name,
+// whose tables are then named "name.table".
+type Attachment struct {
+	Name string
+	Path string
+}
+
+// ATTACH is per connection, so like session pragmas the attachments of a
+// database are remembered per DSN and made on every connection the driver
+// opens. attachedNames holds the schema names attached to each database
+// open, for telling the tables of attached databases from main's tables
+// with a dot in their name.
+var (
+	attachMu      sync.Mutex
+	attachments   = map[string][]Attachment{} // dsn → attached databases
+	attachedNames = map[*sql.DB]map[string]bool{}
+)
+
+func init() {
+	sqlite.RegisterConnectionHook(func(conn sqlite.ExecQuerierContext, dsn string) error {
+		attachMu.Lock()
+		list := slices.Clone(attachments[dsn])
+		attachMu.Unlock()
+		for _, a := range list {
+			args := []driver.NamedValue{{Ordinal: 1, Value: a.Path}}
+			if _, err := conn.ExecContext(context.Background(), "ATTACH DATABASE ? AS "+quoteIdent(a.Name), args); err != nil {
+				return fmt.Errorf("attaching %s: %w", a.Path, err)
+			}
+		}
+		return nil
+	})
+}
+
+// parseAttachment reads a database to attach given as NAME=PATH, or just
+// PATH to name it after the file.
+func parseAttachment(arg string) (Attachment, error) {
+	name, path, ok := strings.Cut(arg, "=")
+	if !ok {
+		path = arg
+		name = strings.Map(func(r rune) rune {
+			if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	}
+	switch {
+	case path == "":
+		return Attachment{}, fmt.Errorf("attach %q: no database path", arg)
+	case name == "" || strings.Contains(name, "."):
+		return Attachment{}, fmt.Errorf("attach %q: the name must not be empty or hold a dot", arg)
+	case strings.EqualFold(name, "main") || strings.EqualFold(name, "temp"):
+		return Attachment{}, fmt.Errorf("attach %q: %s is taken by SQLite", arg, name)
+	}
+	return Attachment{Name: name, Path: path}, nil
+}
+
+// attach makes the databases in list attached to every connection of db,
+// which must not have opened one yet, and checks that they can be.
+func attach(db *sql.DB, dsn string, list []Attachment) error {
+	for i, a := range list {
+		if _, err := os.Stat(a.Path); err != nil {
+			// ATTACH would create a missing file.
+			return fmt.Errorf("attach %s: %w", a.Name, err)
+		}
+		for _, b := range list[:i] {
+			if strings.EqualFold(a.Name, b.Name) {
+				return fmt.Errorf("attach: %s is attached twice", a.Name)
+			}
+		}
+	}
+	names := make(map[string]bool, len(list))
+	for _, a := range list {
+		names[strings.ToLower(a.Name)] = true
+	}
+	attachMu.Lock()
+	attachments[dsn] = slices.Clone(list)
+	attachedNames[db] = names
+	attachMu.Unlock()
+	if len(list) == 0 {
+		return nil
+	}
+	// The first connection makes the attachments, failing if one can't be.
+	return db.PingContext(context.Background())
+}
+
+// detach forgets the schema names attached to db, which is closing.
+func detach(db *sql.DB) {
+	attachMu.Lock()
+	delete(attachedNames, db)
+	attachMu.Unlock()
+}
+
+// isAttached reports whether schema, in lower case, is the name of a
+// database attached to one that is open.
+func isAttached(schema string) bool {
+	attachMu.Lock()
+	defer attachMu.Unlock()
+	for _, names := range attachedNames {
+		if names[schema] {
+			return true
+		}
+	}
+	return false
+}
+
+// Attached returns the databases attached to db, in the order attached.
+func Attached(db *sql.DB) []Attachment {
+	var dsn string
+	sessionMu.Lock()
+	if s, ok := sessions[db]; ok {
+		dsn = s.dsn
+	}
+	sessionMu.Unlock()
+	attachMu.Lock()
+	defer attachMu.Unlock()
+	return slices.Clone(attachments[dsn])
+}
+
+// attachedSchemas returns the schema names of the databases attached to db.
+func attachedSchemas(db *sql.DB) []string {
+	var names []string
+	for _, a := range Attached(db) {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// splitTable splits a table name as listed into the schema of an attached
+// database and the table's own name, or returns "" and the name as it is
+// for main's tables.
+func splitTable(table string) (schema, name string) {
+	if i := strings.IndexByte(table, '.'); i > 0 {
+		if isAttached(strings.ToLower(table[:i])) {
+			return table[:i], table[i+1:]
+		}
+	}
+	return "", table
+}
+
+// SplitTable is splitTable for names put together outside this package.
+func SplitTable(table string) (schema, name string) {
+	return splitTable(table)
+}
+
+// quoteTable quotes a table name as listed for use in SQL, qualified by
+// its schema when it is in an attached database.
+func quoteTable(table string) string {
+	schema, name := splitTable(table)
+	if schema == "" {
+		return quoteIdent(name)
+	}
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+// QuoteTable is quoteTable for SQL put together outside this package.
+func QuoteTable(table string) string {
+	return quoteTable(table)
+}
+
+// qualifyPragma returns the PRAGMA statement calling pragma on table, as
+// PRAGMA "aux".table_info("t") for a table of an attached database.
+func qualifyPragma(pragma, table string) string {
+	schema, name := splitTable(table)
+	if schema == "" {
+		return "PRAGMA " + pragma + "(" + quoteIdent(name) + ")"
+	}
+	return "PRAGMA " + quoteIdent(schema) + "." + pragma + "(" + quoteIdent(name) + ")"
+}
+
+// schemaMaster returns the sqlite_master holding table, and the name table
+// has in it.
+func schemaMaster(table string) (master, name string) {
+	schema, name := splitTable(table)
+	if schema == "" {
+		return "sqlite_master", name
+	}
+	return quoteIdent(schema) + ".sqlite_master", name
+}
+
+// tableSchema is the schema of table for the pragma table-valued
+// functions' schema argument: main, or an attached database's name.
+func tableSchema(table string) (schema, name string) {
+	schema, name = splitTable(table)
+	if schema == "" {
+		schema = "main"
+	}
+	return schema, name
+}
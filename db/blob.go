@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// blobLabelRe matches the placeholder BlobLabel produces.
+var blobLabelRe = regexp.MustCompile(`^<BLOB (\d+) bytes>$`)
+
+// BlobLabel is what the row readers return in place of a BLOB's bytes,
+// which are rarely printable and would garble the grid.
+func BlobLabel(size int) string {
+	return fmt.Sprintf("<BLOB %d bytes>", size)
+}
+
+// ParseBlobLabel reports whether s is a BlobLabel, and the size it names.
+// A TEXT value can look the same, so ReadBlob re-checks the stored type.
+func ParseBlobLabel(s string) (int, bool) {
+	m := blobLabelRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	return n, err == nil
+}
+
+// ReadBlob returns the bytes stored in column of the row with rowid. It is
+// an error for the value not to be a BLOB.
+func ReadBlob(ctx context.Context, db *sql.DB, table, column string, rowid int64) ([]byte, error) {
+	var typ string
+	var data []byte
+	q := "SELECT typeof(" + quoteIdent(column) + "), " + quoteIdent(column) + " FROM " + quoteTable(table) + " WHERE rowid = ?"
+	if err := on(db).QueryRowContext(ctx, q, rowid).Scan(&typ, &data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("row %d no longer exists", rowid)
+		}
+		return nil, err
+	}
+	if typ != "blob" {
+		return nil, fmt.Errorf("%s is %s, not a BLOB", column, typ)
+	}
+	return data, nil
+}
@@ -1,49 +1,150 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	// Import the CGo-free SQLite driver. The underscore means we import
 	// it only for its side effect: registering itself as a database/sql
 	// driver under the name "sqlite".
 	_ "modernc.org/sqlite"
+	"modernc.org/sqlite/vfs"
 )
 
-// Open connects to a SQLite database file. It uses the standard
-// database/sql interface, so all the usual Query/Exec methods work.
-func Open(path string) (*sql.DB, error) {
-	return sql.Open("sqlite", path)
+// Options controls how a database is opened.
+type Options struct {
+	// ReadOnly sets PRAGMA query_only on every pooled connection, so any
+	// statement that would modify the database fails.
+	ReadOnly bool
+
+	// Attach names databases to attach to every connection, each as
+	// NAME=PATH or PATH, named after the file then. Their tables are
+	// listed as "NAME.table".
+	Attach []string
 }
 
-// ListTables returns the names of all user-created tables in the database.
-// sqlite_master is a system table that stores the schema — every CREATE TABLE
-// statement lives here as a row with type='table'.
-func ListTables(db *sql.DB) ([]string, error) {
-	rows, err := db.Query(
-		"SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name",
-	)
+// Open connects to a SQLite database file. It uses the standard
+// database/sql interface, so all the usual Query/Exec methods work.
+// Per-connection settings go through the driver's DSN query parameters,
+// which it applies to each new connection in the pool. A path that
+// IsRemote is read over HTTP, and one that IsSSH from a copy SyncSSH
+// makes; both are always read-only.
+func Open(path string, opts Options) (*sql.DB, error) {
+	params := url.Values{}
+	if opts.ReadOnly || IsSSH(path) {
+		params.Add("_pragma", "query_only(1)")
+	}
+	dsn := path
+	var remote *vfs.FS
+	switch {
+	case IsRemote(path):
+		var err error
+		if dsn, remote, err = openRemote(path); err != nil {
+			return nil, err
+		}
+	case IsSSH(path):
+		local, err := SyncSSH(context.Background(), path)
+		if err != nil {
+			return nil, err
+		}
+		dsn = local + "?" + params.Encode()
+	case len(params) > 0:
+		dsn += "?" + params.Encode()
+	}
+	database, err := openSession(dsn, remote)
 	if err != nil {
+		if remote != nil {
+			remote.Close()
+		}
 		return nil, err
 	}
-	defer rows.Close()
 
+	list := make([]Attachment, 0, len(opts.Attach))
+	for _, arg := range opts.Attach {
+		a, err := parseAttachment(arg)
+		if err != nil {
+			Close(database)
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	if err := attach(database, dsn, list); err != nil {
+		Close(database)
+		return nil, err
+	}
+	return database, nil
+}
+
+// OpenWatchConn reserves a dedicated connection from the pool for polling
+// PRAGMA data_version. data_version is per-connection: it only changes when
+// some *other* connection commits, so the same connection must be reused
+// for every poll or the values aren't comparable.
+func OpenWatchConn(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	return db.Conn(ctx)
+}
+
+// DataVersion returns the current PRAGMA data_version for conn. It is a
+// cheap check that doesn't touch any table data.
+func DataVersion(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var v int64
+	err := conn.QueryRowContext(ctx, "PRAGMA data_version").Scan(&v)
+	return v, err
+}
+
+// ListTables returns the names of all user-created tables in the database,
+// followed by those of the attached databases as "name.table".
+// sqlite_master is a system table that stores the schema — every CREATE TABLE
+// statement lives here as a row with type='table'.
+func ListTables(ctx context.Context, db *sql.DB) ([]string, error) {
 	var tables []string
-	for rows.Next() {
-		var name string
-		if err := rows.Scan(&name); err != nil {
+	for _, schema := range append([]string{""}, attachedSchemas(db)...) {
+		master, prefix := "sqlite_master", ""
+		if schema != "" {
+			master, prefix = quoteIdent(schema)+".sqlite_master", schema+"."
+		}
+		rows, err := on(db).QueryContext(ctx,
+			"SELECT name FROM "+master+" WHERE type = 'table' ORDER BY name",
+		)
+		if err != nil {
 			return nil, err
 		}
-		tables = append(tables, name)
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			tables = append(tables, prefix+name)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// SchemaObjects returns every entry in sqlite_master — tables, indexes,
+// views, and triggers — ordered by type and name. Auto-created objects
+// (like implicit indexes) have a NULL sql.
+func SchemaObjects(ctx context.Context, db *sql.DB) ([]string, [][]string, error) {
+	rows, err := on(db).QueryContext(ctx, "SELECT type, name, tbl_name, sql FROM sqlite_master ORDER BY type, name")
+	if err != nil {
+		return nil, nil, err
 	}
-	return tables, rows.Err()
+	defer rows.Close()
+	return scanRows(rows)
 }
 
 // GetColumns returns column names for a table using PRAGMA table_info.
 // This is a SQLite-specific command that returns schema metadata.
-func GetColumns(db *sql.DB, table string) ([]string, error) {
-	rows, err := db.Query("PRAGMA table_info(" + quoteIdent(table) + ")")
+func GetColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := on(db).QueryContext(ctx, qualifyPragma("table_info", table))
 	if err != nil {
 		return nil, err
 	}
@@ -63,11 +164,43 @@ func GetColumns(db *sql.DB, table string) ([]string, error) {
 	return columns, rows.Err()
 }
 
+// ColumnInfo describes a single table column as reported by PRAGMA table_info.
+type ColumnInfo struct {
+	Name    string
+	Type    string // declared type; may be empty (SQLite allows typeless columns)
+	NotNull bool
+	Default sql.NullString
+	PK      int // 1-based position within the primary key, 0 if not part of it
+}
+
+// GetColumnInfo returns full column metadata for a table. GetColumns is the
+// cheaper variant when only names are needed.
+func GetColumnInfo(ctx context.Context, db *sql.DB, table string) ([]ColumnInfo, error) {
+	rows, err := on(db).QueryContext(ctx, qualifyPragma("table_info", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var cid, notNull int
+		var c ColumnInfo
+		if err := rows.Scan(&cid, &c.Name, &c.Type, &notNull, &c.Default, &c.PK); err != nil {
+			return nil, err
+		}
+		c.NotNull = notNull != 0
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
 // GetRows fetches up to `limit` rows from a table, returning rowids and all
-// values as strings. The rowid is selected separately so DELETE/UPDATE can
-// target the exact row regardless of primary key shape.
-func GetRows(db *sql.DB, table string, limit, offset int) ([]string, []int64, [][]string, error) {
-	rows, err := db.Query("SELECT rowid, * FROM "+quoteIdent(table)+" LIMIT ? OFFSET ?", limit, offset)
+// values as strings (BLOBs as a BlobLabel). The rowid is selected
+// separately so DELETE/UPDATE can target the exact row regardless of
+// primary key shape.
+func GetRows(ctx context.Context, db *sql.DB, table string, order Order, limit, offset int) ([]string, []int64, [][]string, error) {
+	rows, err := on(db).QueryContext(ctx, "SELECT rowid, * FROM "+quoteTable(table)+order.clause()+" LIMIT ? OFFSET ?", limit, offset)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -75,22 +208,103 @@ func GetRows(db *sql.DB, table string, limit, offset int) ([]string, []int64, []
 	return scanRowsWithRowID(rows)
 }
 
+// Order is how GetRows and FilterColumn list rows. The zero value keeps
+// the table's natural order.
+type Order struct {
+	NewestFirst bool      // by rowid, descending: the most recently inserted rows first
+	By          []SortKey // columns to sort by, in turn, before the rowid
+}
+
+// SortKey is a column rows are sorted by, and which way. Pos, counted
+// from 1, picks a query result's column by its position instead, since a
+// query's columns can share a name.
+type SortKey struct {
+	Column string
+	Desc   bool
+	Pos    int
+}
+
+func (o Order) clause() string {
+	if len(o.By) == 0 && !o.NewestFirst {
+		return ""
+	}
+	terms := make([]string, 0, len(o.By)+1)
+	for _, k := range o.By {
+		term := quoteIdent(k.Column)
+		if k.Desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+	// The rowid breaks ties, so rows keep their place from page to page.
+	if o.NewestFirst {
+		terms = append(terms, "rowid DESC")
+	} else {
+		terms = append(terms, "rowid")
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
 // ExecQuery runs an arbitrary SQL query and returns columns + string rows.
-// Intended for custom queries from the query popup.
-func ExecQuery(db *sql.DB, query string) ([]string, [][]string, error) {
-	rows, err := db.Query(query)
+// Intended for custom queries from the query popup. A timeout above zero
+// bounds the whole query, reading the rows included, so a runaway join
+// fails instead of running forever. args are bound to its parameters.
+// The stats tell how long it took and what it did. A query SQLite rejects
+// fails with a *QueryError. Within a transaction a query that may write is
+// neither timed out nor cancelled, since SQLite would roll the whole
+// transaction back, and one that ends the transaction is noticed.
+func ExecQuery(ctx context.Context, db *sql.DB, query string, timeout time.Duration, args ...any) ([]string, [][]string, QueryStats, error) {
+	if _, err := ViewQuery(query); err != nil && InTransaction(db) {
+		ctx, timeout = context.WithoutCancel(ctx), 0
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	// One connection, so that total_changes() counts this query's writes.
+	conn, release, err := reserve(ctx, db)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, QueryStats{}, err
 	}
-	defer rows.Close()
-	return scanRows(rows)
+	defer release()
+	before, err := connChanges(ctx, conn)
+	if err != nil {
+		return nil, nil, QueryStats{}, err
+	}
+
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query, args...)
+	var cols []string
+	var values [][]string
+	if err == nil {
+		cols, values, err = scanRows(rows)
+		rows.Close()
+	}
+	defer checkTx(db, err)
+	stats := QueryStats{Elapsed: time.Since(start), Rows: len(values)}
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, nil, stats, fmt.Errorf("query timed out after %s", timeout)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, nil, stats, err
+		}
+		return nil, nil, stats, queryError(ctx, conn, query, err)
+	}
+	if after, err := connChanges(ctx, conn); err == nil {
+		stats.Changes = after - before
+	}
+	stats.Plan = planSteps(ctx, conn, query, args)
+	return cols, values, stats, nil
 }
 
 // FilterColumn searches a table for rows where a single column matches the
-// query (case-insensitive LIKE). Single-column search is fast even on large tables.
-func FilterColumn(db *sql.DB, table, column, query string, limit, offset int) ([]string, []int64, [][]string, error) {
-	q := "SELECT rowid, * FROM " + quoteIdent(table) + " WHERE " + quoteIdent(column) + " LIKE ? COLLATE NOCASE LIMIT ? OFFSET ?"
-	rows, err := db.Query(q, "%"+query+"%", limit, offset)
+// query (case-insensitive LIKE, or fuzzily). Single-column search is fast even on large tables.
+func FilterColumn(ctx context.Context, db *sql.DB, table, column, query string, mode MatchMode, order Order, limit, offset int) ([]string, []int64, [][]string, error) {
+	cond, args := matchClause(column, query, mode)
+	q := "SELECT rowid, * FROM " + quoteTable(table) + " WHERE " + cond + order.clause() + " LIMIT ? OFFSET ?"
+	rows, err := on(db).QueryContext(ctx, q, append(args, limit, offset)...)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -98,25 +312,255 @@ func FilterColumn(db *sql.DB, table, column, query string, limit, offset int) ([
 	return scanRowsWithRowID(rows)
 }
 
+// FilterFTS is FilterColumn for FTS5 tables with MatchFTS, best matches
+// first unless order says otherwise. Besides the rows it returns, for each,
+// a snippet of column around the matched terms, which are set in «».
+func FilterFTS(ctx context.Context, db *sql.DB, table, column, query string, order Order, limit, offset int) ([]int64, [][]string, []string, error) {
+	cond, args := matchClause(column, query, MatchFTS)
+	orderBy := order.clause()
+	if orderBy == "" {
+		orderBy = " ORDER BY rank"
+	}
+	// With the column filter in cond, column -1 can only pick column. The
+	// table is named without its schema in snippet().
+	_, name := splitTable(table)
+	q := "SELECT rowid, *, snippet(" + quoteIdent(name) + ", -1, '«', '»', '…', 16) FROM " + quoteTable(table) +
+		" WHERE " + cond + orderBy + " LIMIT ? OFFSET ?"
+	rows, err := on(db).QueryContext(ctx, q, append(args, limit, offset)...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	_, rowIDs, data, err := scanRowsWithRowID(rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	snippets := make([]string, len(data))
+	for i, r := range data {
+		snippets[i] = r[len(r)-1]
+		data[i] = r[:len(r)-1]
+	}
+	return rowIDs, data, snippets, nil
+}
+
+// FilterQuery is the SELECT of every row FilterColumn or FilterFTS finds,
+// in the same order, and its arguments, for streaming them all out.
+func FilterQuery(table, column, query string, mode MatchMode, order Order) (string, []any) {
+	cond, args := matchClause(column, query, mode)
+	orderBy := order.clause()
+	if orderBy == "" && mode == MatchFTS {
+		orderBy = " ORDER BY rank"
+	}
+	return "SELECT * FROM " + quoteTable(table) + " WHERE " + cond + orderBy, args
+}
+
 // DeleteRow removes a single row from a table identified by its rowid.
 // Works for any default SQLite table (i.e., not declared WITHOUT ROWID).
-func DeleteRow(db *sql.DB, table string, rowid int64) error {
-	_, err := db.Exec("DELETE FROM "+quoteIdent(table)+" WHERE rowid = ?", rowid)
-	return err
+// The returned Edit holds the row's values, to put it back with UndoEdit.
+func DeleteRow(ctx context.Context, db *sql.DB, table string, rowid int64) (Edit, error) {
+	e := Edit{Op: "delete", Table: table, RowID: rowid}
+	err := inTx(ctx, db, func(tx execQuerier) error {
+		cols, err := storedColumns(ctx, tx, table)
+		if err != nil {
+			return err
+		}
+		values, err := rowValues(ctx, tx, table, rowid, cols)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+quoteTable(table)+" WHERE rowid = ?", rowid); err != nil {
+			return err
+		}
+		quoted := []string{"rowid"}
+		for _, c := range cols {
+			quoted = append(quoted, quoteIdent(c))
+		}
+		e.undo = "INSERT INTO " + quoteTable(table) + " (" + strings.Join(quoted, ", ") + ") VALUES (" + placeholders(len(quoted)) + ")"
+		e.args = append([]any{rowid}, values...)
+		return nil
+	})
+	return e, err
+}
+
+// EmptyTable deletes every row of table and returns how many there were.
+// With resetSequence, the table's AUTOINCREMENT counter in sqlite_sequence
+// goes too, so new rows are numbered from 1 again. Unlike DeleteRow, this
+// can't be undone but by rolling back a transaction it ran in.
+func EmptyTable(ctx context.Context, db *sql.DB, table string, resetSequence bool) (int64, error) {
+	var deleted int64
+	err := inTx(ctx, db, func(tx execQuerier) error {
+		res, err := tx.ExecContext(ctx, "DELETE FROM "+quoteTable(table))
+		if err != nil {
+			return err
+		}
+		if deleted, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		if resetSequence {
+			seq, name := sequenceTable(table)
+			_, err = tx.ExecContext(ctx, "DELETE FROM "+seq+" WHERE name = ?", name)
+		}
+		return err
+	})
+	return deleted, err
+}
+
+// HasSequence reports whether table has an AUTOINCREMENT counter in
+// sqlite_sequence, which EmptyTable can reset.
+func HasSequence(ctx context.Context, db *sql.DB, table string) bool {
+	seq, name := sequenceTable(table)
+	var n int
+	err := on(db).QueryRowContext(ctx, "SELECT count(*) FROM "+seq+" WHERE name = ?", name).Scan(&n)
+	return err == nil && n > 0
+}
+
+// sequenceTable returns the sqlite_sequence holding table's counter, and
+// the name table has in it.
+func sequenceTable(table string) (seq, name string) {
+	schema, name := splitTable(table)
+	if schema == "" {
+		return "sqlite_sequence", name
+	}
+	return quoteIdent(schema) + ".sqlite_sequence", name
+}
+
+// UpdateRow sets the given columns of the row with rowid in one UPDATE.
+// A nil value stores NULL; strings take the column's type affinity. The
+// returned Edit holds the columns' old values, to restore with UndoEdit.
+func UpdateRow(ctx context.Context, db *sql.DB, table string, rowid int64, columns []string, values []any) (Edit, error) {
+	e := Edit{Op: "update", Table: table, RowID: rowid}
+	err := inTx(ctx, db, func(tx execQuerier) error {
+		old, err := rowValues(ctx, tx, table, rowid, columns)
+		if err != nil {
+			return err
+		}
+		sets := make([]string, len(columns))
+		for i, c := range columns {
+			sets[i] = quoteIdent(c) + " = ?"
+		}
+		q := "UPDATE " + quoteTable(table) + " SET " + strings.Join(sets, ", ") + " WHERE rowid = ?"
+		if _, err := tx.ExecContext(ctx, q, append(values, rowid)...); err != nil {
+			return err
+		}
+		e.undo = q
+		e.args = append(old, rowid)
+		return nil
+	})
+	return e, err
+}
+
+// InsertRow inserts a single row, setting only the given columns (others
+// take their defaults). Returns the rowid of the new row, and the Edit
+// deleting it again with UndoEdit.
+func InsertRow(ctx context.Context, db *sql.DB, table string, columns []string, values []any) (int64, Edit, error) {
+	e, err := insertRow(ctx, on(db), table, columns, values)
+	return e.RowID, e, err
+}
+
+// InsertRows inserts rows, each holding the values of columns, all or none
+// of them. Returns the Edit deleting each new row again, in order.
+// Cancelling ctx stops it between rows, taking out those already in.
+func InsertRows(ctx context.Context, db *sql.DB, table string, columns []string, rows [][]any) ([]Edit, error) {
+	// Not interrupted mid-statement, so the rollback is done on return.
+	stop := ctx
+	ctx = context.WithoutCancel(ctx)
+	var edits []Edit
+	err := inTx(ctx, db, func(q execQuerier) error {
+		edits = nil
+		for i, values := range rows {
+			if err := stop.Err(); err != nil {
+				return err
+			}
+			e, err := insertRow(ctx, q, table, columns, values)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", i+1, err)
+			}
+			edits = append(edits, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edits, nil
+}
+
+// insertRow runs the INSERT of InsertRow on q.
+func insertRow(ctx context.Context, q execQuerier, table string, columns []string, values []any) (Edit, error) {
+	stmt := "INSERT INTO " + quoteTable(table) + " DEFAULT VALUES"
+	if len(columns) > 0 {
+		quoted := make([]string, len(columns))
+		for i, c := range columns {
+			quoted[i] = quoteIdent(c)
+		}
+		stmt = "INSERT INTO " + quoteTable(table) + " (" + strings.Join(quoted, ", ") + ") VALUES (" + placeholders(len(columns)) + ")"
+	}
+	res, err := q.ExecContext(ctx, stmt, values...)
+	if err != nil {
+		return Edit{}, err
+	}
+	rowid, err := res.LastInsertId()
+	if err != nil {
+		return Edit{}, err
+	}
+	return Edit{
+		Op:    "insert",
+		Table: table,
+		RowID: rowid,
+		undo:  "DELETE FROM " + quoteTable(table) + " WHERE rowid = ?",
+		args:  []any{rowid},
+	}, nil
 }
 
-// CountRows returns the total number of rows in a table.
-func CountRows(db *sql.DB, table string) (int, error) {
+// CountRows returns the total number of rows in a table. Cancelling ctx
+// interrupts a count that is taking too long.
+func CountRows(ctx context.Context, db *sql.DB, table string) (int, error) {
 	var count int
-	err := db.QueryRow("SELECT COUNT(*) FROM " + quoteIdent(table)).Scan(&count)
+	err := on(db).QueryRowContext(ctx, "SELECT COUNT(*) FROM "+quoteTable(table)).Scan(&count)
 	return count, err
 }
 
-// CountFilteredRows returns the number of rows matching a LIKE filter.
-func CountFilteredRows(db *sql.DB, table, column, query string) (int, error) {
+// IsFTS5 reports whether table is an FTS5 full-text index, which can be
+// searched with MatchFTS.
+func IsFTS5(ctx context.Context, db *sql.DB, table string) (bool, error) {
+	var fts bool
+	master, name := schemaMaster(table)
+	err := on(db).QueryRowContext(ctx,
+		"SELECT sql LIKE 'CREATE VIRTUAL TABLE%USING fts5%' FROM "+master+" WHERE type = 'table' AND name = ?", name,
+	).Scan(&fts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return fts, err
+}
+
+// EstimateRows guesses a table's row count from its largest rowid, an
+// O(log n) lookup where COUNT(*) scans the whole table. Deleted rows make
+// it an overestimate. Views and virtual tables, where MAX(rowid) may be a
+// scan itself, return an error, as do WITHOUT ROWID tables.
+func EstimateRows(ctx context.Context, db *sql.DB, table string) (int, error) {
+	var ordinary bool
+	master, name := schemaMaster(table)
+	err := on(db).QueryRowContext(ctx,
+		"SELECT type = 'table' AND sql NOT LIKE 'CREATE VIRTUAL%' FROM "+master+" WHERE name = ?", name,
+	).Scan(&ordinary)
+	if err != nil {
+		return 0, err
+	}
+	if !ordinary {
+		return 0, fmt.Errorf("%s: no rowid to estimate from", table)
+	}
+	var n sql.NullInt64
+	err = on(db).QueryRowContext(ctx, "SELECT MAX(rowid) FROM "+quoteTable(table)).Scan(&n)
+	return int(n.Int64), err
+}
+
+// CountFilteredRows returns the number of rows matching a filter.
+func CountFilteredRows(ctx context.Context, db *sql.DB, table, column, query string, mode MatchMode) (int, error) {
 	var count int
-	q := "SELECT COUNT(*) FROM " + quoteIdent(table) + " WHERE " + quoteIdent(column) + " LIKE ? COLLATE NOCASE"
-	err := db.QueryRow(q, "%"+query+"%").Scan(&count)
+	cond, args := matchClause(column, query, mode)
+	q := "SELECT COUNT(*) FROM " + quoteTable(table) + " WHERE " + cond
+	err := on(db).QueryRowContext(ctx, q, args...).Scan(&count)
 	return count, err
 }
 
@@ -153,13 +597,7 @@ func scanRowsWithRowID(rows *sql.Rows) ([]string, []int64, [][]string, error) {
 		rowids = append(rowids, rid)
 		row := make([]string, len(userCols))
 		for i, v := range values[1:] {
-			if v == nil {
-				row[i] = "NULL"
-			} else if b, ok := v.([]byte); ok {
-				row[i] = string(b)
-			} else {
-				row[i] = fmt.Sprintf("%v", v)
-			}
+			row[i] = scannedCell(v)
 		}
 		result = append(result, row)
 	}
@@ -167,7 +605,9 @@ func scanRowsWithRowID(rows *sql.Rows) ([]string, []int64, [][]string, error) {
 }
 
 // scanRows reads all rows from a *sql.Rows result set, returning column
-// names and all values as strings. Used by ExecQuery for arbitrary user queries.
+// names and all values as strings, with BLOBs as a BlobLabel and long TEXT
+// cut (see SetMaxValueLength). Used by
+// ExecQuery for arbitrary user queries.
 func scanRows(rows *sql.Rows) ([]string, [][]string, error) {
 	cols, err := rows.Columns()
 	if err != nil {
@@ -186,21 +626,41 @@ func scanRows(rows *sql.Rows) ([]string, [][]string, error) {
 		}
 		row := make([]string, len(cols))
 		for i, v := range values {
-			if v == nil {
-				row[i] = "NULL"
-			} else if b, ok := v.([]byte); ok {
-				row[i] = string(b)
-			} else {
-				row[i] = fmt.Sprintf("%v", v)
-			}
+			row[i] = scannedCell(v)
 		}
 		result = append(result, row)
 	}
 	return cols, result, rows.Err()
 }
 
+// cellString is how the row readers show a scanned value: NULL, a
+// BlobLabel for BLOBs, and the plain value otherwise.
+func cellString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return BlobLabel(len(v))
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// scannedCell is how the row readers show a value: its cellString, with
+// long TEXT cut by truncateText.
+func scannedCell(v any) string {
+	if s, ok := v.(string); ok {
+		return truncateText(s)
+	}
+	return cellString(v)
+}
+
 // quoteIdent wraps a table/column name in double quotes to prevent SQL injection.
 // Any embedded double quotes are doubled (standard SQL escaping).
 func quoteIdent(s string) string {
 	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
 }
+
+// QuoteIdent is quoteIdent for SQL put together outside this package.
+func QuoteIdent(s string) string {
+	return quoteIdent(s)
+}
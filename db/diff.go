@@ -0,0 +1,222 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ChangeKind is how a row differs between two copies of a table.
+type ChangeKind int
+
+const (
+	RowRemoved ChangeKind = iota // only in this database
+	RowAdded                     // only in the other
+	RowChanged                   // in both, with different values
+)
+
+// RowChange is a row that differs between two copies of a table. Values
+// are in the diff's Columns order: the other copy's for an added row, this
+// copy's otherwise, with Other holding the other copy's for a changed row.
+type RowChange struct {
+	Kind   ChangeKind
+	Key    []string
+	Values []string
+	Other  []string
+}
+
+// TableDiff compares a table with its copy in another database, row by
+// row by primary key.
+type TableDiff struct {
+	Key       []string // the columns rows are matched by; rowid without a primary key
+	Columns   []string // compared: those both copies have
+	OnlyHere  []string // columns only this copy has, not compared
+	OnlyThere []string // columns only the other copy has, not compared
+
+	Removed, Added, Changed, Same int
+	Changes                       []RowChange // removed rows, then added, then changed; at most limit of each
+}
+
+// DiffTable compares table with the table of the same name in the
+// database file at otherPath, which is attached for the purpose and only
+// read, much like the sqldiff utility. Rows are matched by the primary
+// key of this copy, or by rowid for a table without one — rowids VACUUM
+// may have renumbered in either copy.
+func DiffTable(ctx context.Context, db *sql.DB, otherPath, table string, limit int) (*TableDiff, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	// ATTACH creates a file that doesn't exist.
+	if _, err := os.Stat(otherPath); err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS sqlitui_other", otherPath); err != nil {
+		return nil, fmt.Errorf("opening %s: %w", otherPath, err)
+	}
+	defer func() {
+		// A connection still attached to the file mustn't go back to the pool.
+		if _, err := conn.ExecContext(context.Background(), "DETACH DATABASE sqlitui_other"); err != nil {
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	// A table of an attached database is compared with the table of its
+	// name in the other file.
+	schema, name := tableSchema(table)
+	here, hereKey, err := tableColumns(ctx, conn, schema, name)
+	if err != nil {
+		return nil, err
+	}
+	there, _, err := tableColumns(ctx, conn, "sqlitui_other", name)
+	if err != nil {
+		return nil, err
+	}
+	if len(there) == 0 {
+		return nil, fmt.Errorf("%s has no table %s", otherPath, name)
+	}
+
+	d := &TableDiff{Key: hereKey}
+	if len(d.Key) == 0 {
+		d.Key = []string{"rowid"}
+	}
+	for _, c := range here {
+		if slices.Contains(there, c) {
+			d.Columns = append(d.Columns, c)
+		} else {
+			d.OnlyHere = append(d.OnlyHere, c)
+		}
+	}
+	for _, c := range there {
+		if !slices.Contains(here, c) {
+			d.OnlyThere = append(d.OnlyThere, c)
+		}
+	}
+	for _, k := range d.Key {
+		if k != "rowid" && !slices.Contains(d.Columns, k) {
+			return nil, fmt.Errorf("the other %s has no %s column to match rows by", table, k)
+		}
+	}
+
+	t, local := quoteIdent(name), quoteIdent(schema)
+	var match, keys, differ []string
+	for _, k := range d.Key {
+		match = append(match, "a."+quoteIdent(k)+" IS b."+quoteIdent(k))
+	}
+	on := strings.Join(match, " AND ")
+	for _, k := range d.Key {
+		keys = append(keys, quoteIdent(k))
+	}
+	for _, c := range d.Columns {
+		differ = append(differ, "a."+quoteIdent(c)+" IS NOT b."+quoteIdent(c))
+	}
+	sel := func(alias string) string {
+		cols := make([]string, 0, len(d.Key)+len(d.Columns))
+		for _, c := range append(slices.Clone(d.Key), d.Columns...) {
+			cols = append(cols, alias+"."+quoteIdent(c))
+		}
+		return strings.Join(cols, ", ")
+	}
+	orderBy := " ORDER BY " + strings.Join(prefixed("a.", keys), ", ")
+
+	missing := func(from, other string) string {
+		return "SELECT " + sel("a") + " FROM " + from + "." + t + " a WHERE NOT EXISTS (SELECT 1 FROM " + other + "." + t + " b WHERE " + on + ")" + orderBy
+	}
+	if d.Removed, err = d.collect(ctx, conn, missing(local, "sqlitui_other"), RowRemoved, limit); err != nil {
+		return nil, err
+	}
+	if d.Added, err = d.collect(ctx, conn, missing("sqlitui_other", local), RowAdded, limit); err != nil {
+		return nil, err
+	}
+	if len(differ) > 0 {
+		q := "SELECT " + sel("a") + ", " + sel("b") + " FROM " + local + "." + t + " a JOIN sqlitui_other." + t + " b ON " + on +
+			" WHERE " + strings.Join(differ, " OR ") + orderBy
+		if d.Changed, err = d.collect(ctx, conn, q, RowChanged, limit); err != nil {
+			return nil, err
+		}
+	}
+	var both int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+local+"."+t+" a JOIN sqlitui_other."+t+" b ON "+on).Scan(&both); err != nil {
+		return nil, err
+	}
+	d.Same = both - d.Changed
+	return d, nil
+}
+
+// collect reads the rows of q as changes of kind, keeping the first limit
+// of them, and returns how many there are.
+func (d *TableDiff) collect(ctx context.Context, conn *sql.Conn, q string, kind ChangeKind, limit int) (int, error) {
+	rows, err := conn.QueryContext(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	n, width := 0, len(d.Key)+len(d.Columns)
+	for rows.Next() {
+		n++
+		if n > limit {
+			continue
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return 0, err
+		}
+		row := make([]string, len(cols))
+		for i, v := range values {
+			row[i] = scannedCell(v)
+		}
+		c := RowChange{Kind: kind, Key: row[:len(d.Key)], Values: row[len(d.Key):width]}
+		if kind == RowChanged {
+			c.Other = row[width+len(d.Key):]
+		}
+		d.Changes = append(d.Changes, c)
+	}
+	return n, rows.Err()
+}
+
+// tableColumns lists the columns of table in schema, and its primary key
+// columns in key order.
+func tableColumns(ctx context.Context, conn *sql.Conn, schema, table string) (cols, key []string, err error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name, pk FROM pragma_table_info(?, ?) ORDER BY cid", table, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	pk := map[int]string{}
+	for rows.Next() {
+		var name string
+		var pos int
+		if err := rows.Scan(&name, &pos); err != nil {
+			return nil, nil, err
+		}
+		cols = append(cols, name)
+		if pos > 0 {
+			pk[pos] = name
+		}
+	}
+	for i := 1; i <= len(pk); i++ {
+		key = append(key, pk[i])
+	}
+	return cols, key, rows.Err()
+}
+
+func prefixed(prefix string, names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = prefix + n
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+)
+
+// DumpObject is a table, index, view, or trigger of the main database
+// and the statement creating it.
+type DumpObject struct {
+	Type string // "table", "index", "view", or "trigger"
+	Name string
+	SQL  string
+}
+
+// dumpObjects returns the objects of the main database in an order their
+// statements can be run in to recreate it: tables, then indexes, views
+// (each after the views it reads from), and triggers, which may be on a
+// view. Within a kind they keep the order they were created in. SQLite's
+// own tables, the shadow tables of virtual tables, and the indexes behind
+// UNIQUE and PRIMARY KEY constraints are left out, since creating their
+// owners creates them too.
+func dumpObjects(ctx context.Context, q execQuerier) ([]DumpObject, error) {
+	rows, err := q.QueryContext(ctx,
+		"SELECT type, name, sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite\\_%' ESCAPE '\\' ORDER BY rowid",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kinds := map[string][]DumpObject{}
+	for rows.Next() {
+		var o DumpObject
+		if err := rows.Scan(&o.Type, &o.Name, &o.SQL); err != nil {
+			return nil, err
+		}
+		kinds[o.Type] = append(kinds[o.Type], o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	shadow, err := shadowTables(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var tables []DumpObject
+	for _, t := range kinds["table"] {
+		if !shadow[strings.ToLower(t.Name)] {
+			tables = append(tables, t)
+		}
+	}
+	objects := append(tables, kinds["index"]...)
+	objects = append(objects, viewOrder(kinds["view"])...)
+	return append(objects, kinds["trigger"]...), nil
+}
+
+// shadowTables returns the lowercased names of the main database's shadow
+// tables, those a virtual table keeps its data in and creates itself, like
+// an FTS5 table's V_data and V_idx. SQLite tells them apart by asking each
+// table's module, so a table merely named like one, as docs_archive is
+// next to an FTS5 table docs, is not among them.
+func shadowTables(ctx context.Context, q execQuerier) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'shadow'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	shadow := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		shadow[strings.ToLower(name)] = true
+	}
+	return shadow, rows.Err()
+}
+
+// viewOrder sorts views so that each follows the views its SELECT names,
+// and otherwise keeps their order.
+func viewOrder(views []DumpObject) []DumpObject {
+	index := map[string]int{}
+	for i, v := range views {
+		index[strings.ToLower(v.Name)] = i
+	}
+	ordered := make([]DumpObject, 0, len(views))
+	// 0 unvisited, 1 being placed, 2 placed; a cycle, which SQLite itself
+	// refuses, is broken where it is found.
+	state := make([]int, len(views))
+	var place func(i int)
+	place = func(i int) {
+		if state[i] != 0 {
+			return
+		}
+		state[i] = 1
+		for _, t := range lexSQL(views[i].SQL) {
+			if j, ok := index[strings.ToLower(unquoteIdent(t.text))]; ok && j != i {
+				place(j)
+			}
+		}
+		state[i] = 2
+		ordered = append(ordered, views[i])
+	}
+	for i := range views {
+		place(i)
+	}
+	return ordered
+}
+
+// DumpSchema writes the statements creating the main database's tables,
+// indexes, views, and triggers to w, tables first and views after those they read from, like
+// the .schema command of the sqlite3 shell. It returns what it wrote.
+// The schema and the shadow tables left out of it are read in one
+// transaction, so a table created in between is either dumped or not.
+func DumpSchema(ctx context.Context, db *sql.DB, w io.Writer) ([]DumpObject, error) {
+	var objects []DumpObject
+	err := inTx(ctx, db, func(q execQuerier) error {
+		var err error
+		objects, err = dumpObjects(ctx, q)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	for _, o := range objects {
+		bw.WriteString(o.SQL + ";\n")
+	}
+	return objects, bw.Flush()
+}
+
+// Dump writes the whole main database to w as SQL, like the .dump command
+// of the sqlite3 shell: each table followed by an INSERT per row, then the
+// indexes, views, and triggers, in one transaction with foreign key checks
+// off, so running the file in an empty database recreates this one. The
+// rows are read in one transaction too, for a consistent snapshot, and
+// streamed, so a database of any size can be dumped. progress, if non-nil,
+// is called periodically with the number of rows written so far.
+// Cancelling ctx stops the dump between rows. It returns the objects
+// dumped and the rows written.
+func Dump(ctx context.Context, db *sql.DB, w io.Writer, progress func(rows int)) ([]DumpObject, int, error) {
+	var objects []DumpObject
+	total := 0
+	err := inTx(ctx, db, func(q execQuerier) error {
+		var err error
+		if objects, err = dumpObjects(ctx, q); err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(w)
+		bw.WriteString("PRAGMA foreign_keys=OFF;\nBEGIN TRANSACTION;\n")
+		for _, o := range objects {
+			if o.Type != "table" {
+				continue
+			}
+			bw.WriteString(o.SQL + ";\n")
+			if err := dumpRows(ctx, q, o.Name, bw, &total, progress); err != nil {
+				return err
+			}
+		}
+		// The inserts bump the AUTOINCREMENT counters to the highest key,
+		// so the stored ones, which may be higher, are put back.
+		var sequence bool
+		if err := q.QueryRowContext(ctx,
+			"SELECT count(*) > 0 FROM sqlite_master WHERE name = 'sqlite_sequence'").Scan(&sequence); err != nil {
+			return err
+		}
+		if sequence {
+			bw.WriteString("DELETE FROM sqlite_sequence;\n")
+			var counters int
+			if err := dumpRows(ctx, q, "sqlite_sequence", bw, &counters, nil); err != nil {
+				return err
+			}
+		}
+		for _, o := range objects {
+			if o.Type != "table" {
+				bw.WriteString(o.SQL + ";\n")
+			}
+		}
+		bw.WriteString("COMMIT;\n")
+		return bw.Flush()
+	})
+	return objects, total, err
+}
+
+// dumpRows writes the INSERTs of table's rows to w, adding them to total.
+func dumpRows(ctx context.Context, q execQuerier, table string, w io.Writer, total *int, progress func(rows int)) error {
+	var opts ExportOptions
+	query, err := sqlExportQuery(ctx, q, table, &opts)
+	if err != nil {
+		return err
+	}
+	opts.rowsOnly = true
+	done := *total
+	n, err := exportRows(ctx, q, query, nil, FormatSQL, w, opts, func(rows int) {
+		if progress != nil {
+			progress(done + rows)
+		}
+	})
+	*total = done + n
+	return err
+}
@@ -0,0 +1,465 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatTSV  Format = "tsv"
+	FormatJSON Format = "json"
+	// FormatTable is aligned columns of text, for reading in a terminal.
+	// It holds every row until the end to size the columns, so it isn't
+	// one of the Formats offered for files.
+	FormatTable Format = "table"
+	// FormatSQL is the statements recreating a table: its CREATE TABLE
+	// and an INSERT per row. Only ExportTable writes it.
+	FormatSQL Format = "sql"
+)
+
+// Formats lists the supported export formats in display order.
+var Formats = []Format{FormatCSV, FormatTSV, FormatJSON}
+
+// progressEvery is how many rows are written between progress callbacks.
+const progressEvery = 500
+
+// ExportOptions controls where an export starts.
+type ExportOptions struct {
+	// SkipRows resumes an interrupted export: that many rows were already
+	// written, so the header/opening was too and only later rows follow.
+	SkipRows int
+	// Resume is set when appending to a file from an earlier, interrupted
+	// export. With SkipRows == 0 it means only the header was written.
+	Resume bool
+
+	// For FormatSQL, set by ExportTable: the table the rows go into and
+	// the statement creating it. rowsOnly, set by Dump, leaves out the
+	// CREATE statement and the transaction, writing just the INSERTs.
+	table, create string
+	rowsOnly      bool
+}
+
+// ExportTable streams every row of a table to w in the given format.
+// See ExportQuery for cancellation and the progress callback.
+func ExportTable(ctx context.Context, db *sql.DB, table string, format Format, w io.Writer, opts ExportOptions, progress func(rows int)) (int, error) {
+	q := "SELECT * FROM " + quoteTable(table)
+	if format == FormatSQL {
+		var err error
+		if q, err = sqlExportQuery(ctx, on(db), table, &opts); err != nil {
+			return opts.SkipRows, err
+		}
+	}
+	var args []any
+	if opts.SkipRows > 0 {
+		// Without ORDER BY the scan order is the table's storage order,
+		// which is stable as long as the table isn't modified in between.
+		q += " LIMIT -1 OFFSET ?"
+		args = append(args, opts.SkipRows)
+	}
+	return ExportQuery(ctx, db, q, args, format, w, opts, progress)
+}
+
+// ExportQuery runs query and streams the result to w row by row, so result
+// sets larger than memory can be exported. progress, if non-nil, is called
+// periodically with the total number of rows written so far (including
+// opts.SkipRows). Returns that total.
+//
+// Cancelling ctx stops the export between rows: every row written so far is
+// flushed complete, and the context's error is returned, so the output is
+// a valid prefix that a later call with ExportOptions can append to. Only
+// the closing of the format (e.g. JSON's "]") is missing.
+func ExportQuery(ctx context.Context, db *sql.DB, query string, args []any, format Format, w io.Writer, opts ExportOptions, progress func(rows int)) (int, error) {
+	return exportRows(ctx, on(db), query, args, format, w, opts, progress)
+}
+
+// exportRows is ExportQuery reading through q, so Dump can read every
+// table in one transaction.
+func exportRows(ctx context.Context, q execQuerier, query string, args []any, format Format, w io.Writer, opts ExportOptions, progress func(rows int)) (int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return opts.SkipRows, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var enc rowEncoder
+	switch format {
+	case FormatCSV:
+		enc = newCSVEncoder(w)
+	case FormatTSV:
+		enc = newTSVEncoder(w)
+	case FormatJSON:
+		enc = newJSONEncoder(w, opts.SkipRows == 0)
+	case FormatTable:
+		enc = &tableEncoder{w: w}
+	case FormatSQL:
+		if opts.table == "" {
+			return opts.SkipRows, errors.New("the sql format exports whole tables only")
+		}
+		enc = &sqlEncoder{w: bufio.NewWriter(w), table: opts.table, create: opts.create, rowsOnly: opts.rowsOnly}
+	default:
+		return opts.SkipRows, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	if err := enc.begin(cols, opts.Resume || opts.SkipRows > 0); err != nil {
+		return opts.SkipRows, err
+	}
+
+	n := opts.SkipRows
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return n, errors.Join(err, enc.flush())
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		if err := enc.row(values); err != nil {
+			return n, err
+		}
+		n++
+		if progress != nil && n%progressEvery == 0 {
+			progress(n)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, errors.Join(err, enc.flush())
+	}
+	if err := enc.end(); err != nil {
+		return n, err
+	}
+	if progress != nil {
+		progress(n)
+	}
+	return n, nil
+}
+
+// rowEncoder writes one export format. begin is called once with the column
+// names (resume means the header is already in the output), row once per
+// row with raw driver values, and end to close and flush. flush writes out
+// buffered rows without closing, for interrupted exports.
+type rowEncoder interface {
+	begin(cols []string, resume bool) error
+	row(values []any) error
+	flush() error
+	end() error
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+// newTSVEncoder writes tab-separated values. Fields holding a tab, quote,
+// or newline are quoted the way CSV quotes them.
+func newTSVEncoder(w io.Writer) *csvEncoder {
+	e := newCSVEncoder(w)
+	e.w.Comma = '\t'
+	return e
+}
+
+func (e *csvEncoder) begin(cols []string, resume bool) error {
+	e.record = make([]string, len(cols))
+	if resume {
+		return nil
+	}
+	return e.w.Write(cols)
+}
+
+// row writes NULL as an empty field; CSV has no way to tell them apart.
+func (e *csvEncoder) row(values []any) error {
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			e.record[i] = ""
+		case []byte:
+			e.record[i] = string(v)
+		default:
+			e.record[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	return e.w.Write(e.record)
+}
+
+func (e *csvEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) end() error {
+	return e.flush()
+}
+
+// jsonEncoder writes an array of objects, one per row, keeping the column
+// order of the result set (a map would sort the keys).
+type jsonEncoder struct {
+	w     *bufio.Writer
+	keys  [][]byte // pre-encoded column names
+	first bool
+}
+
+// newJSONEncoder creates a JSON encoder. first is false when resuming
+// after rows were already written, so the next row gets a leading comma.
+func newJSONEncoder(w io.Writer, first bool) *jsonEncoder {
+	return &jsonEncoder{w: bufio.NewWriter(w), first: first}
+}
+
+func (e *jsonEncoder) begin(cols []string, resume bool) error {
+	e.keys = make([][]byte, len(cols))
+	for i, c := range cols {
+		k, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = k
+	}
+	if resume {
+		return nil
+	}
+	_, err := e.w.WriteString("[\n")
+	return err
+}
+
+func (e *jsonEncoder) row(values []any) error {
+	if !e.first {
+		e.w.WriteString(",\n")
+	}
+	e.first = false
+	e.w.WriteString("  {")
+	for i, v := range values {
+		if i > 0 {
+			e.w.WriteString(", ")
+		}
+		e.w.Write(e.keys[i])
+		e.w.WriteString(": ")
+		// Text arrives as []byte for some column types; keep it a string
+		// when it's valid UTF-8 rather than letting json base64-encode it.
+		if b, ok := v.([]byte); ok && utf8.Valid(b) {
+			v = string(b)
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(val); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.WriteString("}")
+	return err
+}
+
+func (e *jsonEncoder) flush() error {
+	return e.w.Flush()
+}
+
+func (e *jsonEncoder) end() error {
+	if e.first {
+		e.w.WriteString("]\n")
+	} else {
+		e.w.WriteString("\n]\n")
+	}
+	return e.w.Flush()
+}
+
+// tableEncoder writes the rows in columns padded to their widest value,
+// under a header, values shown as the grid shows them.
+type tableEncoder struct {
+	w    io.Writer
+	cols []string
+	rows [][]string
+}
+
+func (e *tableEncoder) begin(cols []string, resume bool) error {
+	e.cols = cols
+	return nil
+}
+
+func (e *tableEncoder) row(values []any) error {
+	r := make([]string, len(values))
+	for i, v := range values {
+		r[i] = tableCellReplacer.Replace(cellString(v))
+	}
+	e.rows = append(e.rows, r)
+	return nil
+}
+
+// tableCellReplacer keeps each value on its line.
+var tableCellReplacer = strings.NewReplacer("\r\n", `\n`, "\n", `\n`, "\t", " ")
+
+// flush writes nothing: the columns can't be sized before the last row.
+func (e *tableEncoder) flush() error {
+	return nil
+}
+
+func (e *tableEncoder) end() error {
+	if len(e.cols) == 0 {
+		return nil // a statement returning no rows, like an UPDATE
+	}
+	widths := make([]int, len(e.cols))
+	for i, c := range e.cols {
+		widths[i] = runewidth.StringWidth(c)
+	}
+	for _, r := range e.rows {
+		for i, v := range r {
+			widths[i] = max(widths[i], runewidth.StringWidth(v))
+		}
+	}
+	w := bufio.NewWriter(e.w)
+	line := func(cells []string) {
+		var b strings.Builder
+		for i, c := range cells {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(runewidth.FillRight(c, widths[i]))
+		}
+		w.WriteString(strings.TrimRight(b.String(), " ") + "\n")
+	}
+	line(e.cols)
+	rule := make([]string, len(e.cols))
+	for i, n := range widths {
+		rule[i] = strings.Repeat("-", n)
+	}
+	line(rule)
+	for _, r := range e.rows {
+		line(r)
+	}
+	return w.Flush()
+}
+
+// sqlExportQuery looks up what FormatSQL needs to recreate table into
+// opts, returning the query reading its rows. A unary + reads each value
+// as stored, so DATETIME text isn't turned into a time and reformatted;
+// generated columns are left out, as an INSERT can't set them.
+func sqlExportQuery(ctx context.Context, db execQuerier, table string, opts *ExportOptions) (string, error) {
+	var kind string
+	master, name := schemaMaster(table)
+	err := db.QueryRowContext(ctx, "SELECT type, sql FROM "+master+" WHERE name = ?", name).Scan(&kind, &opts.create)
+	if err == sql.ErrNoRows || err == nil && kind != "table" {
+		return "", fmt.Errorf("%s is not a table", table)
+	}
+	if err != nil {
+		return "", err
+	}
+	cols, err := storedColumns(ctx, db, table)
+	if err != nil {
+		return "", err
+	}
+	exprs := make([]string, len(cols))
+	for i, c := range cols {
+		exprs[i] = "+" + quoteIdent(c) + " AS " + quoteIdent(c)
+	}
+	// The statements recreate the table under its own name, without the
+	// schema of an attached database.
+	opts.table = name
+	return "SELECT " + strings.Join(exprs, ", ") + " FROM " + quoteTable(table), nil
+}
+
+// sqlEncoder writes a table's rows as INSERT statements in a transaction,
+// after the table's CREATE statement.
+type sqlEncoder struct {
+	w             *bufio.Writer
+	table, create string
+	rowsOnly      bool
+	insert        string // the statement up to its values
+}
+
+func (e *sqlEncoder) begin(cols []string, resume bool) error {
+	quoted := make([]string, len(cols))
+	for i, c := range cols {
+		quoted[i] = quoteIdent(c)
+	}
+	e.insert = "INSERT INTO " + quoteIdent(e.table) + " (" + strings.Join(quoted, ", ") + ") VALUES ("
+	if resume || e.rowsOnly {
+		return nil
+	}
+	_, err := e.w.WriteString("BEGIN TRANSACTION;\n" + e.create + ";\n")
+	return err
+}
+
+func (e *sqlEncoder) row(values []any) error {
+	e.w.WriteString(e.insert)
+	for i, v := range values {
+		if i > 0 {
+			e.w.WriteString(", ")
+		}
+		e.w.WriteString(sqlLiteral(v))
+	}
+	_, err := e.w.WriteString(");\n")
+	return err
+}
+
+func (e *sqlEncoder) flush() error {
+	return e.w.Flush()
+}
+
+func (e *sqlEncoder) end() error {
+	if !e.rowsOnly {
+		e.w.WriteString("COMMIT;\n")
+	}
+	return e.w.Flush()
+}
+
+// sqlLiteral writes v as SQL that reads back as the same value and type.
+func sqlLiteral(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NULL"
+		case math.IsInf(v, 1):
+			return "1e999"
+		case math.IsInf(v, -1):
+			return "-1e999"
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0" // keep it a REAL
+		}
+		return s
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return "X'" + hex.EncodeToString(v) + "'"
+	case string:
+		return quoteLiteral(v)
+	}
+	return quoteLiteral(fmt.Sprint(v))
+}
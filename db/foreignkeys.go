@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// ForeignKey is one FOREIGN KEY constraint: From columns of Table refer to
+// To columns of RefTable. To is empty when the constraint names only the
+// table, which then means its primary key.
+type ForeignKey struct {
+	Table    string
+	From     []string
+	RefTable string
+	To       []string
+	OnUpdate string // NO ACTION unless declared otherwise
+	OnDelete string
+}
+
+// ForeignKeys lists the foreign keys of every table, by table and in the
+// order they were declared (SQLite numbers them backwards), from
+// PRAGMA foreign_key_list. Composite keys come as one ForeignKey. Those of
+// attached databases follow, their tables named "name.table" as ListTables
+// names them.
+func ForeignKeys(ctx context.Context, db *sql.DB) ([]ForeignKey, error) {
+	var fks []ForeignKey
+	for _, schema := range append([]string{""}, attachedSchemas(db)...) {
+		list, err := schemaForeignKeys(ctx, db, schema)
+		if err != nil {
+			return nil, err
+		}
+		fks = append(fks, list...)
+	}
+	return fks, nil
+}
+
+// schemaForeignKeys lists the foreign keys of one schema, "" for main. A
+// foreign key refers to a table of its own schema.
+func schemaForeignKeys(ctx context.Context, db *sql.DB, schema string) ([]ForeignKey, error) {
+	master, prefix, pragmaSchema := "sqlite_master", "", "main"
+	if schema != "" {
+		master, prefix, pragmaSchema = quoteIdent(schema)+".sqlite_master", schema+".", schema
+	}
+	rows, err := on(db).QueryContext(ctx,
+		`SELECT m.name, f.id, f."table", f."from", f."to", f.on_update, f.on_delete
+		FROM `+master+` AS m, pragma_foreign_key_list(m.name, ?) AS f
+		WHERE m.type = 'table'
+		ORDER BY m.name, f.id DESC, f.seq`, pragmaSchema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fks []ForeignKey
+	lastTable, lastID := "", -1
+	for rows.Next() {
+		var table, refTable, from, onUpdate, onDelete string
+		var to sql.NullString
+		var id int
+		if err := rows.Scan(&table, &id, &refTable, &from, &to, &onUpdate, &onDelete); err != nil {
+			return nil, err
+		}
+		if table != lastTable || id != lastID {
+			fks = append(fks, ForeignKey{Table: prefix + table, RefTable: prefix + refTable, OnUpdate: onUpdate, OnDelete: onDelete})
+			lastTable, lastID = table, id
+		}
+		fk := &fks[len(fks)-1]
+		fk.From = append(fk.From, from)
+		if to.Valid && to.String != "" {
+			fk.To = append(fk.To, to.String)
+		}
+	}
+	return fks, rows.Err()
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// sqlFunction is a Go-implemented scalar SQL function.
+type sqlFunction struct {
+	name          string
+	args          int32
+	deterministic bool
+	fn            func(*sqlite.FunctionContext, []driver.Value) (driver.Value, error)
+}
+
+// functionGroups are the optional helper functions, by the group names
+// RegisterFunctions takes. regexp() is not among them: the filter relies
+// on it, so it is always registered (see match.go).
+var functionGroups = []struct {
+	name  string
+	funcs []sqlFunction
+}{
+	{"uuid", []sqlFunction{
+		{name: "uuid", args: 0, fn: sqlUUID},
+	}},
+	{"base64", []sqlFunction{
+		{name: "base64_encode", args: 1, deterministic: true, fn: sqlBase64Encode},
+		{name: "base64_decode", args: 1, deterministic: true, fn: sqlBase64Decode},
+	}},
+	{"time", []sqlFunction{
+		{name: "unix_ms", args: 0, fn: sqlUnixMS},
+		{name: "from_unix", args: 1, deterministic: true, fn: sqlFromUnix},
+		{name: "to_unix", args: 1, deterministic: true, fn: sqlToUnix},
+	}},
+}
+
+// FunctionGroups lists the names accepted by RegisterFunctions.
+func FunctionGroups() []string {
+	names := make([]string, len(functionGroups))
+	for i, g := range functionGroups {
+		names[i] = g.name
+	}
+	return names
+}
+
+// RegisterFunctions makes the helper functions of the named groups
+// available to every connection opened afterwards, so they can be used
+// from the query popup. It must be called once, before the first Open.
+func RegisterFunctions(groups []string) error {
+	for _, name := range groups {
+		found := false
+		for _, g := range functionGroups {
+			if g.name != name {
+				continue
+			}
+			found = true
+			for _, f := range g.funcs {
+				register := sqlite.RegisterScalarFunction
+				if f.deterministic {
+					register = sqlite.RegisterDeterministicScalarFunction
+				}
+				if err := register(f.name, f.args, f.fn); err != nil {
+					return err
+				}
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown SQL function group %q (have %s)", name, strings.Join(FunctionGroups(), ", "))
+		}
+	}
+	return nil
+}
+
+// sqlUUID is uuid(): a random (version 4) UUID as text.
+func sqlUUID(_ *sqlite.FunctionContext, _ []driver.Value) (driver.Value, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// sqlBase64Encode is base64_encode(x): the standard base64 text of a BLOB,
+// or of the UTF-8 bytes of any other value.
+func sqlBase64Encode(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	b, ok := args[0].([]byte)
+	if !ok {
+		b = []byte(valueString(args[0]))
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// sqlBase64Decode is base64_decode(text): the BLOB it encodes, with or
+// without padding.
+func sqlBase64Decode(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	s := strings.TrimSpace(valueString(args[0]))
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		if b, err = base64.RawStdEncoding.DecodeString(s); err != nil {
+			return nil, fmt.Errorf("base64_decode: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// sqlUnixMS is unix_ms(): the current time in milliseconds since the epoch.
+func sqlUnixMS(_ *sqlite.FunctionContext, _ []driver.Value) (driver.Value, error) {
+	return time.Now().UnixMilli(), nil
+}
+
+// sqlFromUnix is from_unix(n): an epoch timestamp in seconds,
+// milliseconds, microseconds, or nanoseconds — told apart by magnitude —
+// as a UTC "YYYY-MM-DD HH:MM:SS" text like datetime() returns, with any
+// fraction of a second kept. It is NULL for values that are not numbers.
+func sqlFromUnix(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	var t time.Time
+	switch v := args[0].(type) {
+	case int64:
+		t = unixTime(v, 0)
+	case float64:
+		t = unixTime(0, v)
+	case string:
+		s := strings.TrimSpace(v)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			t = unixTime(n, 0)
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			t = unixTime(0, f)
+		} else {
+			return nil, nil
+		}
+	default:
+		return nil, nil
+	}
+	return t.UTC().Format("2006-01-02 15:04:05.999999999"), nil
+}
+
+// unixTime reads an epoch timestamp given as n, or as f when n is 0, in
+// the unit its magnitude suggests. Integers are converted exactly.
+func unixTime(n int64, f float64) time.Time {
+	a := math.Abs(f)
+	if n != 0 {
+		a = math.Abs(float64(n))
+	}
+	var perUnit int64 = 1e9 // nanoseconds per unit
+	switch {
+	case a >= 1e17:
+		perUnit = 1
+	case a >= 1e14:
+		perUnit = 1e3
+	case a >= 1e11:
+		perUnit = 1e6
+	}
+	if n != 0 {
+		return time.Unix(0, n*perUnit)
+	}
+	return time.Unix(0, int64(f*float64(perUnit)))
+}
+
+// timeLayouts are the date and time texts to_unix understands: SQLite's
+// own formats, with "T" or a space, and RFC 3339 with a zone.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// sqlToUnix is to_unix(text): the seconds since the epoch of a date and
+// time text, read as UTC unless it carries a zone. It is NULL for text it
+// cannot parse.
+func sqlToUnix(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	if args[0] == nil {
+		return nil, nil
+	}
+	s := strings.TrimSpace(valueString(args[0]))
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Unix(), nil
+		}
+	}
+	return nil, nil
+}
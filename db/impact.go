@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+)
+
+// Impact is an UPDATE or DELETE in a query and the rows it would change.
+type Impact struct {
+	Verb  string // UPDATE or DELETE
+	Table string
+	Rows  int64
+}
+
+// Impacts counts the rows each UPDATE and DELETE statement in query would
+// change, by running its WHERE clause in a SELECT COUNT(*) first. A
+// statement with a LIMIT is left out, as are rows changed by triggers and
+// foreign key actions. Every statement is counted before any of them run,
+// with args bound to its parameters in the order Params numbers them, so a
+// bare ? in the second statement takes the argument after the first's.
+func Impacts(ctx context.Context, db *sql.DB, query string, args ...any) ([]Impact, error) {
+	query, _ = Params(query)
+	var impacts []Impact
+	for _, stmt := range splitStatements(query) {
+		count, impact, ok := countQuery(stmt)
+		if !ok {
+			continue
+		}
+		if err := on(db).QueryRowContext(ctx, count, args...).Scan(&impact.Rows); err != nil {
+			return nil, err
+		}
+		impacts = append(impacts, impact)
+	}
+	return impacts, nil
+}
+
+// sqlToken is a word, quoted name, string, number, or punctuation at
+// src[start:end].
+type sqlToken struct {
+	start, end int
+	text       string
+}
+
+// word is the token in upper case if it is a bare word, else "".
+func (t sqlToken) word() string {
+	c := t.text[0]
+	if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+		return strings.ToUpper(t.text)
+	}
+	return ""
+}
+
+// lexSQL splits src into tokens, skipping comments and whitespace.
+func lexSQL(src string) []sqlToken {
+	var tokens []sqlToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+			continue
+		case c == '-' && strings.HasPrefix(src[i:], "--"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+			continue
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+			continue
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			i++
+			for i < len(src) {
+				if src[i] == closer {
+					// A doubled quote stands for itself.
+					if closer != ']' && i+1 < len(src) && src[i+1] == closer {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i = min(i+1, len(src))
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80:
+			for i < len(src) {
+				c := src[i]
+				if !(c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80) {
+					break
+				}
+				i++
+			}
+		default:
+			i++
+		}
+		tokens = append(tokens, sqlToken{start, i, src[start:i]})
+	}
+	return tokens
+}
+
+// topLevelTokens lexes src into the tokens of a statement that are inside
+// no parentheses. A group in parentheses is one token, whatever it holds.
+func topLevelTokens(src string) []sqlToken {
+	var tokens []sqlToken
+	depth, groupStart := 0, 0
+	for _, t := range lexSQL(src) {
+		switch t.text {
+		case "(":
+			if depth == 0 {
+				groupStart = t.start
+			}
+			depth++
+		case ")":
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					tokens = append(tokens, sqlToken{groupStart, t.end, src[groupStart:t.end]})
+				}
+			}
+		default:
+			if depth == 0 {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+	return tokens
+}
+
+// splitStatements splits src at the semicolons ending its statements,
+// leaving out empty ones.
+func splitStatements(src string) []string {
+	var stmts []string
+	for _, s := range scriptStatements(src) {
+		stmts = append(stmts, s.text)
+	}
+	return stmts
+}
+
+// sqlStatement is a statement of a script, without its semicolon, and the
+// offset in the script of its first token (of its text if it is all
+// comment).
+type sqlStatement struct {
+	text string
+	at   int
+}
+
+// scriptStatements splits src at the semicolons ending its statements,
+// leaving out empty ones. The semicolons in the BEGIN ... END body of a
+// CREATE TRIGGER end the statements of the body, not the trigger.
+func scriptStatements(src string) []sqlStatement {
+	var stmts []sqlStatement
+	from, first := 0, -1
+	var head []string // the first words of the statement
+	trigger, body := false, 0
+	end := func(at int) {
+		text := strings.TrimSpace(src[from:at])
+		if text != "" {
+			if first < 0 {
+				first = from + strings.Index(src[from:at], text)
+			}
+			stmts = append(stmts, sqlStatement{text, first})
+		}
+	}
+	for _, t := range topLevelTokens(src) {
+		if t.text == ";" && body == 0 {
+			end(t.start)
+			from, first, head, trigger = t.end, -1, head[:0], false
+			continue
+		}
+		if first < 0 {
+			first = t.start
+		}
+		w := t.word()
+		if len(head) < 3 {
+			head = append(head, w)
+			trigger = trigger || len(head) > 1 && head[0] == "CREATE" && w == "TRIGGER" &&
+				(len(head) == 2 || head[1] == "TEMP" || head[1] == "TEMPORARY")
+			continue
+		}
+		// A CASE in the body ends with an END too.
+		switch {
+		case trigger && (w == "BEGIN" || w == "CASE"):
+			body++
+		case trigger && w == "END" && body > 0:
+			body--
+		}
+	}
+	end(len(src))
+	return stmts
+}
+
+// countQuery turns an UPDATE or DELETE statement without a LIMIT into the
+// SELECT COUNT(*) of the rows it would change.
+func countQuery(stmt string) (string, Impact, bool) {
+	tokens := topLevelTokens(stmt)
+	// Skip a WITH clause, whose tables the count keeps.
+	verb := 0
+	if len(tokens) > 0 && tokens[0].word() == "WITH" {
+		verb = -1
+		for i, t := range tokens {
+			if w := t.word(); w == "UPDATE" || w == "DELETE" {
+				verb = i
+				break
+			}
+		}
+		if verb < 0 {
+			return "", Impact{}, false
+		}
+	}
+	if verb >= len(tokens) {
+		return "", Impact{}, false
+	}
+	impact := Impact{Verb: tokens[verb].word()}
+	if impact.Verb != "UPDATE" && impact.Verb != "DELETE" {
+		return "", Impact{}, false
+	}
+
+	// clause returns the text from token i up to the first of the words
+	// in stop, and where that is.
+	clause := func(i int, stop ...string) (string, int) {
+		j := i
+		for j < len(tokens) && !slices.Contains(stop, tokens[j].word()) {
+			j++
+		}
+		if i >= j {
+			return "", j
+		}
+		return stmt[tokens[i].start:tokens[j-1].end], j
+	}
+	for _, t := range tokens[verb:] {
+		if t.word() == "LIMIT" {
+			return "", Impact{}, false
+		}
+	}
+	ends := []string{"RETURNING", "ORDER", "LIMIT"}
+
+	var table, from, where string
+	i := verb + 1
+	if impact.Verb == "DELETE" {
+		if i >= len(tokens) || tokens[i].word() != "FROM" {
+			return "", Impact{}, false
+		}
+		table, i = clause(i+1, append([]string{"WHERE"}, ends...)...)
+	} else {
+		if i+1 < len(tokens) && tokens[i].word() == "OR" {
+			i += 2
+		}
+		table, i = clause(i, "SET")
+		if i >= len(tokens) {
+			return "", Impact{}, false
+		}
+		// Skip the assignments.
+		_, i = clause(i+1, append([]string{"FROM", "WHERE"}, ends...)...)
+		if i < len(tokens) && tokens[i].word() == "FROM" {
+			from, i = clause(i+1, append([]string{"WHERE"}, ends...)...)
+		}
+	}
+	if i < len(tokens) && tokens[i].word() == "WHERE" {
+		where, _ = clause(i+1, ends...)
+	}
+	if table == "" {
+		return "", Impact{}, false
+	}
+	impact.Table = tableName(table)
+
+	q := stmt[:tokens[verb].start] + "SELECT COUNT(*) FROM " + table
+	switch {
+	case from != "" && where != "":
+		q += " WHERE EXISTS (SELECT 1 FROM " + from + " WHERE " + where + ")"
+	case from != "":
+		q += " WHERE EXISTS (SELECT 1 FROM " + from + ")"
+	case where != "":
+		q += " WHERE " + where
+	}
+	return q, impact, true
+}
+
+// tableName is the name leading a qualified table, unquoted: "main.users"
+// from `"main"."users" AS u`.
+func tableName(spec string) string {
+	tokens := topLevelTokens(spec)
+	name := unquoteIdent(tokens[0].text)
+	if len(tokens) > 2 && tokens[1].text == "." {
+		name += "." + unquoteIdent(tokens[2].text)
+	}
+	return name
+}
+
+func unquoteIdent(s string) string {
+	if len(s) >= 2 {
+		switch s[0] {
+		case '"', '`':
+			return strings.ReplaceAll(s[1:len(s)-1], s[:1]+s[:1], s[:1])
+		case '[':
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
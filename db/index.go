@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// IndexName is the name CreateIndexSQL gives an index on columns of table
+// unless told another: idx_table_col1_col2.
+func IndexName(table string, columns []string) string {
+	_, name := splitTable(table)
+	return strings.Join(append([]string{"idx", name}, columns...), "_")
+}
+
+// CreateIndexSQL is the CREATE INDEX statement indexing columns of table,
+// in that order, as name. unique makes it a UNIQUE index, and a where
+// expression other than "" a partial one, holding only the rows it is true
+// for. An index of an attached database's table is made in its schema.
+func CreateIndexSQL(table, name string, columns []string, unique bool, where string) string {
+	schema, own := splitTable(table)
+	var b strings.Builder
+	b.WriteString("CREATE ")
+	if unique {
+		b.WriteString("UNIQUE ")
+	}
+	b.WriteString("INDEX ")
+	if schema != "" {
+		b.WriteString(quoteIdent(schema) + ".")
+	}
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = quoteIdent(c)
+	}
+	b.WriteString(quoteIdent(name) + " ON " + quoteIdent(own) + " (" + strings.Join(quoted, ", ") + ")")
+	if where = strings.TrimSpace(where); where != "" {
+		b.WriteString(" WHERE " + where)
+	}
+	return b.String()
+}
+
+// CreateIndex runs the statement CreateIndexSQL makes.
+func CreateIndex(ctx context.Context, db *sql.DB, table, name string, columns []string, unique bool, where string) error {
+	_, err := on(db).ExecContext(ctx, CreateIndexSQL(table, name, columns, unique, where))
+	return err
+}
+
+// IndexInfo describes one index of a table, as PRAGMA index_list and
+// index_info report it.
+type IndexInfo struct {
+	Name    string
+	Unique  bool
+	Partial bool
+	// Origin is how the index came about: "c" for CREATE INDEX, "u" for a
+	// UNIQUE constraint, "pk" for a PRIMARY KEY. Only "c" indexes can be
+	// dropped.
+	Origin  string
+	Columns []string // an expression's columns show as "<expr>"
+}
+
+// TableIndexes returns the indexes of table, ordered by name.
+func TableIndexes(ctx context.Context, db *sql.DB, table string) ([]IndexInfo, error) {
+	schema, name := tableSchema(table)
+	rows, err := on(db).QueryContext(ctx,
+		`SELECT name, "unique", origin, partial FROM pragma_index_list(?, ?) ORDER BY name`, name, schema)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []IndexInfo
+	for rows.Next() {
+		var ix IndexInfo
+		if err := rows.Scan(&ix.Name, &ix.Unique, &ix.Origin, &ix.Partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		indexes = append(indexes, ix)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		cols, err := on(db).QueryContext(ctx,
+			"SELECT name FROM pragma_index_info(?, ?) ORDER BY seqno", indexes[i].Name, schema)
+		if err != nil {
+			return nil, err
+		}
+		for cols.Next() {
+			var c sql.NullString
+			if err := cols.Scan(&c); err != nil {
+				cols.Close()
+				return nil, err
+			}
+			if !c.Valid {
+				c.String = "<expr>"
+			}
+			indexes[i].Columns = append(indexes[i].Columns, c.String)
+		}
+		cols.Close()
+		if err := cols.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return indexes, nil
+}
+
+// DropIndexSQL is the DROP INDEX statement dropping the index name of
+// table, in the table's schema.
+func DropIndexSQL(table, name string) string {
+	if schema, _ := splitTable(table); schema != "" {
+		return "DROP INDEX " + quoteIdent(schema) + "." + quoteIdent(name)
+	}
+	return "DROP INDEX " + quoteIdent(name)
+}
+
+// DropIndex drops the index name of table.
+func DropIndex(ctx context.Context, db *sql.DB, table, name string) error {
+	_, err := on(db).ExecContext(ctx, DropIndexSQL(table, name))
+	return err
+}
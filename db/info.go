@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+)
+
+// Info summarizes a database file and the SQLite library reading it.
+type Info struct {
+	FileSize      int64 // main file only, excluding any -wal/-journal
+	PageSize      int64
+	PageCount     int64
+	Encoding      string
+	JournalMode   string
+	SQLiteVersion string
+	SchemaVersion int64 // bumped by SQLite on every schema change
+}
+
+// DatabaseInfo collects Info for the database at path, open as db.
+func DatabaseInfo(ctx context.Context, db *sql.DB, path string) (Info, error) {
+	var info Info
+	if !IsRemote(path) {
+		st, err := os.Stat(LocalPath(path))
+		if err != nil {
+			return info, err
+		}
+		info.FileSize = st.Size()
+	}
+
+	queries := []struct {
+		query string
+		dest  any
+	}{
+		{"PRAGMA page_size", &info.PageSize},
+		{"PRAGMA page_count", &info.PageCount},
+		{"PRAGMA encoding", &info.Encoding},
+		{"PRAGMA journal_mode", &info.JournalMode},
+		{"PRAGMA schema_version", &info.SchemaVersion},
+		{"SELECT sqlite_version()", &info.SQLiteVersion},
+	}
+	for _, q := range queries {
+		if err := on(db).QueryRowContext(ctx, q.query).Scan(q.dest); err != nil {
+			return info, err
+		}
+	}
+	if IsRemote(path) {
+		info.FileSize = info.PageSize * info.PageCount
+	}
+	return info, nil
+}
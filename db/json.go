@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+)
+
+// JSONPath is a key found in the JSON documents of a column.
+type JSONPath struct {
+	Path  string   // as json_extract takes it, e.g. $.address.city
+	Types []string // json_type of its values: object, text, integer, ...
+	Rows  int64    // documents holding it
+}
+
+// JSONPaths lists the paths of the keys in column's JSON objects and
+// arrays, sorted, reading at most limit documents. Array elements are left
+// out, so a list of a thousand orders is one path rather than a thousand.
+// docs is how many documents were read.
+func JSONPaths(ctx context.Context, db *sql.DB, table, column string, limit int) (docs int64, paths []JSONPath, err error) {
+	c := quoteIdent(column)
+	sample := "SELECT " + c + " AS v FROM " + quoteTable(table) +
+		" WHERE CASE WHEN json_valid(" + c + ") THEN json_type(" + c + ") END IN ('object', 'array') LIMIT ?"
+	if err := on(db).QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+sample+")", limit).Scan(&docs); err != nil {
+		return 0, nil, err
+	}
+	rows, err := on(db).QueryContext(ctx,
+		"SELECT j.fullkey, group_concat(DISTINCT j.type), COUNT(*) FROM ("+sample+") t, json_tree(t.v) j "+
+			"WHERE j.fullkey <> '$' AND j.fullkey NOT LIKE '%[%' GROUP BY j.fullkey ORDER BY j.fullkey", limit)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p JSONPath
+		var types string
+		if err := rows.Scan(&p.Path, &types, &p.Rows); err != nil {
+			return 0, nil, err
+		}
+		p.Types = strings.Split(types, ",")
+		paths = append(paths, p)
+	}
+	return docs, paths, rows.Err()
+}
+
+// JSONProjectQuery selects the value at path in column as its own column,
+// labelled "column.path", ahead of the table's columns.
+func JSONProjectQuery(table, column, path string) string {
+	return "SELECT " + jsonExtract(column, path) + " AS " + quoteIdent(column+strings.TrimPrefix(path, "$")) +
+		", * FROM " + quoteTable(table)
+}
+
+// JSONFilterQuery selects the rows whose value at path in column equals
+// value: a number or true/false compares as one, null matches missing and
+// null values, anything else compares as text. An empty value selects the
+// rows that have the path at all.
+func JSONFilterQuery(table, column, path, value string) string {
+	cond := jsonExtract(column, path)
+	switch {
+	case value == "":
+		cond += " IS NOT NULL"
+	case value == "null":
+		cond += " IS NULL"
+	case value == "true":
+		cond += " = 1"
+	case value == "false":
+		cond += " = 0"
+	case jsonNumber.MatchString(value):
+		cond += " = " + value
+	default:
+		cond += " = " + quoteLiteral(value)
+	}
+	return "SELECT * FROM " + quoteTable(table) + " WHERE " + cond
+}
+
+// jsonNumber matches the numbers JSONFilterQuery compares as numbers,
+// which are pasted into the query as they are.
+var jsonNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+func jsonExtract(column, path string) string {
+	return "json_extract(" + quoteIdent(column) + ", " + quoteLiteral(path) + ")"
+}
+
+// quoteLiteral wraps s in single quotes as an SQL string literal, doubling
+// any embedded ones.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+)
+
+// TableKind is the section of the table list a table or view is shown in.
+type TableKind int
+
+const (
+	KindTable TableKind = iota
+	KindView
+	KindVirtual
+	// KindSystem is SQLite's own tables, like sqlite_master and
+	// sqlite_sequence, and the
+	// shadow tables a virtual table keeps its data in.
+	KindSystem
+)
+
+// ListEntry is a table or view as the table list shows it.
+type ListEntry struct {
+	Name   string
+	Kind   TableKind
+	Module string // a virtual table's module, like fts5 or rtree
+}
+
+// ListEntries returns the tables and views in the database, ordered by
+// name, sorted into their kinds by sqlite_master's type and, for virtual
+// tables, the module their CREATE statement names. sqlite_master, which
+// doesn't list itself, is added as a system table. Those of attached
+// databases follow, named "name.table" as ListTables names them.
+func ListEntries(ctx context.Context, db *sql.DB) ([]ListEntry, error) {
+	var entries []ListEntry
+	for _, schema := range append([]string{""}, attachedSchemas(db)...) {
+		list, err := schemaEntries(ctx, db, schema)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, list...)
+	}
+	return entries, nil
+}
+
+// schemaEntries lists the tables and views of one schema, "" for main.
+func schemaEntries(ctx context.Context, db *sql.DB, schema string) ([]ListEntry, error) {
+	master, prefix := "sqlite_master", ""
+	if schema != "" {
+		master, prefix = quoteIdent(schema)+".sqlite_master", schema+"."
+	}
+	rows, err := on(db).QueryContext(ctx,
+		"SELECT type, name, sql FROM "+master+" WHERE type IN ('table', 'view') ORDER BY name",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ListEntry
+	var virtual []string
+	for rows.Next() {
+		var typ, name string
+		var stmt sql.NullString
+		if err := rows.Scan(&typ, &name, &stmt); err != nil {
+			return nil, err
+		}
+		e := ListEntry{Name: prefix + name}
+		switch {
+		case typ == "view":
+			e.Kind = KindView
+		case strings.HasPrefix(strings.ToLower(name), "sqlite_"):
+			e.Kind = KindSystem
+		default:
+			if module, ok := virtualModule(stmt.String); ok {
+				e.Kind, e.Module = KindVirtual, module
+				virtual = append(virtual, name)
+			}
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// A virtual table V keeps its data in tables named V_something, like
+	// an FTS5 table's V_data and V_idx.
+	for i, e := range entries {
+		if e.Kind != KindTable {
+			continue
+		}
+		own := strings.ToLower(strings.TrimPrefix(e.Name, prefix))
+		for _, v := range virtual {
+			if strings.HasPrefix(own, strings.ToLower(v)+"_") {
+				entries[i].Kind = KindSystem
+				break
+			}
+		}
+	}
+
+	entries = append(entries, ListEntry{Name: prefix + "sqlite_master", Kind: KindSystem})
+	slices.SortStableFunc(entries, func(a, b ListEntry) int { return strings.Compare(a.Name, b.Name) })
+	return entries, nil
+}
+
+// virtualModule returns the module of a CREATE VIRTUAL TABLE statement.
+func virtualModule(stmt string) (string, bool) {
+	tokens := topLevelTokens(stmt)
+	if len(tokens) < 2 || tokens[0].word() != "CREATE" || tokens[1].word() != "VIRTUAL" {
+		return "", false
+	}
+	for i, t := range tokens {
+		if t.word() == "USING" && i+1 < len(tokens) {
+			return strings.ToLower(unquoteIdent(tokens[i+1].text)), true
+		}
+	}
+	return "", true
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// IntegrityCheck runs PRAGMA integrity_check, or the faster quick_check
+// that skips verifying index contents against their tables. A healthy
+// database yields the single finding "ok". Cancelling ctx interrupts the
+// check.
+func IntegrityCheck(ctx context.Context, db *sql.DB, quick bool) ([]string, error) {
+	pragma := "PRAGMA integrity_check"
+	if quick {
+		pragma = "PRAGMA quick_check"
+	}
+	rows, err := on(db).QueryContext(ctx, pragma)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var findings []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		findings = append(findings, line)
+	}
+	return findings, rows.Err()
+}
+
+// Vacuum rebuilds the database file, reclaiming free pages. In WAL mode the
+// rewritten pages land in the -wal file first, so it checkpoints afterwards
+// to let the main file shrink right away.
+func Vacuum(ctx context.Context, db *sql.DB) error {
+	if _, err := on(db).ExecContext(ctx, "VACUUM"); err != nil {
+		return err
+	}
+	_, err := on(db).ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)")
+	return err
+}
+
+// VacuumInto writes a compacted copy of the database to dest, which must
+// not already exist. The open database is left untouched.
+func VacuumInto(ctx context.Context, db *sql.DB, dest string) error {
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("%s already exists", dest)
+	}
+	_, err := on(db).ExecContext(ctx, "VACUUM INTO ?", dest)
+	return err
+}
+
+// Stat1 is one row of sqlite_stat1, the optimizer statistics written by
+// ANALYZE. Stat starts with the table's row count; for an index it goes on
+// with the average number of rows per distinct value of each key prefix.
+type Stat1 struct {
+	Table string
+	Index string // empty for a table without indexes
+	Stat  string
+}
+
+// Analyze gathers optimizer statistics for table, or for the whole
+// database when table is empty, and returns the resulting sqlite_stat1
+// rows for what was analyzed.
+func Analyze(ctx context.Context, db *sql.DB, table string) ([]Stat1, error) {
+	stmt := "ANALYZE"
+	if table != "" {
+		stmt += " " + quoteTable(table)
+	}
+	if _, err := on(db).ExecContext(ctx, stmt); err != nil {
+		return nil, err
+	}
+
+	// ANALYZE only creates sqlite_stat1 once it has something to record.
+	var exists bool
+	if err := on(db).QueryRowContext(ctx,
+		"SELECT count(*) > 0 FROM sqlite_master WHERE name = 'sqlite_stat1'").Scan(&exists); err != nil || !exists {
+		return nil, err
+	}
+	rows, err := on(db).QueryContext(ctx,
+		"SELECT tbl, coalesce(idx, ''), stat FROM sqlite_stat1 WHERE ?1 = '' OR tbl = ?1 COLLATE NOCASE ORDER BY tbl, idx", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []Stat1
+	for rows.Next() {
+		var s Stat1
+		if err := rows.Scan(&s.Table, &s.Index, &s.Stat); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,280 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite"
+)
+
+// MatchMode selects how a filter value is compared with a column.
+type MatchMode int
+
+const (
+	// MatchSubstring is a case-insensitive LIKE '%value%'.
+	MatchSubstring MatchMode = iota
+	// MatchFuzzy tolerates typos and gaps; see FuzzyMatch.
+	MatchFuzzy
+	// MatchFTS is an FTS5 full-text query (words, "phrases", prefix*,
+	// AND/OR/NOT), answered from the table's index. Only FTS5 tables
+	// support it; see IsFTS5.
+	MatchFTS
+	// MatchExact is equality with one value, as picked from the column's
+	// distinct values.
+	MatchExact
+	// MatchRegexp is a Go regular expression (RE2 syntax) found anywhere
+	// in the value, through the regexp() function registered below.
+	MatchRegexp
+)
+
+func (m MatchMode) String() string {
+	switch m {
+	case MatchFuzzy:
+		return "fuzzy"
+	case MatchFTS:
+		return "match"
+	case MatchExact:
+		return "exact"
+	case MatchRegexp:
+		return "regexp"
+	}
+	return "substring"
+}
+
+// ParseMatchMode is the MatchMode whose String is name, or MatchSubstring
+// when there is none.
+func ParseMatchMode(name string) MatchMode {
+	for _, m := range []MatchMode{MatchFuzzy, MatchFTS, MatchExact, MatchRegexp} {
+		if m.String() == name {
+			return m
+		}
+	}
+	return MatchSubstring
+}
+
+// matchClause returns the WHERE condition and its arguments for filtering
+// column by query.
+func matchClause(column, query string, mode MatchMode) (string, []any) {
+	switch mode {
+	case MatchFuzzy:
+		return "sqlitui_fuzzy(" + quoteIdent(column) + ", ?)", []any{query}
+	case MatchFTS:
+		// An FTS5 column on the left of MATCH restricts the query to it.
+		return quoteIdent(column) + " MATCH ?", []any{query}
+	case MatchExact:
+		// The value is the text the grid shows. A column without affinity
+		// compares it with TEXT only, so 42 must be given as a number too.
+		if n, ok := numericValue(query); ok {
+			return quoteIdent(column) + " IN (?, ?)", []any{n, query}
+		}
+		return quoteIdent(column) + " = ?", []any{query}
+	case MatchRegexp:
+		return quoteIdent(column) + " REGEXP ?", []any{query}
+	}
+	return quoteIdent(column) + " LIKE ? COLLATE NOCASE", []any{"%" + query + "%"}
+}
+
+// numericValue is the INTEGER or REAL s shows, if it shows one.
+func numericValue(s string) (any, bool) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f, true
+	}
+	return nil, false
+}
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("sqlitui_fuzzy", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if args[0] == nil || args[1] == nil {
+			return false, nil
+		}
+		return FuzzyMatch(valueString(args[0]), valueString(args[1])), nil
+	})
+	// SQLite parses "x REGEXP y" but leaves regexp(y, x) to the
+	// application; this makes it work in queries typed by the user too.
+	sqlite.MustRegisterDeterministicScalarFunction("regexp", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		if args[0] == nil || args[1] == nil {
+			return nil, nil
+		}
+		re, err := compileRegexp(valueString(args[0]))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(valueString(args[1])), nil
+	})
+}
+
+// regexpCache holds compiled patterns, since regexp() is called once per
+// row with the same one.
+var regexpCache struct {
+	sync.Mutex
+	m map[string]*regexp.Regexp
+}
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	regexpCache.Lock()
+	defer regexpCache.Unlock()
+	if re, ok := regexpCache.m[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	// Filtering as you type compiles every prefix of a pattern; start
+	// over rather than keep them all.
+	if len(regexpCache.m) >= 64 || regexpCache.m == nil {
+		regexpCache.m = make(map[string]*regexp.Regexp)
+	}
+	regexpCache.m[pattern] = re
+	return re, nil
+}
+
+func valueString(v driver.Value) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// FuzzyMatch reports whether query loosely occurs in value, ignoring case:
+// either its characters appear in order with gaps ("usrid" in "user_id"),
+// or some part of value is within a few edits of it ("jhon" in "John
+// Smith") — one edit per four characters, at least one from three up.
+func FuzzyMatch(value, query string) bool {
+	v := []rune(strings.ToLower(value))
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return true
+	}
+	if isSubsequence(q, v) {
+		return true
+	}
+	maxEdits := len(q) / 4
+	if len(q) >= 3 {
+		maxEdits = max(maxEdits, 1)
+	}
+	return maxEdits > 0 && substringDistance(q, v) <= maxEdits
+}
+
+// FuzzySpans returns the byte ranges of value FuzzyMatch finds query in:
+// where it occurs as typed, else its characters, each as far left as it
+// goes, or else the part of value closest to it. It returns nil if
+// FuzzyMatch doesn't match.
+func FuzzySpans(value, query string) [][2]int {
+	if query == "" || !FuzzyMatch(value, query) {
+		return nil
+	}
+	v := []rune(strings.ToLower(value))
+	q := []rune(strings.ToLower(query))
+	// ToLower keeps the number of runes, so v[i] is the i-th rune of value.
+	at := make([]int, 0, len(v)+1)
+	for i := range value {
+		at = append(at, i)
+	}
+	at = append(at, len(value))
+	if len(at) != len(v)+1 {
+		return nil
+	}
+	for i := 0; i+len(q) <= len(v); i++ {
+		if slices.Equal(v[i:i+len(q)], q) {
+			return [][2]int{{at[i], at[i+len(q)]}}
+		}
+	}
+	if isSubsequence(q, v) {
+		var spans [][2]int
+		for i, r := range v {
+			if len(spans) < len(q) && r == q[len(spans)] {
+				spans = append(spans, [2]int{at[i], at[i+1]})
+			}
+		}
+		return spans
+	}
+	start, end := closestSubstring(q, v)
+	return [][2]int{{at[start], at[end]}}
+}
+
+func isSubsequence(q, v []rune) bool {
+	i := 0
+	for _, r := range v {
+		if i < len(q) && r == q[i] {
+			i++
+		}
+	}
+	return i == len(q)
+}
+
+// substringDistance is the fewest edits (insertions, deletions,
+// substitutions, and swaps of adjacent characters) turning q into some
+// substring of v.
+func substringDistance(q, v []rune) int {
+	// d[i][j]: edits to match q[:i] ending at v[j-1]. Row 0 is all zeros
+	// because a match may start anywhere in v.
+	prev2 := make([]int, len(v)+1)
+	prev := make([]int, len(v)+1)
+	cur := make([]int, len(v)+1)
+	for i := 1; i <= len(q); i++ {
+		cur[0] = i
+		for j := 1; j <= len(v); j++ {
+			cost := 1
+			if q[i-1] == v[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && q[i-1] == v[j-2] && q[i-2] == v[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return slices.Min(prev)
+}
+
+// closestSubstring returns the runes v[start:end] that substringDistance
+// finds q closest to, the first of them if several are.
+func closestSubstring(q, v []rune) (start, end int) {
+	// As in substringDistance, with each cell's start in v alongside.
+	d := make([][]int, len(q)+1)
+	from := make([][]int, len(q)+1)
+	for i := range d {
+		d[i] = make([]int, len(v)+1)
+		from[i] = make([]int, len(v)+1)
+		d[i][0] = i
+	}
+	for j := range v {
+		from[0][j+1] = j + 1
+	}
+	for i := 1; i <= len(q); i++ {
+		for j := 1; j <= len(v); j++ {
+			cost := 1
+			if q[i-1] == v[j-1] {
+				cost = 0
+			}
+			d[i][j], from[i][j] = d[i-1][j-1]+cost, from[i-1][j-1]
+			if d[i-1][j]+1 < d[i][j] {
+				d[i][j], from[i][j] = d[i-1][j]+1, from[i-1][j]
+			}
+			if d[i][j-1]+1 < d[i][j] {
+				d[i][j], from[i][j] = d[i][j-1]+1, from[i][j-1]
+			}
+			if i > 1 && j > 1 && q[i-1] == v[j-2] && q[i-2] == v[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j], from[i][j] = d[i-2][j-2]+1, from[i-2][j-2]
+			}
+		}
+	}
+	end = 1
+	for j := 1; j <= len(v); j++ {
+		if d[len(q)][j] < d[len(q)][end] {
+			end = j
+		}
+	}
+	return min(from[len(q)][end], end), end
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Param is a placeholder in a query whose value is asked for before it
+// runs: "?1" for the first ? or for ?1, or a name like ":min" as written.
+type Param struct {
+	Name  string
+	index int // SQLite's number for it: ?NNN, or the next after the largest
+}
+
+// Params finds the placeholders in query — ?, ?NNN, :name, @name, and
+// $name, outside strings, quoted names, and comments — and returns them in
+// SQLite's order, each once, with query rewritten to number them ?1, ?2,
+// ... in that order. Its statements then bind the values of
+// BindParams(params, ...) alike, however many of them query holds.
+func Params(query string) (string, []Param) {
+	type use struct {
+		start, end, index int
+	}
+	var (
+		params []Param
+		uses   []use
+		named  = map[string]int{}
+		seen   = map[int]bool{}
+		last   int
+	)
+	tokens := lexSQL(query)
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		if len(t.text) != 1 || !strings.Contains("?:@$", t.text) {
+			continue
+		}
+		var next sqlToken
+		attached := i+1 < len(tokens) && tokens[i+1].start == t.end
+		if attached {
+			next = tokens[i+1]
+		}
+		index, end := 0, t.end
+		switch {
+		case t.text == "?" && attached && isDigits(next.text):
+			n, err := strconv.Atoi(next.text)
+			if err != nil || n == 0 {
+				continue
+			}
+			index, end = n, next.end
+			i++
+		case t.text == "?":
+			index = last + 1
+		case attached && next.word() != "" || attached && t.text == "$" && isDigits(next.text):
+			name := t.text + next.text
+			if n, ok := named[name]; ok {
+				index = n
+			} else {
+				index = last + 1
+				named[name] = index
+			}
+			end = next.end
+			i++
+		default:
+			continue
+		}
+		last = max(last, index)
+		uses = append(uses, use{t.start, end, index})
+		if !seen[index] {
+			seen[index] = true
+			name := query[t.start:end]
+			if t.text == "?" {
+				name = "?" + strconv.Itoa(index)
+			}
+			params = append(params, Param{Name: name, index: index})
+		}
+	}
+	if len(params) == 0 {
+		return query, nil
+	}
+
+	slices.SortFunc(params, func(a, b Param) int { return a.index - b.index })
+	position := make(map[int]int, len(params))
+	for i, p := range params {
+		position[p.index] = i + 1
+	}
+	var b strings.Builder
+	from := 0
+	for _, u := range uses {
+		b.WriteString(query[from:u.start])
+		b.WriteString("?" + strconv.Itoa(position[u.index]))
+		from = u.end
+	}
+	b.WriteString(query[from:])
+	return b.String(), params
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// BindParams turns the values typed for params, one each, into the
+// arguments of the query Params rewrote.
+func BindParams(params []Param, values []string) []any {
+	args := make([]any, len(params))
+	for i := range params {
+		if i < len(values) {
+			args[i] = ParamValue(values[i])
+		}
+	}
+	return args
+}
+
+// ParamValue reads a typed parameter value: NULL, an integer, a real, or a
+// string, in single quotes or not.
+func ParamValue(s string) any {
+	v := strings.TrimSpace(s)
+	if strings.EqualFold(v, "NULL") {
+		return nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && !strings.ContainsAny(v, "xXnN") {
+		return f
+	}
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+	}
+	return s
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// FullScan is a table that a query plan reads in full.
+type FullScan struct {
+	Table string
+	Rows  int64 // estimated from the table's largest rowid
+}
+
+// FullScans runs EXPLAIN QUERY PLAN for query and returns the tables it
+// would scan end to end whose estimated size exceeds threshold rows.
+// Plans name aliased tables by their alias; those, like CTEs and
+// subqueries, are skipped because their size can't be looked up. args
+// are bound to the query's parameters.
+func FullScans(ctx context.Context, db *sql.DB, query string, threshold int64, args ...any) ([]FullScan, error) {
+	rows, err := on(db).QueryContext(ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	seen := map[string]bool{}
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return nil, err
+		}
+		// "SCAN users" or "SCAN users USING COVERING INDEX idx" — both
+		// visit every row. SEARCH lines use an index lookup.
+		rest, ok := strings.CutPrefix(detail, "SCAN ")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, " ")
+		if name == "CONSTANT" || name == "SUBQUERY" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	var scans []FullScan
+	for _, name := range names {
+		// An estimate, since COUNT(*) would itself be the full scan
+		// we're warning about.
+		n, err := EstimateRows(ctx, db, name)
+		if err != nil {
+			continue
+		}
+		if int64(n) > threshold {
+			scans = append(scans, FullScan{Table: name, Rows: int64(n)})
+		}
+	}
+	return scans, nil
+}
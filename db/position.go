@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FindRow returns the rowid of the row of table whose primary key equals
+// value, or whose rowid does for a table without a primary key. Tables
+// with a primary key of several columns can't be searched this way.
+func FindRow(ctx context.Context, db *sql.DB, table, value string) (int64, error) {
+	cols, err := GetColumnInfo(ctx, db, table)
+	if err != nil {
+		return 0, err
+	}
+	var pk []string
+	for _, c := range cols {
+		if c.PK > 0 {
+			pk = append(pk, c.Name)
+		}
+	}
+	name := "rowid"
+	switch len(pk) {
+	case 0:
+	case 1:
+		name = pk[0]
+	default:
+		return 0, fmt.Errorf("%s has a primary key of several columns (%s)", table, strings.Join(pk, ", "))
+	}
+	// value is typed in as text; matched like a MatchExact filter, it
+	// finds the key as a number too.
+	cond, args := matchClause(name, value, MatchExact)
+	var rowid int64
+	q := "SELECT rowid FROM " + quoteTable(table) + " WHERE " + cond + " LIMIT 1"
+	if err := on(db).QueryRowContext(ctx, q, args...).Scan(&rowid); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("no row with %s = %s", name, value)
+		}
+		return 0, err
+	}
+	return rowid, nil
+}
+
+// RowOffset returns the position of the row with rowid among the rows
+// GetRows lists in order, or FilterColumn when column isn't empty. found
+// is false when the row isn't one of them. FTS5 matches, listed by rank,
+// have no position to find.
+func RowOffset(ctx context.Context, db *sql.DB, table string, rowid int64, column, query string, mode MatchMode, order Order) (offset int, found bool, err error) {
+	var where string
+	var args []any
+	if column != "" {
+		if mode == MatchFTS {
+			return 0, false, errors.New("rows can't be found by position among FTS5 matches")
+		}
+		var cond string
+		cond, args = matchClause(column, query, mode)
+		where = " AND " + cond
+	}
+	t := quoteTable(table)
+	if err := on(db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM "+t+" WHERE rowid = ?"+where+")",
+		append([]any{rowid}, args...)...).Scan(&found); err != nil || !found {
+		return 0, false, err
+	}
+	if len(order.By) > 0 {
+		// Rows sorted by their values have no rowid range to count; number
+		// them all in order instead.
+		q := "SELECT n FROM (SELECT rowid AS r, row_number() OVER (" + strings.TrimPrefix(order.clause(), " ") + ") - 1 AS n FROM " + t +
+			" WHERE 1" + where + ") WHERE r = ?"
+		err = on(db).QueryRowContext(ctx, q, append(args, rowid)...).Scan(&offset)
+		return offset, err == nil, err
+	}
+	// Without an ORDER BY, SQLite reads a table in rowid order.
+	before := "rowid < ?"
+	if order.NewestFirst {
+		before = "rowid > ?"
+	}
+	err = on(db).QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t+" WHERE "+before+where, append([]any{rowid}, args...)...).Scan(&offset)
+	return offset, err == nil, err
+}
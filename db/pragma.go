@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite/vfs"
+)
+
+// PragmaSpec describes a PRAGMA shown in the inspector.
+type PragmaSpec struct {
+	Name string
+	// Choices names the values of an enumerated pragma by index, e.g.
+	// synchronous 0..3 = OFF, NORMAL, FULL, EXTRA. Nil for plain numbers.
+	Choices []string
+	// Session pragmas only affect this process's connections and are safe
+	// to change; the others persist in the file and are read-only here.
+	Session bool
+	Doc     string
+}
+
+// Pragmas lists the inspected PRAGMAs, session-scoped ones first.
+var Pragmas = []PragmaSpec{
+	{Name: "foreign_keys", Choices: []string{"OFF", "ON"}, Session: true, Doc: "enforce FOREIGN KEY constraints"},
+	{Name: "synchronous", Choices: []string{"OFF", "NORMAL", "FULL", "EXTRA"}, Session: true, Doc: "how hard SQLite waits for writes to reach the disk"},
+	{Name: "cache_size", Session: true, Doc: "page cache size: pages if positive, KiB if negative"},
+	{Name: "temp_store", Choices: []string{"DEFAULT", "FILE", "MEMORY"}, Session: true, Doc: "where temporary tables and indexes live"},
+	{Name: "busy_timeout", Session: true, Doc: "milliseconds to wait for a lock before failing"},
+	{Name: "mmap_size", Session: true, Doc: "bytes of the file to memory-map (0 disables)"},
+	{Name: "recursive_triggers", Choices: []string{"OFF", "ON"}, Session: true, Doc: "let triggers fire other triggers recursively"},
+	{Name: "secure_delete", Choices: []string{"OFF", "ON", "FAST"}, Session: true, Doc: "overwrite deleted content with zeros"},
+	{Name: "automatic_index", Choices: []string{"OFF", "ON"}, Session: true, Doc: "build temporary indexes for unindexed joins"},
+	{Name: "wal_autocheckpoint", Session: true, Doc: "WAL pages before an automatic checkpoint"},
+	{Name: "journal_mode", Doc: "rollback journal or write-ahead log; persists for WAL"},
+	{Name: "auto_vacuum", Choices: []string{"NONE", "FULL", "INCREMENTAL"}, Doc: "reclaim free pages automatically; set before the first table"},
+	{Name: "user_version", Doc: "application-defined schema version"},
+	{Name: "application_id", Doc: "application-defined file type tag"},
+	{Name: "page_size", Doc: "bytes per database page"},
+	{Name: "freelist_count", Doc: "unused pages in the file"},
+	{Name: "query_only", Choices: []string{"OFF", "ON"}, Doc: "reject writes; set by read-only mode"},
+}
+
+// PragmaValue is a PRAGMA's current value on one connection.
+type PragmaValue struct {
+	PragmaSpec
+	Value string
+}
+
+// Label renders the value with its choice name, e.g. "2 (FULL)".
+func (v PragmaValue) Label() string {
+	if n, err := strconv.Atoi(v.Value); err == nil && n >= 0 && n < len(v.Choices) {
+		return fmt.Sprintf("%s (%s)", v.Value, v.Choices[n])
+	}
+	return v.Value
+}
+
+// ReadPragmas returns the current value of every inspected PRAGMA.
+func ReadPragmas(ctx context.Context, db *sql.DB) ([]PragmaValue, error) {
+	values := make([]PragmaValue, len(Pragmas))
+	for i, p := range Pragmas {
+		values[i].PragmaSpec = p
+		if err := on(db).QueryRowContext(ctx, "PRAGMA "+p.Name).Scan(&values[i].Value); err != nil {
+			return nil, fmt.Errorf("PRAGMA %s: %w", p.Name, err)
+		}
+	}
+	return values, nil
+}
+
+// Session pragmas are per connection, and database/sql pools several, so
+// a changed value is remembered per database and replayed on every
+// connection its pool opens afterwards. Two databases opened on the same
+// file keep their own.
+var (
+	sessionMu sync.Mutex
+	sessions  = map[*sql.DB]*session{}
+)
+
+// session holds the DSN a database was opened with by Open and the
+// pragmas set on it, as "name = value" statements.
+type session struct {
+	dsn     string
+	pragmas []string
+	remote  *vfs.FS // the VFS serving a remote database, closed with it
+}
+
+// sessionConnector opens the connections of a database opened by Open,
+// with the sqlite driver and its hooks, then replays the session's
+// pragmas on each.
+type sessionConnector struct {
+	driver  driver.Driver
+	dsn     string
+	session *session
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sessionMu.Lock()
+	stmts := slices.Clone(c.session.pragmas)
+	sessionMu.Unlock()
+	for _, stmt := range stmts {
+		if _, err := conn.(driver.ExecerContext).ExecContext(ctx, "PRAGMA "+stmt, nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (c sessionConnector) Driver() driver.Driver { return c.driver }
+
+// openSession opens a database on dsn whose connections replay the
+// pragmas SetPragma sets on it, until Close.
+func openSession(dsn string, remote *vfs.FS) (*sql.DB, error) {
+	// sql.Open connects lazily; it is only asked for the driver here.
+	probe, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+	s := &session{dsn: dsn, remote: remote}
+	database := sql.OpenDB(sessionConnector{driver: drv, dsn: dsn, session: s})
+	sessionMu.Lock()
+	sessions[database] = s
+	sessionMu.Unlock()
+	return database, nil
+}
+
+// Close closes a database opened by Open, forgets its session pragmas,
+// and unregisters the VFS of a remote one.
+func Close(db *sql.DB) error {
+	sessionMu.Lock()
+	s := sessions[db]
+	delete(sessions, db)
+	sessionMu.Unlock()
+	forgetTx(db)
+	detach(db)
+	err := db.Close()
+	if s != nil && s.remote != nil {
+		if cerr := s.remote.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// SetPragma changes a session-scoped PRAGMA for every connection of db.
+// value is a number or, for enumerated pragmas, one of the choice names.
+// Connections checked out at the time (like the change watcher's) keep
+// their old value until they are returned to the pool and replaced.
+func SetPragma(db *sql.DB, name, value string) error {
+	i := slices.IndexFunc(Pragmas, func(p PragmaSpec) bool { return p.Name == name })
+	if i < 0 || !Pragmas[i].Session {
+		return fmt.Errorf("%s can't be changed here", name)
+	}
+	spec := Pragmas[i]
+	value = strings.TrimSpace(value)
+	if c := slices.IndexFunc(spec.Choices, func(c string) bool { return strings.EqualFold(c, value) }); c >= 0 {
+		value = strconv.Itoa(c)
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		if len(spec.Choices) > 0 {
+			return fmt.Errorf("%s must be one of %s", name, strings.Join(spec.Choices, ", "))
+		}
+		return fmt.Errorf("%s must be a number", name)
+	}
+	if len(spec.Choices) > 0 && (n < 0 || n >= len(spec.Choices)) {
+		return fmt.Errorf("%s must be one of %s", name, strings.Join(spec.Choices, ", "))
+	}
+
+	if InTransaction(db) {
+		// The transaction's connection would keep the old value.
+		return fmt.Errorf("%s can't be changed during a transaction", name)
+	}
+	sessionMu.Lock()
+	s, ok := sessions[db]
+	if ok {
+		stmts := slices.DeleteFunc(s.pragmas, func(s string) bool { return strings.HasPrefix(s, name+" ") })
+		s.pragmas = append(stmts, fmt.Sprintf("%s = %d", name, n))
+	}
+	sessionMu.Unlock()
+	if !ok {
+		return fmt.Errorf("database was not opened by db.Open")
+	}
+
+	// Drop idle connections so the next query gets a fresh one with the
+	// new value applied by the connection hook, then restore database/sql's
+	// default idle limit.
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(2)
+	return nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// QueryStats is how a query run by ExecQuery went. SQLite's per-statement
+// VM counters aren't reachable through the driver, so Plan stands in for
+// them: how each table was read, and whether rows had to be sorted.
+type QueryStats struct {
+	Elapsed time.Duration // running the query and reading its rows
+	Rows    int           // returned
+	Changes int64         // inserted, updated, or deleted, triggers included
+	Plan    []string      // like "scan users", "search orders", "sort"
+}
+
+// connChanges is total_changes() on conn: the rows its statements have
+// changed since it opened.
+func connChanges(ctx context.Context, conn *sql.Conn) (int64, error) {
+	var n int64
+	err := conn.QueryRowContext(ctx, "SELECT total_changes()").Scan(&n)
+	return n, err
+}
+
+// planSteps sums up the query plans of query's statements after it ran:
+// the tables scanned in full and searched through an index, and the
+// temporary B-trees built to sort or group rows. A statement whose plan
+// can't be read any more, such as one for a dropped table, is left out.
+func planSteps(ctx context.Context, conn *sql.Conn, query string, args []any) []string {
+	var steps []string
+	for _, stmt := range splitStatements(query) {
+		rows, err := conn.QueryContext(ctx, "EXPLAIN QUERY PLAN "+stmt, args...)
+		if err != nil {
+			continue
+		}
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			if rows.Scan(&id, &parent, &notUsed, &detail) != nil {
+				break
+			}
+			if step := planStep(detail); step != "" {
+				steps = append(steps, step)
+			}
+		}
+		rows.Close()
+	}
+	return steps
+}
+
+// planStep shortens a query plan line: "SEARCH orders USING INDEX
+// idx_customer (customer_id=?)" to "search orders", "USE TEMP B-TREE FOR
+// ORDER BY" to "sort". Other lines come out as "".
+func planStep(detail string) string {
+	for _, verb := range []string{"SCAN ", "SEARCH "} {
+		if rest, ok := strings.CutPrefix(detail, verb); ok {
+			name, _, _ := strings.Cut(rest, " ")
+			if name == "CONSTANT" {
+				return ""
+			}
+			return strings.ToLower(verb) + name
+		}
+	}
+	if strings.HasPrefix(detail, "USE TEMP B-TREE") {
+		return "sort"
+	}
+	return ""
+}
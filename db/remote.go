@@ -0,0 +1,292 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite/vfs"
+)
+
+const (
+	// remoteBlockSize is how much of a remote file one range request
+	// reads: many pages at once, since scans read pages in order.
+	remoteBlockSize = 64 << 10
+	// remoteCacheBlocks is how many blocks a remote database keeps, the
+	// least recently read dropped first: 32 MiB.
+	remoteCacheBlocks = 512
+	// remoteFileName is the name SQLite opens a remote database under;
+	// its VFS serves the URL for it and nothing else, so no journal or
+	// WAL is found.
+	remoteFileName = "remote.db"
+)
+
+// remoteClient fetches the blocks of remote databases.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// IsRemote reports whether path is an http:// or https:// URL, which Open
+// reads with range requests instead of as a local file.
+func IsRemote(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// openRemote registers a VFS reading the database at rawURL through HTTP
+// range requests, returning the DSN that opens it read-only and the VFS,
+// to be closed once the database is. The server must answer a range
+// request with its part of the file; the file is taken not to change while
+// it is open.
+func openRemote(rawURL string) (string, *vfs.FS, error) {
+	f := &httpFS{url: rawURL}
+	size, err := f.probe()
+	if err != nil {
+		return "", nil, err
+	}
+	f.size = size
+	name, fsys, err := vfs.New(f)
+	if err != nil {
+		return "", nil, err
+	}
+	params := url.Values{}
+	params.Add("vfs", name)
+	params.Add("_pragma", "query_only(1)")
+	return remoteFileName + "?" + params.Encode(), fsys, nil
+}
+
+// httpFS is the file system of one remote database, an fs.FS holding the
+// file at url. Its files share a cache of the blocks read, dropped once
+// the last of them is closed.
+type httpFS struct {
+	url  string
+	size int64
+
+	mu     sync.Mutex
+	open   int              // files not yet closed
+	blocks map[int64][]byte // by block number
+	recent []int64          // cached block numbers, least recently read first
+}
+
+func (f *httpFS) Open(name string) (fs.File, error) {
+	if !strings.HasSuffix(name, remoteFileName) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	f.mu.Lock()
+	f.open++
+	f.mu.Unlock()
+	return &httpFile{fs: f}, nil
+}
+
+// probe checks that the server answers range requests and returns the
+// size of the file, which the answer to one for its first byte tells.
+func (f *httpFS) probe() (int64, error) {
+	resp, err := f.get(0, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return 0, fmt.Errorf("%s: the server doesn't answer range requests", redactURL(f.url))
+	}
+	// Content-Range: bytes 0-0/12345
+	_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	size, err := strconv.ParseInt(total, 10, 64)
+	if !ok || err != nil {
+		return 0, fmt.Errorf("%s: the server doesn't tell the file's size", redactURL(f.url))
+	}
+	return size, nil
+}
+
+// get requests bytes from through to of the file, reporting any answer
+// but a partial or whole file as an error.
+func (f *httpFS) get(from, to int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", from, to))
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		// The error repeats the URL, which may carry a signature.
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return nil, fmt.Errorf("%s: %w", redactURL(f.url), err)
+	}
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", redactURL(f.url), resp.Status)
+	}
+	return resp, nil
+}
+
+// block returns block n of the file, from the cache or the server. The
+// lock isn't held while fetching, so connections reading blocks already
+// cached don't wait on another's request.
+func (f *httpFS) block(n int64) ([]byte, error) {
+	f.mu.Lock()
+	if b, ok := f.blocks[n]; ok {
+		i := slices.Index(f.recent, n)
+		f.recent = append(slices.Delete(f.recent, i, i+1), n)
+		f.mu.Unlock()
+		return b, nil
+	}
+	f.mu.Unlock()
+
+	b, err := f.fetch(n)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// Another connection may have fetched it meanwhile, or closed the
+	// last file.
+	if _, ok := f.blocks[n]; ok || f.open == 0 {
+		return b, nil
+	}
+	if f.blocks == nil {
+		f.blocks = map[int64][]byte{}
+	}
+	if len(f.recent) >= remoteCacheBlocks {
+		delete(f.blocks, f.recent[0])
+		f.recent = f.recent[1:]
+	}
+	f.blocks[n] = b
+	f.recent = append(f.recent, n)
+	return b, nil
+}
+
+// fetch reads block n of the file from the server.
+func (f *httpFS) fetch(n int64) ([]byte, error) {
+	from := n * remoteBlockSize
+	to := min(from+remoteBlockSize, f.size) - 1
+	resp, err := f.get(from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("%s: the server stopped answering range requests", redactURL(f.url))
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) != to-from+1 {
+		return nil, fmt.Errorf("%s: got %d bytes at %d, not %d", redactURL(f.url), len(b), from, to-from+1)
+	}
+	return b, nil
+}
+
+// readAt fills p from offset off, short only at the end of the file.
+func (f *httpFS) readAt(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) && off < f.size {
+		b, err := f.block(off / remoteBlockSize)
+		if err != nil {
+			return n, err
+		}
+		c := copy(p[n:], b[off%remoteBlockSize:])
+		n += c
+		off += int64(c)
+	}
+	return n, nil
+}
+
+// closed drops the cache with the last open file.
+func (f *httpFS) closed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.open--; f.open == 0 {
+		f.blocks, f.recent = nil, nil
+	}
+}
+
+// httpFile is the remote database as an fs.File that can seek, as the
+// VFS reads it.
+type httpFile struct {
+	fs  *httpFS
+	off int64
+}
+
+func (f *httpFile) Stat() (fs.FileInfo, error) {
+	return remoteFileInfo{size: f.fs.size}, nil
+}
+
+func (f *httpFile) Read(p []byte) (int, error) {
+	n, err := f.fs.readAt(p, f.off)
+	f.off += int64(n)
+	if n == 0 && err == nil && len(p) > 0 {
+		return 0, io.EOF
+	}
+	return n, err
+}
+
+func (f *httpFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.fs.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("seek to %d", offset)
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *httpFile) Close() error {
+	f.fs.closed()
+	return nil
+}
+
+type remoteFileInfo struct {
+	size int64
+}
+
+func (i remoteFileInfo) Name() string       { return remoteFileName }
+func (i remoteFileInfo) Size() int64        { return i.size }
+func (i remoteFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (i remoteFileInfo) ModTime() time.Time { return time.Time{} }
+func (i remoteFileInfo) IsDir() bool        { return false }
+func (i remoteFileInfo) Sys() any           { return nil }
+
+// redactURL drops the query string and any credentials from a URL for
+// messages, since a signed URL's query is a secret.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "remote database"
+	}
+	u.RawQuery, u.User, u.Fragment = "", nil, ""
+	return u.String()
+}
+
+// DisplayPath is path as shown to the user: a remote database's URL
+// without its query string or credentials.
+func DisplayPath(path string) string {
+	if IsRemote(path) {
+		return redactURL(path)
+	}
+	return path
+}
+
+// BaseName is the file name of a database path or URL.
+func BaseName(path string) string {
+	if IsRemote(path) {
+		if u, err := url.Parse(path); err == nil {
+			return pathpkg.Base(u.Path)
+		}
+	}
+	return filepath.Base(path)
+}
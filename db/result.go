@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resultName is what a query is called in the queries built around it.
+const resultName = "sqlitui_result"
+
+// ResultFilter keeps the rows of a query result whose column numbered
+// Column, from 0, matches Query the way Mode does for FilterColumn. With
+// no Query it keeps every row.
+type ResultFilter struct {
+	Column int
+	Query  string
+	Mode   MatchMode
+}
+
+// resultQuery returns query, a single SELECT whose result has columns,
+// with its rows filtered by f and sorted by the keys, and the arguments
+// the filter adds after the query's own. The query's columns are numbered
+// in a CTE, so they go by position, since they can share a name as the
+// ids of a join do, and are named back after it, since SQLite would call
+// the second id "id:1".
+func resultQuery(query string, columns []string, by []SortKey, f ResultFilter) (string, []any, error) {
+	stmt, err := ViewQuery(query)
+	if err != nil {
+		return "", nil, err
+	}
+	numbered := make([]string, len(columns))
+	named := make([]string, len(columns))
+	for i, c := range columns {
+		numbered[i] = "c" + strconv.Itoa(i+1)
+		named[i] = numbered[i] + " AS " + quoteIdent(c)
+	}
+	// On lines of their own, so a comment ending the query ends there.
+	q := "WITH " + resultName + "(" + strings.Join(numbered, ", ") + ") AS (\n" + stmt + "\n) SELECT " +
+		strings.Join(named, ", ") + " FROM " + resultName
+	var args []any
+	if f.Query != "" {
+		if f.Column < 0 || f.Column >= len(columns) {
+			return "", nil, fmt.Errorf("no column %d in the query result", f.Column+1)
+		}
+		var cond string
+		cond, args = matchClause(numbered[f.Column], f.Query, f.Mode)
+		q += " WHERE " + cond
+	}
+	if len(by) > 0 {
+		terms := make([]string, 0, len(by))
+		for _, k := range by {
+			pos := k.Pos
+			if pos == 0 {
+				pos = slices.Index(columns, k.Column) + 1
+			}
+			if pos < 1 || pos > len(columns) {
+				return "", nil, fmt.Errorf("no column %s in the query result", k.Column)
+			}
+			term := strconv.Itoa(pos)
+			if k.Desc {
+				term += " DESC"
+			}
+			terms = append(terms, term)
+		}
+		q += " ORDER BY " + strings.Join(terms, ", ")
+	}
+	return q, args, nil
+}
+
+// SortedQuery returns query, a single SELECT, with its rows sorted by the
+// keys, columns being the columns of its result, which keep their names.
+func SortedQuery(query string, columns []string, by []SortKey) (string, error) {
+	if len(by) == 0 {
+		return ViewQuery(query)
+	}
+	q, _, err := resultQuery(query, columns, by, ResultFilter{})
+	return q, err
+}
+
+// ResultPage reads up to limit rows of query, a single SELECT, from offset
+// on, filtered by f and sorted by the keys as resultQuery has them. args
+// are bound to the query's parameters and timeout bounds it, as for
+// ExecQuery. With no columns known yet, and so no filter or sort, the
+// rows are read as SQLite names the query's columns.
+func ResultPage(ctx context.Context, db *sql.DB, query string, args []any, timeout time.Duration, columns []string, by []SortKey, f ResultFilter, limit, offset int) ([]string, [][]string, QueryStats, error) {
+	var q string
+	var fargs []any
+	if columns == nil {
+		stmt, err := ViewQuery(query)
+		if err != nil {
+			return nil, nil, QueryStats{}, err
+		}
+		q = "SELECT * FROM (\n" + stmt + "\n)"
+	} else {
+		var err error
+		if q, fargs, err = resultQuery(query, columns, by, f); err != nil {
+			return nil, nil, QueryStats{}, err
+		}
+	}
+	args = append(slices.Clone(args), fargs...)
+	return ExecQuery(ctx, db, q+" LIMIT ? OFFSET ?", timeout, append(args, limit, offset)...)
+}
+
+// CountResult counts the rows of query, a single SELECT whose result has
+// columns, that f keeps, with args bound to its parameters.
+func CountResult(ctx context.Context, db *sql.DB, query string, args []any, columns []string, f ResultFilter) (int, error) {
+	q, fargs, err := resultQuery(query, columns, nil, f)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = on(db).QueryRowContext(ctx, "SELECT COUNT(*) FROM (\n"+q+"\n)", append(slices.Clone(args), fargs...)...).Scan(&count)
+	return count, err
+}
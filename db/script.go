@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ScriptStatement is a statement of a script run by RunScript and what it
+// did.
+type ScriptStatement struct {
+	Line    int // where it starts in the script, from 1
+	SQL     string
+	Changes int64 // rows an INSERT, UPDATE, or DELETE changed
+	Skipped bool  // a BEGIN or COMMIT, which the script's savepoint stands in for
+	Err     error
+}
+
+// RunScript runs the statements of script in order under one savepoint,
+// so that if one fails none of them take effect, inside an open
+// transaction too. The script's own BEGIN and COMMIT statements, as a dump
+// has, are skipped, and foreign keys are checked at the end rather than
+// after each statement, so rows may come in any order. It returns the
+// statements run, up to and including one that failed, whose line the
+// error names.
+func RunScript(ctx context.Context, db *sql.DB, script string) ([]ScriptStatement, error) {
+	if InTransaction(db) {
+		// Interrupted, it would roll the whole transaction back.
+		ctx = context.WithoutCancel(ctx)
+	}
+	conn, release, err := reserve(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if _, err := conn.ExecContext(ctx, "SAVEPOINT sqlitui_script"); err != nil {
+		return nil, err
+	}
+	run, err := runScript(ctx, conn, script)
+	if err == nil {
+		// Releasing the outermost savepoint commits, checking the
+		// deferred foreign keys.
+		_, err = conn.ExecContext(ctx, "RELEASE sqlitui_script")
+	}
+	if err != nil {
+		conn.ExecContext(context.Background(), "ROLLBACK TO sqlitui_script")
+		conn.ExecContext(context.Background(), "RELEASE sqlitui_script")
+	}
+	checkTx(db, err)
+	return run, err
+}
+
+// runScript runs the statements of RunScript inside its savepoint.
+func runScript(ctx context.Context, conn *sql.Conn, script string) ([]ScriptStatement, error) {
+	if _, err := conn.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+		return nil, err
+	}
+	var run []ScriptStatement
+	for _, s := range scriptStatements(script) {
+		tokens := topLevelTokens(s.text)
+		if len(tokens) == 0 {
+			continue // just a comment
+		}
+		stmt := ScriptStatement{Line: 1 + strings.Count(script[:s.at], "\n"), SQL: s.text}
+		switch verb := tokens[0].word(); verb {
+		case "BEGIN", "COMMIT", "END":
+			stmt.Skipped = true
+		case "ROLLBACK":
+			if len(tokens) < 2 || tokens[1].word() != "TO" {
+				stmt.Err = fmt.Errorf("%s would end the savepoint the script runs under", tokens[0].text)
+				break
+			}
+			fallthrough
+		default:
+			res, err := conn.ExecContext(ctx, s.text)
+			stmt.Err = err
+			if err == nil && (verb == "INSERT" || verb == "REPLACE" || verb == "UPDATE" || verb == "DELETE") {
+				stmt.Changes, _ = res.RowsAffected()
+			}
+		}
+		run = append(run, stmt)
+		if stmt.Err != nil {
+			return run, fmt.Errorf("line %d: %w", stmt.Line, stmt.Err)
+		}
+	}
+	return run, nil
+}
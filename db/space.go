@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SpaceUsage is how a database file's pages are spent.
+type SpaceUsage struct {
+	PageSize  int64
+	PageCount int64
+	FreePages int64 // on the freelist, reclaimed by VACUUM
+	Objects   []ObjectSize
+}
+
+// ObjectSize is the space one table or index takes up.
+type ObjectSize struct {
+	Name   string
+	Type   string // table or index; SQLite's own sqlite_* tables count as tables
+	Table  string // the table an index belongs to; Name for tables
+	Pages  int64
+	Bytes  int64
+	Unused int64 // bytes within those pages holding no data
+}
+
+// SpaceUsageStats measures every table and index of schema's file from the
+// dbstat virtual table, largest first: main's for "", else the attached
+// database's, its objects named "name.table" as ListTables names them.
+// dbstat reads every page of the file, so on a large database this takes
+// a while; ctx cancels it. SQLite builds without dbstat return an error
+// saying so.
+func SpaceUsageStats(ctx context.Context, db *sql.DB, schema string) (SpaceUsage, error) {
+	pragma, prefix, statSchema := "PRAGMA ", "", "main"
+	if schema != "" {
+		pragma, prefix, statSchema = "PRAGMA "+quoteIdent(schema)+".", schema+".", schema
+	}
+	var u SpaceUsage
+	for name, dest := range map[string]*int64{
+		"page_size":      &u.PageSize,
+		"page_count":     &u.PageCount,
+		"freelist_count": &u.FreePages,
+	} {
+		if err := on(db).QueryRowContext(ctx, pragma+name).Scan(dest); err != nil {
+			return SpaceUsage{}, err
+		}
+	}
+
+	rows, err := on(db).QueryContext(ctx,
+		`SELECT ? || s.name, COALESCE(m.type, 'table'), ? || COALESCE(m.tbl_name, s.name), COUNT(*), SUM(s.pgsize), SUM(s.unused)
+		FROM dbstat(?) AS s LEFT JOIN `+quoteIdent(statSchema)+`.sqlite_master AS m ON m.name = s.name
+		GROUP BY s.name
+		ORDER BY 5 DESC, 1`, prefix, prefix, statSchema)
+	if err != nil {
+		if strings.Contains(err.Error(), "no such table: dbstat") {
+			return SpaceUsage{}, fmt.Errorf("this SQLite build has no dbstat table")
+		}
+		return SpaceUsage{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var o ObjectSize
+		if err := rows.Scan(&o.Name, &o.Type, &o.Table, &o.Pages, &o.Bytes, &o.Unused); err != nil {
+			return SpaceUsage{}, err
+		}
+		u.Objects = append(u.Objects, o)
+	}
+	return u, rows.Err()
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// QueryError is an error of ExecQuery with the part of the query it is
+// about. SQLite knows the offset of the token at fault, but the driver
+// doesn't pass it on, so the part is found from what the message names:
+// the token a syntax error is near, or the table, column, or function
+// that doesn't exist.
+type QueryError struct {
+	Err        error
+	Query      string
+	Start, End int // byte offsets of the part in Query; equal when it is unknown
+}
+
+func (e *QueryError) Error() string { return e.Err.Error() }
+
+func (e *QueryError) Unwrap() error { return e.Err }
+
+// Position is the line and column of the start of the part, from 1, or
+// 0, 0 when it is unknown.
+func (e *QueryError) Position() (line, col int) {
+	if e.Start == e.End {
+		return 0, 0
+	}
+	before := e.Query[:e.Start]
+	line = 1 + strings.Count(before, "\n")
+	return line, 1 + utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:])
+}
+
+var (
+	nearToken    = regexp.MustCompile(`near "(.*)": syntax error`)
+	unrecognized = regexp.MustCompile(`unrecognized token: "(.*)"`)
+	// errorNames find the name an error is about; the driver's " (1)"
+	// with the result code is cut off first.
+	errorNames = []*regexp.Regexp{
+		regexp.MustCompile(`no such (?:table|column|function|collation sequence|index|view|trigger|module): (.+)$`),
+		regexp.MustCompile(`ambiguous column name: (.+)$`),
+		regexp.MustCompile(`has no column named (.+)$`),
+		regexp.MustCompile(`(?:wrong number of arguments to|misuse of aggregate|misuse of window) function (.+)\(\)$`),
+		regexp.MustCompile(`misuse of aggregate: (.+)\(\)$`),
+		regexp.MustCompile(`(?:table|index|view|trigger) (.+) already exists$`),
+	}
+	resultCode = regexp.MustCompile(`\s*\(\d+\)$`)
+)
+
+// queryError wraps err, which running query on conn returned, in a
+// QueryError locating it in query.
+func queryError(ctx context.Context, conn *sql.Conn, query string, err error) *QueryError {
+	qe := &QueryError{Err: err, Query: query}
+	msg := resultCode.ReplaceAllString(err.Error(), "")
+	tokens := lexSQL(query)
+	found := func(start, end int) *QueryError {
+		qe.Start, qe.End = start, end
+		return qe
+	}
+
+	if m := nearToken.FindStringSubmatch(msg); m != nil {
+		var first *sqlToken
+		for i, t := range tokens {
+			if t.text != m[1] {
+				continue
+			}
+			if first == nil {
+				first = &tokens[i]
+			}
+			if syntaxErrorAt(ctx, conn, query, t, m[0]) {
+				return found(t.start, t.end)
+			}
+		}
+		if first != nil {
+			return found(first.start, first.end)
+		}
+		return qe
+	}
+	if m := unrecognized.FindStringSubmatch(msg); m != nil {
+		if i := strings.Index(query, m[1]); i >= 0 {
+			return found(i, i+len(m[1]))
+		}
+		return qe
+	}
+	if strings.HasSuffix(msg, "incomplete input") {
+		if len(tokens) > 0 {
+			last := tokens[len(tokens)-1]
+			return found(last.start, last.end)
+		}
+		return qe
+	}
+	for _, re := range errorNames {
+		if m := re.FindStringSubmatch(msg); m != nil {
+			if start, end, ok := findName(tokens, m[1]); ok {
+				return found(start, end)
+			}
+			return qe
+		}
+	}
+	return qe
+}
+
+// syntaxErrorAt reports whether the statement of query holding t fails to
+// parse at t, the occurrence a syntax error near its text is about rather
+// than one before it: the statement cut off after t then fails with the
+// same error, not for being incomplete. Preparing runs nothing.
+func syntaxErrorAt(ctx context.Context, conn *sql.Conn, query string, t sqlToken, near string) bool {
+	from := 0
+	for _, s := range scriptStatements(query) {
+		if s.at <= t.start {
+			from = s.at
+		}
+	}
+	stmt, err := conn.PrepareContext(ctx, query[from:t.end])
+	if err != nil {
+		return strings.Contains(err.Error(), near)
+	}
+	stmt.Close()
+	return false
+}
+
+// findName returns the span of the first tokens of query naming name,
+// which may be qualified, like "main.users" or "u.email": the whole name
+// as one identifier, or its parts separated by dots.
+func findName(tokens []sqlToken, name string) (start, end int, ok bool) {
+	for _, t := range tokens {
+		if strings.EqualFold(unquoteIdent(t.text), name) {
+			return t.start, t.end, true
+		}
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) == 1 {
+		return 0, 0, false
+	}
+outer:
+	for i := 0; i+2*len(parts)-1 <= len(tokens); i++ {
+		for j, p := range parts {
+			if j > 0 && tokens[i+2*j-1].text != "." {
+				continue outer
+			}
+			if !strings.EqualFold(unquoteIdent(tokens[i+2*j].text), p) {
+				continue outer
+			}
+		}
+		return tokens[i].start, tokens[i+2*len(parts)-2].end, true
+	}
+	// A qualified column may be written without its table.
+	return findName(tokens, parts[len(parts)-1])
+}
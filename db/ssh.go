@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	sshMu sync.Mutex
+	// sshCopies maps each scp:// URL opened to the directory holding its
+	// local copy.
+	sshCopies = map[string]string{}
+)
+
+// IsURL reports whether path is a URL Open reads from another machine
+// rather than a local file: one that IsRemote or IsSSH.
+func IsURL(path string) bool {
+	return IsRemote(path) || IsSSH(path)
+}
+
+// IsSSH reports whether path is an scp://[user@]host[:port]/path URL,
+// which Open copies over SSH and opens the copy of. A path starting with
+// /~/ is in the remote home directory.
+func IsSSH(path string) bool {
+	u, err := url.Parse(path)
+	return err == nil && u.Scheme == "scp" && u.Host != "" && u.Path != "" && u.Path != "/"
+}
+
+// SyncSSH copies the database at an scp:// URL, with its -wal and -shm
+// files if it has them, into a temporary directory with scp, replacing
+// any earlier copy, and returns the local copy's path. scp runs in batch
+// mode, so the host must let in the user without a password prompt, as
+// with a key in ssh-agent.
+func SyncSSH(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !IsSSH(rawURL) {
+		return "", fmt.Errorf("%s is not an scp://host/path URL", rawURL)
+	}
+	remote := strings.TrimPrefix(u.Path, "/")
+	if !strings.HasPrefix(remote, "~/") {
+		remote = "/" + remote
+	}
+	host := u.Hostname()
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+	// scp would take a user or host starting with a dash for an option,
+	// such as -oProxyCommand=... running a command here.
+	if strings.HasPrefix(host, "-") {
+		return "", fmt.Errorf("%s: a user or host can't start with -", rawURL)
+	}
+	args := []string{"-q", "-B"}
+	if port := u.Port(); port != "" {
+		args = append(args, "-P", port)
+	}
+
+	sshMu.Lock()
+	dir, ok := sshCopies[rawURL]
+	sshMu.Unlock()
+	if !ok {
+		if dir, err = os.MkdirTemp("", "sqlitui-ssh-*"); err != nil {
+			return "", err
+		}
+		sshMu.Lock()
+		sshCopies[rawURL] = dir
+		sshMu.Unlock()
+	}
+	// Copy into a fresh directory first, so a failed copy leaves the
+	// earlier one whole.
+	staging, err := os.MkdirTemp(dir, "sync-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(staging)
+
+	name := pathpkg.Base(remote)
+	local := filepath.Join(dir, name)
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		src := host + ":" + remote + suffix
+		dest := filepath.Join(staging, name+suffix)
+		out, err := exec.CommandContext(ctx, "scp", append(args, "--", src, dest)...).CombinedOutput()
+		if err != nil {
+			msg := strings.TrimSpace(string(out))
+			if suffix != "" && strings.Contains(msg, "No such file") {
+				continue // no WAL, or no shared memory index
+			}
+			if errors.Is(err, exec.ErrNotFound) {
+				return "", errors.New("opening scp:// URLs needs the scp command")
+			}
+			if msg == "" {
+				msg = err.Error()
+			}
+			return "", fmt.Errorf("scp %s: %s", src, msg)
+		}
+	}
+	// The database goes last, so a connection opening it in between finds
+	// no old WAL beside it.
+	for _, suffix := range []string{"-wal", "-shm", ""} {
+		src, dest := filepath.Join(staging, name+suffix), local+suffix
+		if _, err := os.Stat(src); err != nil {
+			os.Remove(dest)
+			continue
+		}
+		if err := os.Rename(src, dest); err != nil {
+			return "", err
+		}
+	}
+	return local, nil
+}
+
+// LocalPath is where the database at path is read from: the local copy of
+// an scp:// URL, or path itself.
+func LocalPath(path string) string {
+	sshMu.Lock()
+	defer sshMu.Unlock()
+	if dir, ok := sshCopies[path]; ok {
+		u, _ := url.Parse(path)
+		return filepath.Join(dir, pathpkg.Base(u.Path))
+	}
+	return path
+}
+
+// Reconnect drops db's idle connections, so the next queries open its
+// file anew: after SyncSSH, they read the new copy.
+func Reconnect(db *sql.DB) {
+	db.SetMaxIdleConns(0)
+	db.SetMaxIdleConns(2)
+}
+
+// RemoveSSHCopy deletes the local copy of the database at an scp:// URL,
+// once it is closed.
+func RemoveSSHCopy(rawURL string) {
+	sshMu.Lock()
+	dir, ok := sshCopies[rawURL]
+	delete(sshCopies, rawURL)
+	sshMu.Unlock()
+	if ok {
+		os.RemoveAll(dir)
+	}
+}
+
+// RemoveSSHCopies deletes every local copy, for when sqlitui exits.
+func RemoveSSHCopies() {
+	sshMu.Lock()
+	urls := make([]string, 0, len(sshCopies))
+	for u := range sshCopies {
+		urls = append(urls, u)
+	}
+	sshMu.Unlock()
+	for _, u := range urls {
+		RemoveSSHCopy(u)
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// ColumnStats profiles the values of one column.
+type ColumnStats struct {
+	Rows     int64 // rows in the table
+	Count    int64 // non-NULL values
+	Nulls    int64
+	Distinct int64 // distinct non-NULL values
+	Min, Max string
+	// Numeric is set when every non-NULL value is an INTEGER or REAL;
+	// only then is Avg meaningful.
+	Numeric bool
+	Avg     float64
+	Top     []ValueCount // most frequent non-NULL values, most common first
+}
+
+// ValueCount is a value and how many rows hold it.
+type ValueCount struct {
+	Value string
+	Count int64
+}
+
+// topValues is how many of the most frequent values ColumnStatistics lists.
+const topValues = 5
+
+// ColumnStatistics computes ColumnStats for column in two passes over the
+// table: one for the aggregates and a GROUP BY for the frequent values.
+func ColumnStatistics(ctx context.Context, db *sql.DB, table, column string) (ColumnStats, error) {
+	c, t := quoteIdent(column), quoteTable(table)
+	var s ColumnStats
+	var minV, maxV any
+	var avg sql.NullFloat64
+	var numeric int64
+	err := on(db).QueryRowContext(ctx,
+		"SELECT COUNT(*), COUNT("+c+"), COUNT(DISTINCT "+c+"), MIN("+c+"), MAX("+c+"), AVG("+c+"), "+
+			"COALESCE(SUM(typeof("+c+") IN ('integer', 'real')), 0) FROM "+t,
+	).Scan(&s.Rows, &s.Count, &s.Distinct, &minV, &maxV, &avg, &numeric)
+	if err != nil {
+		return ColumnStats{}, err
+	}
+	s.Nulls = s.Rows - s.Count
+	s.Min, s.Max = cellString(minV), cellString(maxV)
+	s.Numeric = s.Count > 0 && numeric == s.Count
+	s.Avg = avg.Float64
+
+	rows, err := on(db).QueryContext(ctx,
+		"SELECT "+c+", COUNT(*) AS n FROM "+t+" WHERE "+c+" IS NOT NULL GROUP BY 1 ORDER BY n DESC, 1 LIMIT ?", topValues)
+	if err != nil {
+		return ColumnStats{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v any
+		var vc ValueCount
+		if err := rows.Scan(&v, &vc.Count); err != nil {
+			return ColumnStats{}, err
+		}
+		vc.Value = cellString(v)
+		s.Top = append(s.Top, vc)
+	}
+	return s, rows.Err()
+}
+
+// ColumnProfile counts the missing and distinct values of one column.
+type ColumnProfile struct {
+	Column   string
+	Nulls    int64
+	Empty    int64 // empty strings, which are not NULL
+	Distinct int64 // distinct non-NULL values
+}
+
+// TableProfile computes a ColumnProfile for every column of table, and its
+// row count, in a single pass: one aggregate query with three expressions
+// per column.
+func TableProfile(ctx context.Context, db *sql.DB, table string) (int64, []ColumnProfile, error) {
+	columns, err := GetColumns(ctx, db, table)
+	if err != nil {
+		return 0, nil, err
+	}
+	exprs := []string{"COUNT(*)"}
+	for _, col := range columns {
+		c := quoteIdent(col)
+		exprs = append(exprs,
+			"COUNT(*) - COUNT("+c+")",
+			"COALESCE(SUM(typeof("+c+") = 'text' AND "+c+" = ''), 0)",
+			"COUNT(DISTINCT "+c+")")
+	}
+
+	var rows int64
+	profiles := make([]ColumnProfile, len(columns))
+	dest := []any{&rows}
+	for i, col := range columns {
+		profiles[i].Column = col
+		dest = append(dest, &profiles[i].Nulls, &profiles[i].Empty, &profiles[i].Distinct)
+	}
+	q := "SELECT " + strings.Join(exprs, ", ") + " FROM " + quoteTable(table)
+	if err := on(db).QueryRowContext(ctx, q).Scan(dest...); err != nil {
+		return 0, nil, err
+	}
+	return rows, profiles, nil
+}
+
+// DistinctValues lists the distinct values of column with how many rows
+// hold each, most common first, up to limit of them. NULLs and BLOBs are
+// left out: neither can be picked as a filter value.
+func DistinctValues(ctx context.Context, db *sql.DB, table, column string, limit int) ([]ValueCount, error) {
+	c := quoteIdent(column)
+	rows, err := on(db).QueryContext(ctx,
+		"SELECT "+c+", COUNT(*) AS n FROM "+quoteTable(table)+" WHERE "+c+" IS NOT NULL AND typeof("+c+") != 'blob' GROUP BY 1 ORDER BY n DESC, 1 LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var values []ValueCount
+	for rows.Next() {
+		var v any
+		var vc ValueCount
+		if err := rows.Scan(&v, &vc.Count); err != nil {
+			return nil, err
+		}
+		vc.Value = cellString(v)
+		values = append(values, vc)
+	}
+	return values, rows.Err()
+}
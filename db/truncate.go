@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// maxValueLength is the most characters of a TEXT value the row readers
+// return; see SetMaxValueLength.
+var maxValueLength int
+
+// SetMaxValueLength makes the row readers cut TEXT values longer than n
+// characters, ending them with a TruncatedMarker, so that megabyte-sized
+// values don't have to be held and drawn in full. 0 keeps every value
+// whole. ReadValue fetches a cut value in full.
+func SetMaxValueLength(n int) {
+	maxValueLength = n
+}
+
+// truncatedRe matches the TruncatedMarker at the end of a cut value.
+var truncatedRe = regexp.MustCompile(`…\(\d+ [KMG]?B\)$`)
+
+// TruncatedMarker is what ends a value cut by the row readers: its full
+// size, as in "…(12 KB)".
+func TruncatedMarker(size int) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("…(%d GB)", size>>30)
+	case size >= 1<<20:
+		return fmt.Sprintf("…(%d MB)", size>>20)
+	case size >= 1<<10:
+		return fmt.Sprintf("…(%d KB)", size>>10)
+	}
+	return fmt.Sprintf("…(%d B)", size)
+}
+
+// IsTruncated reports whether s may be a value cut by the row readers. A
+// stored value can end the same way, so ReadValue is what tells for sure.
+func IsTruncated(s string) bool {
+	return maxValueLength > 0 && truncatedRe.MatchString(s)
+}
+
+// truncateText cuts s to maxValueLength characters plus a TruncatedMarker.
+func truncateText(s string) string {
+	if maxValueLength <= 0 || len(s) <= maxValueLength || utf8.RuneCountInString(s) <= maxValueLength {
+		return s
+	}
+	i, n := 0, 0
+	for i = range s {
+		if n == maxValueLength {
+			break
+		}
+		n++
+	}
+	return s[:i] + TruncatedMarker(len(s))
+}
+
+// ReadValue returns the whole value stored in column of the row with
+// rowid, as the row readers show it but never cut.
+func ReadValue(ctx context.Context, db *sql.DB, table, column string, rowid int64) (string, error) {
+	var v any
+	q := "SELECT " + quoteIdent(column) + " FROM " + quoteTable(table) + " WHERE rowid = ?"
+	if err := on(db).QueryRowContext(ctx, q, rowid).Scan(&v); err != nil {
+		if err == sql.ErrNoRows {
+			return "", fmt.Errorf("row %d no longer exists", rowid)
+		}
+		return "", err
+	}
+	return cellString(v), nil
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoTransaction is returned by Commit and Rollback when the transaction
+// had already ended, say by a COMMIT run from the query popup.
+var ErrNoTransaction = errors.New("the transaction had already ended")
+
+// ErrRolledBack is why a transaction ended when SQLite rolled it back on
+// its own, as it does when a write in it is interrupted or runs out of
+// disk.
+var ErrRolledBack = errors.New("SQLite rolled the transaction back")
+
+var (
+	txMu    sync.Mutex
+	openTx  = map[*sql.DB]*sql.Conn{}
+	endedTx = map[*sql.DB]error{}
+)
+
+// Begin opens a transaction spanning every statement later run on db,
+// until Commit or Rollback. It runs on a connection of its own, checked
+// out of the pool for as long as it is open, so the pages read meanwhile
+// see the uncommitted changes too, while a connection checked out before
+// (like the change watcher's) goes on seeing what is committed.
+func Begin(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "BEGIN"); err != nil {
+		conn.Close()
+		return err
+	}
+	txMu.Lock()
+	openTx[db] = conn
+	delete(endedTx, db)
+	txMu.Unlock()
+	return nil
+}
+
+// InTransaction reports whether Begin opened a transaction on db that
+// hasn't ended.
+func InTransaction(db *sql.DB) bool {
+	txMu.Lock()
+	defer txMu.Unlock()
+	return openTx[db] != nil
+}
+
+// TransactionEnded returns why the transaction Begin opened on db ended
+// without Commit or Rollback, once: ErrRolledBack, wrapping the error of
+// the statement SQLite rolled it back after, or ErrNoTransaction when a
+// statement of its own ended it. It returns nil if it didn't.
+func TransactionEnded(db *sql.DB) error {
+	txMu.Lock()
+	defer txMu.Unlock()
+	err := endedTx[db]
+	delete(endedTx, db)
+	return err
+}
+
+// txConn returns the connection of the transaction open on db, or nil.
+func txConn(db *sql.DB) *sql.Conn {
+	txMu.Lock()
+	defer txMu.Unlock()
+	return openTx[db]
+}
+
+// on is what to run statements on db with: the connection of the
+// transaction open on it, or db itself.
+func on(db *sql.DB) execQuerier {
+	if conn := txConn(db); conn != nil {
+		return txQuerier{db: db, conn: conn}
+	}
+	return db
+}
+
+// reserve checks out a connection of db for statements that have to share
+// one, and returns the func that gives it back. While a transaction is
+// open that is the transaction's connection, which stays checked out.
+func reserve(ctx context.Context, db *sql.DB) (*sql.Conn, func(), error) {
+	if conn := txConn(db); conn != nil {
+		return conn, func() {}, nil
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, func() { conn.Close() }, nil
+}
+
+// txQuerier runs statements in the transaction open on db. A write is
+// neither cancelled nor timed out, since SQLite rolls back the whole
+// transaction when one is interrupted, and after a statement that fails
+// the transaction is checked to still be open.
+type txQuerier struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func (q txQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	res, err := q.conn.ExecContext(context.WithoutCancel(ctx), query, args...)
+	if err != nil {
+		checkTx(q.db, err)
+	}
+	return res, err
+}
+
+func (q txQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	rows, err := q.conn.QueryContext(writeContext(ctx, query), query, args...)
+	if err != nil {
+		checkTx(q.db, err)
+	}
+	return rows, err
+}
+
+func (q txQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.conn.QueryRowContext(writeContext(ctx, query), query, args...)
+}
+
+// writeContext is ctx for running query in a transaction: without its
+// cancellation when query may write, which only a single SELECT doesn't.
+func writeContext(ctx context.Context, query string) context.Context {
+	if _, err := ViewQuery(query); err != nil {
+		return context.WithoutCancel(ctx)
+	}
+	return ctx
+}
+
+// checkTx finds out whether the transaction open on db is still open after
+// a statement run in it, which failed with err unless that is nil, and
+// records why it ended if it isn't. SQLite has no statement telling, but
+// only outside a transaction does a BEGIN succeed.
+func checkTx(db *sql.DB, err error) {
+	conn := txConn(db)
+	if conn == nil {
+		return
+	}
+	ctx := context.Background()
+	if _, berr := conn.ExecContext(ctx, "BEGIN"); berr != nil {
+		return // still open, or the connection is past telling
+	}
+	_, _ = conn.ExecContext(ctx, "ROLLBACK")
+	why := ErrNoTransaction
+	if err != nil {
+		why = fmt.Errorf("%w after: %v", ErrRolledBack, err)
+	}
+	txMu.Lock()
+	if openTx[db] == conn {
+		delete(openTx, db)
+		endedTx[db] = why
+	}
+	txMu.Unlock()
+	conn.Close()
+}
+
+// forgetTx drops what is known of db's transaction, giving its connection
+// back, which rolls it back if it is still open.
+func forgetTx(db *sql.DB) {
+	txMu.Lock()
+	conn := openTx[db]
+	delete(openTx, db)
+	delete(endedTx, db)
+	txMu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// TotalChanges returns how many rows the statements run on db's
+// connection have inserted, updated, or deleted since it opened. Within a
+// transaction that is the transaction's connection, so the difference
+// between two calls counts the rows changed in between.
+func TotalChanges(ctx context.Context, db *sql.DB) (int64, error) {
+	var n int64
+	err := on(db).QueryRowContext(ctx, "SELECT total_changes()").Scan(&n)
+	return n, err
+}
+
+// Commit ends the transaction Begin opened, keeping its changes.
+func Commit(ctx context.Context, db *sql.DB) error {
+	return endTx(ctx, db, "COMMIT")
+}
+
+// Rollback ends the transaction Begin opened, discarding its changes.
+func Rollback(ctx context.Context, db *sql.DB) error {
+	return endTx(ctx, db, "ROLLBACK")
+}
+
+// endTx runs stmt and, unless the transaction is still open because stmt
+// failed (a COMMIT waiting on another process's lock, say), gives its
+// connection back to the pool.
+func endTx(ctx context.Context, db *sql.DB, stmt string) error {
+	conn := txConn(db)
+	if conn == nil {
+		return ErrNoTransaction
+	}
+	_, err := conn.ExecContext(ctx, stmt)
+	if err != nil {
+		if !strings.Contains(err.Error(), "no transaction is active") {
+			return err
+		}
+		err = ErrNoTransaction
+	}
+	txMu.Lock()
+	delete(openTx, db)
+	delete(endedTx, db)
+	txMu.Unlock()
+	conn.Close()
+	return err
+}
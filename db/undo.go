@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Edit is a change DeleteRow, UpdateRow, or InsertRow made to one row,
+// with the statement reverting it.
+type Edit struct {
+	Op    string // "delete", "update", or "insert"
+	Table string
+	RowID int64
+	undo  string
+	args  []any
+}
+
+func (e Edit) String() string {
+	return fmt.Sprintf("%s of %s row %d", e.Op, e.Table, e.RowID)
+}
+
+// UndoEdit reverts e: a deleted row is inserted back with its rowid and
+// values, an updated row gets its old values back, and an inserted row is
+// deleted. It fails when the row has since been deleted, or another row
+// has taken a deleted row's rowid or unique values.
+func UndoEdit(ctx context.Context, db *sql.DB, e Edit) error {
+	res, err := on(db).ExecContext(ctx, e.undo, e.args...)
+	if err != nil {
+		return fmt.Errorf("undoing %s: %w", e, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("undoing %s: the row no longer exists", e)
+	}
+	return nil
+}
+
+// execQuerier runs statements on a database or in a transaction.
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx runs fn in a transaction, committed if fn succeeds. Within one
+// opened by Begin, fn runs in that one under a savepoint, as SQLite doesn't
+// nest BEGINs, released if fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(q execQuerier) error) error {
+	if InTransaction(db) {
+		q := on(db)
+		if _, err := q.ExecContext(ctx, "SAVEPOINT sqlitui_edit"); err != nil {
+			return err
+		}
+		if err := fn(q); err != nil {
+			q.ExecContext(ctx, "ROLLBACK TO sqlitui_edit")
+			q.ExecContext(ctx, "RELEASE sqlitui_edit")
+			return err
+		}
+		_, err := q.ExecContext(ctx, "RELEASE sqlitui_edit")
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// storedColumns lists the columns of table an INSERT can set: all but the
+// generated ones.
+func storedColumns(ctx context.Context, q execQuerier, table string) ([]string, error) {
+	schema, name := tableSchema(table)
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_xinfo(?, ?) WHERE hidden = 0 ORDER BY cid", name, schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cols []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		cols = append(cols, c)
+	}
+	return cols, rows.Err()
+}
+
+// rowValues reads the values of columns in the row with rowid as they are
+// stored. A unary + keeps the driver from turning the text of DATETIME
+// columns into times, which would be written back reformatted.
+func rowValues(ctx context.Context, q execQuerier, table string, rowid int64, columns []string) ([]any, error) {
+	exprs := make([]string, len(columns))
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i, c := range columns {
+		exprs[i] = "+" + quoteIdent(c)
+		ptrs[i] = &values[i]
+	}
+	stmt := "SELECT " + strings.Join(exprs, ", ") + " FROM " + quoteTable(table) + " WHERE rowid = ?"
+	if err := q.QueryRowContext(ctx, stmt, rowid).Scan(ptrs...); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("row %d no longer exists", rowid)
+		}
+		return nil, err
+	}
+	return values, nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
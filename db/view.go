@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ViewDefinition returns the SELECT statement view is defined by, as it
+// was written in its CREATE VIEW.
+func ViewDefinition(ctx context.Context, db *sql.DB, view string) (string, error) {
+	_, name := splitTable(view)
+	master, _ := schemaMaster(view)
+	var stmt string
+	if err := on(db).QueryRowContext(ctx,
+		"SELECT sql FROM "+master+" WHERE type = 'view' AND name = ?", name,
+	).Scan(&stmt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("no view named %s", view)
+		}
+		return "", err
+	}
+	// The SELECT follows the first AS outside of the view's column list.
+	for _, t := range topLevelTokens(stmt) {
+		if t.word() == "AS" {
+			return strings.TrimSpace(stmt[t.end:]), nil
+		}
+	}
+	return stmt, nil
+}
+
+// ViewQuery returns query without its trailing semicolon if it can define
+// a view, being a single SELECT (or VALUES), and an error saying why not
+// otherwise.
+func ViewQuery(query string) (string, error) {
+	var stmt string
+	var tokens []sqlToken
+	for _, s := range splitStatements(query) {
+		t := topLevelTokens(s)
+		if len(t) == 0 {
+			continue // just a comment
+		}
+		if tokens != nil {
+			return "", errors.New("a view holds a single SELECT, and the query has several statements")
+		}
+		stmt, tokens = s, t
+	}
+	if tokens == nil {
+		return "", errors.New("no query to save")
+	}
+	switch tokens[0].word() {
+	case "SELECT", "WITH", "VALUES":
+	default:
+		return "", fmt.Errorf("a view holds a SELECT, not %s", strings.ToUpper(tokens[0].text))
+	}
+	return stmt, nil
+}
+
+// CreateViewSQL is the CREATE VIEW statement saving query as the view name.
+func CreateViewSQL(name, query string) (string, error) {
+	query, err := ViewQuery(query)
+	if err != nil {
+		return "", err
+	}
+	return "CREATE VIEW " + quoteIdent(name) + " AS\n" + query, nil
+}
+
+// CreateView saves query as the view name.
+func CreateView(ctx context.Context, db *sql.DB, name, query string) error {
+	stmt, err := CreateViewSQL(name, query)
+	if err != nil {
+		return err
+	}
+	_, err = on(db).ExecContext(ctx, stmt)
+	return err
+}
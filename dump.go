@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+func dumpUsage() {
+	fmt.Println("Usage: sqlitui dump [options] database-path")
+	fmt.Println()
+	fmt.Println("Writes the schema and every row as SQL, like .dump in the sqlite3 shell,")
+	fmt.Println("without starting the UI.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("      --schema-only    Write just the CREATE statements, like .schema")
+	fmt.Println("      --out PATH       Write to PATH instead of stdout")
+	fmt.Println("      --config PATH    Read configuration from PATH")
+}
+
+// runDump is the dump subcommand: it writes the database as SQL to a file
+// or stdout, putting the file in place only once complete, as export does.
+func runDump(args []string) error {
+	var (
+		showHelp, schemaOnly bool
+		out, configPath      string
+	)
+	fs := flag.NewFlagSet("sqlitui dump", flag.ExitOnError)
+	fs.Usage = dumpUsage
+	fs.BoolVar(&showHelp, "h", false, "")
+	fs.BoolVar(&showHelp, "help", false, "")
+	fs.BoolVar(&schemaOnly, "schema-only", false, "")
+	fs.StringVar(&out, "out", "", "")
+	fs.StringVar(&configPath, "config", "", "")
+	args = parseInterspersed(fs, args)
+	if showHelp {
+		dumpUsage()
+		return nil
+	}
+	if len(args) != 1 {
+		return errors.New("dump needs a database path (see sqlitui dump --help)")
+	}
+	path := args[0]
+
+	// Opening a missing file would create an empty database.
+	if _, err := os.Stat(path); err != nil && !db.IsURL(path) {
+		return err
+	}
+	// The config's SQL functions and attachments apply here as in the UI.
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	database, err := db.Open(path, db.Options{ReadOnly: true, Attach: cfg.Attach})
+	if err != nil {
+		return err
+	}
+	defer db.Close(database)
+	defer db.RemoveSSHCopies()
+
+	var w io.Writer = os.Stdout
+	var file *outputFile
+	if out != "" && out != "-" {
+		if file, err = createOutput(out); err != nil {
+			return err
+		}
+		w = file
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var objects []db.DumpObject
+	var rows int
+	if schemaOnly {
+		objects, err = db.DumpSchema(ctx, database, w)
+	} else {
+		objects, rows, err = db.Dump(ctx, database, w, nil)
+	}
+	if file != nil {
+		err = file.finish(err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return errors.New("dump cancelled")
+	}
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		fmt.Fprintf(os.Stderr, "Dumped %d objects and %d rows to %s\n", len(objects), rows, out)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/markovic-nikola/sqlitui/config"
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// execFormats are the --format values, in the order the help lists them.
+var execFormats = []db.Format{db.FormatTable, db.FormatCSV, db.FormatTSV, db.FormatJSON}
+
+// runExec runs query against the database at path and prints its result
+// to stdout in format, for --exec. A query of "-" is read from stdin.
+// Ctrl+C and the configured query timeout cancel it.
+func runExec(path, query, format string, cfg config.Config) error {
+	if path == "" {
+		return errors.New("--exec needs a database path")
+	}
+	f := db.Format(strings.ToLower(format))
+	if !slices.Contains(execFormats, f) {
+		return fmt.Errorf("unknown format %q (expected table, csv, tsv, or json)", format)
+	}
+	if query == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		query = string(data)
+	}
+	if strings.TrimSpace(query) == "" {
+		return errors.New("no query given")
+	}
+	// Opening a missing file would create an empty database.
+	if _, err := os.Stat(path); err != nil && !db.IsURL(path) {
+		return err
+	}
+
+	database, err := db.Open(path, db.Options{ReadOnly: cfg.ReadOnly, Attach: cfg.Attach})
+	if err != nil {
+		return err
+	}
+	defer db.Close(database)
+	defer db.RemoveSSHCopies()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if cfg.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.QueryTimeout)
+		defer cancel()
+	}
+	_, err = db.ExportQuery(ctx, database, query, nil, f, os.Stdout, db.ExportOptions{}, nil)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query timed out after %s", cfg.QueryTimeout)
+	}
+	return err
+}
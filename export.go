@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// exportFormats are the export --format values, in the order the help
+// lists them.
+var exportFormats = []db.Format{db.FormatCSV, db.FormatTSV, db.FormatJSON, db.FormatSQL}
+
+func exportUsage() {
+	fmt.Println("Usage: sqlitui export [options] database-path table")
+	fmt.Println()
+	fmt.Println("Writes every row of a table without starting the UI.")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("      --format FORMAT  csv, tsv, json, or sql (INSERT statements)")
+	fmt.Println("                       (default: from the --out extension, else csv)")
+	fmt.Println("      --out PATH       Write to PATH instead of stdout")
+	fmt.Println("      --config PATH    Read configuration from PATH")
+}
+
+// runExport is the export subcommand: it streams a table to a file or
+// stdout. The file is only put in place once complete, so an error or
+// Ctrl+C leaves a file already there as it was.
+func runExport(args []string) error {
+	var (
+		showHelp                bool
+		format, out, configPath string
+	)
+	fs := flag.NewFlagSet("sqlitui export", flag.ExitOnError)
+	fs.Usage = exportUsage
+	fs.BoolVar(&showHelp, "h", false, "")
+	fs.BoolVar(&showHelp, "help", false, "")
+	fs.StringVar(&format, "format", "", "")
+	fs.StringVar(&out, "out", "", "")
+	fs.StringVar(&configPath, "config", "", "")
+	args = parseInterspersed(fs, args)
+	if showHelp {
+		exportUsage()
+		return nil
+	}
+	if len(args) != 2 {
+		return errors.New("export needs a database path and a table name (see sqlitui export --help)")
+	}
+	path, table := args[0], args[1]
+
+	if format == "" {
+		format = string(db.FormatCSV)
+		if ext := strings.TrimPrefix(filepath.Ext(out), "."); slices.Contains(exportFormats, db.Format(strings.ToLower(ext))) {
+			format = ext
+		}
+	}
+	f := db.Format(strings.ToLower(format))
+	if !slices.Contains(exportFormats, f) {
+		return fmt.Errorf("unknown format %q (expected csv, tsv, json, or sql)", format)
+	}
+	// Opening a missing file would create an empty database.
+	if _, err := os.Stat(path); err != nil && !db.IsURL(path) {
+		return err
+	}
+	// The config's SQL functions and attachments apply here as in the UI.
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	database, err := db.Open(path, db.Options{ReadOnly: true, Attach: cfg.Attach})
+	if err != nil {
+		return err
+	}
+	defer db.Close(database)
+	defer db.RemoveSSHCopies()
+
+	var w io.Writer = os.Stdout
+	var file *outputFile
+	if out != "" && out != "-" {
+		if file, err = createOutput(out); err != nil {
+			return err
+		}
+		w = file
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	n, err := db.ExportTable(ctx, database, table, f, w, db.ExportOptions{}, nil)
+	if file != nil {
+		err = file.finish(err)
+	}
+	if errors.Is(err, context.Canceled) {
+		return errors.New("export cancelled")
+	}
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		fmt.Fprintf(os.Stderr, "Exported %d rows of %s to %s\n", n, table, out)
+	}
+	return nil
+}
+
+// outputFile is the file a subcommand's --out names, written as a
+// temporary file in the same directory and renamed over it by finish.
+type outputFile struct {
+	*os.File
+	path string
+}
+
+func createOutput(path string) (*outputFile, error) {
+	mode := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Chmod(mode); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &outputFile{File: f, path: path}, nil
+}
+
+// finish closes the file and, unless err or the close failed, renames it
+// to its path; otherwise the temporary file is removed. It returns the
+// first error.
+func (f *outputFile) finish(err error) error {
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -1,55 +1,225 @@
 package main
 
 import (
+	_ "embed"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/markovic-nikola/sqlitui/config"
+	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
 	"github.com/markovic-nikola/sqlitui/ui"
 	"github.com/markovic-nikola/sqlitui/update"
 )
 
+//go:embed CHANGELOG.md
+var changelog string
+
 var (
 	version = "dev"
 	commit  = "none"
 	date    = "unknown"
 )
 
+func usage() {
+	fmt.Println("sqlitui - Terminal UI for SQLite databases")
+	fmt.Println()
+	fmt.Println("Usage: sqlitui [options] [database-path]")
+	fmt.Println("       sqlitui export [options] database-path table")
+	fmt.Println("       sqlitui dump [options] database-path")
+	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  -h, --help           Show this help message")
+	fmt.Println("  -v, --version        Show version information")
+	fmt.Println("      --update         Update to the latest version")
+	fmt.Println("      --config PATH    Read configuration from PATH")
+	fmt.Println("                       (default ~/.config/sqlitui/config.toml)")
+	fmt.Println("      --page-size N    Rows per page (default: fit to screen)")
+	fmt.Println("      --read-only      Open the database read-only")
+	fmt.Println("      --theme NAME     Color theme: auto, dark, light, solarized")
+	fmt.Println("      --query-timeout D")
+	fmt.Println("                       Cancel queries running longer than D (e.g. 30s;")
+	fmt.Println("                       0 for no timeout)")
+	fmt.Println("      --exec QUERY     Print the result of QUERY (- reads it from stdin)")
+	fmt.Println("                       and exit without starting the UI")
+	fmt.Println("      --format FORMAT  Output of --exec: table, csv, tsv, json")
+	fmt.Println("                       (default table)")
+	fmt.Println("      --attach [NAME=]PATH")
+	fmt.Println("                       Attach another database, its tables listed as")
+	fmt.Println("                       NAME.table (repeatable)")
+}
+
 func main() {
-	if len(os.Args) >= 2 {
-		switch os.Args[1] {
-		case "--help", "-h":
-			fmt.Println("sqlitui - Terminal UI for SQLite databases")
-			fmt.Println()
-			fmt.Println("Usage: sqlitui [options] [database-path]")
-			fmt.Println()
-			fmt.Println("Options:")
-			fmt.Println("  -h, --help      Show this help message")
-			fmt.Println("  -v, --version   Show version information")
-			fmt.Println("      --update    Update to the latest version")
-			return
-		case "--version", "-v":
-			fmt.Printf("sqlitui %s (%s, %s)\n", version, commit, date)
-			return
-		case "--update":
-			update.Run(version)
-			return
+	var (
+		showHelp, showVersion, runUpdate, readOnly bool
+		configPath, theme, execQuery, format       string
+		pageSize                                   int
+		queryTimeout                               time.Duration
+		attach                                     stringsFlag
+	)
+
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		if err := runDump(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fs := flag.NewFlagSet("sqlitui", flag.ExitOnError)
+	fs.Usage = usage
+	fs.BoolVar(&showHelp, "h", false, "")
+	fs.BoolVar(&showHelp, "help", false, "")
+	fs.BoolVar(&showVersion, "v", false, "")
+	fs.BoolVar(&showVersion, "version", false, "")
+	fs.BoolVar(&runUpdate, "update", false, "")
+	fs.StringVar(&configPath, "config", "", "")
+	fs.IntVar(&pageSize, "page-size", 0, "")
+	fs.BoolVar(&readOnly, "read-only", false, "")
+	fs.StringVar(&theme, "theme", "", "")
+	fs.DurationVar(&queryTimeout, "query-timeout", 0, "")
+	fs.StringVar(&execQuery, "exec", "", "")
+	fs.StringVar(&format, "format", string(db.FormatTable), "")
+	fs.Var(&attach, "attach", "")
+
+	args := parseInterspersed(fs, os.Args[1:])
+
+	switch {
+	case showHelp:
+		usage()
+		return
+	case showVersion:
+		fmt.Printf("sqlitui %s (%s, %s)\n", version, commit, date)
+		return
+	case runUpdate:
+		update.Run(version)
+		return
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// CLI flags override the config file.
+	if pageSize > 0 {
+		cfg.PageSize = pageSize
+	}
+	if readOnly {
+		cfg.ReadOnly = true
+	}
+	if theme != "" {
+		cfg.Theme = theme
+	}
+	// --query-timeout 0 turns off a timeout set in the file.
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "query-timeout" {
+			cfg.QueryTimeout = queryTimeout
 		}
+	})
+	if cfg.QueryTimeout < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --query-timeout must not be negative (got %s)\n", cfg.QueryTimeout)
+		os.Exit(1)
+	}
+	if len(attach) > 0 {
+		cfg.Attach = attach
 	}
 
 	var path string
-	if len(os.Args) >= 2 {
-		path = os.Args[1]
+	if len(args) >= 1 {
+		path = args[0]
+	}
+
+	if execQuery != "" {
+		if err := runExec(path, execQuery, format, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	showUpdateNotice := update.CheckInBackground(version)
 
-	p := tea.NewProgram(ui.NewModel(path), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	model := ui.NewModel(path, cfg)
+	if notes := whatsNew(); notes != "" {
+		model = model.WithWhatsNew(version, notes)
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	_, err = p.Run()
+	db.RemoveSSHCopies()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	showUpdateNotice()
 }
+
+// loadConfig reads the config file at path, the default one if empty, and
+// registers the SQL functions it asks for, which must happen before any
+// database is opened: the UI and every subcommand start with it.
+func loadConfig(path string) (config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := db.RegisterFunctions(cfg.SQLFunctions); err != nil {
+		return cfg, fmt.Errorf("sql_functions: %w", err)
+	}
+	return cfg, nil
+}
+
+// whatsNew returns the release notes to greet the user with when this is
+// the first run of a new version, and records the version as seen.
+func whatsNew() string {
+	prefs := state.LoadPrefs()
+	if prefs.SeenVersion == version {
+		return ""
+	}
+	notes := update.ReleaseNotes(changelog, prefs.SeenVersion, version)
+	if version != "dev" {
+		prefs.SeenVersion = version
+		_ = state.SavePrefs(prefs)
+	}
+	return notes
+}
+
+// stringsFlag collects the values of a flag given more than once.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments (the flag package stops at the first non-flag), returning the
+// positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,50 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Bookmark marks a row of a table by its primary key value, or its rowid
+// for a table without a primary key. Label is a glimpse of the row's
+// values when it was marked.
+type Bookmark struct {
+	Table string `json:"table"`
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// LoadBookmarks reads the bookmarks saved for dbPath, oldest first. A
+// missing or unreadable file yields none.
+func LoadBookmarks(dbPath string) []Bookmark {
+	path, err := dbStatePath("bookmarks", dbPath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var b []Bookmark
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil
+	}
+	return b
+}
+
+// SaveBookmarks writes b as the bookmarks of dbPath.
+func SaveBookmarks(dbPath string, b []Bookmark) error {
+	path, err := dbStatePath("bookmarks", dbPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
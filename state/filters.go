@@ -0,0 +1,53 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Filter is a filter applied to a table: the column, the text filtered
+// for, and the name of the match mode.
+type Filter struct {
+	Column string `json:"column"`
+	Query  string `json:"query"`
+	Mode   string `json:"mode"`
+}
+
+// FilterHistory holds the filters applied to each table of one database,
+// newest first, keyed by table name.
+type FilterHistory map[string][]Filter
+
+// LoadFilterHistory reads the filter history saved for dbPath. A missing
+// or unreadable file yields an empty history.
+func LoadFilterHistory(dbPath string) FilterHistory {
+	h := FilterHistory{}
+	path, err := dbStatePath("filters", dbPath)
+	if err != nil {
+		return h
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(data, &h); err != nil || h == nil {
+		return FilterHistory{}
+	}
+	return h
+}
+
+// SaveFilterHistory writes h as the filter history of dbPath.
+func SaveFilterHistory(dbPath string, h FilterHistory) error {
+	path, err := dbStatePath("filters", dbPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,54 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Prefs are UI preferences adjusted at runtime and remembered across runs.
+// Zero values mean "not set"; callers fall back to their defaults.
+type Prefs struct {
+	SplitPercent int    `json:"split_percent,omitempty"` // table list width as % of the terminal
+	SeenVersion  string `json:"seen_version,omitempty"`  // last version run, for the what's-new popup
+}
+
+func prefsPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prefs.json"), nil
+}
+
+// LoadPrefs reads saved preferences. A missing or unreadable file yields
+// zero Prefs.
+func LoadPrefs() Prefs {
+	var p Prefs
+	path, err := prefsPath()
+	if err != nil {
+		return p
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p
+	}
+	_ = json.Unmarshal(data, &p)
+	return p
+}
+
+// SavePrefs writes preferences, replacing any previous file.
+func SavePrefs(p Prefs) error {
+	path, err := prefsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,120 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// FileKey fingerprints a database file so a cached schema can be discarded
+// as soon as the file changes. The -wal file is included because in WAL
+// mode commits land there and the main file's mtime doesn't move.
+type FileKey struct {
+	ModTime    int64 `json:"mod_time"`
+	Size       int64 `json:"size"`
+	WALModTime int64 `json:"wal_mod_time"`
+	WALSize    int64 `json:"wal_size"`
+}
+
+// FileKeyFor computes the FileKey of the database at path.
+func FileKeyFor(path string) (FileKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileKey{}, err
+	}
+	key := FileKey{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+	// An empty -wal is equivalent to none: opening a WAL database creates
+	// one, which must not invalidate the cache by itself.
+	if wal, err := os.Stat(path + "-wal"); err == nil && wal.Size() > 0 {
+		key.WALModTime = wal.ModTime().UnixNano()
+		key.WALSize = wal.Size()
+	}
+	return key, nil
+}
+
+// SchemaCache is the schema metadata remembered for one database file.
+// Columns and Counts fill in lazily as tables are viewed; the column names
+// spare a PRAGMA table_info later, the counts a COUNT(*).
+type SchemaCache struct {
+	Key     FileKey             `json:"key"`
+	Tables  []string            `json:"tables"`
+	Columns map[string][]string `json:"columns"`
+	Counts  map[string]int      `json:"counts"`
+}
+
+// NewSchemaCache starts an empty cache for a file with the given key.
+func NewSchemaCache(key FileKey, tables []string) *SchemaCache {
+	return &SchemaCache{
+		Key:     key,
+		Tables:  tables,
+		Columns: map[string][]string{},
+		Counts:  map[string]int{},
+	}
+}
+
+func schemaCachePath(dbPath string) (string, error) {
+	return dbStatePath("schema", dbPath)
+}
+
+// dbStatePath is where state of the given kind is kept for the database
+// at dbPath: a file named after a hash of its absolute path, in a
+// directory per kind.
+func dbStatePath(kind, dbPath string) (string, error) {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		return "", err
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, kind, hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+// LoadSchemaCache returns the cached schema for dbPath, or nil when there
+// is none or the file has changed since it was written.
+func LoadSchemaCache(dbPath string) *SchemaCache {
+	key, err := FileKeyFor(dbPath)
+	if err != nil {
+		return nil
+	}
+	path, err := schemaCachePath(dbPath)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var c SchemaCache
+	if err := json.Unmarshal(data, &c); err != nil || c.Key != key {
+		return nil
+	}
+	if c.Columns == nil {
+		c.Columns = map[string][]string{}
+	}
+	if c.Counts == nil {
+		c.Counts = map[string]int{}
+	}
+	return &c
+}
+
+// SaveSchemaCache writes c for dbPath. It keeps c.Key as-is: if the file
+// changed after the key was taken, the entry simply won't match next time.
+func SaveSchemaCache(dbPath string, c *SchemaCache) error {
+	path, err := schemaCachePath(dbPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,87 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxRecentFiles caps how many recently opened databases are remembered.
+const maxRecentFiles = 10
+
+// Dir returns the directory where sqlitui keeps state between runs.
+// It follows the XDG base directory spec: $XDG_STATE_HOME/sqlitui,
+// falling back to ~/.local/state/sqlitui.
+func Dir() (string, error) {
+	if d := os.Getenv("XDG_STATE_HOME"); d != "" {
+		return filepath.Join(d, "sqlitui"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "sqlitui"), nil
+}
+
+func recentFilesPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recent"), nil
+}
+
+// RecentFiles returns the absolute paths of recently opened databases,
+// most recent first. Files that no longer exist are skipped. Any error
+// reading the state file yields an empty list — recents are a convenience.
+func RecentFiles() []string {
+	path, err := recentFilesPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if info, err := os.Stat(line); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, line)
+	}
+	return files
+}
+
+// AddRecentFile moves path to the front of the recent files list,
+// dropping duplicates and trimming the list to maxRecentFiles.
+func AddRecentFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	files := []string{abs}
+	for _, f := range RecentFiles() {
+		if f != abs {
+			files = append(files, f)
+		}
+	}
+	if len(files) > maxRecentFiles {
+		files = files[:maxRecentFiles]
+	}
+
+	statePath, err := recentFilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(statePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(statePath, []byte(strings.Join(files, "\n")+"\n"), 0o644)
+}
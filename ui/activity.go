@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// busyAfter is how long a command runs before the status bar names it, so
+// quick page loads don't flicker.
+const busyAfter = 300 * time.Millisecond
+
+// newSpinner is the spinner shown wherever sqlitui waits on the database.
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(TitleStyle))
+}
+
+// spinnerLine says what is being waited on and for how long, as in
+// "⣾ counting rows... 1.2s".
+func spinnerLine(s spinner.Model, what string, started time.Time) string {
+	return fmt.Sprintf("%s %s... %s", s.View(), what, time.Since(started).Round(100*time.Millisecond))
+}
+
+// inFlight is the background work the status bar reports: the commands
+// wrapped by track, from when they start running until they return. It is
+// shared, as grids and popups start commands without a handle on the
+// model.
+var inFlight = &activityLog{tasks: map[int]activityTask{}}
+
+type activityLog struct {
+	mu    sync.Mutex
+	next  int
+	tasks map[int]activityTask
+}
+
+type activityTask struct {
+	label   string
+	started time.Time
+}
+
+func (l *activityLog) start(label string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.next++
+	l.tasks[l.next] = activityTask{label: label, started: time.Now()}
+	return l.next
+}
+
+func (l *activityLog) finish(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.tasks, id)
+}
+
+// oldest returns the task running longest and how many are running.
+func (l *activityLog) oldest() (activityTask, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var first activityTask
+	for _, t := range l.tasks {
+		if first.started.IsZero() || t.started.Before(first.started) {
+			first = t
+		}
+	}
+	return first, len(l.tasks)
+}
+
+// activityStartedMsg tells the model a tracked command is running, so the
+// status bar spinner turns.
+type activityStartedMsg struct{}
+
+// track wraps cmd so that while it runs the status bar shows label with a
+// spinner and the time it has taken.
+func track(label string, cmd tea.Cmd) tea.Cmd {
+	return tea.Batch(func() tea.Msg {
+		id := inFlight.start(label)
+		defer inFlight.finish(id)
+		return cmd()
+	}, func() tea.Msg { return activityStartedMsg{} })
+}
+
+// updateBusy turns the status bar spinner while tracked commands run.
+func (m Model) updateBusy(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case activityStartedMsg:
+		if m.busyTicking {
+			return m, nil
+		}
+		m.busyTicking = true
+		return m, m.busy.Tick
+	case spinner.TickMsg:
+		if _, n := inFlight.oldest(); n == 0 {
+			m.busyTicking = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.busy, cmd = m.busy.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// busyText is the status bar's note of the longest running command, once
+// it has run for busyAfter, or "".
+func (m Model) busyText() string {
+	task, n := inFlight.oldest()
+	if n == 0 || time.Since(task.started) < busyAfter {
+		return ""
+	}
+	s := spinnerLine(m.busy, task.label, task.started)
+	if n > 1 {
+		s += fmt.Sprintf(" (+%d more)", n-1)
+	}
+	return s
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/markovic-nikola/sqlitui/config"
+)
+
+// Grid alignment per kind of column, installed from the config by
+// applyConfig. columnAligns holds per-column overrides keyed by "column"
+// or "table.column".
+var (
+	numberAlign  = lipgloss.Right
+	textAlign    = lipgloss.Left
+	dateAlign    = lipgloss.Center
+	columnAligns map[string]lipgloss.Position
+)
+
+// dateLayouts are the timestamp shapes recognized as dates: SQLite's own
+// date and datetime text, ISO 8601, and how the driver prints time values.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+}
+
+// parseAlign maps a config alignment name to a lipgloss position.
+func parseAlign(s string) (lipgloss.Position, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "left":
+		return lipgloss.Left, nil
+	case "right":
+		return lipgloss.Right, nil
+	case "center", "centre":
+		return lipgloss.Center, nil
+	}
+	return 0, fmt.Errorf("unknown alignment %q (expected left, right, or center)", s)
+}
+
+// applyAlignConfig installs the [align] section of the user config.
+func applyAlignConfig(cfg config.AlignConfig) error {
+	for _, def := range []struct {
+		name  string
+		value string
+		dst   *lipgloss.Position
+	}{
+		{"numbers", cfg.Numbers, &numberAlign},
+		{"text", cfg.Text, &textAlign},
+		{"dates", cfg.Dates, &dateAlign},
+	} {
+		if def.value == "" {
+			continue
+		}
+		pos, err := parseAlign(def.value)
+		if err != nil {
+			return fmt.Errorf("align.%s: %w", def.name, err)
+		}
+		*def.dst = pos
+	}
+	columnAligns = make(map[string]lipgloss.Position, len(cfg.Columns))
+	for col, value := range cfg.Columns {
+		pos, err := parseAlign(value)
+		if err != nil {
+			return fmt.Errorf("align.columns.%s: %w", col, err)
+		}
+		columnAligns[strings.ToLower(col)] = pos
+	}
+	return nil
+}
+
+// columnAlignments picks the alignment of each of the first n columns: a
+// configured override if there is one, otherwise the default for the kind
+// of values the column holds in rows.
+func columnAlignments(tableName string, columns []string, rows [][]string, n int) []lipgloss.Position {
+	aligns := make([]lipgloss.Position, n)
+	for i := range n {
+		name := strings.ToLower(columns[i])
+		if pos, ok := columnAligns[strings.ToLower(tableName)+"."+name]; ok {
+			aligns[i] = pos
+		} else if pos, ok := columnAligns[name]; ok {
+			aligns[i] = pos
+		} else {
+			aligns[i] = inferAlign(i, rows)
+		}
+	}
+	return aligns
+}
+
+// inferAlign classifies column i by its non-NULL values: numbers if every
+// one parses as a number, dates if every one parses as a timestamp, and
+// text otherwise (including columns with nothing but NULLs).
+func inferAlign(i int, rows [][]string) lipgloss.Position {
+	numbers, dates, seen := true, true, false
+	for _, r := range rows {
+		if i >= len(r) || r[i] == "NULL" || r[i] == "" {
+			continue
+		}
+		seen = true
+		if numbers {
+			if _, err := strconv.ParseFloat(r[i], 64); err != nil {
+				numbers = false
+			}
+		}
+		if dates && !isDate(r[i]) {
+			dates = false
+		}
+		if !numbers && !dates {
+			break
+		}
+	}
+	switch {
+	case !seen:
+		return textAlign
+	case numbers:
+		return numberAlign
+	case dates:
+		return dateAlign
+	}
+	return textAlign
+}
+
+func isDate(s string) bool {
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// alignCell pads s to width according to pos. Values already at least as
+// wide are returned unchanged and truncated by the table as usual.
+func alignCell(s string, width int, pos lipgloss.Position) string {
+	if pos == lipgloss.Left {
+		return s
+	}
+	return lipgloss.PlaceHorizontal(width, pos, s)
+}
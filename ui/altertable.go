@@ -0,0 +1,404 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
+)
+
+// alterOp is one change the ALTER TABLE popup can make.
+type alterOp struct {
+	label  string
+	desc   string
+	prompt string
+	column bool // asks which column first
+}
+
+const (
+	alterAddColumn = iota
+	alterRenameColumn
+	alterRenameTable
+)
+
+var alterOps = []alterOp{
+	alterAddColumn: {
+		label:  "Add column",
+		desc:   "ADD COLUMN; a column ALTER TABLE can't add, like a UNIQUE one or one NOT NULL without a default, is added by rebuilding the table",
+		prompt: "column: ",
+	},
+	alterRenameColumn: {
+		label:  "Rename column",
+		desc:   "RENAME COLUMN; indexes, triggers, and views using the column follow the new name",
+		prompt: "new name: ",
+		column: true,
+	},
+	alterRenameTable: {
+		label:  "Rename table",
+		desc:   "RENAME TO; foreign keys, triggers, and views referring to the table follow the new name",
+		prompt: "new name: ",
+	},
+}
+
+type alterPhase int
+
+const (
+	alterMenu alterPhase = iota
+	alterPickColumn
+	alterPrompt
+	alterRunning
+)
+
+// tableAlteredMsg reports a change the ALTER TABLE popup made to a table,
+// now named table.
+type tableAlteredMsg struct {
+	database *sql.DB
+	from     string
+	table    string
+	note     string
+}
+
+// alterFailedMsg carries the error of a change that couldn't be made.
+type alterFailedMsg struct {
+	err error
+}
+
+// AlterTableModel is the popup for changing a table's definition: it
+// walks through picking the change and naming what it needs, showing the
+// statement it will run, then runs it in the background.
+type AlterTableModel struct {
+	database *sql.DB
+	table    string
+	columns  []string
+	phase    alterPhase
+	cursor   int // the change picked
+	column   int // the column picked, for a rename
+	scroll   int // first column shown
+	listLen  int // columns shown at once
+	input    textinput.Model
+	err      error
+	width    int
+}
+
+func NewAlterTableModel(database *sql.DB, table string, columns []string, termWidth, termHeight int) AlterTableModel {
+	return AlterTableModel{
+		database: database,
+		table:    table,
+		columns:  columns,
+		input:    textinput.New(),
+		width:    max(termWidth*60/100, 50),
+		// Border, padding, title, gaps, and help take 8 lines.
+		listLen: max(termHeight*60/100-8, 3),
+	}
+}
+
+func (m AlterTableModel) Update(msg tea.Msg) (AlterTableModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case alterFailedMsg:
+		m.phase = alterPrompt
+		m.err = msg.err
+		return m, m.input.Focus()
+
+	case tea.KeyMsg:
+		switch m.phase {
+		case alterMenu:
+			switch msg.String() {
+			case "esc":
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			case "up", "k":
+				m.cursor = max(m.cursor-1, 0)
+			case "down", "j":
+				m.cursor = min(m.cursor+1, len(alterOps)-1)
+			case "enter":
+				if alterOps[m.cursor].column {
+					m.phase = alterPickColumn
+					return m, nil
+				}
+				return m, m.prompt("")
+			default:
+				if key.Matches(msg, Keys.AlterTable) {
+					return m, func() tea.Msg { return CloseDetailMsg{} }
+				}
+			}
+			return m, nil
+		case alterPickColumn:
+			switch msg.String() {
+			case "esc":
+				m.phase = alterMenu
+			case "up", "k":
+				m.column = max(m.column-1, 0)
+			case "down", "j":
+				m.column = min(m.column+1, len(m.columns)-1)
+			case "enter":
+				if len(m.columns) > 0 {
+					return m, m.prompt(m.columns[m.column])
+				}
+			}
+			m.scroll = scrollTo(m.column, m.scroll, m.listLen)
+			return m, nil
+		case alterPrompt:
+			switch msg.String() {
+			case "esc":
+				m.input.Blur()
+				m.err = nil
+				if alterOps[m.cursor].column {
+					m.phase = alterPickColumn
+				} else {
+					m.phase = alterMenu
+				}
+				return m, nil
+			case "enter":
+				if m.statement() == "" {
+					return m, nil
+				}
+				m.input.Blur()
+				m.phase = alterRunning
+				m.err = nil
+				return m, m.run()
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	default:
+		if m.phase == alterPrompt {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// prompt asks for what the picked change needs, starting from value.
+func (m *AlterTableModel) prompt(value string) tea.Cmd {
+	op := alterOps[m.cursor]
+	m.phase = alterPrompt
+	m.err = nil
+	m.input.Prompt = op.prompt
+	m.input.Placeholder = ""
+	if m.cursor == alterAddColumn {
+		m.input.Placeholder = "name TYPE [NOT NULL] [DEFAULT ...]"
+	}
+	m.input.Width = m.width - 8 - len(op.prompt)
+	if m.cursor == alterRenameTable {
+		_, value = db.SplitTable(m.table)
+	}
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// statement is the ALTER TABLE statement the popup would run, or "" while
+// the prompt is empty or unchanged.
+func (m AlterTableModel) statement() string {
+	v := strings.TrimSpace(m.input.Value())
+	if v == "" {
+		return ""
+	}
+	switch m.cursor {
+	case alterAddColumn:
+		return db.AddColumnSQL(m.table, v)
+	case alterRenameColumn:
+		if v == m.columns[m.column] {
+			return ""
+		}
+		return db.RenameColumnSQL(m.table, m.columns[m.column], v)
+	default:
+		if _, name := db.SplitTable(m.table); v == name {
+			return ""
+		}
+		return db.RenameTableSQL(m.table, v)
+	}
+}
+
+// run makes the change in the background.
+func (m AlterTableModel) run() tea.Cmd {
+	database, table, op := m.database, m.table, m.cursor
+	v := strings.TrimSpace(m.input.Value())
+	if op == alterRenameTable {
+		return renameTableCmd(database, table, v)
+	}
+	var column string
+	if op == alterRenameColumn {
+		column = m.columns[m.column]
+	}
+	return func() tea.Msg {
+		ctx := context.Background()
+		done := tableAlteredMsg{database: database, from: table, table: table}
+		switch op {
+		case alterAddColumn:
+			rebuilt, err := db.AddColumn(ctx, database, table, v)
+			if err != nil {
+				return alterFailedMsg{err: err}
+			}
+			done.note = "added a column to " + table
+			if rebuilt {
+				done.note += " by rebuilding it"
+			}
+		case alterRenameColumn:
+			if err := db.RenameColumn(ctx, database, table, column, v); err != nil {
+				return alterFailedMsg{err: err}
+			}
+			done.note = fmt.Sprintf("renamed %s.%s to %s", table, column, v)
+		}
+		return done
+	}
+}
+
+// renameTableCmd renames table to name in the background, for the popup
+// and for renaming in the table list.
+func renameTableCmd(database *sql.DB, table, name string) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.RenameTable(context.Background(), database, table, name); err != nil {
+			return alterFailedMsg{err: err}
+		}
+		to := db.RenamedName(table, name)
+		return tableAlteredMsg{database: database, from: table, table: to, note: fmt.Sprintf("renamed %s to %s", table, to)}
+	}
+}
+
+// tableAltered follows a change to a table's definition: the journal's
+// edits to it go, grids showing a renamed table are bound to its new name,
+// and the tables are listed anew.
+func (m *Model) tableAltered(msg tableAlteredMsg) tea.Cmd {
+	m.forgetTableEdits(msg.database, msg.from)
+	m.note = msg.note
+	var refresh tea.Cmd
+	if msg.from != msg.table && msg.database == m.db {
+		if m.dataLoaded && !m.tableData.static && m.tableData.tableName == msg.from {
+			m.tableData.tableName = msg.table
+		}
+		for i := range m.tabs {
+			if m.tabs[i].tableName == msg.from && !m.tabs[i].static {
+				m.tabs[i].tableName = msg.table
+			}
+		}
+		if m.lastTableName == msg.from {
+			m.lastTableName = msg.table
+		}
+		if m.showPinned && !m.pinned.static && m.pinned.database == m.db && m.pinned.tableName == msg.from {
+			m.pinned.tableName = msg.table
+			refresh = m.pinned.refreshCmd()
+		}
+	}
+	return tea.Batch(reloadSchemaCmd(msg.database, m.dbPath, msg.table), refresh, m.txChangesCmd())
+}
+
+func (m AlterTableModel) View() string {
+	op := alterOps[m.cursor]
+	title := " Alter " + m.table + " "
+	var body, help string
+	switch m.phase {
+	case alterMenu:
+		var b strings.Builder
+		for i, o := range alterOps {
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+o.label) + "\n")
+			} else {
+				b.WriteString(StatusBarStyle.Render("  "+o.label) + "\n")
+			}
+		}
+		body = b.String() + "\n" + StatusBarStyle.Render(op.desc)
+		help = "↑↓: select | enter: choose | esc: close"
+	case alterPickColumn:
+		title = " " + op.label + " "
+		var b strings.Builder
+		end := min(m.scroll+m.listLen, len(m.columns))
+		for i, c := range m.columns[m.scroll:end] {
+			if i += m.scroll; i == m.column {
+				b.WriteString(TitleStyle.Render("▸ "+c) + "\n")
+			} else {
+				b.WriteString(StatusBarStyle.Render("  "+c) + "\n")
+			}
+		}
+		body = strings.TrimSuffix(b.String(), "\n")
+		help = "↑↓: select | enter: choose | esc: back"
+	case alterPrompt, alterRunning:
+		title = " " + op.label + " "
+		body = StatusBarStyle.Render(op.desc) + "\n\n" + m.input.View()
+		if stmt := m.statement(); stmt != "" {
+			body += "\n\n" + StatusBarStyle.Render(stmt+";")
+		}
+		if m.err != nil {
+			body += "\n\n" + ErrorStyle.Render("Error: "+m.err.Error())
+		}
+		help = "enter: run | esc: back"
+		if m.phase == alterRunning {
+			help = "running..."
+		}
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+// targetTable is the table the ALTER TABLE and empty table popups work on:
+// the one selected in the table list when it has focus, else the one in
+// the data pane, or "" for a view or a query result.
+func (m Model) targetTable() string {
+	if m.focused == paneList {
+		if item, ok := m.tableList.list.SelectedItem().(TableItem); ok && item.Kind != db.KindView {
+			return item.Name
+		}
+		return ""
+	}
+	if m.dataLoaded && !m.tableData.static {
+		return m.tableData.tableName
+	}
+	return ""
+}
+
+// targetView is the view the view definition popup shows: the one
+// selected in the table list when it has focus, else the one in the data
+// pane, or "".
+func (m Model) targetView() string {
+	if m.focused == paneList {
+		if item, ok := m.tableList.list.SelectedItem().(TableItem); ok && item.Kind == db.KindView {
+			return item.Name
+		}
+		return ""
+	}
+	if m.dataLoaded && m.tableData.static && m.tableList.isView(m.tableData.tableName) {
+		return m.tableData.tableName
+	}
+	return ""
+}
+
+// schemaReloadedMsg carries the tables listed anew after a change to the
+// schema of table, which the data pane reloads if it shows it.
+type schemaReloadedMsg struct {
+	database *sql.DB
+	tables   []string
+	entries  []db.ListEntry
+	schema   *state.SchemaCache
+	table    string
+	err      error
+}
+
+// reloadSchemaCmd lists the tables of database again after the schema of
+// table changed. The schema cache is left alone while a transaction is
+// open: the file doesn't have the change yet.
+func reloadSchemaCmd(database *sql.DB, path, table string) tea.Cmd {
+	return func() tea.Msg {
+		msg := schemaReloadedMsg{database: database, table: table}
+		if db.InTransaction(database) {
+			msg.entries, msg.err = db.ListEntries(context.Background(), database)
+			if msg.err == nil {
+				msg.tables, msg.err = db.ListTables(context.Background(), database)
+			}
+			return msg
+		}
+		msg.tables, msg.entries, msg.schema, msg.err = listTables(database, path)
+		return msg
+	}
+}
@@ -0,0 +1,243 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
+)
+
+// bookmarks are the rows marked on one database, oldest first, saved in
+// the state directory after every change. Every grid of the database
+// shares them.
+type bookmarks struct {
+	dbPath string
+	list   []state.Bookmark
+}
+
+func loadBookmarks(dbPath string) *bookmarks {
+	return &bookmarks{dbPath: dbPath, list: state.LoadBookmarks(dbPath)}
+}
+
+func (b *bookmarks) index(table, key string) int {
+	if b == nil {
+		return -1
+	}
+	return slices.IndexFunc(b.list, func(e state.Bookmark) bool { return e.Table == table && e.Key == key })
+}
+
+// toggle adds bm, or removes it if its row is already marked.
+func (b *bookmarks) toggle(bm state.Bookmark) error {
+	if i := b.index(bm.Table, bm.Key); i >= 0 {
+		b.list = slices.Delete(b.list, i, i+1)
+	} else {
+		b.list = append(b.list, bm)
+	}
+	return state.SaveBookmarks(b.dbPath, b.list)
+}
+
+func (b *bookmarks) remove(i int) error {
+	b.list = slices.Delete(b.list, i, i+1)
+	return state.SaveBookmarks(b.dbPath, b.list)
+}
+
+// Row keys: the index of the single primary key column values are
+// bookmarked by, or one of these.
+const (
+	keyRowID     = -1 // no primary key: the rowid
+	keyComposite = -2 // a primary key of several columns, which can't be bookmarked
+)
+
+// loadRowKey looks up which column identifies the grid's rows.
+func (m *TableDataModel) loadRowKey() {
+	if m.keyChecked || m.static || m.database == nil {
+		return
+	}
+	m.keyChecked = true
+	m.keyCol = keyRowID
+	cols, err := db.GetColumnInfo(context.Background(), m.database, m.tableName)
+	if err != nil {
+		return
+	}
+	for i, c := range cols {
+		if c.PK == 0 {
+			continue
+		}
+		if m.keyCol != keyRowID {
+			m.keyCol = keyComposite
+			return
+		}
+		m.keyCol = i
+	}
+}
+
+// rowKey is what the n-th row of the page is bookmarked by.
+func (m TableDataModel) rowKey(n int) (string, bool) {
+	if m.static || !m.keyChecked || n < 0 || n >= len(m.allRows) {
+		return "", false
+	}
+	switch {
+	case m.keyCol == keyRowID && n < len(m.allRowIDs):
+		return strconv.FormatInt(m.allRowIDs[n], 10), true
+	case m.keyCol >= 0 && m.keyCol < len(m.allRows[n]):
+		return m.allRows[n][m.keyCol], true
+	}
+	return "", false
+}
+
+// toggleBookmark marks the selected row, or unmarks it.
+func (m *TableDataModel) toggleBookmark() tea.Cmd {
+	m.loadRowKey()
+	if m.keyCol == keyComposite {
+		return noteCmd(errors.New("rows of tables with a primary key of several columns can't be bookmarked"))
+	}
+	cursor := m.table.Cursor()
+	k, ok := m.rowKey(cursor)
+	if !ok || m.bookmarks == nil {
+		return nil
+	}
+	label := strings.Join(m.allRows[cursor], " · ")
+	if err := m.bookmarks.toggle(state.Bookmark{Table: m.tableName, Key: k, Label: truncateValue(label, 80)}); err != nil {
+		return noteCmd(err)
+	}
+	return nil
+}
+
+// bookmarked reports whether the selected row is bookmarked.
+func (m TableDataModel) bookmarked() bool {
+	k, ok := m.rowKey(m.table.Cursor())
+	return ok && m.bookmarks.index(m.tableName, k) >= 0
+}
+
+// jumpToBookmarkMsg asks to show a bookmarked row.
+type jumpToBookmarkMsg struct {
+	bookmark state.Bookmark
+}
+
+// jumpToBookmark shows the table of bm with the cursor on its row, in the
+// grid or tab that already shows the table if there is one.
+func (m *Model) jumpToBookmark(bm state.Bookmark) tea.Cmd {
+	if !slices.Contains(m.tables, bm.Table) {
+		m.note = ErrorStyle.Render(fmt.Sprintf("no table %q in this database", bm.Table))
+		return nil
+	}
+	m.focused = paneData
+	if i := m.findTab(bm.Table); i >= 0 {
+		m.switchTab(i)
+	}
+	if m.dataLoaded && !m.tableData.static && m.tableData.tableName == bm.Table {
+		return m.tableData.keyJumpCmd(bm.Key)
+	}
+	m.pendingBookmark = &bm
+	return m.loadTableCmd(bm.Table)
+}
+
+// BookmarksModel is the popup listing the bookmarked rows of the database,
+// to jump back to one or drop it.
+type BookmarksModel struct {
+	marks  *bookmarks
+	cursor int
+	scroll int
+	err    error
+
+	width   int
+	listLen int // rows visible at once
+}
+
+func NewBookmarksModel(marks *bookmarks, termWidth, termHeight int) BookmarksModel {
+	return BookmarksModel{
+		marks: marks,
+		width: max(termWidth*70/100, 60),
+		// Border, padding, title, gaps, and help take 8 lines.
+		listLen: max(termHeight*70/100-8, 3),
+	}
+}
+
+func (m BookmarksModel) Update(msg tea.Msg) (BookmarksModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	n := len(m.marks.list)
+	switch keyMsg.String() {
+	case "esc":
+		return m, func() tea.Msg { return CloseDetailMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, n-1)
+	case "pgup":
+		m.cursor = max(m.cursor-m.listLen, 0)
+	case "pgdown":
+		m.cursor = min(m.cursor+m.listLen, n-1)
+	case "enter":
+		if n > 0 {
+			bm := m.marks.list[m.cursor]
+			return m, func() tea.Msg { return jumpToBookmarkMsg{bookmark: bm} }
+		}
+	case "d", "delete":
+		if n > 0 {
+			m.err = m.marks.remove(m.cursor)
+			m.cursor = max(min(m.cursor, n-2), 0)
+		}
+	default:
+		if key.Matches(keyMsg, Keys.Bookmarks) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	m.cursor = max(m.cursor, 0)
+	m.scroll = scrollTo(m.cursor, m.scroll, m.listLen)
+	return m, nil
+}
+
+func (m BookmarksModel) View() string {
+	list := m.marks.list
+	title := TitleStyle.Render(fmt.Sprintf(" Bookmarks (%d) ", len(list)))
+	help := "↑↓: select | enter: go to row | d: remove | esc: close"
+	var body string
+	if len(list) == 0 {
+		body = "No bookmarks. " + Keys.Bookmark.Help().Key + " on a row marks it."
+		help = "esc: close"
+	} else {
+		width := m.width - 6
+		tableWidth, keyWidth := 0, 0
+		for _, bm := range list {
+			tableWidth = max(tableWidth, len([]rune(bm.Table)))
+			keyWidth = max(keyWidth, len([]rune(bm.Key)))
+		}
+		tableWidth, keyWidth = min(tableWidth, width/4), min(keyWidth, width/4)
+		var b strings.Builder
+		end := min(m.scroll+m.listLen, len(list))
+		for i := m.scroll; i < end; i++ {
+			bm := list[i]
+			t := truncateValue(bm.Table, tableWidth)
+			t += strings.Repeat(" ", tableWidth-len([]rune(t)))
+			k := truncateValue(bm.Key, keyWidth)
+			k += strings.Repeat(" ", keyWidth-len([]rune(k)))
+			label := StatusBarStyle.Render(truncateValue(bm.Label, max(width-tableWidth-keyWidth-4, 5)))
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+t+" "+k) + " " + label + "\n")
+			} else {
+				b.WriteString("  " + t + " " + k + " " + label + "\n")
+			}
+		}
+		if len(list) > m.listLen {
+			b.WriteString(StatusBarStyle.Render(fmt.Sprintf("%d/%d", m.cursor+1, len(list))))
+		}
+		body = strings.TrimRight(b.String(), "\n")
+	}
+	if m.err != nil {
+		body += "\n\n" + ErrorStyle.Render("Error: "+m.err.Error())
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(title + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+)
+
+// copyToClipboard puts text on the system clipboard. Without a clipboard
+// tool (xclip, xsel, wl-copy, pbcopy, ...) — typically over SSH — it falls
+// back to the OSC 52 escape sequence, which terminals that support it turn
+// into a copy on the user's machine.
+func copyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+// readClipboard returns the text on the system clipboard. Without a
+// clipboard tool there is no reading it, OSC 52 or not; the terminal's own
+// paste still gets through as a bracketed paste.
+func readClipboard() (string, error) {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		return "", fmt.Errorf("can't read the clipboard (%v); paste with the terminal instead", err)
+	}
+	return text, nil
+}
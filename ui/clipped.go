@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// clippedMark stands in the left margin of a row with a value cut off at
+// its column's width.
+const clippedMark = "›"
+
+// clippedCell returns the column, as the table widget holds them, of the
+// widest value of the page's row r cut off at its column's width, or -1 if
+// none is.
+func (m TableDataModel) clippedCell(r int) int {
+	rows, cols := m.table.Rows(), m.table.Columns()
+	if r < 0 || r >= len(rows) {
+		return -1
+	}
+	widest, over := -1, 0
+	for i := len(m.leading); i < len(m.leading)+m.displayCols && i < len(rows[r]) && i < len(cols); i++ {
+		if w := runewidth.StringWidth(rows[r][i]) - cols[i].Width; w > over {
+			widest, over = i, w
+		}
+	}
+	return widest
+}
+
+// markClipped sets clippedMark in the left margin of the rows of the table
+// widget's view with a value cut off. The selected row is on line of the
+// body (see cursorLine).
+func (m TableDataModel) markClipped(view string, line int) string {
+	lines := strings.Split(view, "\n")
+	if line < 0 || len(lines) <= headerLines {
+		return view
+	}
+	body := lines[headerLines:]
+	for k := range body {
+		if m.clippedCell(m.table.Cursor()-line+k) >= 0 {
+			body[k] = setMargin(body[k], clippedMark)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setMargin puts mark in place of the space a drawn row starts with, the
+// first cell's padding, after the escape codes styling it.
+func setMargin(row, mark string) string {
+	i := 0
+	for strings.HasPrefix(row[i:], "\x1b[") {
+		end := strings.IndexByte(row[i:], 'm')
+		if end < 0 {
+			return row
+		}
+		i += end + 1
+	}
+	if !strings.HasPrefix(row[i:], " ") {
+		return row
+	}
+	return row[:i] + mark + row[i+1:]
+}
+
+// clippedText shows, for the status bar, the widest value of the selected
+// row cut off in the grid, in full, with its column's name.
+func (m TableDataModel) clippedText() string {
+	cursor := m.table.Cursor()
+	i := m.clippedCell(cursor)
+	if i < 0 {
+		return ""
+	}
+	return m.columns[i-len(m.leading)] + ": " + strings.TrimSpace(m.table.Rows()[cursor][i])
+}
@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// minResizeWidth is the narrowest a column can be made with the resize
+// keys: enough for a character and the "…" cutting it off.
+const minResizeWidth = 3
+
+// columnWidths holds the widths columns were given with the resize keys,
+// by table and column, for the session. Every grid of a database shares
+// it; query results keep their own.
+type columnWidths map[string]map[string]int
+
+// setWidths hands the grid the database's column widths, fitting its
+// columns again if some of its table's were set.
+func (m *TableDataModel) setWidths(widths columnWidths) {
+	m.widths = widths
+	if len(widths[m.tableName]) > 0 {
+		m.refit()
+	}
+}
+
+// setWidthsOf lists the width set for each column, 0 where none is, or
+// returns nil if none is.
+func (m TableDataModel) setWidthsOf() []int {
+	set := m.widths[m.tableName]
+	if len(set) == 0 {
+		return nil
+	}
+	widths := make([]int, len(m.columns))
+	for i, c := range m.columns {
+		widths[i] = set[c]
+	}
+	return widths
+}
+
+// refit fits the columns again, as after resizing one, keeping the cursor.
+func (m *TableDataModel) refit() {
+	cursor := m.table.Cursor()
+	m.refitColumns()
+	m.setTableRows(m.allRows)
+	m.table.SetCursor(max(cursor, 0))
+}
+
+// startResize picks the first column shown for resizing; the arrows pick
+// another one until esc or enter.
+func (m *TableDataModel) startResize() {
+	m.resizing = true
+	m.resizeCol = 0
+	m.table.SetHeight(m.tableHeight())
+}
+
+// updateResize handles keys while a column is picked for resizing: ← and
+// → pick the one before or after it, + and - widen or narrow it, and 0
+// measures it again, as if it had never been resized.
+func (m TableDataModel) updateResize(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "enter":
+		m.resizing = false
+		m.table.SetHeight(m.tableHeight())
+	case "left", "h":
+		m.resizeCol = max(m.resizeCol-1, 0)
+	case "right", "l":
+		m.resizeCol = max(min(m.resizeCol+1, m.displayCols-1), 0)
+	case "+", "=":
+		m.resizeBy(1)
+	case "-", "_":
+		m.resizeBy(-1)
+	case "0":
+		if col, ok := m.resizedColumn(); ok {
+			delete(m.widths[m.tableName], col)
+			m.refit()
+		}
+	}
+	return m, nil
+}
+
+// resizedColumn names the column picked for resizing, if it is shown.
+func (m TableDataModel) resizedColumn() (string, bool) {
+	if m.resizeCol >= m.displayCols || m.resizeCol >= len(m.columns) {
+		return "", false
+	}
+	return m.columns[m.resizeCol], true
+}
+
+// resizeBy widens the column picked by delta, or narrows it, no further
+// than the grid is wide. Its new width is then set for the session.
+func (m *TableDataModel) resizeBy(delta int) {
+	col, ok := m.resizedColumn()
+	if !ok {
+		return
+	}
+	// The width fitColumns leaves for the columns, the last shown one's
+	// at the least.
+	room := m.width - 2 - leadingSpace(m.leading) - 2
+	w := m.table.Columns()[len(m.leading)+m.resizeCol].Width + delta
+	w = max(min(w, room), minResizeWidth)
+	if m.widths == nil {
+		m.widths = columnWidths{}
+	}
+	if m.widths[m.tableName] == nil {
+		m.widths[m.tableName] = map[string]int{}
+	}
+	m.widths[m.tableName][col] = w
+	m.refit()
+	// Widening a column can leave the one picked out of sight.
+	m.resizeCol = min(m.resizeCol, max(m.displayCols-1, 0))
+}
+
+// resizeStatus tells, below the grid, which column is picked for
+// resizing and how wide it is.
+func (m TableDataModel) resizeStatus() string {
+	col, ok := m.resizedColumn()
+	if !ok {
+		return StatusBarStyle.Render("no column to resize")
+	}
+	width := m.table.Columns()[len(m.leading)+m.resizeCol].Width
+	how := "fitted"
+	if m.widths[m.tableName][col] > 0 {
+		how = "set"
+	}
+	return StatusBarStyle.Render(fmt.Sprintf("resize %s: %d, %s (←→: column, +/-: width, 0: fit, esc: done)", col, width, how))
+}
+
+// markResizedHeader redraws the header of the table widget's view, as the
+// widget does, with the column picked for resizing marked.
+func (m TableDataModel) markResizedHeader(view string) string {
+	// The header and its bottom border come first.
+	lines := strings.SplitN(view, "\n", 3)
+	if len(lines) < 3 {
+		return view
+	}
+	styles := tableStyles()
+	cols := m.table.Columns()
+	cells := make([]string, 0, len(cols))
+	for i, c := range cols {
+		if c.Width <= 0 {
+			continue
+		}
+		header := styles.Header
+		if i == len(m.leading)+m.resizeCol {
+			header = header.Inherit(MatchStyle)
+		}
+		style := lipgloss.NewStyle().Width(c.Width).MaxWidth(c.Width).Inline(true)
+		cells = append(cells, header.Render(style.Render(runewidth.Truncate(c.Title, c.Width, "…"))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cells...) + "\n" + lines[2]
+}
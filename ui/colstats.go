@@ -0,0 +1,344 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// columnStatsMsg carries the statistics computed for one column.
+type columnStatsMsg struct {
+	column  string
+	stats   db.ColumnStats
+	elapsed time.Duration
+	err     error
+}
+
+// tableProfileMsg carries the NULL, empty, and distinct counts of every
+// column of the table.
+type tableProfileMsg struct {
+	rows     int64
+	profiles []db.ColumnProfile
+	elapsed  time.Duration
+	err      error
+}
+
+// ColumnStatsModel is the popup profiling a table's columns: pick a column
+// and its counts, range, average, and most frequent values are computed in
+// the background, or profile every column at once for its missing and
+// distinct values.
+type ColumnStatsModel struct {
+	database *sql.DB
+	table    string
+	columns  []db.ColumnInfo
+	cursor   int
+	scroll   int
+
+	running   bool
+	profiling bool // the running computation is the whole-table profile
+	cancel    context.CancelFunc
+	spinner   spinner.Model
+	started   time.Time
+
+	result        *columnStatsMsg  // shown instead of the list once computed
+	profile       *tableProfileMsg // likewise
+	profileScroll int
+	width         int
+	listLen       int // column rows visible at once
+}
+
+func NewColumnStatsModel(database *sql.DB, table string, termWidth, termHeight int) (ColumnStatsModel, error) {
+	columns, err := db.GetColumnInfo(context.Background(), database, table)
+	if err != nil {
+		return ColumnStatsModel{}, err
+	}
+	return ColumnStatsModel{
+		database: database,
+		table:    table,
+		columns:  columns,
+		spinner:  newSpinner(),
+		width:    max(termWidth*50/100, 50),
+		// Border, padding, title, gap, and help take 8 lines.
+		listLen: max(termHeight*60/100-8, 3),
+	}, nil
+}
+
+func (m ColumnStatsModel) Update(msg tea.Msg) (ColumnStatsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case columnStatsMsg:
+		if !m.running || m.profiling || msg.column != m.columns[m.cursor].Name {
+			return m, nil
+		}
+		m.running = false
+		m.cancel = nil
+		m.result = &msg
+		return m, nil
+
+	case tableProfileMsg:
+		if !m.running || !m.profiling {
+			return m, nil
+		}
+		m.running = false
+		m.cancel = nil
+		m.profile = &msg
+		m.profileScroll = 0
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.running:
+			if msg.String() == "esc" {
+				m.cancel()
+				m.cancel = nil
+				m.running = false
+			}
+			return m, nil
+		case m.result != nil:
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.result = nil
+			}
+			return m, nil
+		case m.profile != nil:
+			switch msg.String() {
+			case "esc", "enter":
+				m.profile = nil
+			case "up", "k":
+				m.profileScroll = max(m.profileScroll-1, 0)
+			case "down", "j":
+				m.profileScroll = min(m.profileScroll+1, max(len(m.profile.profiles)-m.profileLen(), 0))
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, len(m.columns)-1)
+		case "pgup":
+			m.cursor = max(m.cursor-m.listLen, 0)
+		case "pgdown":
+			m.cursor = min(m.cursor+m.listLen, len(m.columns)-1)
+		case "enter":
+			if len(m.columns) > 0 {
+				return m.start()
+			}
+		case "p":
+			if len(m.columns) > 0 {
+				return m.startProfile()
+			}
+		default:
+			if key.Matches(msg, Keys.ColumnStats) {
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+		}
+		// Keep the cursor within the visible window.
+		if m.cursor < m.scroll {
+			m.scroll = m.cursor
+		} else if m.cursor >= m.scroll+m.listLen {
+			m.scroll = m.cursor - m.listLen + 1
+		}
+	}
+	return m, nil
+}
+
+// start computes the selected column's statistics in the background.
+func (m ColumnStatsModel) start() (ColumnStatsModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.profiling = false
+	m.started = time.Now()
+	database, table, column, started := m.database, m.table, m.columns[m.cursor].Name, m.started
+	run := func() tea.Msg {
+		stats, err := db.ColumnStatistics(ctx, database, table, column)
+		return columnStatsMsg{column: column, stats: stats, elapsed: time.Since(started), err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+// startProfile counts every column's NULLs, empty strings, and distinct
+// values in the background.
+func (m ColumnStatsModel) startProfile() (ColumnStatsModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.profiling = true
+	m.started = time.Now()
+	database, table, started := m.database, m.table, m.started
+	run := func() tea.Msg {
+		rows, profiles, err := db.TableProfile(ctx, database, table)
+		return tableProfileMsg{rows: rows, profiles: profiles, elapsed: time.Since(started), err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+func (m ColumnStatsModel) View() string {
+	var title, body, help string
+	switch {
+	case m.running:
+		title = " " + m.columns[m.cursor].Name + " "
+		if m.profiling {
+			title = " Profile: " + m.table + " "
+		}
+		body = spinnerLine(m.spinner, "computing", m.started)
+		help = "esc: cancel"
+	case m.result != nil:
+		title = fmt.Sprintf(" %s (%s) ", m.result.column, m.result.elapsed.Round(time.Millisecond))
+		if m.result.err != nil {
+			body = ErrorStyle.Render("Error: " + m.result.err.Error())
+		} else {
+			body = formatColumnStats(m.result.stats, m.width-6)
+		}
+		help = "esc/enter: back"
+	case m.profile != nil:
+		title = fmt.Sprintf(" Profile: %s (%s) ", m.table, m.profile.elapsed.Round(time.Millisecond))
+		if m.profile.err != nil {
+			body = ErrorStyle.Render("Error: " + m.profile.err.Error())
+		} else {
+			body = m.profileView()
+		}
+		help = "esc/enter: back"
+		if m.profile.err == nil && len(m.profile.profiles) > m.profileLen() {
+			help = "↑↓: scroll | " + help
+		}
+	default:
+		title = " Column statistics: " + m.table + " "
+		var b strings.Builder
+		end := min(m.scroll+m.listLen, len(m.columns))
+		for i := m.scroll; i < end; i++ {
+			c := m.columns[i]
+			line := c.Name
+			if c.Type != "" {
+				line += " " + StatusBarStyle.Render(strings.ToLower(c.Type))
+			}
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+c.Name) + strings.TrimPrefix(line, c.Name) + "\n")
+			} else {
+				b.WriteString("  " + line + "\n")
+			}
+		}
+		body = strings.TrimRight(b.String(), "\n")
+		help = "↑↓: select | enter: compute | p: profile all | esc: close"
+		if len(m.columns) > m.listLen {
+			help += fmt.Sprintf(" (%d/%d)", m.cursor+1, len(m.columns))
+		}
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+// profileLen is how many columns the profile shows at once, below its row
+// count and header.
+func (m ColumnStatsModel) profileLen() int {
+	return max(m.listLen-3, 1)
+}
+
+// profileView tabulates the profile: per column, its NULLs and empty
+// strings with their share of the rows, and its distinct values.
+func (m ColumnStatsModel) profileView() string {
+	p := m.profile
+	pct := func(n int64) string {
+		if p.rows == 0 || n == 0 {
+			return strconv.FormatInt(n, 10)
+		}
+		return fmt.Sprintf("%d (%.1f%%)", n, float64(n)*100/float64(p.rows))
+	}
+	nameWidth := len("column")
+	for _, c := range p.profiles {
+		nameWidth = max(nameWidth, len([]rune(c.Column)))
+	}
+	nameWidth = min(nameWidth, (m.width-6)/3)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d rows\n\n", p.rows)
+	b.WriteString(PopupLabelStyle.Render(fmt.Sprintf("%-*s %16s %16s %10s", nameWidth, "column", "nulls", "empty", "distinct")) + "\n")
+	end := min(m.profileScroll+m.profileLen(), len(p.profiles))
+	for _, c := range p.profiles[m.profileScroll:end] {
+		name := truncateValue(c.Column, nameWidth)
+		fmt.Fprintf(&b, "%s%s %16s %16s %10d\n", name, strings.Repeat(" ", nameWidth-len([]rune(name))), pct(c.Nulls), pct(c.Empty), c.Distinct)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatColumnStats lays out stats as aligned label/value lines followed
+// by a bar chart of the most frequent values.
+func formatColumnStats(s db.ColumnStats, width int) string {
+	pct := func(n int64) string {
+		if s.Rows == 0 {
+			return strconv.FormatInt(n, 10)
+		}
+		return fmt.Sprintf("%d (%.1f%%)", n, float64(n)*100/float64(s.Rows))
+	}
+	lines := [][2]string{
+		{"rows", strconv.FormatInt(s.Rows, 10)},
+		{"values", pct(s.Count)},
+		{"nulls", pct(s.Nulls)},
+		{"distinct", strconv.FormatInt(s.Distinct, 10)},
+	}
+	if s.Count > 0 {
+		lines = append(lines, [2]string{"min", s.Min}, [2]string{"max", s.Max})
+	}
+	if s.Numeric {
+		lines = append(lines, [2]string{"avg", strconv.FormatFloat(s.Avg, 'g', 6, 64)})
+	}
+
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteString(PopupLabelStyle.Render(fmt.Sprintf("%8s", l[0])) + " : " + truncateValue(l[1], width-11) + "\n")
+	}
+	if len(s.Top) == 0 {
+		return strings.TrimRight(b.String(), "\n")
+	}
+	if s.Distinct == s.Count {
+		return b.String() + "\n" + StatusBarStyle.Render("every value is distinct")
+	}
+
+	b.WriteString("\n" + TitleStyle.Render("Most frequent") + "\n")
+	valueWidth := 0
+	for _, vc := range s.Top {
+		valueWidth = max(valueWidth, len([]rune(truncateValue(vc.Value, width))))
+	}
+	valueWidth = min(valueWidth, width/2)
+	countWidth := len(strconv.FormatInt(s.Top[0].Count, 10))
+	barWidth := max(width-valueWidth-countWidth-4, 1)
+	for _, vc := range s.Top {
+		bar := int(vc.Count * int64(barWidth) / s.Top[0].Count)
+		v := truncateValue(vc.Value, valueWidth)
+		v += strings.Repeat(" ", valueWidth-len([]rune(v)))
+		fmt.Fprintf(&b, "%s  %*d %s\n", v, countWidth, vc.Count, TitleStyle.Render(strings.Repeat("█", max(bar, 1))))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// truncateValue shortens s to at most width runes, flattened to one line,
+// marking a cut with "…".
+func truncateValue(s string, width int) string {
+	if strings.ContainsAny(s, "\n\r\t") {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	r := []rune(s)
+	if len(r) <= width || width < 1 {
+		return s
+	}
+	return string(r[:width-1]) + "…"
+}
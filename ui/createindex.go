@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// indexCreatedMsg reports the index the create index popup made.
+type indexCreatedMsg struct {
+	table string
+	name  string
+}
+
+// indexFailedMsg carries the error of a CREATE INDEX that failed.
+type indexFailedMsg struct {
+	err error
+}
+
+// The parts of the create index popup tab moves between.
+const (
+	indexFocusColumns = iota
+	indexFocusName
+	indexFocusWhere
+)
+
+// CreateIndexModel is the popup for indexing a table: pick the columns in
+// order, choose whether the index is UNIQUE, and optionally give a WHERE
+// for a partial index, with the CREATE INDEX statement shown as it forms.
+type CreateIndexModel struct {
+	database   *sql.DB
+	table      string
+	columns    []string
+	picked     []string // in index order
+	unique     bool
+	focus      int
+	cursor     int
+	scroll     int
+	listLen    int
+	name       textinput.Model
+	nameEdited bool // typed over, so picking columns leaves it alone
+	where      textinput.Model
+	running    bool
+	err        error
+	width      int
+}
+
+// NewCreateIndexModel opens the popup on table, with suggest, the column
+// the grid is filtered by if any, already picked.
+func NewCreateIndexModel(database *sql.DB, table string, columns []string, suggest string, termWidth, termHeight int) CreateIndexModel {
+	width := max(termWidth*60/100, 50)
+	m := CreateIndexModel{
+		database: database,
+		table:    table,
+		columns:  columns,
+		name:     textinput.New(),
+		where:    textinput.New(),
+		width:    width,
+		// Border, padding, title, inputs, statement, gaps, and help take
+		// 16 lines.
+		listLen: max(termHeight*70/100-16, 3),
+	}
+	m.name.Prompt = "name: "
+	m.name.Width = width - 8 - len(m.name.Prompt)
+	m.where.Prompt = "where: "
+	m.where.Placeholder = "optional, for a partial index"
+	m.where.Width = width - 8 - len(m.where.Prompt)
+	if i := slices.Index(columns, suggest); i >= 0 {
+		m.picked = []string{suggest}
+		m.cursor = i
+		m.scroll = scrollTo(m.cursor, 0, m.listLen)
+	}
+	m.name.SetValue(db.IndexName(table, m.picked))
+	return m
+}
+
+func (m CreateIndexModel) Update(msg tea.Msg) (CreateIndexModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case indexFailedMsg:
+		m.running = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "tab", "shift+tab":
+			step := 1
+			if msg.String() == "shift+tab" {
+				step = 2
+			}
+			return m, m.setFocus((m.focus + step) % 3)
+		case "enter":
+			if len(m.picked) == 0 || strings.TrimSpace(m.name.Value()) == "" {
+				return m, nil
+			}
+			m.running = true
+			m.err = nil
+			database, table, picked, unique := m.database, m.table, slices.Clone(m.picked), m.unique
+			name, where := strings.TrimSpace(m.name.Value()), m.where.Value()
+			return m, func() tea.Msg {
+				if err := db.CreateIndex(context.Background(), database, table, name, picked, unique, where); err != nil {
+					return indexFailedMsg{err: err}
+				}
+				return indexCreatedMsg{table: table, name: name}
+			}
+		}
+		if m.focus == indexFocusColumns {
+			if key.Matches(msg, Keys.CreateIndex) {
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+			return m.updateColumns(msg), nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.focus {
+	case indexFocusName:
+		before := m.name.Value()
+		m.name, cmd = m.name.Update(msg)
+		if m.name.Value() != before {
+			m.nameEdited = true
+		}
+	case indexFocusWhere:
+		m.where, cmd = m.where.Update(msg)
+	}
+	return m, cmd
+}
+
+// updateColumns moves through the columns and picks them.
+func (m CreateIndexModel) updateColumns(msg tea.KeyMsg) CreateIndexModel {
+	switch msg.String() {
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.columns)-1)
+	case " ":
+		col := m.columns[m.cursor]
+		if i := slices.Index(m.picked, col); i >= 0 {
+			m.picked = slices.Delete(m.picked, i, i+1)
+		} else {
+			m.picked = append(m.picked, col)
+		}
+		if !m.nameEdited {
+			m.name.SetValue(db.IndexName(m.table, m.picked))
+		}
+	case "u":
+		m.unique = !m.unique
+	}
+	m.scroll = scrollTo(m.cursor, m.scroll, m.listLen)
+	return m
+}
+
+func (m *CreateIndexModel) setFocus(focus int) tea.Cmd {
+	m.focus = focus
+	m.name.Blur()
+	m.where.Blur()
+	switch focus {
+	case indexFocusName:
+		return m.name.Focus()
+	case indexFocusWhere:
+		return m.where.Focus()
+	}
+	return nil
+}
+
+func (m CreateIndexModel) View() string {
+	var b strings.Builder
+	end := min(m.scroll+m.listLen, len(m.columns))
+	for i, c := range m.columns[m.scroll:end] {
+		i += m.scroll
+		mark := "[ ]"
+		if n := slices.Index(m.picked, c); n >= 0 {
+			mark = fmt.Sprintf("[%d]", n+1)
+		}
+		line := mark + " " + c
+		if i == m.cursor && m.focus == indexFocusColumns {
+			b.WriteString(TitleStyle.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(StatusBarStyle.Render("  "+line) + "\n")
+		}
+	}
+	unique := "[ ]"
+	if m.unique {
+		unique = "[x]"
+	}
+	body := b.String() + "\n" + unique + " UNIQUE\n\n" + m.name.View() + "\n" + m.where.View()
+	if len(m.picked) > 0 {
+		stmt := db.CreateIndexSQL(m.table, strings.TrimSpace(m.name.Value()), m.picked, m.unique, m.where.Value())
+		body += "\n\n" + StatusBarStyle.Render(stmt+";")
+	}
+	if m.err != nil {
+		body += "\n\n" + ErrorStyle.Render("Error: "+m.err.Error())
+	}
+
+	help := "tab: next field | enter: create | esc: close"
+	if m.focus == indexFocusColumns {
+		help = "↑↓: move | space: pick column | u: unique | " + help
+	}
+	if m.running {
+		help = "creating..."
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(" Create index on "+m.table+" ") + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
@@ -0,0 +1,246 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// diffRowLimit is how many rows of each kind of change the data diff
+// lists; all of them are counted.
+const diffRowLimit = 1000
+
+// dataDiffMsg carries the comparison of a table with its copy in another
+// database.
+type dataDiffMsg struct {
+	diff    *db.TableDiff
+	elapsed time.Duration
+	err     error
+}
+
+// DataDiffModel is the popup comparing the rows of a table with the same
+// table in another database file: ask for the file, then list the rows
+// added, removed, and changed there, matched by primary key.
+type DataDiffModel struct {
+	database *sql.DB
+	table    string
+	path     textinput.Model
+
+	running bool
+	cancel  context.CancelFunc
+	spinner spinner.Model
+	started time.Time
+
+	result *dataDiffMsg
+	lines  []string // the changes, one line each
+	scroll int
+
+	width   int
+	listLen int // lines visible at once
+}
+
+// NewDataDiffModel opens the popup for table, suggesting otherPath (such
+// as another open database) as the file to compare with.
+func NewDataDiffModel(database *sql.DB, table, otherPath string, termWidth, termHeight int) (DataDiffModel, tea.Cmd) {
+	width := max(termWidth*80/100, 60)
+	ti := textinput.New()
+	ti.Prompt = "compare with: "
+	ti.Placeholder = "path to another database file"
+	ti.SetValue(otherPath)
+	ti.Width = width - 6 - len(ti.Prompt) - 1
+	return DataDiffModel{
+		database: database,
+		table:    table,
+		path:     ti,
+		spinner:  newSpinner(),
+		width:    width,
+		// Border, padding, title, gaps, summary, and help take 11 lines.
+		listLen: max(termHeight*80/100-11, 3),
+	}, ti.Focus()
+}
+
+func (m DataDiffModel) Update(msg tea.Msg) (DataDiffModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case dataDiffMsg:
+		if !m.running {
+			return m, nil
+		}
+		m.running = false
+		m.cancel = nil
+		m.result = &msg
+		m.lines = nil
+		if msg.err == nil {
+			m.lines = diffLines(msg.diff, m.width-6)
+		}
+		m.scroll = 0
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.running:
+			if msg.String() == "esc" {
+				m.cancel()
+				m.cancel = nil
+				m.running = false
+			}
+			return m, nil
+		case m.result != nil:
+			switch msg.String() {
+			case "esc":
+				m.result = nil
+				return m, m.path.Focus()
+			case "up", "k":
+				m.scroll = max(m.scroll-1, 0)
+			case "down", "j":
+				m.scroll = max(min(m.scroll+1, len(m.lines)-m.listLen), 0)
+			case "pgup":
+				m.scroll = max(m.scroll-m.listLen, 0)
+			case "pgdown":
+				m.scroll = max(min(m.scroll+m.listLen, len(m.lines)-m.listLen), 0)
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "enter":
+			if strings.TrimSpace(m.path.Value()) != "" {
+				return m.start()
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.path, cmd = m.path.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// start compares the table with the chosen file in the background.
+func (m DataDiffModel) start() (DataDiffModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.started = time.Now()
+	m.path.Blur()
+	database, table, path, started := m.database, m.table, expandHome(strings.TrimSpace(m.path.Value())), m.started
+	run := func() tea.Msg {
+		d, err := db.DiffTable(ctx, database, path, table, diffRowLimit)
+		return dataDiffMsg{diff: d, elapsed: time.Since(started), err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+func (m DataDiffModel) View() string {
+	title := " Data diff: " + m.table + " "
+	var body, help string
+	switch {
+	case m.running:
+		body = spinnerLine(m.spinner, "comparing with "+filepath.Base(m.path.Value()), m.started)
+		help = "esc: cancel"
+	case m.result != nil && m.result.err != nil:
+		body = ErrorStyle.Render("Error: " + m.result.err.Error())
+		help = "esc: back"
+	case m.result != nil:
+		title = fmt.Sprintf(" Data diff: %s (%s) ", m.table, m.result.elapsed.Round(time.Millisecond))
+		body = m.resultView()
+		help = "↑↓: scroll | esc: back"
+	default:
+		body = "Rows of " + m.table + " are matched by primary key with the same table in the other file.\n\n" + m.path.View()
+		help = "enter: compare | esc: close"
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+func (m DataDiffModel) resultView() string {
+	d := m.result.diff
+	summary := fmt.Sprintf("%s: %d removed, %d added, %d changed, %d the same · matched by %s",
+		filepath.Base(m.path.Value()), d.Removed, d.Added, d.Changed, d.Same, strings.Join(d.Key, ", "))
+	var notes []string
+	if len(d.OnlyHere) > 0 {
+		notes = append(notes, "only here, not compared: "+strings.Join(d.OnlyHere, ", "))
+	}
+	if len(d.OnlyThere) > 0 {
+		notes = append(notes, "only there, not compared: "+strings.Join(d.OnlyThere, ", "))
+	}
+	if len(notes) > 0 {
+		summary += "\n" + StatusBarStyle.Render(strings.Join(notes, " · "))
+	}
+	if len(m.lines) == 0 {
+		return summary + "\n\nThe rows are the same."
+	}
+	end := min(m.scroll+m.listLen, len(m.lines))
+	out := summary + "\n\n" + strings.Join(m.lines[m.scroll:end], "\n")
+	if len(m.lines) > m.listLen {
+		out += "\n" + StatusBarStyle.Render(fmt.Sprintf("%d-%d/%d", m.scroll+1, end, len(m.lines)))
+	}
+	return out
+}
+
+// diffLines lists the changes of d, one line each cut to width: "-" and
+// the values of a removed row, "+" and those of an added one, and "~" with
+// the changed columns of the rest.
+func diffLines(d *db.TableDiff, width int) []string {
+	// A whole row shows its key among its values, except a rowid.
+	keyShown := !slices.ContainsFunc(d.Key, func(k string) bool { return !slices.Contains(d.Columns, k) })
+	var lines []string
+	listed := map[db.ChangeKind]int{}
+	for _, c := range d.Changes {
+		listed[c.Kind]++
+		key := strings.Join(c.Key, ", ")
+		row := strings.Join(c.Values, " · ")
+		if !keyShown {
+			row = key + "  " + row
+		}
+		switch c.Kind {
+		case db.RowRemoved:
+			lines = append(lines, ErrorStyle.Render("- ")+truncateValue(row, width-2))
+		case db.RowAdded:
+			lines = append(lines, TitleStyle.Render("+ ")+truncateValue(row, width-2))
+		case db.RowChanged:
+			var changed []string
+			for i, col := range d.Columns {
+				if c.Values[i] != c.Other[i] {
+					changed = append(changed, col+": "+c.Values[i]+" → "+c.Other[i])
+				}
+			}
+			lines = append(lines, PopupLabelStyle.Render("~ ")+truncateValue(key+"  "+strings.Join(changed, "; "), width-2))
+		}
+	}
+	for i, total := range []int{db.RowRemoved: d.Removed, db.RowAdded: d.Added, db.RowChanged: d.Changed} {
+		if kind := db.ChangeKind(i); total > listed[kind] {
+			lines = append(lines, StatusBarStyle.Render(fmt.Sprintf("… %d more %s rows not listed", total-listed[kind], changeName(kind))))
+		}
+	}
+	return lines
+}
+
+func changeName(k db.ChangeKind) string {
+	switch k {
+	case db.RowRemoved:
+		return "removed"
+	case db.RowAdded:
+		return "added"
+	}
+	return "changed"
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// dataVersionInterval is how often PRAGMA data_version is polled.
+const dataVersionInterval = 2 * time.Second
+
+// dataVersionTickMsg triggers the next data_version poll. gen ties the tick
+// to the database session that started it so ticks from a closed database
+// are dropped instead of spawning a second polling loop.
+type dataVersionTickMsg struct {
+	gen int
+}
+
+// dataVersionMsg carries the result of a data_version poll.
+type dataVersionMsg struct {
+	gen     int
+	version int64
+}
+
+func dataVersionTickCmd(gen int) tea.Cmd {
+	return tea.Tick(dataVersionInterval, func(time.Time) tea.Msg {
+		return dataVersionTickMsg{gen: gen}
+	})
+}
+
+// readDataVersionCmd polls data_version on the watch connection. Errors are
+// swallowed — the indicator is best-effort and must never block browsing.
+func readDataVersionCmd(conn *sql.Conn, gen int) tea.Cmd {
+	if conn == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		v, err := db.DataVersion(context.Background(), conn)
+		if err != nil {
+			return nil
+		}
+		return dataVersionMsg{gen: gen, version: v}
+	}
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// dbInfoMsg carries freshly read database info. show opens the popup;
+// otherwise only the status bar summary is updated.
+type dbInfoMsg struct {
+	database *sql.DB
+	info     db.Info
+	err      error
+	show     bool
+}
+
+func loadDBInfoCmd(database *sql.DB, path string, show bool) tea.Cmd {
+	return func() tea.Msg {
+		info, err := db.DatabaseInfo(context.Background(), database, path)
+		return dbInfoMsg{database: database, info: info, err: err, show: show}
+	}
+}
+
+// DatabaseInfoModel is the popup listing file and library details.
+type DatabaseInfoModel struct {
+	path  string
+	info  db.Info
+	width int
+}
+
+func NewDatabaseInfoModel(path string, info db.Info, termWidth int) DatabaseInfoModel {
+	return DatabaseInfoModel{
+		path:  path,
+		info:  info,
+		width: max(termWidth*50/100, 50),
+	}
+}
+
+func (m DatabaseInfoModel) Update(msg tea.Msg) (DatabaseInfoModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(keyMsg, Keys.DatabaseInfo) || keyMsg.String() == "esc" || keyMsg.String() == "enter" {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	return m, nil
+}
+
+func (m DatabaseInfoModel) View() string {
+	rows := [][2]string{
+		{"file", db.DisplayPath(m.path)},
+		{"file size", formatBytes(m.info.FileSize)},
+		{"page size", fmt.Sprintf("%d bytes", m.info.PageSize)},
+		{"page count", fmt.Sprintf("%d", m.info.PageCount)},
+		{"encoding", m.info.Encoding},
+		{"journal mode", m.info.JournalMode},
+		{"schema version", fmt.Sprintf("%d", m.info.SchemaVersion)},
+		{"SQLite version", m.info.SQLiteVersion},
+	}
+	maxLabel := 0
+	for _, r := range rows {
+		maxLabel = max(maxLabel, len(r[0]))
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		b.WriteString(PopupLabelStyle.Render(fmt.Sprintf("%*s", maxLabel, r[0])) + " : " + r[1] + "\n")
+	}
+
+	title := TitleStyle.Render(" Database Info ")
+	help := StatusBarStyle.Render("esc/enter: close")
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(title + "\n\n" + b.String() + "\n" + help)
+}
+
+// infoSummary is the short form of the database info for the status bar.
+func infoSummary(path string, info db.Info) string {
+	return fmt.Sprintf("%s %s, %s", db.BaseName(path), formatBytes(info.FileSize), info.JournalMode)
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. "4.2 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
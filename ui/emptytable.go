@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// emptyCountMsg carries the row count the empty table popup asks about,
+// and whether the table has an AUTOINCREMENT counter to reset.
+type emptyCountMsg struct {
+	count    int
+	sequence bool
+	err      error
+}
+
+// tableEmptiedMsg reports that the empty table popup deleted every row of
+// table.
+type tableEmptiedMsg struct {
+	database *sql.DB
+	table    string
+	deleted  int64
+	reset    bool
+}
+
+// emptyFailedMsg carries the error of a DELETE that didn't go through.
+type emptyFailedMsg struct {
+	err error
+}
+
+// EmptyTableModel is the popup confirming that every row of a table is to
+// be deleted. It shows how many rows that is, and offers to reset the
+// table's AUTOINCREMENT counter along with them.
+type EmptyTableModel struct {
+	database *sql.DB
+	table    string
+	count    int // -1 until counted
+	sequence bool
+	reset    bool
+	running  bool
+	err      error
+	width    int
+}
+
+func NewEmptyTableModel(database *sql.DB, table string, termWidth int) (EmptyTableModel, tea.Cmd) {
+	m := EmptyTableModel{
+		database: database,
+		table:    table,
+		count:    -1,
+		width:    max(termWidth*50/100, 50),
+	}
+	count := func() tea.Msg {
+		ctx := context.Background()
+		n, err := db.CountRows(ctx, database, table)
+		return emptyCountMsg{count: n, sequence: db.HasSequence(ctx, database, table), err: err}
+	}
+	return m, count
+}
+
+func (m EmptyTableModel) Update(msg tea.Msg) (EmptyTableModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case emptyCountMsg:
+		m.count, m.sequence, m.err = msg.count, msg.sequence, msg.err
+		return m, nil
+
+	case emptyFailedMsg:
+		m.running = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "r":
+			m.reset = m.sequence && !m.reset
+		case "enter":
+			if m.count < 0 {
+				return m, nil
+			}
+			m.running = true
+			m.err = nil
+			database, table, reset := m.database, m.table, m.reset
+			return m, func() tea.Msg {
+				n, err := db.EmptyTable(context.Background(), database, table, reset)
+				if err != nil {
+					return emptyFailedMsg{err: err}
+				}
+				return tableEmptiedMsg{database: database, table: table, deleted: n, reset: reset}
+			}
+		default:
+			if key.Matches(msg, Keys.EmptyTable) {
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m EmptyTableModel) View() string {
+	var body string
+	switch {
+	case m.count < 0 && m.err == nil:
+		body = StatusBarStyle.Render("counting rows...")
+	case m.count >= 0:
+		rows := "rows"
+		if m.count == 1 {
+			rows = "row"
+		}
+		body = fmt.Sprintf("Delete all %s %s of %s?", groupDigits(int64(m.count)), rows, m.table)
+		if db.InTransaction(m.database) {
+			body += "\n" + StatusBarStyle.Render("They come back if the open transaction is rolled back.")
+		} else {
+			body += "\n" + ErrorStyle.Render("This can't be undone.")
+		}
+		if m.sequence {
+			check := "[ ]"
+			if m.reset {
+				check = "[x]"
+			}
+			body += "\n\n" + check + " reset the AUTOINCREMENT counter"
+		}
+	}
+	if m.err != nil {
+		if body != "" {
+			body += "\n\n"
+		}
+		body += ErrorStyle.Render("Error: " + m.err.Error())
+	}
+
+	help := "enter: delete all rows | esc: cancel"
+	if m.sequence {
+		help = "enter: delete all rows | r: reset counter | esc: cancel"
+	}
+	if m.running {
+		help = "deleting..."
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(" Empty "+m.table+" ") + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
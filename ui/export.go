@@ -0,0 +1,542 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// exportPhase tracks the export popup flow: choose options, run, summary.
+type exportPhase int
+
+const (
+	exportSetup exportPhase = iota
+	exportRunning
+	exportDone
+)
+
+// exportProgressMsg reports rows written for the table currently exporting.
+type exportProgressMsg struct {
+	table      string
+	tableIndex int // 0-based index into the table list
+	rows       int
+}
+
+// exportFinishedMsg is sent once the whole export ends, successfully or not.
+type exportFinishedMsg struct {
+	tables int // tables fully written
+	rows   int // rows written across all tables
+	err    error
+}
+
+// exportManifestName is the bookkeeping file left in the export directory
+// when an export is interrupted, so the next run can resume it.
+const exportManifestName = ".sqlitui-export.json"
+
+// exportManifest records how far an interrupted export got: which tables
+// are complete and how many rows of the next one are already on disk. It
+// names the database and the tables exported, as only an export of the
+// same ones can pick up where it stopped.
+type exportManifest struct {
+	Format      db.Format `json:"format"`
+	Database    string    `json:"database"`
+	Tables      []string  `json:"tables"`
+	Done        []string  `json:"done"`
+	Partial     string    `json:"partial,omitempty"`
+	PartialRows int       `json:"partial_rows"`
+	PartialSize int64     `json:"partial_size"` // file size after the last complete row
+}
+
+func loadExportManifest(dir string) *exportManifest {
+	data, err := os.ReadFile(filepath.Join(dir, exportManifestName))
+	if err != nil {
+		return nil
+	}
+	var mf exportManifest
+	if json.Unmarshal(data, &mf) != nil {
+		return nil
+	}
+	return &mf
+}
+
+// matches reports whether the interrupted export was of tables of the
+// database at source.
+func (mf *exportManifest) matches(source string, tables []string) bool {
+	return mf.Database == source && slices.Equal(mf.Tables, tables)
+}
+
+func (mf *exportManifest) save(dir string) error {
+	data, err := json.Marshal(mf)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, exportManifestName), data, 0o644)
+}
+
+// exportFilter is the filter applied to the focused grid, whose rows the
+// export popup offers to write instead of every table.
+type exportFilter struct {
+	database *sql.DB
+	table    string
+	query    string // the filter's SELECT, from db.FilterQuery
+	args     []any
+	rows     int // as counted for the grid, or unknownTotal
+}
+
+// ExportModel is the "dump all tables" popup. It writes one file per table
+// into a directory, streaming rows so big tables never sit in memory. With
+// a filter applied to the grid, it can write just the rows it matches.
+type ExportModel struct {
+	database  *sql.DB
+	source    string // the database's path, as the manifest names it
+	tables    []string
+	filter    *exportFilter
+	filtered  bool // exporting the filter's rows rather than every table
+	dirInput  textinput.Model
+	formatIdx int // index into db.Formats
+	phase     exportPhase
+	bar       progress.Model
+	events    <-chan tea.Msg     // progress/finish messages from the export goroutine
+	cancel    context.CancelFunc // aborts the running export
+	aborting  bool               // esc was pressed; waiting for the goroutine to stop
+
+	// An interrupted export found in the chosen directory, whether it was
+	// of other tables or another database, and whether to continue it
+	// rather than start over.
+	manifest *exportManifest
+	foreign  bool
+	resume   bool
+
+	// Progress of the running export.
+	current    string
+	tableIndex int
+	tableRows  int
+	result     exportFinishedMsg
+
+	width  int
+	height int
+}
+
+// NewExportModel creates the export popup for all tables of a database,
+// opened from source. defaultDir pre-fills the destination directory.
+// filter, if not nil, is offered first.
+func NewExportModel(database *sql.DB, source string, tables []string, filter *exportFilter, defaultDir string, termWidth, termHeight int) (ExportModel, tea.Cmd) {
+	popupWidth := max(termWidth*60/100, 50)
+	popupHeight := 14
+	if filter != nil {
+		popupHeight++ // the choice of what to export
+	}
+
+	ti := textinput.New()
+	ti.Prompt = "directory: "
+	ti.SetValue(defaultDir)
+	ti.Width = popupWidth - 6 - len(ti.Prompt) - 1
+	cmd := ti.Focus()
+
+	bar := progress.New(progress.WithDefaultGradient(), progress.WithoutPercentage())
+	bar.Width = popupWidth - 6
+
+	m := ExportModel{
+		database: database,
+		source:   source,
+		tables:   tables,
+		filter:   filter,
+		filtered: filter != nil,
+		dirInput: ti,
+		bar:      bar,
+		width:    popupWidth,
+		height:   popupHeight,
+	}
+	m.checkManifest()
+	return m, cmd
+}
+
+// checkManifest looks for an interrupted export in the chosen directory
+// and, if one of these tables is found, defaults to resuming it in its
+// original format. One of another database or table set can't be resumed.
+func (m *ExportModel) checkManifest() {
+	m.manifest = loadExportManifest(strings.TrimSpace(m.dirInput.Value()))
+	m.foreign = m.manifest != nil && !m.manifest.matches(m.source, m.tables)
+	m.resume = m.manifest != nil && !m.foreign
+	if m.resume {
+		for i, f := range db.Formats {
+			if f == m.manifest.Format {
+				m.formatIdx = i
+			}
+		}
+	}
+}
+
+func (m ExportModel) format() db.Format {
+	return db.Formats[m.formatIdx]
+}
+
+func (m ExportModel) Update(msg tea.Msg) (ExportModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case exportProgressMsg:
+		m.current = msg.table
+		m.tableIndex = msg.tableIndex
+		m.tableRows = msg.rows
+		return m, waitForExportEvent(m.events)
+
+	case exportFinishedMsg:
+		m.phase = exportDone
+		m.result = msg
+		m.cancel()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.phase {
+		case exportSetup:
+			switch msg.String() {
+			case "esc":
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			case "tab":
+				if m.resuming() {
+					return m, nil // a resumed export keeps its original format
+				}
+				m.formatIdx = (m.formatIdx + 1) % len(db.Formats)
+				return m, nil
+			case "up", "down":
+				m.filtered = m.filter != nil && !m.filtered
+				return m, nil
+			case "ctrl+t":
+				if !m.filtered && !m.foreign {
+					m.resume = m.manifest != nil && !m.resume
+				}
+				return m, nil
+			case "enter":
+				return m.start()
+			}
+		case exportRunning:
+			if msg.String() == "esc" && !m.aborting {
+				m.aborting = true
+				m.cancel()
+			}
+			return m, nil
+		case exportDone:
+			switch msg.String() {
+			case "esc", "enter":
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+			return m, nil
+		}
+	}
+
+	if m.phase == exportSetup {
+		var cmd tea.Cmd
+		prev := m.dirInput.Value()
+		m.dirInput, cmd = m.dirInput.Update(msg)
+		if m.dirInput.Value() != prev {
+			m.checkManifest()
+		}
+		return m, cmd
+	}
+	return m, nil
+}
+
+// filterCount is the filter's row count in parentheses, when it is known.
+func (m ExportModel) filterCount() string {
+	if m.filter.rows < 0 {
+		return ""
+	}
+	return " (" + groupDigits(int64(m.filter.rows)) + ")"
+}
+
+// resuming reports whether the export continues an interrupted one, which
+// only exports of every table do.
+func (m ExportModel) resuming() bool {
+	return m.resume && !m.filtered
+}
+
+// start creates the destination directory and launches the export goroutine.
+func (m ExportModel) start() (ExportModel, tea.Cmd) {
+	dir := strings.TrimSpace(m.dirInput.Value())
+	if dir == "" {
+		return m, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		m.phase = exportDone
+		m.result = exportFinishedMsg{err: err}
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 16)
+	if m.filtered {
+		go exportFilteredRows(ctx, *m.filter, dir, m.format(), events)
+	} else {
+		var resume *exportManifest
+		if m.resume {
+			resume = m.manifest
+		} else {
+			os.Remove(filepath.Join(dir, exportManifestName))
+		}
+		go exportAllTables(ctx, m.database, m.source, m.tables, dir, m.format(), resume, events)
+	}
+
+	m.events = events
+	m.cancel = cancel
+	m.phase = exportRunning
+	m.dirInput.Blur()
+	return m, waitForExportEvent(events)
+}
+
+// waitForExportEvent blocks until the export goroutine sends its next message.
+func waitForExportEvent(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-events
+	}
+}
+
+// exportAllTables writes each table to <dir>/<table>.<format>, reporting
+// progress on events and finishing with exportFinishedMsg. It stops at the
+// first error or when ctx is cancelled; either way it leaves a manifest in
+// dir describing what's complete, and the partial file holds only whole
+// rows, so a later run with that manifest as resume picks up where this
+// one stopped. A successful run removes the manifest. A manifest of another
+// database, source being this one's path, or other tables starts over.
+func exportAllTables(ctx context.Context, database *sql.DB, source string, tables []string, dir string, format db.Format, resume *exportManifest, events chan<- tea.Msg) {
+	mf := &exportManifest{Format: format, Database: source, Tables: tables}
+	done := map[string]bool{}
+	partialRows := map[string]int{}
+	if resume != nil && !resume.matches(source, tables) {
+		resume = nil
+	}
+	if resume != nil {
+		for _, t := range resume.Done {
+			done[t] = true
+		}
+		mf.Done = append(mf.Done, resume.Done...)
+		if resume.Partial != "" {
+			partialRows[resume.Partial] = resume.PartialRows
+		}
+	}
+
+	total := 0
+	finish := func(err error) {
+		if err != nil {
+			if saveErr := mf.save(dir); saveErr != nil {
+				err = errors.Join(err, saveErr)
+			}
+		} else {
+			os.Remove(filepath.Join(dir, exportManifestName))
+		}
+		events <- exportFinishedMsg{tables: len(mf.Done), rows: total, err: err}
+	}
+
+	for i, table := range tables {
+		if done[table] {
+			continue
+		}
+		events <- exportProgressMsg{table: table, tableIndex: i}
+
+		path := filepath.Join(dir, exportFileName(table, format))
+		opts := db.ExportOptions{}
+		var f *os.File
+		var err error
+		if skip, ok := partialRows[table]; ok {
+			// Cut off anything past the last complete row, then append.
+			f, err = os.OpenFile(path, os.O_WRONLY, 0o644)
+			if err == nil {
+				err = f.Truncate(resume.PartialSize)
+			}
+			if err == nil {
+				_, err = f.Seek(resume.PartialSize, 0)
+			}
+			opts = db.ExportOptions{SkipRows: skip, Resume: true}
+		} else {
+			f, err = os.Create(path)
+		}
+		if err != nil {
+			if f != nil {
+				f.Close()
+			}
+			finish(err)
+			return
+		}
+
+		n, err := db.ExportTable(ctx, database, table, format, f, opts, func(rows int) {
+			events <- exportProgressMsg{table: table, tableIndex: i, rows: rows}
+		})
+		var size int64
+		if info, statErr := f.Stat(); statErr == nil {
+			size = info.Size()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		total += n - opts.SkipRows
+		if err != nil {
+			// An empty file has no header yet, so it restarts from scratch.
+			if size > 0 {
+				mf.Partial, mf.PartialRows, mf.PartialSize = table, n, size
+			}
+			if !errors.Is(err, context.Canceled) {
+				err = fmt.Errorf("%s: %w", table, err)
+			}
+			finish(err)
+			return
+		}
+		mf.Done = append(mf.Done, table)
+	}
+	finish(nil)
+}
+
+// exportFilteredRows writes the rows filter matches to
+// <dir>/<table>-filtered.<format>, streaming them from the filter's query
+// and reporting progress on events like exportAllTables. There is no
+// resuming it: an incomplete file is removed.
+func exportFilteredRows(ctx context.Context, filter exportFilter, dir string, format db.Format, events chan<- tea.Msg) {
+	events <- exportProgressMsg{table: filter.table}
+	f, err := os.Create(filepath.Join(dir, filteredFileName(filter.table, format)))
+	if err != nil {
+		events <- exportFinishedMsg{err: err}
+		return
+	}
+	n, err := db.ExportQuery(ctx, filter.database, filter.query, filter.args, format, f, db.ExportOptions{}, func(rows int) {
+		events <- exportProgressMsg{table: filter.table, rows: rows}
+	})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		events <- exportFinishedMsg{rows: n, err: err}
+		return
+	}
+	events <- exportFinishedMsg{tables: 1, rows: n}
+}
+
+// filteredFileName is the file exportFilteredRows writes for table.
+func filteredFileName(table string, format db.Format) string {
+	return strings.TrimSuffix(exportFileName(table, format), "."+string(format)) + "-filtered." + string(format)
+}
+
+// exportFileName builds a safe file name for a table: path separators and
+// other characters that are awkward in file names become underscores.
+func exportFileName(table string, format db.Format) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|', 0:
+			return '_'
+		}
+		return r
+	}, table)
+	return name + "." + string(format)
+}
+
+func (m ExportModel) View() string {
+	title := TitleStyle.Render(" Export all tables ")
+	if m.filtered {
+		title = TitleStyle.Render(" Export filtered rows ")
+	}
+
+	var body, help string
+	switch m.phase {
+	case exportSetup:
+		var formats []string
+		for i, f := range db.Formats {
+			if i == m.formatIdx {
+				formats = append(formats, TitleStyle.Render("["+string(f)+"]"))
+			} else {
+				formats = append(formats, StatusBarStyle.Render(" "+string(f)+" "))
+			}
+		}
+		body = fmt.Sprintf("%d tables, one file each.\n\n", len(m.tables))
+		if m.filter != nil {
+			options := []string{"export filtered rows" + m.filterCount() + " of " + m.filter.table, fmt.Sprintf("export all %d tables", len(m.tables))}
+			for i, o := range options {
+				if (i == 0) == m.filtered {
+					options[i] = TitleStyle.Render("▸ " + o)
+				} else {
+					options[i] = StatusBarStyle.Render("  " + o)
+				}
+			}
+			body = strings.Join(options, "\n") + "\n\n"
+			if m.filtered {
+				body += StatusBarStyle.Render("into "+filteredFileName(m.filter.table, m.format())) + "\n"
+			}
+		}
+		body += m.dirInput.View() + "\n" +
+			"format: " + strings.Join(formats, " ")
+		help = "enter: start | tab: format | esc: close"
+		if m.filter != nil {
+			help = "↑↓: what to export | " + help
+		}
+		if mf := m.manifest; mf != nil && !m.filtered && m.foreign {
+			body += "\n" + StatusBarStyle.Render("resume: no — this directory holds another export; starting over")
+		} else if mf != nil && !m.filtered {
+			status := fmt.Sprintf("%d tables done", len(mf.Done))
+			if mf.Partial != "" {
+				status += fmt.Sprintf(", %s stopped at row %d", mf.Partial, mf.PartialRows)
+			}
+			if m.resume {
+				body += "\n" + TitleStyle.Render("resume: ") + "continue interrupted export (" + status + ")"
+			} else {
+				body += "\n" + StatusBarStyle.Render("resume: off — existing files will be overwritten")
+			}
+			help += " | ctrl+t: toggle resume"
+		}
+		help = StatusBarStyle.Render(help)
+
+	case exportRunning:
+		var percent float64
+		if len(m.tables) > 0 {
+			percent = float64(m.tableIndex) / float64(len(m.tables))
+		}
+		body = fmt.Sprintf("table %d/%d: %s\n%d rows written\n\n", m.tableIndex+1, len(m.tables), m.current, m.tableRows)
+		if m.filtered {
+			if m.filter.rows > 0 {
+				percent = min(float64(m.tableRows)/float64(m.filter.rows), 1)
+			}
+			body = fmt.Sprintf("filtered rows of %s\n%d rows written\n\n", m.filter.table, m.tableRows)
+		}
+		body += m.bar.ViewAs(percent)
+		help = StatusBarStyle.Render("exporting... | esc: abort")
+		if m.aborting {
+			help = StatusBarStyle.Render("aborting...")
+		}
+
+	case exportDone:
+		if m.filtered {
+			switch {
+			case errors.Is(m.result.err, context.Canceled):
+				body = "Aborted; the partial file was removed."
+			case m.result.err != nil:
+				body = ErrorStyle.Render("Error: " + m.result.err.Error())
+			default:
+				body = fmt.Sprintf("Exported %d filtered rows of %s to %s.\n\n", m.result.rows, m.filter.table,
+					filepath.Join(m.dirInput.Value(), filteredFileName(m.filter.table, m.format()))) +
+					m.bar.ViewAs(1)
+			}
+		} else if errors.Is(m.result.err, context.Canceled) {
+			body = fmt.Sprintf("Aborted: %d of %d tables complete, %d rows written.\n\n", m.result.tables, len(m.tables), m.result.rows) +
+				"Files hold only complete rows. Export to the same directory again to resume."
+		} else if m.result.err != nil {
+			body = ErrorStyle.Render("Error: "+m.result.err.Error()) + "\n\n" +
+				fmt.Sprintf("%d of %d tables written before the error. Export to the same directory again to resume.", m.result.tables, len(m.tables))
+		} else {
+			body = fmt.Sprintf("Exported %d tables (%d rows) to %s.\n\n", m.result.tables, m.result.rows, m.dirInput.Value()) +
+				m.bar.ViewAs(1)
+		}
+		help = StatusBarStyle.Render("esc/enter: close")
+	}
+
+	return PopupStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(title + "\n\n" + body + "\n\n" + help)
+}
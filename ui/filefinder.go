@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+const (
+	// finderDepth is how many directories deep below the current one the
+	// picker's finder looks for SQLite files.
+	finderDepth = 4
+	// finderMaxDirs bounds the directories it reads, so starting it in a
+	// large tree stays quick.
+	finderMaxDirs = 5000
+	// finderShown is how many matching files it lists at once.
+	finderShown = 10
+)
+
+// finderFilesMsg carries the SQLite files found below the current
+// directory for the picker's finder.
+type finderFilesMsg struct {
+	files []string
+}
+
+// findFilesCmd walks the current directory for the finder.
+func findFilesCmd() tea.Cmd {
+	return func() tea.Msg {
+		return finderFilesMsg{files: findSQLiteFilesUnder(".", finderDepth, finderMaxDirs)}
+	}
+}
+
+// findSQLiteFilesUnder returns the SQLite files in root and the directories
+// below it, up to depth levels and maxDirs directories, shallowest first.
+// Hidden directories are skipped.
+func findSQLiteFilesUnder(root string, depth, maxDirs int) []string {
+	var files []string
+	level := []string{root}
+	read := 0
+	for d := 0; d <= depth && len(level) > 0; d++ {
+		var next []string
+		for _, dir := range level {
+			if read >= maxDirs {
+				return files
+			}
+			read++
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				continue
+			}
+			for _, e := range entries {
+				path := filepath.Join(dir, e.Name())
+				if e.IsDir() {
+					if !strings.HasPrefix(e.Name(), ".") {
+						next = append(next, path)
+					}
+					continue
+				}
+				if validExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+					files = append(files, path)
+				}
+			}
+		}
+		level = next
+	}
+	return files
+}
+
+// matchFiles returns the files query fuzzily matches, those holding it as
+// it is typed first.
+func matchFiles(files []string, query string) []string {
+	q := strings.ToLower(query)
+	var exact, fuzzy []string
+	for _, f := range files {
+		switch {
+		case strings.Contains(strings.ToLower(f), q):
+			exact = append(exact, f)
+		case db.FuzzyMatch(f, query):
+			fuzzy = append(fuzzy, f)
+		}
+	}
+	return append(exact, fuzzy...)
+}
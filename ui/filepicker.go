@@ -6,12 +6,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
 )
 
 type pickerFocus int
@@ -23,20 +26,38 @@ const (
 
 // dbOpenedMsg is sent when a database is successfully opened.
 type dbOpenedMsg struct {
-	db     *sql.DB
-	tables []string
+	db      *sql.DB
+	path    string
+	tables  []string
+	entries []db.ListEntry
+	schema  *state.SchemaCache
 }
 
-// FilePickerModel shows a text input for typing a path and a list of
-// SQLite files found in the current directory.
+// pickerCancelledMsg is sent when esc closes a picker opened on top of an
+// already open database.
+type pickerCancelledMsg struct{}
+
+// FilePickerModel shows a text input for typing a path, the list of
+// recently opened databases, and SQLite files found in the current directory.
+// Its finder lists the SQLite files in the directories below instead, those
+// the input fuzzily matches.
 type FilePickerModel struct {
 	input   textinput.Model
-	files   []string
-	cursor  int
+	recent  []string // recently opened databases, most recent first
+	files   []string // SQLite files in the current directory, minus recents
+	cursor  int      // index into entries() — recents first, then files
 	focused pickerFocus
 	pathErr string
+	dbOpts  db.Options
+	backOut bool // esc returns to the open database instead of quitting
 	width   int
 	height  int
+
+	finding bool     // the finder is listing matches rather than the directory
+	walking bool     // the finder is still looking for files
+	found   []string // SQLite files below the current directory, nil until walked
+	matches []string // found files matching the input, best first
+	scroll  int      // first of the matches shown
 }
 
 // validExtensions are the file extensions we recognize as SQLite databases.
@@ -46,15 +67,23 @@ var validExtensions = map[string]bool{
 	".sqlite3": true,
 }
 
-func NewFilePickerModel() FilePickerModel {
+const (
+	pathPlaceholder   = "/path/to/database.db"
+	finderPlaceholder = "part of a file name or path"
+)
+
+func NewFilePickerModel(dbOpts db.Options) FilePickerModel {
 	ti := textinput.New()
-	ti.Placeholder = "/path/to/database.db"
+	ti.Placeholder = pathPlaceholder
 	ti.Width = 50
 
-	files := findSQLiteFiles()
+	recent := state.RecentFiles()
+	files := withoutRecent(findSQLiteFiles(), recent)
 
+	// With recents present the cursor starts on index 0, which is the most
+	// recently opened database — enter reopens it straight away.
 	focused := focusInput
-	if len(files) > 0 {
+	if len(recent)+len(files) > 0 {
 		focused = focusList
 	} else {
 		ti.Focus()
@@ -62,11 +91,23 @@ func NewFilePickerModel() FilePickerModel {
 
 	return FilePickerModel{
 		input:   ti,
+		recent:  recent,
 		files:   files,
 		focused: focused,
+		dbOpts:  dbOpts,
 	}
 }
 
+// entries returns every selectable path in display order.
+func (m FilePickerModel) entries() []string {
+	if m.finding {
+		return m.matches
+	}
+	entries := make([]string, 0, len(m.recent)+len(m.files))
+	entries = append(entries, m.recent...)
+	return append(entries, m.files...)
+}
+
 func (m FilePickerModel) Init() tea.Cmd {
 	if m.focused == focusInput {
 		return textinput.Blink
@@ -81,21 +122,48 @@ func (m FilePickerModel) Update(msg tea.Msg) (FilePickerModel, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case finderFilesMsg:
+		m.walking = false
+		m.found = msg.files
+		m.matchFiles()
+		return m, nil
+
 	case tea.KeyMsg:
+		if key.Matches(msg, Keys.FuzzyFilter) {
+			return m.toggleFinder()
+		}
+		if m.finding {
+			switch msg.Type {
+			case tea.KeyUp:
+				m.moveMatch(-1)
+				return m, nil
+			case tea.KeyDown:
+				m.moveMatch(1)
+				return m, nil
+			}
+		}
+
 		switch msg.Type {
 		case tea.KeyEnter:
 			return m.submit()
 
-		case tea.KeyEsc, tea.KeyCtrlC:
+		case tea.KeyEsc:
+			if m.backOut {
+				return m, func() tea.Msg { return pickerCancelledMsg{} }
+			}
+			return m, tea.Quit
+
+		case tea.KeyCtrlC:
 			return m, tea.Quit
 
 		case tea.KeyUp:
-			if len(m.files) == 0 {
+			n := len(m.entries())
+			if n == 0 {
 				return m, nil
 			}
 			if m.focused == focusInput {
 				// Move from input to last file in list.
-				return m.switchToList(len(m.files) - 1)
+				return m.switchToList(n - 1)
 			}
 			if m.cursor > 0 {
 				m.cursor--
@@ -106,14 +174,15 @@ func (m FilePickerModel) Update(msg tea.Msg) (FilePickerModel, tea.Cmd) {
 			return m, nil
 
 		case tea.KeyDown:
-			if len(m.files) == 0 {
+			n := len(m.entries())
+			if n == 0 {
 				return m, nil
 			}
 			if m.focused == focusInput {
 				// Move from input to first file in list.
 				return m.switchToList(0)
 			}
-			if m.cursor < len(m.files)-1 {
+			if m.cursor < n-1 {
 				m.cursor++
 			}
 			return m, nil
@@ -129,7 +198,7 @@ func (m FilePickerModel) Update(msg tea.Msg) (FilePickerModel, tea.Cmd) {
 				}
 				return m, nil
 			case "j":
-				if m.cursor < len(m.files)-1 {
+				if m.cursor < len(m.entries())-1 {
 					m.cursor++
 				}
 				return m, nil
@@ -138,14 +207,54 @@ func (m FilePickerModel) Update(msg tea.Msg) (FilePickerModel, tea.Cmd) {
 	}
 
 	if m.focused == focusInput {
+		query := m.input.Value()
 		var cmd tea.Cmd
 		m.input, cmd = m.input.Update(msg)
+		if m.finding && m.input.Value() != query {
+			m.matchFiles()
+		}
 		return m, cmd
 	}
 
 	return m, nil
 }
 
+// toggleFinder switches between listing the current directory and finding
+// files below it, typing into the input either way. The directories are
+// walked the first time.
+func (m FilePickerModel) toggleFinder() (FilePickerModel, tea.Cmd) {
+	m.finding = !m.finding
+	m.cursor, m.scroll = 0, 0
+	m.pathErr = ""
+	m, cmd := m.switchToInput()
+	if !m.finding {
+		m.input.Placeholder = pathPlaceholder
+		return m, cmd
+	}
+	m.input.Placeholder = finderPlaceholder
+	if m.found == nil && !m.walking {
+		m.walking = true
+		cmd = tea.Batch(cmd, findFilesCmd())
+	}
+	m.matchFiles()
+	return m, cmd
+}
+
+// matchFiles lists the found files matching the input, selecting the best.
+func (m *FilePickerModel) matchFiles() {
+	m.matches = matchFiles(m.found, m.input.Value())
+	m.cursor, m.scroll = 0, 0
+}
+
+// moveMatch moves the finder's selection by delta, within the matches.
+func (m *FilePickerModel) moveMatch(delta int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.matches)-1)
+	m.scroll = scrollTo(m.cursor, m.scroll, finderShown)
+}
+
 func (m FilePickerModel) View() string {
 	boxWidth := 50
 
@@ -159,35 +268,23 @@ func (m FilePickerModel) View() string {
 		Padding(0, 1).
 		Render(m.input.View())
 
-	// File list box.
-	var fileListBox string
-	if len(m.files) > 0 {
-		listStyle := UnfocusedPaneStyle
-		if m.focused == focusList {
-			listStyle = FocusedPaneStyle
-		}
-
-		var lines []string
-		for i, f := range m.files {
-			if m.focused == focusList && i == m.cursor {
-				lines = append(lines, TitleStyle.Render(" > "+f))
-			} else {
-				lines = append(lines, "   "+f)
-			}
-		}
-
-		fileListBox = listStyle.
-			Width(boxWidth).
-			Padding(0, 1).
-			Render(strings.Join(lines, "\n"))
-	}
+	recentBox := m.renderFileList(m.recent, m.listCursor(0, len(m.recent)), boxWidth)
+	fileListBox := m.renderFileList(m.files, m.listCursor(len(m.recent), len(m.files)), boxWidth)
 
 	errLine := ""
 	if m.pathErr != "" {
 		errLine = ErrorStyle.Render("Error: " + m.pathErr)
 	}
 
-	help := StatusBarStyle.Render("enter: open | esc: quit")
+	escHelp := "esc: quit"
+	if m.backOut {
+		escHelp = "esc: back"
+	}
+	findHelp := Keys.FuzzyFilter.Help().Key + ": find below"
+	if m.finding {
+		findHelp = Keys.FuzzyFilter.Help().Key + ": this directory"
+	}
+	help := StatusBarStyle.Render("enter: open | " + findHelp + " | " + escHelp)
 
 	sections := []string{
 		Logo,
@@ -196,8 +293,20 @@ func (m FilePickerModel) View() string {
 		inputBox,
 	}
 
-	if fileListBox != "" {
-		sections = append(sections, "", StatusBarStyle.Render("  Files in current directory"), fileListBox)
+	switch {
+	case m.finding:
+		sections = append(sections, "", StatusBarStyle.Render("  "+m.finderTitle()))
+		end := min(m.scroll+finderShown, len(m.matches))
+		if box := m.renderFileList(m.matches[m.scroll:end], m.cursor-m.scroll, boxWidth); box != "" {
+			sections = append(sections, box)
+		}
+	default:
+		if recentBox != "" {
+			sections = append(sections, "", StatusBarStyle.Render("  Recent databases"), recentBox)
+		}
+		if fileListBox != "" {
+			sections = append(sections, "", StatusBarStyle.Render("  Files in current directory"), fileListBox)
+		}
 	}
 
 	if errLine != "" {
@@ -214,6 +323,71 @@ func (m FilePickerModel) View() string {
 	return content
 }
 
+// finderTitle heads the finder's matches, saying how many there are.
+func (m FilePickerModel) finderTitle() string {
+	switch {
+	case m.walking:
+		return "Looking for SQLite files below the current directory…"
+	case len(m.found) == 0:
+		return "No SQLite files below the current directory"
+	case len(m.matches) == 0:
+		return fmt.Sprintf("No match among %d files below the current directory", len(m.found))
+	case len(m.matches) > finderShown:
+		return fmt.Sprintf("%d-%d of %d matching files", m.scroll+1, min(m.scroll+finderShown, len(m.matches)), len(m.matches))
+	}
+	if len(m.matches) == 1 {
+		return "1 matching file"
+	}
+	return fmt.Sprintf("%d matching files", len(m.matches))
+}
+
+// listCursor is where the cursor is within the n entries from offset in
+// entries(), or -1 when it isn't on one of them.
+func (m FilePickerModel) listCursor(offset, n int) int {
+	if m.focused == focusList && m.cursor >= offset && m.cursor < offset+n {
+		return m.cursor - offset
+	}
+	return -1
+}
+
+// renderFileList draws one bordered list of paths, highlighting the one at
+// cursor unless it is -1.
+func (m FilePickerModel) renderFileList(paths []string, cursor, boxWidth int) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	listStyle := UnfocusedPaneStyle
+	if cursor >= 0 {
+		listStyle = FocusedPaneStyle
+	}
+
+	// Each entry shows when the file was last modified, right-aligned.
+	now := time.Now()
+	var lines []string
+	for i, f := range paths {
+		var modified string
+		if info, err := os.Stat(f); err == nil {
+			modified = humanizeTime(info.ModTime(), now)
+		}
+		name := f
+		if avail := boxWidth - 2 - 3 - len(modified) - 1; lipgloss.Width(name) > avail && avail > 1 {
+			name = "…" + string([]rune(name)[len([]rune(name))-avail+1:])
+		}
+		pad := max(boxWidth-2-3-lipgloss.Width(name)-len(modified), 1)
+		suffix := strings.Repeat(" ", pad) + StatusBarStyle.Render(modified)
+		if i == cursor {
+			lines = append(lines, TitleStyle.Render(" > "+name)+suffix)
+		} else {
+			lines = append(lines, "   "+name+suffix)
+		}
+	}
+
+	return listStyle.
+		Width(boxWidth).
+		Padding(0, 1).
+		Render(strings.Join(lines, "\n"))
+}
+
 func (m FilePickerModel) switchToList(cursor int) (FilePickerModel, tea.Cmd) {
 	m.focused = focusList
 	m.cursor = cursor
@@ -229,8 +403,8 @@ func (m FilePickerModel) switchToInput() (FilePickerModel, tea.Cmd) {
 
 func (m FilePickerModel) submit() (FilePickerModel, tea.Cmd) {
 	var path string
-	if m.focused == focusList && len(m.files) > 0 {
-		path = m.files[m.cursor]
+	if entries := m.entries(); (m.focused == focusList || m.finding) && len(entries) > 0 {
+		path = entries[m.cursor]
 	} else {
 		path = m.input.Value()
 	}
@@ -244,27 +418,36 @@ func (m FilePickerModel) submit() (FilePickerModel, tea.Cmd) {
 		return m, nil
 	}
 
-	database, err := db.Open(path)
+	database, err := db.Open(path, m.dbOpts)
 	if err != nil {
 		m.pathErr = err.Error()
 		return m, nil
 	}
 
-	tables, err := db.ListTables(database)
+	tables, entries, schema, err := listTables(database, path)
 	if err != nil {
-		database.Close()
+		db.Close(database)
 		m.pathErr = err.Error()
 		return m, nil
 	}
 
+	// Failing to record the recent file shouldn't block opening it. URLs
+	// aren't recorded: an HTTP one may carry a signature.
+	if !db.IsURL(path) {
+		_ = state.AddRecentFile(path)
+	}
+
 	return m, func() tea.Msg {
-		return dbOpenedMsg{db: database, tables: tables}
+		return dbOpenedMsg{db: database, path: path, tables: tables, entries: entries, schema: schema}
 	}
 }
 
 // validatePath checks that the path points to an existing regular file
-// with a recognized SQLite extension.
+// with a recognized SQLite extension. A URL is checked by opening it.
 func validatePath(path string) error {
+	if db.IsURL(path) {
+		return nil
+	}
 	info, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		return fmt.Errorf("file not found: %s", path)
@@ -301,3 +484,20 @@ func findSQLiteFiles() []string {
 	}
 	return files
 }
+
+// withoutRecent drops files that already appear in the recent list so the
+// same database isn't shown twice.
+func withoutRecent(files, recent []string) []string {
+	seen := make(map[string]bool, len(recent))
+	for _, r := range recent {
+		seen[r] = true
+	}
+	var out []string
+	for _, f := range files {
+		if abs, err := filepath.Abs(f); err == nil && seen[abs] {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/config"
+	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
+)
+
+// filterHistory remembers the filters confirmed on the tables of one
+// database, newest first, for recalling with up and down in the filter
+// input. Every grid of the database shares it.
+type filterHistory struct {
+	dbPath string // saved here after every change; "" keeps it for the session
+	limit  int    // filters kept per table
+	tables state.FilterHistory
+}
+
+func newFilterHistory(dbPath string, cfg config.Config) *filterHistory {
+	h := &filterHistory{limit: cfg.FilterHistory, tables: state.FilterHistory{}}
+	if cfg.SaveFilterHistory {
+		h.dbPath = dbPath
+		h.tables = state.LoadFilterHistory(dbPath)
+	}
+	return h
+}
+
+// add puts f at the front of table's history, dropping an earlier copy of
+// it and the oldest entries past the limit.
+func (h *filterHistory) add(table string, f state.Filter) {
+	if h == nil || h.limit == 0 {
+		return
+	}
+	entries := slices.DeleteFunc(slices.Clone(h.tables[table]), func(e state.Filter) bool { return e == f })
+	entries = append([]state.Filter{f}, entries...)
+	h.tables[table] = entries[:min(len(entries), h.limit)]
+	if h.dbPath != "" {
+		_ = state.SaveFilterHistory(h.dbPath, h.tables)
+	}
+}
+
+// list is table's history, newest first.
+func (h *filterHistory) list(table string) []state.Filter {
+	if h == nil {
+		return nil
+	}
+	return h.tables[table]
+}
+
+// recordFilter adds the filter just confirmed to the history.
+func (m *TableDataModel) recordFilter() {
+	if m.static || m.fQuery == "" {
+		return
+	}
+	m.history.add(m.tableName, state.Filter{Column: m.fCol, Query: m.fQuery, Mode: m.fMode.String()})
+}
+
+// recallFilter steps through the table's history while typing a filter:
+// delta 1 recalls an older filter, -1 a newer one, and stepping past the
+// newest brings back what was being typed. A recalled filter brings its
+// column and match mode along.
+func (m *TableDataModel) recallFilter(delta int) tea.Cmd {
+	var entries []state.Filter
+	for _, f := range m.history.list(m.tableName) {
+		if slices.Contains(m.columns, f.Column) {
+			entries = append(entries, f)
+		}
+	}
+	pos := m.histPos + delta
+	if pos < 0 || pos > len(entries) {
+		return nil
+	}
+	if m.histPos == 0 {
+		m.histDraft = state.Filter{Column: m.fCol, Query: m.fInput.Value(), Mode: m.fMode.String()}
+	}
+	m.histPos = pos
+	f := m.histDraft
+	if pos > 0 {
+		f = entries[pos-1]
+	}
+	m.fCol = f.Column
+	m.fMode = db.ParseMatchMode(f.Mode)
+	m.fInput.Prompt = m.filterPrompt()
+	m.fInput.SetValue(f.Query)
+	m.fInput.CursorEnd()
+	return m.applyFilter()
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// filterCell is the column of the table widget holding the filter column,
+// while the rows shown are filter results and it is one of the columns
+// displayed, or -1.
+func (m TableDataModel) filterCell() int {
+	if !m.fFiltered || m.fInput.Value() == "" {
+		return -1
+	}
+	col := slices.Index(m.columns, m.fCol)
+	if col < 0 || col >= m.displayCols {
+		return -1
+	}
+	return len(m.leading) + col
+}
+
+// filterSpans returns the byte ranges of value, a cell of the filter
+// column as shown, that the filter matched: where the text occurs for a
+// substring filter, the regular expression's matches, the terms FTS5 set
+// in «» in its snippet, the whole value for an exact one, and for a fuzzy
+// one what db.FuzzySpans says.
+func (m TableDataModel) filterSpans(value string) [][2]int {
+	query := m.fInput.Value()
+	switch m.fMode {
+	case db.MatchExact:
+		trimmed := strings.TrimLeft(value, " ")
+		start := len(value) - len(trimmed)
+		return [][2]int{{start, start + len(strings.TrimRight(trimmed, " "))}}
+	case db.MatchFTS:
+		return snippetSpans(value)
+	case db.MatchRegexp:
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil
+		}
+		return regexpSpans(re, value)
+	case db.MatchFuzzy:
+		return db.FuzzySpans(value, query)
+	}
+	return regexpSpans(substringRegexp(query), value)
+}
+
+// substringRegexp finds text in any case, with the offsets of the value
+// it is found in rather than of a lowered copy.
+func substringRegexp(text string) *regexp.Regexp {
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+}
+
+// regexpSpans returns re's matches in value, leaving out empty ones.
+func regexpSpans(re *regexp.Regexp, value string) [][2]int {
+	var spans [][2]int
+	for _, loc := range re.FindAllStringIndex(value, -1) {
+		if loc[0] < loc[1] {
+			spans = append(spans, [2]int{loc[0], loc[1]})
+		}
+	}
+	return spans
+}
+
+// snippetSpans returns the terms an FTS5 snippet sets in «».
+func snippetSpans(value string) [][2]int {
+	var spans [][2]int
+	for at := 0; ; {
+		open := strings.Index(value[at:], "«")
+		if open < 0 {
+			return spans
+		}
+		start := at + open + len("«")
+		end := strings.Index(value[start:], "»")
+		if end < 0 {
+			return spans
+		}
+		spans = append(spans, [2]int{start, start + end})
+		at = start + end + len("»")
+	}
+}
+
+// clipSpans shifts spans by -from and keeps what of them lies in the
+// first n bytes after it.
+func clipSpans(spans [][2]int, from, n int) [][2]int {
+	var out [][2]int
+	for _, s := range spans {
+		start, end := max(s[0]-from, 0), min(s[1]-from, n)
+		if start < end {
+			out = append(out, [2]int{start, end})
+		}
+	}
+	return out
+}
@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// columnPick is what the column picker is open for.
+type columnPick int
+
+const (
+	pickFilterCol   columnPick = iota // the column to filter
+	pickSortCol                       // the column to sort by alone
+	pickThenSortCol                   // a column to sort by after the others
+)
+
+// label heads the picker's search line.
+func (p columnPick) label() string {
+	switch p {
+	case pickSortCol:
+		return "sort by"
+	case pickThenSortCol:
+		return "then sort by"
+	}
+	return "column"
+}
+
+// startSort opens the column picker to sort the table by the column
+// picked: alone, or with then after the columns it is already sorted by.
+func (m *TableDataModel) startSort(then bool) tea.Cmd {
+	if m.static && !m.rerunnable() {
+		return noteCmd(errors.New("only tables, views, and the results of a single SELECT can be sorted"))
+	}
+	m.fPick = pickSortCol
+	if then {
+		m.fPick = pickThenSortCol
+	}
+	m.openColumnPicker()
+	return nil
+}
+
+// sortBy sorts the rows by column col, starting over from the first page,
+// or running the query again for a query result held in full.
+// Picked alone, col becomes the only column sorted by, ascending, unless
+// it already is: then it goes descending, and after that unsorted. Added
+// with then, it comes after the others, or turns over in place the same
+// way if it is one of them.
+func (m *TableDataModel) sortBy(col int, then bool) tea.Cmd {
+	m.fState = filterOff
+	m.table.SetHeight(m.tableHeight())
+	sort := slices.Clone(m.sort)
+	i := slices.IndexFunc(sort, func(k db.SortKey) bool { return m.sortColumn(k) == col })
+	switch {
+	case !then && (i != 0 || len(sort) > 1):
+		sort = []db.SortKey{m.sortKey(col)}
+	case i < 0:
+		sort = append(sort, m.sortKey(col))
+	case !sort[i].Desc:
+		sort[i].Desc = true
+	default:
+		sort = slices.Delete(sort, i, i+1)
+	}
+	if len(sort) == 0 {
+		sort = nil
+	}
+	m.sort = sort
+	// The markers change the header's width.
+	m.refitColumns()
+	m.setTableRows(m.allRows)
+	if m.inMemory() {
+		return m.rerunCmd()
+	}
+	return m.pageCmd(0, false)
+}
+
+// sortKey sorts by column i: by its position for a query result or view,
+// whose columns can share a name.
+func (m TableDataModel) sortKey(i int) db.SortKey {
+	k := db.SortKey{Column: m.columns[i]}
+	if m.static {
+		k.Pos = i + 1
+	}
+	return k
+}
+
+// sortColumn is the index of the column k sorts by, or -1 if the grid has
+// no such column.
+func (m TableDataModel) sortColumn(k db.SortKey) int {
+	if k.Pos == 0 {
+		return slices.Index(m.columns, k.Column)
+	}
+	if k.Pos > len(m.columns) || m.columns[k.Pos-1] != k.Column {
+		return -1
+	}
+	return k.Pos - 1
+}
+
+// sortMarker is the ▲ or ▼ after the name of column col when the rows
+// are sorted by it, numbered when they are sorted by several.
+func (m TableDataModel) sortMarker(col int) string {
+	for i, k := range m.sort {
+		if m.sortColumn(k) != col {
+			continue
+		}
+		mark := " ▲"
+		if k.Desc {
+			mark = " ▼"
+		}
+		if len(m.sort) > 1 {
+			mark += strconv.Itoa(i + 1)
+		}
+		return mark
+	}
+	return ""
+}
+
+// columnTitles are the column names as the header shows them, with their
+// sort markers.
+func (m TableDataModel) columnTitles() []string {
+	if len(m.sort) == 0 {
+		return m.columns
+	}
+	titles := make([]string, len(m.columns))
+	for i, c := range m.columns {
+		titles[i] = c + m.sortMarker(i)
+	}
+	return titles
+}
+
+// fitTitle is a column's name with its sort marker, shortened to width by
+// cutting the name rather than the marker, which the table would cut first.
+func fitTitle(name, mark string, width int) string {
+	if runewidth.StringWidth(name+mark) <= width {
+		return name + mark
+	}
+	if mark == "" {
+		return name
+	}
+	return runewidth.Truncate(name, max(width-runewidth.StringWidth(mark), 1), "…") + mark
+}
+
+// sortText tells the status bar what the rows are sorted by, since the
+// columns may be out of sight.
+func (m TableDataModel) sortText() string {
+	by := make([]string, len(m.sort))
+	for i, k := range m.sort {
+		by[i] = k.Column
+		if k.Desc {
+			by[i] += " desc"
+		}
+	}
+	return "sorted by " + strings.Join(by, ", ")
+}
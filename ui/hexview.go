@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// hexDumpLimit caps how much of a BLOB the row detail dumps; past it the
+// dump notes how many bytes were left out.
+const hexDumpLimit = 64 << 10
+
+// hexDump renders data as offset, hex, and ASCII columns in the style of
+// hexdump -C, with as many bytes per line (16 or 8) as fit in width.
+func hexDump(data []byte, width int) []string {
+	perLine := 16
+	// offset (8) + gap (2) + 3 per byte + group gap (1) + bars (2) + 1 per byte
+	if 13+perLine*4 > width {
+		perLine = 8
+	}
+	shown := data[:min(len(data), hexDumpLimit)]
+
+	var lines []string
+	for off := 0; off < len(shown); off += perLine {
+		chunk := shown[off:min(off+perLine, len(shown))]
+		var hex, ascii strings.Builder
+		for i := range perLine {
+			if i == perLine/2 {
+				hex.WriteByte(' ')
+			}
+			if i >= len(chunk) {
+				hex.WriteString("   ")
+				continue
+			}
+			fmt.Fprintf(&hex, "%02x ", chunk[i])
+			if c := chunk[i]; c >= 0x20 && c < 0x7f {
+				ascii.WriteByte(c)
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		lines = append(lines, PopupLabelStyle.Render(fmt.Sprintf("%08x", off))+"  "+hex.String()+"|"+ascii.String()+"|")
+	}
+	if len(shown) == 0 {
+		lines = append(lines, StatusBarStyle.Render("(empty)"))
+	}
+	if rest := len(data) - len(shown); rest > 0 {
+		lines = append(lines, StatusBarStyle.Render(fmt.Sprintf("… %d more bytes", rest)))
+	}
+	return lines
+}
+
+// blobExtensions maps the content types http.DetectContentType recognizes
+// most often to the extension a saved BLOB gets by default.
+var blobExtensions = map[string]string{
+	"image/png":          ".png",
+	"image/jpeg":         ".jpg",
+	"image/gif":          ".gif",
+	"image/webp":         ".webp",
+	"image/bmp":          ".bmp",
+	"application/pdf":    ".pdf",
+	"application/zip":    ".zip",
+	"application/x-gzip": ".gz",
+	"audio/mpeg":         ".mp3",
+	"video/mp4":          ".mp4",
+	"text/plain":         ".txt",
+}
+
+// blobFileName suggests a file name for a BLOB from where it's stored,
+// with an extension guessed from its first bytes.
+func blobFileName(table, column string, rowID int64, data []byte) string {
+	ext := ".bin"
+	typ, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if e, ok := blobExtensions[typ]; ok {
+		ext = e
+	}
+	return fmt.Sprintf("%s-%s-%d%s", table, column, rowID, ext)
+}
+
+// writeNewFile writes data to path, refusing to replace an existing file.
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg" // registers the JPEG decoder for image.Decode
+	"image/png"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// graphicsProtocol is how the terminal can be sent an image.
+type graphicsProtocol int
+
+const (
+	graphicsNone   graphicsProtocol = iota
+	graphicsKitty                   // kitty graphics protocol with Unicode placeholders
+	graphicsITerm2                  // iTerm2 inline images (OSC 1337)
+	graphicsSixel                   // DEC sixel
+)
+
+// imageProtocol is the protocol image previews use, from the
+// image_preview config setting or detected from the environment.
+var imageProtocol = detectGraphics()
+
+const (
+	// imagePreviewRows caps the height of a preview in terminal rows.
+	imagePreviewRows = 12
+	// imagePreviewMaxBytes skips previews of BLOBs too large to decode
+	// every time a row is opened.
+	imagePreviewMaxBytes = 16 << 20
+	// Images are scaled for cells of this many pixels before being sent,
+	// which keeps the payload small; the terminal fits them to the cells.
+	cellPixelWidth  = 8
+	cellPixelHeight = 16
+)
+
+// parseGraphics reads the image_preview setting: auto (or empty), off,
+// kitty, iterm2, or sixel.
+func parseGraphics(name string) (graphicsProtocol, error) {
+	switch strings.ToLower(name) {
+	case "", "auto":
+		return detectGraphics(), nil
+	case "off", "none":
+		return graphicsNone, nil
+	case "kitty":
+		return graphicsKitty, nil
+	case "iterm2":
+		return graphicsITerm2, nil
+	case "sixel":
+		return graphicsSixel, nil
+	}
+	return graphicsNone, fmt.Errorf("image_preview: unknown protocol %q (want auto, off, kitty, iterm2, or sixel)", name)
+}
+
+// detectGraphics guesses the terminal's image protocol from the variables
+// terminals set. Terminals can't be asked without reading a reply from
+// stdin, which Bubble Tea owns, so anything unrecognized gets no previews.
+func detectGraphics() graphicsProtocol {
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return graphicsKitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return graphicsITerm2
+	case strings.Contains(term, "sixel") || strings.HasPrefix(term, "foot") || term == "mlterm":
+		return graphicsSixel
+	}
+	return graphicsNone
+}
+
+// imagePreview is an image BLOB ready to draw in the row detail: a caption
+// and the lines holding the image, one per terminal row.
+type imagePreview struct {
+	caption string
+	lines   []string
+}
+
+// nextImageID numbers kitty images. Placeholder cells name their image in
+// a 256-color foreground, so ids stay within 1-255; reusing one replaces
+// the older image, which by then is no longer on screen.
+var nextImageID = 0
+
+// newImagePreview decodes a PNG or JPEG BLOB and encodes it for the
+// terminal, at most maxCols wide. ok is false for other data, or when the
+// terminal has no image protocol.
+func newImagePreview(data []byte, maxCols int) (imagePreview, bool) {
+	if imageProtocol == graphicsNone || maxCols < 1 {
+		return imagePreview{}, false
+	}
+	kind := ""
+	switch http.DetectContentType(data) {
+	case "image/png":
+		kind = "PNG"
+	case "image/jpeg":
+		kind = "JPEG"
+	default:
+		return imagePreview{}, false
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return imagePreview{}, false
+	}
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return imagePreview{}, false
+	}
+
+	// Fit the image into maxCols × imagePreviewRows cells, never
+	// enlarging it past its own pixel size.
+	cols := min(maxCols, (b.Dx()+cellPixelWidth-1)/cellPixelWidth)
+	rows := max(1, cols*cellPixelWidth*b.Dy()/b.Dx()/cellPixelHeight)
+	if rows > imagePreviewRows {
+		rows = imagePreviewRows
+		cols = max(1, rows*cellPixelHeight*b.Dx()/b.Dy()/cellPixelWidth)
+	}
+	scaled := scaleImage(img, cols*cellPixelWidth, rows*cellPixelHeight)
+
+	p := imagePreview{
+		caption: fmt.Sprintf("%s %d×%d, %s", kind, b.Dx(), b.Dy(), formatBytes(int64(len(data)))),
+		lines:   make([]string, rows),
+	}
+	switch imageProtocol {
+	case graphicsKitty:
+		nextImageID = nextImageID%255 + 1
+		for r := range p.lines {
+			p.lines[r] = kittyPlaceholders(nextImageID, r, cols)
+		}
+		p.lines[0] = kittyTransmit(scaled, nextImageID, cols, rows) + p.lines[0]
+	case graphicsITerm2:
+		var buf bytes.Buffer
+		png.Encode(&buf, scaled)
+		p.lines[0] = fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=0:%s\a",
+			buf.Len(), cols, rows, base64.StdEncoding.EncodeToString(buf.Bytes()))
+	case graphicsSixel:
+		p.lines[0] = encodeSixel(scaled)
+	}
+	return p, true
+}
+
+// scaleImage resizes img to w × h by nearest-neighbor sampling, which is
+// plenty for a preview of a few hundred pixels.
+func scaleImage(img image.Image, w, h int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		sy := b.Min.Y + y*b.Dy()/h
+		for x := range w {
+			out.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, sy))
+		}
+	}
+	return out
+}
+
+// kittyTransmit sends img to kitty as a PNG with a virtual placement of
+// cols × rows cells, shown wherever its placeholder cells are printed. The
+// payload goes in chunks of at most 4096 bytes, as the protocol requires.
+func kittyTransmit(img image.Image, id, cols, rows int) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var b strings.Builder
+	for first := true; first || payload != ""; first = false {
+		chunk := payload[:min(4096, len(payload))]
+		payload = payload[len(chunk):]
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(&b, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return b.String()
+}
+
+// kittyPlaceholder is the character kitty replaces with a cell of a
+// virtually placed image.
+const kittyPlaceholder = "\U0010EEEE"
+
+// kittyDiacritics encode row and column numbers on placeholder cells, per
+// the kitty protocol's table; the first imagePreviewRows are enough here.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D, 0x033E, 0x033F,
+	0x0346, 0x034A, 0x034B, 0x034C, 0x0350, 0x0351, 0x0352, 0x0357,
+}
+
+// kittyPlaceholders is row r of image id's placeholder cells. Only the
+// first cell carries row and column; kitty counts the rest from it.
+func kittyPlaceholders(id, r, cols int) string {
+	return fmt.Sprintf("\x1b[38;5;%dm", id) + kittyPlaceholder + string(kittyDiacritics[r]) + string(kittyDiacritics[0]) +
+		strings.Repeat(kittyPlaceholder, cols-1) + "\x1b[39m"
+}
+
+// encodeSixel renders img as sixel graphics, with colors reduced to a
+// 6×6×6 cube.
+func encodeSixel(img *image.RGBA) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	index := make([]int, w*h)
+	used := map[int]bool{}
+	for y := range h {
+		for x := range w {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i := int(c.R)*6/256*36 + int(c.G)*6/256*6 + int(c.B)*6/256
+			index[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var s strings.Builder
+	fmt.Fprintf(&s, "\x1bP0;1q\"1;1;%d;%d", w, h)
+	for i := range 216 {
+		if used[i] {
+			fmt.Fprintf(&s, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+		}
+	}
+	for y0 := 0; y0 < h; y0 += 6 {
+		for i := range 216 {
+			if !used[i] {
+				continue
+			}
+			var band strings.Builder
+			present := false
+			run, last := 0, byte(0)
+			flush := func() {
+				switch {
+				case run > 3:
+					fmt.Fprintf(&band, "!%d%c", run, last)
+				default:
+					band.WriteString(strings.Repeat(string(last), run))
+				}
+			}
+			for x := range w {
+				bits := 0
+				for k := range 6 {
+					if y := y0 + k; y < h && index[y*w+x] == i {
+						bits |= 1 << k
+					}
+				}
+				present = present || bits != 0
+				ch := byte(63 + bits)
+				if ch == last {
+					run++
+					continue
+				}
+				flush()
+				run, last = 1, ch
+			}
+			flush()
+			if present {
+				fmt.Fprintf(&s, "#%d%s$", i, band.String())
+			}
+		}
+		s.WriteByte('-')
+	}
+	s.WriteString("\x1b\\")
+	return s.String()
+}
@@ -0,0 +1,189 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// indexesLoadedMsg carries the indexes of the table the index popup lists.
+type indexesLoadedMsg struct {
+	indexes []db.IndexInfo
+	err     error
+}
+
+// indexDroppedMsg reports the outcome of dropping an index.
+type indexDroppedMsg struct {
+	name string
+	err  error
+}
+
+// IndexesModel is the popup listing a table's indexes with their columns,
+// from which one made with CREATE INDEX can be dropped: d arms the drop,
+// and d again confirms it.
+type IndexesModel struct {
+	database *sql.DB
+	table    string
+	indexes  []db.IndexInfo
+	loaded   bool
+	cursor   int
+	scroll   int
+	listLen  int
+	armed    bool // d was pressed once on the selected index
+	running  bool
+	note     string // the outcome of the last drop
+	err      error
+	width    int
+}
+
+func NewIndexesModel(database *sql.DB, table string, termWidth, termHeight int) (IndexesModel, tea.Cmd) {
+	m := IndexesModel{
+		database: database,
+		table:    table,
+		width:    max(termWidth*60/100, 50),
+		// Border, padding, title, gaps, note, and help take 9 lines.
+		listLen: max(termHeight*60/100-9, 3),
+	}
+	return m, m.load()
+}
+
+func (m IndexesModel) load() tea.Cmd {
+	database, table := m.database, m.table
+	return func() tea.Msg {
+		indexes, err := db.TableIndexes(context.Background(), database, table)
+		return indexesLoadedMsg{indexes: indexes, err: err}
+	}
+}
+
+func (m IndexesModel) Update(msg tea.Msg) (IndexesModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case indexesLoadedMsg:
+		m.loaded = true
+		m.indexes, m.err = msg.indexes, msg.err
+		m.cursor = min(m.cursor, max(len(m.indexes)-1, 0))
+		m.scroll = scrollTo(m.cursor, min(m.scroll, m.cursor), m.listLen)
+		return m, nil
+
+	case indexDroppedMsg:
+		m.running = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.note = "dropped " + msg.name
+		return m, m.load()
+
+	case tea.KeyMsg:
+		if m.running {
+			return m, nil
+		}
+		drop := msg.String() == "d" || key.Matches(msg, Keys.DeleteRow)
+		if m.armed {
+			m.armed = false
+			if drop {
+				return m.drop()
+			}
+			return m, nil // any other key keeps the index
+		}
+		m.note, m.err = "", nil
+		switch {
+		case msg.String() == "esc" || key.Matches(msg, Keys.Indexes):
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case msg.String() == "up" || msg.String() == "k":
+			m.cursor = max(m.cursor-1, 0)
+		case msg.String() == "down" || msg.String() == "j":
+			m.cursor = min(m.cursor+1, max(len(m.indexes)-1, 0))
+		case drop && m.cursor < len(m.indexes):
+			if ix := m.indexes[m.cursor]; ix.Origin != "c" {
+				m.err = fmt.Errorf("%s belongs to a %s constraint and goes only with it", ix.Name, originName(ix.Origin))
+			} else {
+				m.armed = true
+			}
+		}
+		m.scroll = scrollTo(m.cursor, m.scroll, m.listLen)
+	}
+	return m, nil
+}
+
+// drop drops the selected index in the background.
+func (m IndexesModel) drop() (IndexesModel, tea.Cmd) {
+	m.running = true
+	database, table, name := m.database, m.table, m.indexes[m.cursor].Name
+	return m, func() tea.Msg {
+		return indexDroppedMsg{name: name, err: db.DropIndex(context.Background(), database, table, name)}
+	}
+}
+
+// originName names the constraint an index came with.
+func originName(origin string) string {
+	if origin == "pk" {
+		return "PRIMARY KEY"
+	}
+	return "UNIQUE"
+}
+
+func (m IndexesModel) View() string {
+	var body string
+	switch {
+	case !m.loaded:
+		body = StatusBarStyle.Render("reading indexes...")
+	case len(m.indexes) == 0:
+		body = StatusBarStyle.Render("no indexes")
+	default:
+		nameW := 0
+		for _, ix := range m.indexes {
+			nameW = max(nameW, len(ix.Name))
+		}
+		var b strings.Builder
+		end := min(m.scroll+m.listLen, len(m.indexes))
+		for i, ix := range m.indexes[m.scroll:end] {
+			i += m.scroll
+			var flags []string
+			switch {
+			case ix.Origin != "c":
+				flags = append(flags, originName(ix.Origin)+" constraint")
+			case ix.Unique:
+				flags = append(flags, "unique")
+			}
+			if ix.Partial {
+				flags = append(flags, "partial")
+			}
+			line := fmt.Sprintf("%-*s  (%s)", nameW, ix.Name, strings.Join(ix.Columns, ", "))
+			if len(flags) > 0 {
+				line += "  " + strings.Join(flags, ", ")
+			}
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+line) + "\n")
+			} else {
+				b.WriteString(StatusBarStyle.Render("  "+line) + "\n")
+			}
+		}
+		body = strings.TrimSuffix(b.String(), "\n")
+	}
+
+	status := " "
+	switch {
+	case m.err != nil:
+		status = ErrorStyle.Render("Error: " + m.err.Error())
+	case m.note != "":
+		status = StatusBarStyle.Render(m.note)
+	}
+	help := "↑↓: select | d: drop index | esc: close"
+	switch {
+	case m.armed:
+		help = ErrorStyle.Render(fmt.Sprintf("press d again to run %s | any other key cancels", db.DropIndexSQL(m.table, m.indexes[m.cursor].Name)))
+	case m.running:
+		help = StatusBarStyle.Render("dropping...")
+	default:
+		help = StatusBarStyle.Render(help)
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(" Indexes of "+m.table+" ") + "\n\n" + body + "\n\n" + status + "\n" + help)
+}
@@ -0,0 +1,240 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// RowInsertedMsg is sent after the insert popup adds a row, so the parent
+// can close the popup and refresh the grid.
+type RowInsertedMsg struct {
+	TableName string
+	RowID     int64
+	Edit      db.Edit
+}
+
+// InsertRowModel is the "paste a row" popup. The user pastes a JSON object
+// (keys are column names) or a single CSV line (values in column order);
+// a live preview shows how the values map onto the table's columns.
+type InsertRowModel struct {
+	textarea  textarea.Model
+	database  *sql.DB
+	tableName string
+	columns   []string
+	insertErr string
+	width     int
+	height    int
+}
+
+// NewInsertRowModel creates the popup for inserting into tableName.
+func NewInsertRowModel(database *sql.DB, tableName string, columns []string, termWidth, termHeight int) (InsertRowModel, tea.Cmd) {
+	popupWidth := max(termWidth*70/100, 50)
+	popupHeight := max(termHeight*70/100, 16)
+
+	ta := textarea.New()
+	ta.Placeholder = `{"name": "..."}  or  a,b,c`
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.FocusedStyle.Base = lipgloss.NewStyle()
+	ta.BlurredStyle.Base = lipgloss.NewStyle()
+	ta.SetWidth(popupWidth - 6)
+	ta.SetHeight(4)
+	cmd := ta.Focus()
+
+	return InsertRowModel{
+		textarea:  ta,
+		database:  database,
+		tableName: tableName,
+		columns:   columns,
+		width:     popupWidth,
+		height:    popupHeight,
+	}, cmd
+}
+
+func (m InsertRowModel) Update(msg tea.Msg) (InsertRowModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		if key.Matches(msg, Keys.Save) {
+			cols, vals, err := parseRowInput(m.textarea.Value(), m.columns)
+			if err != nil {
+				m.insertErr = err.Error()
+				return m, nil
+			}
+			rowID, edit, err := db.InsertRow(context.Background(), m.database, m.tableName, cols, vals)
+			if err != nil {
+				m.insertErr = err.Error()
+				return m, nil
+			}
+			tableName := m.tableName
+			return m, func() tea.Msg { return RowInsertedMsg{TableName: tableName, RowID: rowID, Edit: edit} }
+		}
+		if msg.String() == "esc" {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	m.insertErr = ""
+	return m, cmd
+}
+
+func (m InsertRowModel) View() string {
+	title := TitleStyle.Render(" Insert into " + m.tableName + " ")
+	help := StatusBarStyle.Render(Keys.Save.Help().Key + ": insert | esc: close")
+
+	// Preview area: everything between the textarea and the help line.
+	// Overhead: border(2) + padding(2) + title(1) + gap(1) + textarea(4) +
+	// gap(1) + error(1) + help(1).
+	previewH := max(m.height-13, 1)
+
+	var preview string
+	cols, vals, err := parseRowInput(m.textarea.Value(), m.columns)
+	switch {
+	case strings.TrimSpace(m.textarea.Value()) == "":
+		preview = StatusBarStyle.Render("Paste a JSON object or a CSV line. Columns: " + strings.Join(m.columns, ", "))
+	case err != nil:
+		preview = ErrorStyle.Render(err.Error())
+	default:
+		preview = renderInsertPreview(m.columns, cols, vals, previewH)
+	}
+
+	errLine := " "
+	if m.insertErr != "" {
+		errLine = ErrorStyle.Render("Error: " + m.insertErr)
+	}
+
+	previewBox := lipgloss.NewStyle().Height(previewH).MaxHeight(previewH).Render(preview)
+
+	return PopupStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(title + "\n\n" + m.textarea.View() + "\n\n" + previewBox + "\n" + errLine + "\n" + help)
+}
+
+// renderInsertPreview lists every table column with the value it will get,
+// or "(default)" for columns the input doesn't set.
+func renderInsertPreview(tableCols, cols []string, vals []any, maxLines int) string {
+	set := make(map[string]any, len(cols))
+	for i, c := range cols {
+		set[c] = vals[i]
+	}
+	maxLabel := 0
+	for _, c := range tableCols {
+		maxLabel = max(maxLabel, len(c))
+	}
+
+	var lines []string
+	for _, c := range tableCols {
+		label := PopupLabelStyle.Render(fmt.Sprintf("%*s", maxLabel, c))
+		v, ok := set[c]
+		var val string
+		switch {
+		case !ok:
+			val = StatusBarStyle.Render("(default)")
+		case v == nil:
+			val = "NULL"
+		default:
+			val = fmt.Sprintf("%v", v)
+		}
+		lines = append(lines, label+" : "+val)
+	}
+	if len(lines) > maxLines {
+		hidden := len(lines) - maxLines + 1
+		lines = append(lines[:maxLines-1], StatusBarStyle.Render(fmt.Sprintf("… %d more columns", hidden)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseRowInput maps pasted text onto table columns. Input starting with
+// "{" is a JSON object whose keys must be column names; anything else is
+// one CSV record whose values fill columns left to right. Returns the
+// columns being set and their values.
+func parseRowInput(input string, tableCols []string) ([]string, []any, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil, nil, fmt.Errorf("nothing to insert")
+	}
+	if strings.HasPrefix(input, "{") {
+		return parseJSONRow(input, tableCols)
+	}
+	return parseCSVRow(input, tableCols)
+}
+
+func parseJSONRow(input string, tableCols []string) ([]string, []any, error) {
+	known := make(map[string]bool, len(tableCols))
+	for _, c := range tableCols {
+		known[c] = true
+	}
+
+	dec := json.NewDecoder(strings.NewReader(input))
+	dec.UseNumber()
+	var obj map[string]any
+	if err := dec.Decode(&obj); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+
+	// Keep table column order so the preview and INSERT are stable.
+	var cols []string
+	var vals []any
+	for _, c := range tableCols {
+		v, ok := obj[c]
+		if !ok {
+			continue
+		}
+		switch v := v.(type) {
+		case json.Number:
+			vals = append(vals, v.String())
+		case map[string]any, []any:
+			// Nested values are stored as JSON text.
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			if err := enc.Encode(v); err != nil {
+				return nil, nil, err
+			}
+			vals = append(vals, strings.TrimSpace(buf.String()))
+		default:
+			vals = append(vals, v)
+		}
+		cols = append(cols, c)
+	}
+	for k := range obj {
+		if !known[k] {
+			return nil, nil, fmt.Errorf("unknown column %q", k)
+		}
+	}
+	return cols, vals, nil
+}
+
+func parseCSVRow(input string, tableCols []string) ([]string, []any, error) {
+	r := csv.NewReader(strings.NewReader(input))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) != 1 {
+		return nil, nil, fmt.Errorf("expected one CSV line, got %d", len(records))
+	}
+	record := records[0]
+	if len(record) > len(tableCols) {
+		return nil, nil, fmt.Errorf("%d values but the table has %d columns", len(record), len(tableCols))
+	}
+	vals := make([]any, len(record))
+	for i, v := range record {
+		vals[i] = v
+	}
+	return tableCols[:len(record)], vals, nil
+}
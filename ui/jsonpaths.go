@@ -0,0 +1,299 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// jsonSampleDocs is how many JSON documents the explorer reads to find a
+// column's paths, so a huge table answers quickly.
+const jsonSampleDocs = 10000
+
+// jsonPathsMsg carries the paths found in one column's JSON values.
+type jsonPathsMsg struct {
+	column  string
+	docs    int64
+	paths   []db.JSONPath
+	elapsed time.Duration
+	err     error
+}
+
+// editQueryMsg opens the query popup holding query, ready to run or edit.
+type editQueryMsg struct {
+	query string
+}
+
+// JSONPathsModel is the popup exploring the JSON stored in a column: pick
+// a column to list the keys its documents hold, then select a path's
+// values as a column of their own or filter the rows on one. Both open
+// the generated query in the query popup.
+type JSONPathsModel struct {
+	database *sql.DB
+	table    string
+	columns  []db.ColumnInfo
+	cursor   int
+	scroll   int
+
+	running bool
+	cancel  context.CancelFunc
+	spinner spinner.Model
+	started time.Time
+
+	result     *jsonPathsMsg // shown instead of the column list once read
+	pathCursor int
+	pathScroll int
+
+	filtering bool // asking for the value to filter the selected path on
+	value     textinput.Model
+
+	width   int
+	listLen int // rows visible at once
+}
+
+func NewJSONPathsModel(database *sql.DB, table string, termWidth, termHeight int) (JSONPathsModel, error) {
+	columns, err := db.GetColumnInfo(context.Background(), database, table)
+	if err != nil {
+		return JSONPathsModel{}, err
+	}
+	value := textinput.New()
+	value.Prompt = "= "
+	value.Placeholder = "value (empty: has the key)"
+	return JSONPathsModel{
+		database: database,
+		table:    table,
+		columns:  columns,
+		spinner:  newSpinner(),
+		value:    value,
+		width:    max(termWidth*60/100, 60),
+		// Border, padding, title, gaps, summary, input, and help take 11 lines.
+		listLen: max(termHeight*70/100-11, 3),
+	}, nil
+}
+
+func (m JSONPathsModel) Update(msg tea.Msg) (JSONPathsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case jsonPathsMsg:
+		if !m.running || msg.column != m.columns[m.cursor].Name {
+			return m, nil
+		}
+		m.running = false
+		m.cancel = nil
+		m.result = &msg
+		m.pathCursor, m.pathScroll = 0, 0
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case m.running:
+			if msg.String() == "esc" {
+				m.cancel()
+				m.cancel = nil
+				m.running = false
+			}
+			return m, nil
+		case m.filtering:
+			return m.updateFilter(msg)
+		case m.result != nil:
+			return m.updatePaths(msg)
+		}
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, len(m.columns)-1)
+		case "pgup":
+			m.cursor = max(m.cursor-m.listLen, 0)
+		case "pgdown":
+			m.cursor = min(m.cursor+m.listLen, len(m.columns)-1)
+		case "enter":
+			if len(m.columns) > 0 {
+				return m.start()
+			}
+		default:
+			if key.Matches(msg, Keys.JSONPaths) {
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+		}
+		m.scroll = scrollTo(m.cursor, m.scroll, m.listLen)
+	}
+	return m, nil
+}
+
+// updatePaths handles keys on the list of paths found in a column.
+func (m JSONPathsModel) updatePaths(msg tea.KeyMsg) (JSONPathsModel, tea.Cmd) {
+	paths := m.result.paths
+	switch msg.String() {
+	case "esc":
+		m.result = nil
+		return m, nil
+	case "up", "k":
+		m.pathCursor = max(m.pathCursor-1, 0)
+	case "down", "j":
+		m.pathCursor = min(m.pathCursor+1, len(paths)-1)
+	case "pgup":
+		m.pathCursor = max(m.pathCursor-m.listLen, 0)
+	case "pgdown":
+		m.pathCursor = min(m.pathCursor+m.listLen, len(paths)-1)
+	case "enter":
+		if len(paths) > 0 {
+			query := db.JSONProjectQuery(m.table, m.result.column, paths[m.pathCursor].Path)
+			return m, func() tea.Msg { return editQueryMsg{query: query} }
+		}
+	case "f":
+		if len(paths) > 0 {
+			m.filtering = true
+			m.value.SetValue("")
+			return m, m.value.Focus()
+		}
+	}
+	m.pathScroll = scrollTo(m.pathCursor, m.pathScroll, m.listLen)
+	return m, nil
+}
+
+// updateFilter handles keys while the filter value is typed.
+func (m JSONPathsModel) updateFilter(msg tea.KeyMsg) (JSONPathsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filtering = false
+		m.value.Blur()
+		return m, nil
+	case "enter":
+		query := db.JSONFilterQuery(m.table, m.result.column, m.result.paths[m.pathCursor].Path, m.value.Value())
+		return m, func() tea.Msg { return editQueryMsg{query: query} }
+	}
+	var cmd tea.Cmd
+	m.value, cmd = m.value.Update(msg)
+	return m, cmd
+}
+
+// scrollTo returns the scroll offset keeping cursor within a window of n
+// rows starting at scroll.
+func scrollTo(cursor, scroll, n int) int {
+	if cursor < scroll {
+		return cursor
+	}
+	if cursor >= scroll+n {
+		return cursor - n + 1
+	}
+	return scroll
+}
+
+// start reads the selected column's JSON paths in the background.
+func (m JSONPathsModel) start() (JSONPathsModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.started = time.Now()
+	database, table, column, started := m.database, m.table, m.columns[m.cursor].Name, m.started
+	run := func() tea.Msg {
+		docs, paths, err := db.JSONPaths(ctx, database, table, column, jsonSampleDocs)
+		return jsonPathsMsg{column: column, docs: docs, paths: paths, elapsed: time.Since(started), err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+func (m JSONPathsModel) View() string {
+	var title, body, help string
+	switch {
+	case m.running:
+		title = " " + m.columns[m.cursor].Name + " "
+		body = spinnerLine(m.spinner, "reading JSON", m.started)
+		help = "esc: cancel"
+	case m.result != nil:
+		title = fmt.Sprintf(" JSON paths: %s (%s) ", m.result.column, m.result.elapsed.Round(time.Millisecond))
+		switch {
+		case m.result.err != nil:
+			body = ErrorStyle.Render("Error: " + m.result.err.Error())
+			help = "esc: back"
+		case len(m.result.paths) == 0:
+			body = "No JSON objects or arrays in this column."
+			help = "esc: back"
+		default:
+			body = m.pathsView()
+			help = "↑↓: select | enter: select as column | f: filter on value | esc: back"
+			if m.filtering {
+				body += "\n\n" + m.value.View()
+				help = "enter: filter | esc: back"
+			}
+		}
+	default:
+		title = " JSON paths: " + m.table + " "
+		var b strings.Builder
+		end := min(m.scroll+m.listLen, len(m.columns))
+		for i := m.scroll; i < end; i++ {
+			c := m.columns[i]
+			line := c.Name
+			if c.Type != "" {
+				line += " " + StatusBarStyle.Render(strings.ToLower(c.Type))
+			}
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+c.Name) + strings.TrimPrefix(line, c.Name) + "\n")
+			} else {
+				b.WriteString("  " + line + "\n")
+			}
+		}
+		body = strings.TrimRight(b.String(), "\n")
+		help = "↑↓: select | enter: list paths | esc: close"
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+// pathsView lists the paths with the types of their values and how many
+// of the documents read hold them.
+func (m JSONPathsModel) pathsView() string {
+	r := m.result
+	summary := fmt.Sprintf("%d paths in %d documents", len(r.paths), r.docs)
+	if r.docs == jsonSampleDocs {
+		summary += fmt.Sprintf(" (the first %d)", jsonSampleDocs)
+	}
+
+	width := m.width - 6
+	pathWidth := 4
+	for _, p := range r.paths {
+		pathWidth = max(pathWidth, len([]rune(p.Path)))
+	}
+	pathWidth = min(pathWidth, width/2)
+
+	var b strings.Builder
+	b.WriteString(summary + "\n\n")
+	b.WriteString(PopupLabelStyle.Render(fmt.Sprintf("  %-*s %16s  %s", pathWidth, "path", "documents", "types")) + "\n")
+	end := min(m.pathScroll+m.listLen, len(r.paths))
+	for i := m.pathScroll; i < end; i++ {
+		p := r.paths[i]
+		path := truncateValue(p.Path, pathWidth)
+		path += strings.Repeat(" ", pathWidth-len([]rune(path)))
+		count := fmt.Sprintf("%d (%.0f%%)", p.Rows, float64(p.Rows)*100/float64(max(r.docs, 1)))
+		types := StatusBarStyle.Render(truncateValue(strings.Join(p.Types, ", "), max(width-pathWidth-21, 5)))
+		if i == m.pathCursor {
+			fmt.Fprintf(&b, "%s %16s  %s\n", TitleStyle.Render("▸ "+path), count, types)
+		} else {
+			fmt.Fprintf(&b, "  %s %16s  %s\n", path, count, types)
+		}
+	}
+	if len(r.paths) > m.listLen {
+		b.WriteString(StatusBarStyle.Render(fmt.Sprintf("%d/%d", m.pathCursor+1, len(r.paths))))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
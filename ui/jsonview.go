@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// isJSON reports whether s is a JSON object or array — bare strings and
+// numbers are valid JSON too, but formatting them would change nothing.
+func isJSON(s string) bool {
+	t := strings.TrimSpace(s)
+	if t == "" || (t[0] != '{' && t[0] != '[') {
+		return false
+	}
+	return json.Valid([]byte(t))
+}
+
+// formatJSON indents s, which must satisfy isJSON, and colors it for the
+// row detail: keys in the label color, numbers and literals in the accent
+// color. Lines wider than width are wrapped without color.
+func formatJSON(s string, width int) []string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(s)), "", "  "); err != nil {
+		return wrapText(s, width)
+	}
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if lipgloss.Width(line) > width {
+			lines = append(lines, wrapText(line, width)...)
+			continue
+		}
+		lines = append(lines, colorJSONLine(line))
+	}
+	return lines
+}
+
+// colorJSONLine colors one line of indented JSON. It relies on json.Indent
+// putting at most one key and one value on each line.
+func colorJSONLine(line string) string {
+	keyStyle := lipgloss.NewStyle().Foreground(activeTheme.Label)
+	litStyle := lipgloss.NewStyle().Foreground(activeTheme.Accent)
+	punctStyle := lipgloss.NewStyle().Foreground(activeTheme.Muted)
+
+	var b strings.Builder
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case c == '"':
+			end := stringEnd(line, i)
+			tok := line[i:end]
+			if strings.HasPrefix(strings.TrimLeft(line[end:], " "), ":") {
+				b.WriteString(keyStyle.Render(tok))
+			} else {
+				b.WriteString(tok)
+			}
+			i = end
+		case strings.IndexByte("{}[],:", c) >= 0:
+			b.WriteString(punctStyle.Render(string(c)))
+			i++
+		case c == ' ':
+			b.WriteByte(c)
+			i++
+		default:
+			end := i
+			for end < len(line) && strings.IndexByte(" ,]}", line[end]) < 0 {
+				end++
+			}
+			b.WriteString(litStyle.Render(line[i:end]))
+			i = end
+		}
+	}
+	return b.String()
+}
+
+// stringEnd returns the index just past the JSON string starting at
+// line[start], skipping escaped quotes.
+func stringEnd(line string, start int) int {
+	for i := start + 1; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(line)
+}
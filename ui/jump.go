@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// jumpKind is what the grid's jump prompt asks for.
+type jumpKind int
+
+const (
+	jumpOff    jumpKind = iota
+	jumpPage            // a page number (Keys.GoToPage)
+	jumpRow             // a primary key value, or #position (Keys.GoToRow)
+	jumpSearch          // text to find on the page (Keys.Search)
+)
+
+func newJumpInput() textinput.Model {
+	ti := textinput.New()
+	ti.CharLimit = 256
+	return ti
+}
+
+// startJump opens the prompt asking for a page or row to go to.
+func (m *TableDataModel) startJump(kind jumpKind) tea.Cmd {
+	m.jump = kind
+	m.jumpInput.SetValue("")
+	switch kind {
+	case jumpPage:
+		m.jumpInput.Prompt = "go to page: "
+		m.jumpInput.Placeholder = "number"
+		if pages := m.pageCount(); pages != "?" && !strings.HasPrefix(pages, "~") {
+			m.jumpInput.Placeholder = "1-" + pages
+		}
+	case jumpRow:
+		m.jumpInput.Prompt = "go to row: "
+		m.jumpInput.Placeholder = "primary key, or #position"
+		if m.static {
+			m.jumpInput.Placeholder = "#position"
+		}
+	case jumpSearch:
+		m.jumpInput.Prompt = "/"
+		m.jumpInput.Placeholder = "text on this page (↑↓: previous/next match)"
+	}
+	m.table.SetHeight(m.tableHeight())
+	return m.jumpInput.Focus()
+}
+
+// updateJump handles keys while the page or row to go to is typed.
+func (m TableDataModel) updateJump(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
+	if m.jump == jumpSearch {
+		return m.updateSearch(msg)
+	}
+	switch msg.String() {
+	case "esc":
+		m.closeJump()
+		return m, nil
+	case "enter":
+		kind, value := m.jump, strings.TrimSpace(m.jumpInput.Value())
+		m.closeJump()
+		if value == "" {
+			return m, nil
+		}
+		if kind == jumpPage {
+			return m.goToPage(value)
+		}
+		return m.goToRow(value)
+	}
+	// Page numbers take only digits.
+	if m.jump == jumpPage && msg.Type == tea.KeyRunes && strings.ContainsFunc(string(msg.Runes), func(r rune) bool { return r < '0' || r > '9' }) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	return m, cmd
+}
+
+func (m *TableDataModel) closeJump() {
+	m.jump = jumpOff
+	m.jumpInput.Blur()
+	m.table.SetHeight(m.tableHeight())
+}
+
+// knownTotal reports the rows paging works against, if exactly known.
+func (m TableDataModel) knownTotal() (int, bool) {
+	total := m.currentTotal()
+	return total, total != unknownTotal && !m.estimating()
+}
+
+// goToPage loads page number s, or the last page if s is past it.
+func (m TableDataModel) goToPage(s string) (TableDataModel, tea.Cmd) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return m, nil
+	}
+	// The last page is only known for sure with an exact total.
+	if _, ok := m.knownTotal(); ok {
+		n = min(n, m.totalPages())
+	}
+	page := max(n, 1) - 1
+	if page == m.page {
+		return m, nil
+	}
+	return m, m.pageCmd(page, false)
+}
+
+// goToRow puts the cursor on the row at position #N, counted from 1 in
+// the whole table or filter results, or on the row whose primary key is
+// s, loading the page it is on.
+func (m TableDataModel) goToRow(s string) (TableDataModel, tea.Cmd) {
+	if pos, ok := strings.CutPrefix(s, "#"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(pos))
+		if err != nil || n < 1 {
+			return m, noteCmd(errors.New("row position must be a number from 1"))
+		}
+		offset := n - 1
+		if total, ok := m.knownTotal(); ok {
+			offset = min(offset, max(total-1, 0))
+		}
+		return m.showOffset(offset)
+	}
+	if m.static {
+		return m, noteCmd(errors.New("query results are searched by #position only"))
+	}
+	return m, m.keyJumpCmd(s)
+}
+
+// keyJumpCmd loads the page holding the row whose primary key is s, with
+// the cursor on it. It runs with the grid's loads, so Keys.Cancel stops it.
+func (m TableDataModel) keyJumpCmd(s string) tea.Cmd {
+	database, table, order := m.database, m.tableName, m.rowOrder()
+	var col, query string
+	var mode db.MatchMode
+	if m.fActive {
+		col, query, mode = m.fCol, m.fQuery, m.fMode
+	}
+	pageSize, pageCmd := m.pageSize, m.pageCmd
+	return track("finding row", m.loads.run(func(ctx context.Context) tea.Msg {
+		rowid, err := db.FindRow(ctx, database, table, s)
+		if err != nil {
+			return noteMsg{err: err}
+		}
+		offset, found, err := db.RowOffset(ctx, database, table, rowid, col, query, mode, order)
+		if err != nil {
+			return noteMsg{err: err}
+		}
+		if !found {
+			return noteMsg{err: errors.New("that row isn't among the filter results")}
+		}
+		return withCursorRow(pageCmd(offset/pageSize, false)(), offset%pageSize)
+	}))
+}
+
+// showOffset puts the cursor on the row offset rows from the first,
+// loading its page unless it is on the current one.
+func (m TableDataModel) showOffset(offset int) (TableDataModel, tea.Cmd) {
+	if m.inMemory() || m.pageSize <= 0 {
+		m.table.SetCursor(min(offset, max(len(m.allRows)-1, 0)))
+		return m, nil
+	}
+	page, row := offset/m.pageSize, offset%m.pageSize
+	if page == m.page {
+		m.table.SetCursor(min(row, max(len(m.allRows)-1, 0)))
+		return m, nil
+	}
+	load := m.pageCmd(page, false)
+	return m, func() tea.Msg { return withCursorRow(load(), row) }
+}
+
+// withCursorRow has msg, if a loaded page, put the cursor on its row-th
+// row.
+func withCursorRow(msg tea.Msg, row int) tea.Msg {
+	switch msg := msg.(type) {
+	case pageDataLoadedMsg:
+		msg.cursorRow = row
+		return msg
+	case tea.BatchMsg:
+		// A tracked load comes back as a batch still to run.
+		cmds := make(tea.BatchMsg, 0, len(msg))
+		for _, cmd := range msg {
+			if cmd != nil {
+				cmds = append(cmds, func() tea.Msg { return withCursorRow(cmd(), row) })
+			}
+		}
+		return cmds
+	}
+	return msg
+}
+
+func errCmd(err error) tea.Cmd {
+	return func() tea.Msg { return errMsg{err: err} }
+}
+
+func noteCmd(err error) tea.Cmd {
+	return func() tea.Msg { return noteMsg{err: err} }
+}
@@ -1,21 +1,102 @@
 package ui
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 // KeyMap defines shared key bindings used across all views.
 // Centralizing them here (DRY) means one place to change shortcuts.
 type KeyMap struct {
-	Quit          key.Binding
-	SwitchTab     key.Binding
-	FocusRight    key.Binding
-	FocusLeft     key.Binding
-	Select        key.Binding
-	OpenQuery     key.Binding
-	Refresh       key.Binding
-	NextPage      key.Binding
-	PrevPage      key.Binding
-	ToggleSidebar key.Binding
-	DeleteRow     key.Binding
+	Quit           key.Binding
+	SwitchTab      key.Binding
+	FocusRight     key.Binding
+	FocusLeft      key.Binding
+	Select         key.Binding
+	OpenQuery      key.Binding
+	Refresh        key.Binding
+	NextPage       key.Binding
+	PrevPage       key.Binding
+	ToggleSidebar  key.Binding
+	DeleteRow      key.Binding
+	EditRow        key.Binding
+	CopyField      key.Binding
+	RawView        key.Binding
+	HexView        key.Binding
+	SaveBlob       key.Binding
+	Filter         key.Binding
+	RunQuery       key.Binding
+	Pin            key.Binding
+	ExportAll      key.Binding
+	InsertRow      key.Binding
+	Save           key.Binding
+	GrowSidebar    key.Binding
+	ShrinkSidebar  key.Binding
+	Zoom           key.Binding
+	NewTab         key.Binding
+	NextTab        key.Binding
+	PrevTab        key.Binding
+	CloseTab       key.Binding
+	OpenDatabase   key.Binding
+	SwitchDatabase key.Binding
+	DatabaseInfo   key.Binding
+	SchemaObjects  key.Binding
+	Pragmas        key.Binding
+	Maintenance    key.Binding
+	Live           key.Binding
+	Follow         key.Binding
+	ViewLink       key.Binding
+	FuzzyFilter    key.Binding
+	ColumnStats    key.Binding
+	Cancel         key.Binding
+	QueryTimeout   key.Binding
+	ExternalEditor key.Binding
+	OpenFile       key.Binding
+	SaveResults    key.Binding
+	Relationships  key.Binding
+	SpaceUsage     key.Binding
+	JSONPaths      key.Binding
+	Timestamps     key.Binding
+	WrapRow        key.Binding
+	RowNumbers     key.Binding
+	RowIDs         key.Binding
+	GoToPage       key.Binding
+	GoToRow        key.Binding
+	Bookmark       key.Binding
+	Bookmarks      key.Binding
+	DataDiff       key.Binding
+	Undo           key.Binding
+	Transaction    key.Binding
+	Snippets       key.Binding
+	SortTables     key.Binding
+	InternalTables key.Binding
+	AlterTable     key.Binding
+	EmptyTable     key.Binding
+	RenameTable    key.Binding
+	CreateIndex    key.Binding
+	Indexes        key.Binding
+	ViewDefinition key.Binding
+	SaveAsView     key.Binding
+	PasteRows      key.Binding
+	CopyMarkdown   key.Binding
+	Sort           key.Binding
+	ThenSort       key.Binding
+	ResizeColumns  key.Binding
+
+	// The data pane's own keys, ahead of other bindings on the same keys
+	// there: the vim set, off unless vim_keys is set, and the page
+	// search, whose matches vim_keys moves to n and N. A key may be a
+	// sequence pressed in turn, written with spaces, like "g g".
+	FirstPage    key.Binding
+	LastPage     key.Binding
+	HalfPageDown key.Binding
+	HalfPageUp   key.Binding
+	Search       key.Binding
+	NextMatch    key.Binding
+	PrevMatch    key.Binding
 }
 
 var Keys = KeyMap{
@@ -63,4 +144,426 @@ var Keys = KeyMap{
 		key.WithKeys("delete"),
 		key.WithHelp("del", "delete row"),
 	),
+	EditRow: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "edit row"),
+	),
+	CopyField: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy field"),
+	),
+	RawView: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "raw/formatted"),
+	),
+	HexView: key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "hex dump"),
+	),
+	SaveBlob: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "save BLOB"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "filter"),
+	),
+	RunQuery: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "run"),
+	),
+	Pin: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "pin / unpin"),
+	),
+	ExportAll: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "export all tables"),
+	),
+	InsertRow: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "add row"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save"),
+	),
+	GrowSidebar: key.NewBinding(
+		key.WithKeys("ctrl+right"),
+		key.WithHelp("ctrl+→", "widen table list"),
+	),
+	ShrinkSidebar: key.NewBinding(
+		key.WithKeys("ctrl+left"),
+		key.WithHelp("ctrl+←", "narrow table list"),
+	),
+	Zoom: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "zoom grid"),
+	),
+	NewTab: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "open table in new tab"),
+	),
+	NextTab: key.NewBinding(
+		key.WithKeys("}"),
+		key.WithHelp("}", "next tab"),
+	),
+	PrevTab: key.NewBinding(
+		key.WithKeys("{"),
+		key.WithHelp("{", "previous tab"),
+	),
+	CloseTab: key.NewBinding(
+		key.WithKeys("ctrl+w"),
+		key.WithHelp("ctrl+w", "close tab"),
+	),
+	OpenDatabase: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("O", "open another database"),
+	),
+	SwitchDatabase: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("D", "switch database"),
+	),
+	DatabaseInfo: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "database info"),
+	),
+	SchemaObjects: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "schema objects"),
+	),
+	Pragmas: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "pragmas"),
+	),
+	Maintenance: key.NewBinding(
+		key.WithKeys("M"),
+		key.WithHelp("M", "maintenance"),
+	),
+	Live: key.NewBinding(
+		key.WithKeys("W"),
+		key.WithHelp("W", "toggle live reload"),
+	),
+	Follow: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("F", "follow newest rows"),
+	),
+	ViewLink: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "share or open a view link"),
+	),
+	FuzzyFilter: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "toggle fuzzy matching"),
+	),
+	ColumnStats: key.NewBinding(
+		key.WithKeys("C"),
+		key.WithHelp("C", "column stats"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "cancel"),
+	),
+	QueryTimeout: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "query timeout"),
+	),
+	ExternalEditor: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "edit in $EDITOR"),
+	),
+	OpenFile: key.NewBinding(
+		key.WithKeys("ctrl+l"),
+		key.WithHelp("ctrl+l", "open SQL file"),
+	),
+	SaveResults: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save query results"),
+	),
+	Relationships: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("R", "relationships"),
+	),
+	SpaceUsage: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("U", "space usage"),
+	),
+	JSONPaths: key.NewBinding(
+		key.WithKeys("J"),
+		key.WithHelp("J", "json paths"),
+	),
+	Timestamps: key.NewBinding(
+		key.WithKeys("T"),
+		key.WithHelp("T", "timestamps"),
+	),
+	WrapRow: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "wrap row"),
+	),
+	RowNumbers: key.NewBinding(
+		key.WithKeys("#"),
+		key.WithHelp("#", "row numbers"),
+	),
+	RowIDs: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("I", "rowids"),
+	),
+	GoToPage: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "go to page"),
+	),
+	GoToRow: key.NewBinding(
+		key.WithKeys("G"),
+		key.WithHelp("G", "go to row"),
+	),
+	Bookmark: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "bookmark row"),
+	),
+	Bookmarks: key.NewBinding(
+		key.WithKeys("'"),
+		key.WithHelp("'", "bookmarks"),
+	),
+	DataDiff: key.NewBinding(
+		key.WithKeys("X"),
+		key.WithHelp("X", "data diff"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("u"),
+		key.WithHelp("u", "undo"),
+	),
+	Transaction: key.NewBinding(
+		key.WithKeys("B"),
+		key.WithHelp("B", "transaction"),
+	),
+	Snippets: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "snippets"),
+	),
+	SortTables: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sort tables"),
+	),
+	InternalTables: key.NewBinding(
+		key.WithKeys("H"),
+		key.WithHelp("H", "internal tables"),
+	),
+	AlterTable: key.NewBinding(
+		key.WithKeys("A"),
+		key.WithHelp("A", "alter table"),
+	),
+	EmptyTable: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "empty table"),
+	),
+	RenameTable: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "rename table"),
+	),
+	CreateIndex: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "new index"),
+	),
+	Indexes: key.NewBinding(
+		key.WithKeys("K"),
+		key.WithHelp("K", "indexes"),
+	),
+	ViewDefinition: key.NewBinding(
+		key.WithKeys("V"),
+		key.WithHelp("V", "view sql"),
+	),
+	SaveAsView: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "save as view"),
+	),
+	PasteRows: key.NewBinding(
+		key.WithKeys("ctrl+v"),
+		key.WithHelp("ctrl+v", "paste rows"),
+	),
+	CopyMarkdown: key.NewBinding(
+		key.WithKeys("Y"),
+		key.WithHelp("Y", "copy as markdown"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "sort"),
+	),
+	ThenSort: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "then sort by"),
+	),
+	ResizeColumns: key.NewBinding(
+		key.WithKeys("|"),
+		key.WithHelp("|", "resize columns"),
+	),
+	FirstPage: key.NewBinding(
+		key.WithKeys("g g"),
+		key.WithHelp("gg", "first page"),
+		key.WithDisabled(),
+	),
+	LastPage: key.NewBinding(
+		key.WithKeys("G"),
+		key.WithHelp("G", "last page"),
+		key.WithDisabled(),
+	),
+	HalfPageDown: key.NewBinding(
+		key.WithKeys("ctrl+d"),
+		key.WithHelp("ctrl+d", "half page down"),
+		key.WithDisabled(),
+	),
+	HalfPageUp: key.NewBinding(
+		key.WithKeys("ctrl+u"),
+		key.WithHelp("ctrl+u", "half page up"),
+		key.WithDisabled(),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("ctrl+n"),
+		key.WithHelp("ctrl+n", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "previous match"),
+	),
+}
+
+// actions maps the config-file action names to their bindings.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"quit":            &k.Quit,
+		"switch_tab":      &k.SwitchTab,
+		"focus_right":     &k.FocusRight,
+		"focus_left":      &k.FocusLeft,
+		"select":          &k.Select,
+		"open_query":      &k.OpenQuery,
+		"refresh":         &k.Refresh,
+		"next_page":       &k.NextPage,
+		"prev_page":       &k.PrevPage,
+		"toggle_sidebar":  &k.ToggleSidebar,
+		"delete_row":      &k.DeleteRow,
+		"edit_row":        &k.EditRow,
+		"copy_field":      &k.CopyField,
+		"raw_view":        &k.RawView,
+		"hex_view":        &k.HexView,
+		"save_blob":       &k.SaveBlob,
+		"filter":          &k.Filter,
+		"run_query":       &k.RunQuery,
+		"pin":             &k.Pin,
+		"export_all":      &k.ExportAll,
+		"insert_row":      &k.InsertRow,
+		"save":            &k.Save,
+		"grow_sidebar":    &k.GrowSidebar,
+		"shrink_sidebar":  &k.ShrinkSidebar,
+		"zoom":            &k.Zoom,
+		"new_tab":         &k.NewTab,
+		"next_tab":        &k.NextTab,
+		"prev_tab":        &k.PrevTab,
+		"close_tab":       &k.CloseTab,
+		"open_database":   &k.OpenDatabase,
+		"switch_database": &k.SwitchDatabase,
+		"database_info":   &k.DatabaseInfo,
+		"schema_objects":  &k.SchemaObjects,
+		"pragmas":         &k.Pragmas,
+		"maintenance":     &k.Maintenance,
+		"live":            &k.Live,
+		"follow":          &k.Follow,
+		"view_link":       &k.ViewLink,
+		"fuzzy_filter":    &k.FuzzyFilter,
+		"column_stats":    &k.ColumnStats,
+		"cancel":          &k.Cancel,
+		"query_timeout":   &k.QueryTimeout,
+		"external_editor": &k.ExternalEditor,
+		"open_file":       &k.OpenFile,
+		"save_results":    &k.SaveResults,
+		"relationships":   &k.Relationships,
+		"space_usage":     &k.SpaceUsage,
+		"json_paths":      &k.JSONPaths,
+		"timestamps":      &k.Timestamps,
+		"wrap_row":        &k.WrapRow,
+		"row_numbers":     &k.RowNumbers,
+		"rowids":          &k.RowIDs,
+		"go_to_page":      &k.GoToPage,
+		"go_to_row":       &k.GoToRow,
+		"bookmark":        &k.Bookmark,
+		"bookmarks":       &k.Bookmarks,
+		"data_diff":       &k.DataDiff,
+		"undo":            &k.Undo,
+		"transaction":     &k.Transaction,
+		"snippets":        &k.Snippets,
+		"sort_tables":     &k.SortTables,
+		"internal_tables": &k.InternalTables,
+		"alter_table":     &k.AlterTable,
+		"empty_table":     &k.EmptyTable,
+		"rename_table":    &k.RenameTable,
+		"create_index":    &k.CreateIndex,
+		"indexes":         &k.Indexes,
+		"view_definition": &k.ViewDefinition,
+		"save_as_view":    &k.SaveAsView,
+		"paste_rows":      &k.PasteRows,
+		"copy_markdown":   &k.CopyMarkdown,
+		"first_page":      &k.FirstPage,
+		"last_page":       &k.LastPage,
+		"half_page_down":  &k.HalfPageDown,
+		"half_page_up":    &k.HalfPageUp,
+		"search":          &k.Search,
+		"next_match":      &k.NextMatch,
+		"prev_match":      &k.PrevMatch,
+		"sort":            &k.Sort,
+		"then_sort":       &k.ThenSort,
+		"resize_columns":  &k.ResizeColumns,
+	}
+}
+
+// Rebind replaces the keys of the named actions. The first key of each
+// action becomes the one shown in help text, which is why views build their
+// hints from Help().Key rather than hard-coding key names. Unknown action names are an
+// error so a typo in the config doesn't silently leave the old binding, and
+// so is a key that clashes with one of the data pane's own.
+func (k *KeyMap) Rebind(overrides map[string][]string) error {
+	actions := k.actions()
+	for name, keys := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			names := make([]string, 0, len(actions))
+			for n := range actions {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown key action %q (valid: %s)", name, strings.Join(names, ", "))
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("key action %q has no keys", name)
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(displayKey(keys[0]), b.Help().Desc)
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return k.checkClashes(names)
+}
+
+// displayKey shortens key names for help text, matching the defaults above.
+func displayKey(k string) string {
+	switch k {
+	case "delete":
+		return "del"
+	case "right":
+		return "→"
+	case "left":
+		return "←"
+	case "ctrl+right":
+		return "ctrl+→"
+	case "ctrl+left":
+		return "ctrl+←"
+	}
+	if seq := keySeq(k); len(seq) > 1 {
+		return strings.Join(seq, "")
+	}
+	return k
 }
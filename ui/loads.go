@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"context"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadScope is the context reads of rows run under — a grid's page and
+// filter loads, or the model's table and view loads — so that Keys.Cancel,
+// or leaving the grid, stops the ones still running. Copies of a grid
+// share it. A nil scope runs its loads to the end.
+type loadScope struct {
+	mu      sync.Mutex
+	gen     int // bumped by cancel; loads of an older generation are dropped
+	ctx     context.Context
+	stop    context.CancelFunc
+	running int
+}
+
+func newLoadScope() *loadScope {
+	s := &loadScope{}
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	return s
+}
+
+// run has load read under the scope's context. A load cancelled before it
+// returns gives no message, so the rows already shown stay.
+func (s *loadScope) run(load func(ctx context.Context) tea.Msg) tea.Cmd {
+	if s == nil {
+		return func() tea.Msg { return load(context.Background()) }
+	}
+	s.mu.Lock()
+	ctx, gen := s.ctx, s.gen
+	s.mu.Unlock()
+	return func() tea.Msg {
+		s.mu.Lock()
+		if gen != s.gen {
+			s.mu.Unlock()
+			return nil
+		}
+		s.running++
+		s.mu.Unlock()
+
+		msg := load(ctx)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.running--
+		if gen != s.gen {
+			return nil
+		}
+		return msg
+	}
+}
+
+// cancel stops the loads running under the scope, and those not started
+// yet, reporting whether any were running. Later loads get a fresh context.
+func (s *loadScope) cancel() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := s.running > 0
+	s.stop()
+	s.gen++
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	return running
+}
+
+// busy reports whether any loads are running under the scope.
+func (s *loadScope) busy() bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running > 0
+}
@@ -0,0 +1,543 @@
+package ui
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// maintenanceTarget is the database a maintenance task works on. table is
+// the one currently open, if any. rows counts what a running task has
+// written, for its progress.
+type maintenanceTarget struct {
+	database *sql.DB
+	path     string
+	table    string
+	rows     *atomic.Int64
+}
+
+// maintenanceTask is one entry in the maintenance menu. run returns the
+// lines to show as its findings; arg is the answer to prompt, if any.
+type maintenanceTask struct {
+	label string
+	desc  string
+
+	// prompt asks for an argument before running; defaultArg pre-fills it.
+	prompt     string
+	defaultArg func(t maintenanceTarget) string
+
+	// progress, when set, describes how far a running task has got. It is
+	// polled on every spinner tick.
+	progress func(t maintenanceTarget, arg string) string
+
+	// rewrites marks tasks that change the file, so open views reload;
+	// reschema those that may change the schema, so the table list does.
+	rewrites bool
+	reschema bool
+
+	run func(ctx context.Context, t maintenanceTarget, arg string) ([]string, error)
+}
+
+var maintenanceTasks = []maintenanceTask{
+	{
+		label: "Integrity check",
+		desc:  "full PRAGMA integrity_check: pages, records, and indexes",
+		run: func(ctx context.Context, t maintenanceTarget, _ string) ([]string, error) {
+			return db.IntegrityCheck(ctx, t.database, false)
+		},
+	},
+	{
+		label: "Quick check",
+		desc:  "PRAGMA quick_check: like integrity_check, minus index contents; much faster",
+		run: func(ctx context.Context, t maintenanceTarget, _ string) ([]string, error) {
+			return db.IntegrityCheck(ctx, t.database, true)
+		},
+	},
+	{
+		label:    "Vacuum",
+		desc:     "VACUUM: rebuild the file in place, reclaiming free pages (may renumber rowids)",
+		rewrites: true,
+		run: func(ctx context.Context, t maintenanceTarget, _ string) ([]string, error) {
+			before, err := fileSize(t.path)
+			if err != nil {
+				return nil, err
+			}
+			if err := db.Vacuum(ctx, t.database); err != nil {
+				return nil, err
+			}
+			after, err := fileSize(t.path)
+			if err != nil {
+				return nil, err
+			}
+			return sizeSummary("before", before, "after", after), nil
+		},
+	},
+	{
+		label:  "Vacuum into...",
+		desc:   "VACUUM INTO: write a compacted copy to a new file, leaving this one as is",
+		prompt: "backup file: ",
+		defaultArg: func(t maintenanceTarget) string {
+			ext := filepath.Ext(t.path)
+			return strings.TrimSuffix(t.path, ext) + "-backup" + ext
+		},
+		progress: func(t maintenanceTarget, arg string) string {
+			written, err := fileSize(arg)
+			if err != nil {
+				return ""
+			}
+			total, _ := fileSize(t.path)
+			return fmt.Sprintf("%s written of at most %s", formatBytes(written), formatBytes(total))
+		},
+		run: func(ctx context.Context, t maintenanceTarget, dest string) ([]string, error) {
+			before, err := fileSize(t.path)
+			if err != nil {
+				return nil, err
+			}
+			if err := db.VacuumInto(ctx, t.database, dest); err != nil {
+				return nil, err
+			}
+			after, err := fileSize(dest)
+			if err != nil {
+				return nil, err
+			}
+			return append(sizeSummary("source", before, "copy", after), "", "wrote "+dest), nil
+		},
+	},
+	{
+		label:  "Dump schema...",
+		desc:   "write every CREATE statement to a .sql file, in an order it can be run in, like .schema",
+		prompt: "schema file: ",
+		defaultArg: func(t maintenanceTarget) string {
+			return strings.TrimSuffix(t.path, filepath.Ext(t.path)) + "-schema.sql"
+		},
+		run: func(ctx context.Context, t maintenanceTarget, dest string) ([]string, error) {
+			var b bytes.Buffer
+			objects, err := db.DumpSchema(ctx, t.database, &b)
+			if err != nil {
+				return nil, err
+			}
+			if err := writeNewFile(dest, b.Bytes()); err != nil {
+				return nil, err
+			}
+			return append(schemaSummary(objects), "", "wrote "+dest), nil
+		},
+	},
+	{
+		label:  "Dump...",
+		desc:   "write the schema and every row as SQL to a .sql file, in one transaction, like .dump",
+		prompt: "dump file: ",
+		defaultArg: func(t maintenanceTarget) string {
+			return strings.TrimSuffix(t.path, filepath.Ext(t.path)) + "-dump.sql"
+		},
+		progress: func(t maintenanceTarget, arg string) string {
+			written, err := fileSize(arg)
+			if err != nil {
+				return ""
+			}
+			return fmt.Sprintf("%s rows, %s written", groupDigits(t.rows.Load()), formatBytes(written))
+		},
+		run: func(ctx context.Context, t maintenanceTarget, dest string) ([]string, error) {
+			f, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return nil, err
+			}
+			objects, rows, err := db.Dump(ctx, t.database, f, func(rows int) { t.rows.Store(int64(rows)) })
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				// A dump cut short would quietly restore part of the data.
+				os.Remove(dest)
+				return nil, err
+			}
+			size, err := fileSize(dest)
+			if err != nil {
+				return nil, err
+			}
+			lines := append(schemaSummary(objects), fmt.Sprintf("%-9s %s", "rows:", groupDigits(int64(rows))))
+			return append(lines, "", fmt.Sprintf("wrote %s (%s)", dest, formatBytes(size))), nil
+		},
+	},
+	{
+		label:    "Run script...",
+		desc:     "run the statements of a .sql file in one transaction, all of them or none",
+		prompt:   "script file: ",
+		rewrites: true,
+		reschema: true,
+		defaultArg: func(t maintenanceTarget) string {
+			return filepath.Dir(t.path) + string(filepath.Separator)
+		},
+		run: func(ctx context.Context, t maintenanceTarget, file string) ([]string, error) {
+			script, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			run, err := db.RunScript(ctx, t.database, string(script))
+			return scriptSummary(run, err), err
+		},
+	},
+	{
+		label: "Analyze",
+		desc:  "ANALYZE: refresh the optimizer statistics for every table and index",
+		run: func(ctx context.Context, t maintenanceTarget, _ string) ([]string, error) {
+			stats, err := db.Analyze(ctx, t.database, "")
+			return formatStats(stats), err
+		},
+	},
+	{
+		label:      "Analyze table...",
+		desc:       "ANALYZE one table: refresh the statistics for it and its indexes",
+		prompt:     "table: ",
+		defaultArg: func(t maintenanceTarget) string { return t.table },
+		run: func(ctx context.Context, t maintenanceTarget, table string) ([]string, error) {
+			stats, err := db.Analyze(ctx, t.database, table)
+			return formatStats(stats), err
+		},
+	},
+}
+
+func fileSize(path string) (int64, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return st.Size(), nil
+}
+
+// formatStats lays out sqlite_stat1 rows as an aligned table.
+func formatStats(stats []db.Stat1) []string {
+	if len(stats) == 0 {
+		return []string{"no statistics recorded (empty tables are skipped)"}
+	}
+	tw, iw := len("table"), len("index")
+	for _, s := range stats {
+		tw = max(tw, len(s.Table))
+		iw = max(iw, len(s.Index))
+	}
+	lines := []string{
+		fmt.Sprintf("%-*s  %-*s  %s", tw, "table", iw, "index", "stat"),
+	}
+	for _, s := range stats {
+		idx := s.Index
+		if idx == "" {
+			idx = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %-*s  %s", tw, s.Table, iw, idx, s.Stat))
+	}
+	return append(lines, "", "stat: row count, then average rows per distinct value of each index prefix")
+}
+
+// schemaSummary counts the dumped objects of each kind.
+func schemaSummary(objects []db.DumpObject) []string {
+	counts := map[string]int{}
+	for _, o := range objects {
+		counts[o.Type]++
+	}
+	var lines []string
+	for _, k := range []struct{ kind, label string }{
+		{"table", "tables:"}, {"index", "indexes:"}, {"view", "views:"}, {"trigger", "triggers:"},
+	} {
+		lines = append(lines, fmt.Sprintf("%-9s %d", k.label, counts[k.kind]))
+	}
+	return lines
+}
+
+// scriptSummary sums up a script run: what it changed, or the statement
+// that failed.
+func scriptSummary(run []db.ScriptStatement, err error) []string {
+	var ran, skipped int
+	var changes int64
+	for _, s := range run {
+		if s.Skipped {
+			skipped++
+		} else {
+			ran++
+		}
+		changes += s.Changes
+	}
+	if err != nil {
+		if len(run) == 0 || run[len(run)-1].Err == nil {
+			return nil
+		}
+		failed := run[len(run)-1]
+		before := "statements"
+		if ran-1 == 1 {
+			before = "statement"
+		}
+		lines := []string{fmt.Sprintf("line %d failed after %d %s:", failed.Line, ran-1, before), ""}
+		stmt := strings.Split(failed.SQL, "\n")
+		if len(stmt) > 5 {
+			stmt = append(stmt[:4], "...")
+		}
+		for _, l := range stmt {
+			lines = append(lines, "  "+l)
+		}
+		return append(lines, "", "rolled back: the script changed nothing")
+	}
+	lines := []string{
+		fmt.Sprintf("%-11s %s", "statements:", groupDigits(int64(ran))),
+		fmt.Sprintf("%-11s %s", "rows:", groupDigits(changes)) + " inserted, updated, or deleted",
+	}
+	if skipped > 0 {
+		lines = append(lines, fmt.Sprintf("%-11s %d BEGIN or COMMIT, as the script ran in a transaction of its own", "skipped:", skipped))
+	}
+	return lines
+}
+
+// sizeSummary reports two file sizes and how much smaller the second is.
+func sizeSummary(beforeLabel string, before int64, afterLabel string, after int64) []string {
+	lines := []string{
+		fmt.Sprintf("%-8s %s", beforeLabel+":", formatBytes(before)),
+		fmt.Sprintf("%-8s %s", afterLabel+":", formatBytes(after)),
+	}
+	if saved := before - after; saved > 0 && before > 0 {
+		lines = append(lines, fmt.Sprintf("%-8s %s (%.1f%%)", "saved:", formatBytes(saved), float64(saved)*100/float64(before)))
+	}
+	return lines
+}
+
+type maintenancePhase int
+
+const (
+	maintenanceMenu maintenancePhase = iota
+	maintenancePrompt
+	maintenanceRunning
+	maintenanceDone
+)
+
+// maintenanceDoneMsg carries a finished task's findings. rewrote is set
+// when the task changed the database file, reschema when it may have
+// changed the schema.
+type maintenanceDoneMsg struct {
+	database *sql.DB
+	lines    []string
+	elapsed  time.Duration
+	rewrote  bool
+	reschema bool
+	err      error
+}
+
+// MaintenanceModel is the popup listing maintenance tasks. A task runs in
+// the background behind a spinner, then its findings are shown in a
+// scrollable view.
+type MaintenanceModel struct {
+	target   maintenanceTarget
+	phase    maintenancePhase
+	cursor   int
+	input    textinput.Model
+	arg      string
+	spinner  spinner.Model
+	started  time.Time
+	progress string
+	cancel   context.CancelFunc
+	result   viewport.Model
+	elapsed  time.Duration
+	err      error
+	width    int
+	height   int
+}
+
+func NewMaintenanceModel(database *sql.DB, path, table string, termWidth, termHeight int) MaintenanceModel {
+	width := max(termWidth*60/100, 50)
+	height := max(termHeight*60/100, 12)
+	return MaintenanceModel{
+		target:  maintenanceTarget{database: database, path: path, table: table, rows: new(atomic.Int64)},
+		input:   textinput.New(),
+		spinner: newSpinner(),
+		// Border (2) + padding (4) horizontally; border, padding, title,
+		// gap, and help vertically.
+		result: viewport.New(width-6, height-8),
+		width:  width,
+		height: height,
+	}
+}
+
+func (m MaintenanceModel) Update(msg tea.Msg) (MaintenanceModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if m.phase != maintenanceRunning {
+			return m, nil
+		}
+		if task := maintenanceTasks[m.cursor]; task.progress != nil {
+			m.progress = task.progress(m.target, m.arg)
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case maintenanceDoneMsg:
+		m.phase = maintenanceDone
+		m.cancel = nil
+		m.elapsed = msg.elapsed
+		m.err = msg.err
+		lines := msg.lines
+		if m.err != nil && len(lines) > 0 {
+			// What the task found before it failed goes above the error.
+			lines = append(lines, "", ErrorStyle.Render("Error: "+m.err.Error()))
+			m.err = nil
+		}
+		if len(lines) == 1 && lines[0] == "ok" {
+			lines = []string{"ok — no problems found"}
+		}
+		// Shrink to fit short findings; long lists scroll.
+		m.result.Height = max(min(len(lines), m.height-8), 1)
+		m.result.SetContent(strings.Join(lines, "\n"))
+		m.result.GotoTop()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.phase {
+		case maintenanceMenu:
+			return m.updateMenu(msg)
+		case maintenancePrompt:
+			switch msg.String() {
+			case "esc":
+				m.phase = maintenanceMenu
+				m.input.Blur()
+				return m, nil
+			case "enter":
+				if strings.TrimSpace(m.input.Value()) == "" {
+					return m, nil
+				}
+				m.input.Blur()
+				return m.start(strings.TrimSpace(m.input.Value()))
+			}
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		case maintenanceRunning:
+			if msg.String() == "esc" {
+				m.cancel()
+				m.cancel = nil
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+			return m, nil
+		case maintenanceDone:
+			if msg.String() == "esc" || msg.String() == "enter" {
+				m.phase = maintenanceMenu
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.result, cmd = m.result.Update(msg)
+			return m, cmd
+		}
+
+	default:
+		if m.phase == maintenancePrompt {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m MaintenanceModel) updateMenu(msg tea.KeyMsg) (MaintenanceModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return m, func() tea.Msg { return CloseDetailMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(maintenanceTasks)-1)
+	case "enter":
+		task := maintenanceTasks[m.cursor]
+		if task.prompt == "" {
+			return m.start("")
+		}
+		m.phase = maintenancePrompt
+		m.input.Prompt = task.prompt
+		m.input.Width = m.width - 8 - len(task.prompt)
+		m.input.SetValue("")
+		if task.defaultArg != nil {
+			m.input.SetValue(task.defaultArg(m.target))
+		}
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	default:
+		if key.Matches(msg, Keys.Maintenance) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	return m, nil
+}
+
+// start runs the selected task in the background.
+func (m MaintenanceModel) start(arg string) (MaintenanceModel, tea.Cmd) {
+	task := maintenanceTasks[m.cursor]
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.phase = maintenanceRunning
+	m.arg = arg
+	m.progress = ""
+	m.target.rows.Store(0)
+	m.started = time.Now()
+	target, started := m.target, m.started
+	run := func() tea.Msg {
+		lines, err := task.run(ctx, target, arg)
+		return maintenanceDoneMsg{
+			database: target.database,
+			lines:    lines,
+			elapsed:  time.Since(started),
+			rewrote:  task.rewrites && err == nil,
+			reschema: task.reschema && err == nil,
+			err:      err,
+		}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+func (m MaintenanceModel) View() string {
+	task := maintenanceTasks[m.cursor]
+	var title, body, help string
+	switch m.phase {
+	case maintenanceMenu:
+		title = " Maintenance "
+		var b strings.Builder
+		for i, t := range maintenanceTasks {
+			if i == m.cursor {
+				b.WriteString(TitleStyle.Render("▸ "+t.label) + "\n")
+			} else {
+				b.WriteString(StatusBarStyle.Render("  "+t.label) + "\n")
+			}
+		}
+		body = b.String() + "\n" + StatusBarStyle.Render(task.desc)
+		help = "↑↓: select | enter: run | esc: close"
+	case maintenancePrompt:
+		title = " " + task.label + " "
+		body = StatusBarStyle.Render(task.desc) + "\n\n" + m.input.View()
+		help = "enter: run | esc: back"
+	case maintenanceRunning:
+		title = " " + task.label + " "
+		body = spinnerLine(m.spinner, "running", m.started)
+		if m.progress != "" {
+			body += "\n" + StatusBarStyle.Render(m.progress)
+		}
+		help = "esc: cancel"
+	case maintenanceDone:
+		title = fmt.Sprintf(" %s (%s) ", task.label, m.elapsed.Round(time.Millisecond))
+		if m.err != nil {
+			body = ErrorStyle.Render("Error: " + m.err.Error())
+		} else {
+			body = m.result.View()
+		}
+		help = fmt.Sprintf("↑↓: scroll | esc/enter: back (%d%%)", int(m.result.ScrollPercent()*100))
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
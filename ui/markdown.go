@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// markdownTable renders rows as a GitHub-flavored Markdown table, padded
+// so it reads as a table in plain text too. Columns holding only numbers
+// are right-aligned; pipes are escaped and line breaks become <br>, since
+// a cell can't span lines.
+func markdownTable(columns []string, rows [][]string) string {
+	cell := func(s string) string {
+		s = strings.ReplaceAll(s, "|", `\|`)
+		s = strings.ReplaceAll(s, "\r\n", "<br>")
+		return strings.ReplaceAll(s, "\n", "<br>")
+	}
+	header := make([]string, len(columns))
+	widths := make([]int, len(columns))
+	numeric := make([]bool, len(columns))
+	for i, c := range columns {
+		header[i] = cell(c)
+		widths[i] = max(lipgloss.Width(header[i]), 3)
+		numeric[i] = len(rows) > 0
+	}
+	body := make([][]string, len(rows))
+	for r, row := range rows {
+		body[r] = make([]string, len(columns))
+		for i := range columns {
+			var v string
+			if i < len(row) {
+				v = row[i]
+			}
+			if _, err := strconv.ParseFloat(v, 64); err != nil && v != "" && v != "NULL" {
+				numeric[i] = false
+			}
+			body[r][i] = cell(v)
+			widths[i] = max(widths[i], lipgloss.Width(body[r][i]))
+		}
+	}
+
+	var b strings.Builder
+	line := func(cells []string) {
+		b.WriteString("|")
+		for i, c := range cells {
+			pad := strings.Repeat(" ", widths[i]-lipgloss.Width(c))
+			if numeric[i] {
+				b.WriteString(" " + pad + c + " |")
+			} else {
+				b.WriteString(" " + c + pad + " |")
+			}
+		}
+		b.WriteString("\n")
+	}
+	line(header)
+	rule := make([]string, len(columns))
+	for i, w := range widths {
+		rule[i] = strings.Repeat("-", w)
+		if numeric[i] {
+			rule[i] = strings.Repeat("-", w-1) + ":"
+		}
+	}
+	line(rule)
+	for _, r := range body {
+		line(r)
+	}
+	return b.String()
+}
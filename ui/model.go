@@ -1,49 +1,90 @@
 package ui
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/fsnotify/fsnotify"
+	"github.com/mattn/go-runewidth"
 
+	"github.com/markovic-nikola/sqlitui/config"
 	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
 )
 
 // pane tracks which panel currently receives keyboard input.
 type pane int
 
+// Bounds and step for the adjustable table list width, in percent.
+const (
+	defaultSplitPercent = 30
+	minSplitPercent     = 15
+	maxSplitPercent     = 70
+	splitStep           = 5
+)
+
+// splitSaveDelay is how long the table list width is left unchanged
+// before it is saved, so holding a resize key writes the prefs file once.
+const splitSaveDelay = time.Second
+
 const (
 	paneList pane = iota
 	paneData
+	panePinned // the pinned grid above the data pane, when one is pinned
 )
 
 // --- Custom message types ---
 
 type tablesLoadedMsg struct {
-	tables []string
+	tables  []string
+	entries []db.ListEntry     // tables and views, for the table list
+	schema  *state.SchemaCache // nil when the cache is unavailable
 }
 
 type tableDataLoadedMsg struct {
+	database  *sql.DB // the connection it was read from
 	tableName string
 	columns   []string
 	rows      [][]string
 	rowIDs    []int64
 	page      int
 	pageSize  int
-	totalRows int
+	totalRows int  // unknownTotal when neither counted nor estimated
+	estimated bool // totalRows is an estimate; the count runs separately
+	hasMore   bool // another page follows
+	newTab    bool // open in a new tab rather than replacing the active one
+	view      bool // a page of a view's rows, shown like a query result
+	query     string
 }
 
 type errMsg struct {
 	err error
 }
 
+// noteMsg reports something asked for that can't be done, in the status
+// bar until the next key, where errMsg would end the session.
+type noteMsg struct {
+	err error
+}
+
 // --- Root Model ---
 
 type Model struct {
 	db      *sql.DB
+	dbPath  string
+	schema  *state.SchemaCache // persisted schema metadata for dbPath; may be nil
+	cfg     config.Config
 	focused pane
 	loaded  bool // true once the table list is ready
 
@@ -55,45 +96,223 @@ type Model struct {
 	showPathInput bool
 	filePicker    FilePickerModel
 
+	tables        []string       // every table name, as listed in the left pane
+	filterHistory *filterHistory // created with the first grid of the database
+	bookmarks     *bookmarks     // likewise
+	columnWidths  columnWidths   // likewise
 	tableList     TableListModel
 	tableData     TableDataModel
 	dataLoaded    bool   // true once any table's data has been fetched
-	lastTableName string // last real table viewed; used to refresh after a query result overrides the view
+	lastTableName string // last real table viewed; the one snippets and maintenance refer to while a query result is shown
+
+	// Open tables; see tabs.go. tabs[activeTab] is stale while tableData is live.
+	tabs      []TableDataModel
+	activeTab int
+
+	// Other open databases, parked while this one is active; see sessions.go.
+	sessions []dbSession
+
+	// Pinned grid shown above tableData for side-by-side comparison.
+	pinned     TableDataModel
+	showPinned bool
+	nextGridID int // last id handed to a TableDataModel; see newGridID
 
 	// Modal popup for row detail.
 	rowDetail  RowDetailModel
 	showDetail bool
+	writingRow bool // a delete or update from the popup is running
 
 	// Modal popup for SQL query input.
 	queryInput QueryInputModel
 	showQuery  bool
+	// paramValues holds the value last typed for each query placeholder,
+	// offered again by later query popups.
+	paramValues map[string]string
+
+	// Modal popup for inserting a pasted row.
+	insertRow  InsertRowModel
+	showInsert bool
+
+	// Modal popup for exporting every table.
+	export     ExportModel
+	showExport bool
+
+	// Database info: the popup, and the latest reading for the status bar.
+	dbInfoView DatabaseInfoModel
+	showDBInfo bool
+	dbInfo     *db.Info
+
+	// Modal popup for sharing and opening view links. pendingView is a
+	// pasted link waiting for its table to load.
+	viewLinkPopup ViewLinkModel
+	showViewLink  bool
+	pendingView   *viewLink
+
+	// Bookmarked rows; pendingBookmark waits for its table to load.
+	bookmarkList    BookmarksModel
+	showBookmarks   bool
+	pendingBookmark *state.Bookmark
+
+	// Modal popup for inspecting and changing PRAGMAs.
+	pragmas     PragmaModel
+	showPragmas bool
+
+	maintenance     MaintenanceModel
+	showMaintenance bool
+	columnStats     ColumnStatsModel
+	showColumnStats bool
+	saveResults     SaveResultsModel
+	showSaveResults bool
+	relations       RelationsModel
+	showRelations   bool
+	spaceUsage      SpaceUsageModel
+	showSpaceUsage  bool
+	jsonPaths       JSONPathsModel
+	showJSONPaths   bool
+	dataDiff        DataDiffModel
+	showDataDiff    bool
+	alterTable      AlterTableModel
+	showAlterTable  bool
+	emptyTable      EmptyTableModel
+	showEmptyTable  bool
+	createIndex     CreateIndexModel
+	showCreateIndex bool
+	indexes         IndexesModel
+	showIndexes     bool
+	viewDef         ViewDefModel
+	showViewDef     bool
+	pasteRows       PasteRowsModel
+	showPasteRows   bool
+
+	// Row edits made through the UI, latest last, for Keys.Undo.
+	edits []journalEntry
+	note  string // outcome of the last undo or transaction step, shown until the next key
+
+	txn      *transaction // open on the active database, nil if none
+	txPrompt bool         // asking whether to commit or roll back txn
+	syncing  bool         // copying an scp:// database from its host again
+
+	whatsNew     WhatsNewModel
+	showWhatsNew bool
+
+	// External change detection via PRAGMA data_version.
+	watchConn   *sql.Conn // dedicated connection; data_version is per-connection
+	watchGen    int       // bumped per opened database to retire stale ticks
+	viewVersion int64     // data_version when the current view loaded; -1 = unknown
+	dbChanged   bool      // true when another connection wrote since the view loaded
+
+	// Live mode reloads the current table whenever the file changes on disk.
+	live      bool
+	fsWatcher *fsnotify.Watcher
+	liveGen   int // bumped per watcher to drop events from retired ones
+
+	tailTicking bool // the tail mode tick loop is running
+
+	// The status bar spinner for tracked commands (see track).
+	busy        spinner.Model
+	busyTicking bool
+	// loads is what table and view loads run under, so selecting another
+	// table or Keys.Cancel stops them.
+	loads *loadScope
 
 	// Pane dimensions — recalculated on every WindowSizeMsg.
 	leftWidth     int
 	rightWidth    int
 	sidebarHidden bool
+	zoomed        bool // focused grid temporarily takes the whole screen
+	splitPercent  int  // table list width as % of the available width
+	splitChanges  int  // resizes so far, numbering splitSaveMsg
+	splitUnsaved  bool // splitPercent differs from the saved prefs
 }
 
-func NewModel(path string) Model {
+func NewModel(path string, cfg config.Config) Model {
+	if err := applyConfig(cfg); err != nil {
+		return Model{err: err}
+	}
+
+	split := defaultSplitPercent
+	if p := state.LoadPrefs().SplitPercent; p >= minSplitPercent && p <= maxSplitPercent {
+		split = p
+	}
+
 	if path != "" {
 		if err := validatePath(path); err != nil {
 			return Model{err: err}
 		}
-		database, err := db.Open(path)
+		database, err := db.Open(path, dbOptions(cfg))
 		if err != nil {
 			return Model{err: err}
 		}
+		if !db.IsURL(path) {
+			_ = state.AddRecentFile(path)
+		}
 		return Model{
-			db:      database,
-			focused: paneList,
+			db:           database,
+			dbPath:       path,
+			cfg:          cfg,
+			focused:      paneList,
+			splitPercent: split,
+			paramValues:  map[string]string{},
+			busy:         newSpinner(),
+			loads:        newLoadScope(),
 		}
 	}
 
 	return Model{
+		cfg:           cfg,
 		showPathInput: true,
-		filePicker:    NewFilePickerModel(),
+		filePicker:    NewFilePickerModel(dbOptions(cfg)),
 		focused:       paneList,
+		splitPercent:  split,
+		paramValues:   map[string]string{},
+		busy:          newSpinner(),
+		loads:         newLoadScope(),
+	}
+}
+
+// applyConfig installs the package-wide settings from the user config:
+// theme, time display, column alignment and width limits, value length,
+// and key bindings.
+func applyConfig(cfg config.Config) error {
+	theme, err := lookupTheme(cfg.Theme)
+	if err != nil {
+		return err
+	}
+	applyTheme(theme)
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return fmt.Errorf("timezone: %w", err)
+		}
+		displayLocation = loc
+	}
+	if cfg.WeekStart != "" {
+		d, err := parseWeekday(cfg.WeekStart)
+		if err != nil {
+			return err
+		}
+		weekStart = d
 	}
+	if err := applyAlignConfig(cfg.Align); err != nil {
+		return err
+	}
+	if imageProtocol, err = parseGraphics(cfg.ImagePreview); err != nil {
+		return err
+	}
+	formatTimestamps = cfg.FormatTimestamps
+	showRowNumbers = cfg.RowNumbers
+	showRowIDs = cfg.RowIDs
+	confirmWrites = cfg.ConfirmWrites
+	db.SetMaxValueLength(cfg.MaxValueLength)
+	minColWidth = cfg.MinColWidth
+	maxColWidth = cfg.MaxColWidth
+	Keys.setVimKeys(cfg.VimKeys)
+	return Keys.Rebind(cfg.Keys)
+}
+
+// dbOptions translates the user config into connection options.
+func dbOptions(cfg config.Config) db.Options {
+	return db.Options{ReadOnly: cfg.ReadOnly, Attach: cfg.Attach}
 }
 
 func (m Model) Init() tea.Cmd {
@@ -103,41 +322,176 @@ func (m Model) Init() tea.Cmd {
 	if m.db == nil {
 		return nil
 	}
+	database, path := m.db, m.dbPath
 	return func() tea.Msg {
-		tables, err := db.ListTables(m.db)
+		tables, entries, schema, err := listTables(database, path)
 		if err != nil {
 			return errMsg{err: err}
 		}
-		return tablesLoadedMsg{tables: tables}
+		return tablesLoadedMsg{tables: tables, entries: entries, schema: schema}
+	}
+}
+
+// listTables returns the table names for a database, served from the
+// on-disk schema cache when the file hasn't changed since it was cached.
+// Otherwise it lists them from sqlite_master and starts a fresh cache. The
+// table list's entries, views among them, are read either way.
+func listTables(database *sql.DB, path string) ([]string, []db.ListEntry, *state.SchemaCache, error) {
+	entries, err := db.ListEntries(context.Background(), database)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(db.Attached(database)) > 0 {
+		// The cache describes the file alone, not what is attached to it.
+		tables, err := db.ListTables(context.Background(), database)
+		return tables, entries, nil, err
 	}
+	if c := state.LoadSchemaCache(path); c != nil {
+		return c.Tables, entries, c, nil
+	}
+	// Take the key before listing so a concurrent write invalidates the entry.
+	key, keyErr := state.FileKeyFor(path)
+	tables, err := db.ListTables(context.Background(), database)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if keyErr != nil {
+		return tables, entries, nil, nil
+	}
+	c := state.NewSchemaCache(key, tables)
+	_ = state.SaveSchemaCache(path, c)
+	return tables, entries, c, nil
 }
 
-// calcPaneSizes splits the terminal width into left (~30%) and right (~70%).
-// When the sidebar is hidden, the right pane gets the full width.
+// calcPaneSizes splits the terminal width into left (splitPercent, 30% by
+// default) and right (the rest). When the sidebar is hidden, the right pane
+// gets the full width.
 func (m *Model) calcPaneSizes() {
 	available := m.width - 4
-	if m.sidebarHidden {
+	if m.sidebarHidden || m.zoomed {
 		m.leftWidth = 0
 		m.rightWidth = available
 		return
 	}
-	m.leftWidth = available * 30 / 100
+	m.leftWidth = available * m.splitPercent / 100
 	if m.leftWidth < 25 {
 		m.leftWidth = 25
 	}
 	m.rightWidth = available - m.leftWidth
 }
 
+// splitSaveMsg saves the table list width, unless it changed again after
+// the resize numbered change.
+type splitSaveMsg struct{ change int }
+
+// resizeSidebar widens or narrows the table list by step percent, saving
+// the width once it is left alone for splitSaveDelay.
+func (m *Model) resizeSidebar(step int) tea.Cmd {
+	m.splitPercent = max(minSplitPercent, min(m.splitPercent+step, maxSplitPercent))
+	m.calcPaneSizes()
+	m.resizeGrids()
+	m.splitChanges++
+	m.splitUnsaved = true
+	change := m.splitChanges
+	return tea.Tick(splitSaveDelay, func(time.Time) tea.Msg { return splitSaveMsg{change: change} })
+}
+
+// saveSplit writes the table list width to the prefs file if it changed.
+func (m *Model) saveSplit() {
+	if !m.splitUnsaved {
+		return
+	}
+	m.splitUnsaved = false
+	prefs := state.LoadPrefs()
+	prefs.SplitPercent = m.splitPercent
+	_ = state.SavePrefs(prefs)
+}
+
 // paneHeight returns the total height for a pane's border box.
 func (m Model) paneHeight() int {
 	return max(m.height-4, 5)
 }
 
-// pageSize returns the number of visible data rows in the table, used as page size.
-// paneHeight-3 is the bubbles table Height, and the header (with border-bottom)
+// pinnedHeight returns the border-box height of the pinned grid: the top
+// half of the right column.
+func (m Model) pinnedHeight() int {
+	return m.paneHeight() / 2
+}
+
+// pinnedVisible reports whether the pinned grid is on screen alongside the
+// main grid (zoom shows only the focused one).
+func (m Model) pinnedVisible() bool {
+	return m.showPinned && !m.zoomed
+}
+
+// dataHeight returns the border-box height of the main data grid, which
+// shares the right column with the pinned grid when one is shown and gives
+// up a line to the tab bar when several tables are open.
+func (m Model) dataHeight() int {
+	h := m.paneHeight()
+	if m.pinnedVisible() {
+		h -= m.pinnedHeight()
+	}
+	if len(m.tabs) > 1 {
+		h--
+	}
+	return h
+}
+
+// pageSize returns the number of rows fetched per page. Unless the config
+// fixes it, this is the number of visible data rows in the table:
+// dataHeight-3 is the bubbles table Height, and the header (with border-bottom)
 // takes 2 of those lines, leaving Height-2 for actual data rows.
 func (m Model) pageSize() int {
-	return max(m.paneHeight()-5, 1)
+	if m.cfg.PageSize > 0 {
+		return m.cfg.PageSize
+	}
+	return max(m.dataHeight()-5, 1)
+}
+
+// newGridID returns a fresh id for a TableDataModel so async page loads can
+// be routed back to the grid that asked for them.
+func (m *Model) newGridID() int {
+	m.nextGridID++
+	return m.nextGridID
+}
+
+// resizeGrids pushes the current pane sizes down to every visible grid.
+func (m *Model) resizeGrids() {
+	if m.loaded {
+		m.tableList.SetSize(m.leftWidth, m.paneHeight())
+	}
+	if m.dataLoaded {
+		m.tableData.SetSize(m.rightWidth, m.dataHeight())
+	}
+	if m.showPinned {
+		if m.zoomed && m.focused == panePinned {
+			m.pinned.SetSize(m.rightWidth, m.paneHeight())
+		} else {
+			m.pinned.SetSize(m.rightWidth, m.pinnedHeight())
+		}
+	}
+}
+
+// inputActive reports whether the focused pane is capturing typed text
+// (list filter or grid filter), in which case single-letter shortcuts
+// must fall through to it.
+func (m *Model) inputActive() bool {
+	switch m.focused {
+	case paneList:
+		return m.loaded && (m.tableList.list.FilterState() == list.Filtering || m.tableList.renaming)
+	default:
+		return m.dataLoaded && (m.focusedGrid().fState != filterOff || m.focusedGrid().jump != jumpOff)
+	}
+}
+
+// focusedGrid returns the grid that currently has focus — the pinned grid
+// when it is focused, otherwise the main data grid.
+func (m *Model) focusedGrid() *TableDataModel {
+	if m.focused == panePinned && m.showPinned {
+		return &m.pinned
+	}
+	return &m.tableData
 }
 
 // helpItem is a key binding + description pair for the status bar.
@@ -236,18 +590,82 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if wsm, ok := msg.(tea.WindowSizeMsg); ok {
 		m.width = wsm.Width
 		m.height = wsm.Height
+		if m.showWhatsNew {
+			m.whatsNew.SetSize(m.width, m.height)
+		}
+	}
+
+	// A transaction SQLite ended on its own is left whatever comes next,
+	// the note saying so outliving a key that would clear it.
+	if m.txn != nil {
+		if why := db.TransactionEnded(m.db); why != nil {
+			lost := m.txLost(why)
+			next, cmd := m.Update(msg)
+			if nm, ok := next.(Model); ok && nm.note == "" {
+				nm.note = m.note
+				next = nm
+			}
+			return next, tea.Batch(lost, cmd)
+		}
+	}
+
+	// Background counts land whatever popup is open, or the grid that
+	// asked for one would wait on it forever.
+	if count, ok := msg.(rowCountMsg); ok {
+		m.applyRowCount(count)
+		return m, nil
+	}
+	// So does the end of a commit or rollback.
+	if ended, ok := msg.(txEndedMsg); ok {
+		return m, m.txEnded(ended)
+	}
+	// So does the debounced save of the table list width.
+	if save, ok := msg.(splitSaveMsg); ok {
+		if save.change == m.splitChanges {
+			m.saveSplit()
+		}
+		return m, nil
+	}
+	// So does a row written from the detail popup, which may have closed.
+	if written, ok := msg.(rowWrittenMsg); ok {
+		return m.rowWritten(written)
+	}
+	// So does the status bar spinner.
+	if tick, ok := msg.(spinner.TickMsg); ok && tick.ID == m.busy.ID() {
+		return m.updateBusy(msg)
+	}
+	if _, ok := msg.(activityStartedMsg); ok {
+		return m.updateBusy(msg)
+	}
+	switch msg.(type) {
+	case dataVersionTickMsg, dataVersionMsg, tailTickMsg, fileChangedMsg, pageDataLoadedMsg, tableMeasuresMsg:
+		return m.updateBackground(msg)
+	}
+
+	// The what's-new popup takes keys (and its close message) until
+	// dismissed; everything else, like the database loading behind it,
+	// goes on as usual.
+	if m.showWhatsNew {
+		switch msg.(type) {
+		case CloseDetailMsg:
+			m.showWhatsNew = false
+			return m, nil
+		case tea.KeyMsg, tea.MouseMsg:
+			var cmd tea.Cmd
+			m.whatsNew, cmd = m.whatsNew.Update(msg)
+			return m, cmd
+		}
 	}
 
 	// File picker captures all input when shown.
 	if m.showPathInput {
 		switch msg := msg.(type) {
 		case dbOpenedMsg:
-			m.db = msg.db
 			m.showPathInput = false
-			m.calcPaneSizes()
-			return m, func() tea.Msg {
-				return tablesLoadedMsg{tables: msg.tables}
-			}
+			return m, m.openDatabase(msg)
+		case pickerCancelledMsg:
+			m.showPathInput = false
+			return m, nil
 		default:
 			var cmd tea.Cmd
 			m.filePicker, cmd = m.filePicker.Update(msg)
@@ -261,16 +679,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case CloseDetailMsg:
 			m.showQuery = false
 			return m, nil
+		case QueryTimeoutMsg:
+			m.cfg.QueryTimeout = msg.Timeout
+			return m, nil
+		case viewSavedMsg:
+			if msg.err != nil {
+				var cmd tea.Cmd
+				m.queryInput, cmd = m.queryInput.Update(msg)
+				return m, cmd
+			}
+			m.showQuery = false
+			m.note = "created view " + msg.name
+			return m, tea.Batch(reloadSchemaCmd(m.db, m.dbPath, msg.name), m.txChangesCmd())
 		case QueryResultMsg:
 			m.showQuery = false
-			m.tableData = NewTableDataModel(
-				"query result", msg.Columns, msg.Rows, nil,
-				m.rightWidth, m.paneHeight(), m.db,
-				0, len(msg.Rows), len(msg.Rows),
-			)
+			prev := m.tableData.queryStats
+			m.tableData = newStaticGrid(queryResultName, msg.Columns, msg.Rows, m.rightWidth, m.dataHeight(), m.db)
+			m.tableData.sourceQuery = msg.Query
+			m.tableData.sourceArgs = msg.Args
+			m.tableData.sourceTimeout = m.cfg.QueryTimeout
+			m.tableData.pageRows = m.pageSize()
+			m.tableData.queryStats = &msg.Stats
+			if prev != nil {
+				m.tableData.prevElapsed = prev.Elapsed
+			}
+			m.tableData.id = m.newGridID()
 			m.dataLoaded = true
 			m.focused = paneData
-			return m, nil
+			return m, tea.Batch(m.resetDataVersion(), m.txChangesCmd())
 		default:
 			var cmd tea.Cmd
 			m.queryInput, cmd = m.queryInput.Update(msg)
@@ -278,190 +714,1104 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	// Row detail popup captures all input when open.
-	if m.showDetail {
+	// Insert popup captures all input when open.
+	if m.showInsert {
 		switch msg := msg.(type) {
 		case CloseDetailMsg:
-			m.showDetail = false
+			m.showInsert = false
 			return m, nil
-		case DeleteRowMsg:
-			if err := db.DeleteRow(m.db, msg.TableName, msg.RowID); err != nil {
-				m.err = err
-				return m, nil
-			}
-			m.showDetail = false
-			return m, m.tableData.refreshCmd()
+		case RowInsertedMsg:
+			m.showInsert = false
+			m.journal(m.insertRow.database, msg.Edit)
+			return m, tea.Batch(m.focusedGrid().refreshCmd(), m.txChangesCmd())
 		default:
 			var cmd tea.Cmd
-			m.rowDetail, cmd = m.rowDetail.Update(msg)
+			m.insertRow, cmd = m.insertRow.Update(msg)
 			return m, cmd
 		}
 	}
 
-	switch msg := msg.(type) {
-
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.calcPaneSizes()
-		if m.loaded {
-			m.tableList.SetSize(m.leftWidth, m.paneHeight())
-		}
-		if m.dataLoaded {
-			m.tableData.SetSize(m.rightWidth, m.paneHeight())
-		}
-		return m, nil
-
-	case tea.KeyMsg:
-		if key.Matches(msg, Keys.SwitchTab) {
-			if m.focused == paneList {
-				m.focused = paneData
-			} else {
-				m.focused = paneList
-			}
+	// Paste popup captures all input when open, including the outcome of
+	// the insert.
+	if m.showPasteRows {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showPasteRows = false
 			return m, nil
-		}
-
-		if key.Matches(msg, Keys.FocusRight) && m.focused == paneList && m.loaded {
-			if m.tableList.list.FilterState() != list.Filtering {
-				m.focused = paneData
-				item, ok := m.tableList.list.SelectedItem().(TableItem)
-				if ok && (!m.dataLoaded || m.tableData.tableName != item.Name) {
-					return m, loadTableDataCmd(m.db, item.Name, m.pageSize())
-				}
+		case rowsPastedMsg:
+			m.showPasteRows = false
+			for _, e := range msg.edits {
+				m.journal(m.pasteRows.database, e)
 			}
-			return m, nil
+			rows := "rows"
+			if len(msg.edits) == 1 {
+				rows = "row"
+			}
+			m.note = fmt.Sprintf("inserted %s %s into %s", groupDigits(int64(len(msg.edits))), rows, msg.table)
+			return m, tea.Batch(m.focusedGrid().refreshCmd(), m.txChangesCmd())
+		default:
+			var cmd tea.Cmd
+			m.pasteRows, cmd = m.pasteRows.Update(msg)
+			return m, cmd
 		}
+	}
 
-		if key.Matches(msg, Keys.FocusLeft) && m.focused == paneData {
-			m.focused = paneList
+	// Export popup captures all input when open.
+	if m.showExport {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showExport = false
 			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.export, cmd = m.export.Update(msg)
+			return m, cmd
 		}
+	}
 
-		if msg.Type == tea.KeyEsc {
-			if m.focused == paneList && m.tableList.list.FilterState() == list.Filtering {
-				break // let the list handle esc to cancel filter
-			}
-			if m.db != nil {
-				m.db.Close()
-				m.db = nil
+	// View link popup captures all input when open.
+	if m.showViewLink {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showViewLink = false
+			return m, nil
+		case openViewMsg:
+			if !slices.Contains(m.tables, msg.link.Table) {
+				m.viewLinkPopup.SetError(fmt.Errorf("no table %q in this database", msg.link.Table))
+				return m, nil
 			}
-			m.loaded = false
-			m.dataLoaded = false
-			m.showPathInput = true
-			m.filePicker = NewFilePickerModel()
-			m.filePicker.width = m.width
-			m.filePicker.height = m.height
-			return m, m.filePicker.Init()
+			m.showViewLink = false
+			m.pendingView = &msg.link
+			m.focused = paneData
+			return m, m.loadTableCmd(msg.link.Table)
+		default:
+			var cmd tea.Cmd
+			m.viewLinkPopup, cmd = m.viewLinkPopup.Update(msg)
+			return m, cmd
 		}
+	}
 
-		if key.Matches(msg, Keys.Quit) {
-			if m.focused == paneList && m.tableList.list.FilterState() == list.Filtering {
-				break
-			}
-			return m, tea.Quit
+	// Bookmarks popup captures all input when open; jumping to a row
+	// closes it.
+	if m.showBookmarks {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showBookmarks = false
+			return m, nil
+		case jumpToBookmarkMsg:
+			m.showBookmarks = false
+			return m, m.jumpToBookmark(msg.bookmark)
+		default:
+			var cmd tea.Cmd
+			m.bookmarkList, cmd = m.bookmarkList.Update(msg)
+			return m, cmd
 		}
+	}
 
-		if key.Matches(msg, Keys.ToggleSidebar) {
-			m.sidebarHidden = !m.sidebarHidden
-			if m.sidebarHidden {
-				m.focused = paneData
-			}
-			m.calcPaneSizes()
-			if m.loaded {
-				m.tableList.SetSize(m.leftWidth, m.paneHeight())
-			}
-			if m.dataLoaded {
-				m.tableData.SetSize(m.rightWidth, m.paneHeight())
-			}
+	// PRAGMA popup captures all input when open.
+	if m.showPragmas {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showPragmas = false
 			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.pragmas, cmd = m.pragmas.Update(msg)
+			return m, cmd
 		}
+	}
 
-		if key.Matches(msg, Keys.Refresh) && m.dataLoaded {
-			if m.tableData.tableName == "query result" {
-				if m.lastTableName == "" {
-					return m, nil
-				}
-				return m, loadTableDataCmd(m.db, m.lastTableName, m.pageSize())
-			}
-			return m, m.tableData.refreshCmd()
+	// Column statistics popup captures all input when open, including the
+	// spinner ticks and results of a running computation.
+	if m.showColumnStats {
+		if _, ok := msg.(CloseDetailMsg); ok {
+			m.showColumnStats = false
+			return m, nil
 		}
+		var cmd tea.Cmd
+		m.columnStats, cmd = m.columnStats.Update(msg)
+		return m, cmd
+	}
 
-		if key.Matches(msg, Keys.OpenQuery) {
-			qi, cmd := NewQueryInputModel(m.db, m.width, m.height)
-			m.queryInput = qi
-			m.showQuery = true
-			return m, cmd
+	// JSON paths popup captures all input when open, including the
+	// spinner ticks and results of reading a column. A generated query
+	// moves to the query popup.
+	if m.showJSONPaths {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showJSONPaths = false
+			return m, nil
+		case editQueryMsg:
+			m.showJSONPaths = false
+			return m, m.openQuery(msg.query)
 		}
+		var cmd tea.Cmd
+		m.jsonPaths, cmd = m.jsonPaths.Update(msg)
+		return m, cmd
+	}
 
-	case tablesLoadedMsg:
-		m.tableList = NewTableListModel(msg.tables, m.leftWidth, m.paneHeight())
-		m.loaded = true
-		if len(msg.tables) > 0 {
-			return m, loadTableDataCmd(m.db, msg.tables[0], m.pageSize())
+	// View definition popup captures all input when open; its SELECT can
+	// move to the query popup.
+	if m.showViewDef {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showViewDef = false
+			return m, nil
+		case editQueryMsg:
+			m.showViewDef = false
+			return m, m.openQuery(msg.query)
 		}
-		return m, nil
-
-	case tableDataLoadedMsg:
-		m.tableData = NewTableDataModel(
-			msg.tableName, msg.columns, msg.rows, msg.rowIDs,
-			m.rightWidth, m.paneHeight(), m.db,
-			msg.page, msg.pageSize, msg.totalRows,
-		)
-		m.dataLoaded = true
-		m.lastTableName = msg.tableName
-		return m, nil
+		var cmd tea.Cmd
+		m.viewDef, cmd = m.viewDef.Update(msg)
+		return m, cmd
+	}
 
-	case pageDataLoadedMsg:
-		m.tableData.allRows = msg.rows
-		m.tableData.allRowIDs = msg.rowIDs
-		m.tableData.page = msg.page
-		if m.tableData.fActive {
-			m.tableData.fTotalRows = msg.totalRows
-		} else {
-			m.tableData.totalRows = msg.totalRows
-		}
-		m.tableData.table.SetRows(truncateRows(msg.rows, m.tableData.displayCols, m.tableData.hasHiddenCols()))
-		if msg.cursorEnd && len(msg.rows) > 0 {
-			m.tableData.table.SetCursor(len(msg.rows) - 1)
-			m.tableData.table.GotoBottom()
-		} else {
-			m.tableData.table.SetCursor(0)
+	// Data diff popup captures all input when open, including the spinner
+	// ticks and result of the comparison.
+	if m.showDataDiff {
+		if _, ok := msg.(CloseDetailMsg); ok {
+			m.showDataDiff = false
+			return m, nil
 		}
-		return m, nil
-
-	case TableSelectedMsg:
-		return m, loadTableDataCmd(m.db, msg.Name, m.pageSize())
-
-	case RowSelectedMsg:
-		m.rowDetail = NewRowDetailModel(msg.Columns, msg.Values, msg.TableName, msg.RowID, m.width, m.height)
-		m.showDetail = true
-		return m, nil
+		var cmd tea.Cmd
+		m.dataDiff, cmd = m.dataDiff.Update(msg)
+		return m, cmd
+	}
 
-	case errMsg:
-		m.err = msg.err
-		return m, nil
+	// Space usage popup captures all input when open, including the
+	// spinner ticks and result of the measurement.
+	if m.showSpaceUsage {
+		if _, ok := msg.(CloseDetailMsg); ok {
+			m.showSpaceUsage = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spaceUsage, cmd = m.spaceUsage.Update(msg)
+		return m, cmd
 	}
 
-	switch m.focused {
-	case paneList:
-		if m.loaded {
+	// Relationships popup captures all input when open; opening a table
+	// from it closes it.
+	if m.showRelations {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showRelations = false
+			return m, nil
+		case TableSelectedMsg:
+			m.showRelations = false
+			m.focused = paneData
+			return m, m.showTable(msg.Name)
+		default:
 			var cmd tea.Cmd
-			m.tableList, cmd = m.tableList.Update(msg)
+			m.relations, cmd = m.relations.Update(msg)
 			return m, cmd
 		}
-	case paneData:
-		if m.dataLoaded {
-			var cmd tea.Cmd
-			m.tableData, cmd = m.tableData.Update(msg)
-			return m, cmd
+	}
+
+	// Save results popup captures all input when open, including the
+	// progress of a running save.
+	if m.showSaveResults {
+		if _, ok := msg.(CloseDetailMsg); ok {
+			m.showSaveResults = false
+			return m, nil
 		}
+		var cmd tea.Cmd
+		m.saveResults, cmd = m.saveResults.Update(msg)
+		return m, cmd
 	}
 
-	return m, nil
+	// ALTER TABLE popup captures all input when open; a change made closes
+	// it and lists the tables anew.
+	if m.showAlterTable {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showAlterTable = false
+			return m, nil
+		case tableAlteredMsg:
+			m.showAlterTable = false
+			return m, m.tableAltered(msg)
+		default:
+			var cmd tea.Cmd
+			m.alterTable, cmd = m.alterTable.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Create index popup captures all input when open, including the
+	// outcome of the CREATE INDEX.
+	if m.showCreateIndex {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showCreateIndex = false
+			return m, nil
+		case indexCreatedMsg:
+			m.showCreateIndex = false
+			m.note = "created index " + msg.name + " on " + msg.table
+			return m, tea.Batch(loadDBInfoCmd(m.db, m.dbPath, false), m.txChangesCmd())
+		default:
+			var cmd tea.Cmd
+			m.createIndex, cmd = m.createIndex.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Index popup captures all input when open, including the indexes it
+	// lists and the outcome of a DROP INDEX, which the popup stays open for.
+	if m.showIndexes {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showIndexes = false
+			return m, nil
+		case indexDroppedMsg:
+			var cmd tea.Cmd
+			m.indexes, cmd = m.indexes.Update(msg)
+			if msg.err != nil {
+				return m, cmd
+			}
+			return m, tea.Batch(cmd, loadDBInfoCmd(m.db, m.dbPath, false), m.txChangesCmd())
+		default:
+			var cmd tea.Cmd
+			m.indexes, cmd = m.indexes.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Empty table popup captures all input when open, including the count
+	// it asks about and the outcome of the DELETE.
+	if m.showEmptyTable {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showEmptyTable = false
+			return m, nil
+		case tableEmptiedMsg:
+			m.showEmptyTable = false
+			m.forgetTableEdits(msg.database, msg.table)
+			rows := "rows"
+			if msg.deleted == 1 {
+				rows = "row"
+			}
+			m.note = fmt.Sprintf("deleted %s %s of %s", groupDigits(msg.deleted), rows, msg.table)
+			if msg.reset {
+				m.note += ", its counter reset"
+			}
+			return m, tea.Batch(m.refreshGrids(msg.database, msg.table), m.txChangesCmd())
+		default:
+			var cmd tea.Cmd
+			m.emptyTable, cmd = m.emptyTable.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Maintenance popup captures all input when open, including the
+	// spinner ticks and result of a running task.
+	if m.showMaintenance {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showMaintenance = false
+			return m, nil
+		case schemaReloadedMsg, tableDataLoadedMsg, dbInfoMsg, txChangesMsg:
+			// What a finished task reloads lands behind the popup, which
+			// stays open with its findings.
+		case maintenanceDoneMsg:
+			var cmd tea.Cmd
+			m.maintenance, cmd = m.maintenance.Update(msg)
+			if msg.reschema && msg.database == m.db {
+				table := m.lastTableName
+				if item, ok := m.tableList.list.SelectedItem().(TableItem); ok && !m.dataLoaded {
+					table = item.Name
+				}
+				return m, tea.Batch(cmd, reloadSchemaCmd(m.db, m.dbPath, table), m.txChangesCmd())
+			}
+			if msg.rewrote && msg.database == m.db {
+				// VACUUM may renumber rowids and changes the file size.
+				cmds := []tea.Cmd{cmd, loadDBInfoCmd(m.db, m.dbPath, false)}
+				if m.dataLoaded && !m.tableData.static {
+					cmds = append(cmds, m.tableData.refreshCmd())
+				}
+				return m, tea.Batch(cmds...)
+			}
+			return m, cmd
+		default:
+			var cmd tea.Cmd
+			m.maintenance, cmd = m.maintenance.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Info popup captures all input when open.
+	if m.showDBInfo {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showDBInfo = false
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			m.dbInfoView, cmd = m.dbInfoView.Update(msg)
+			return m, cmd
+		}
+	}
+
+	// Row detail popup captures all input when open.
+	if m.showDetail {
+		switch msg := msg.(type) {
+		case CloseDetailMsg:
+			m.showDetail = false
+			return m, nil
+		case DeleteRowMsg:
+			if m.writingRow {
+				return m, nil
+			}
+			m.writingRow = true
+			return m, deleteRowCmd(m.focusedGrid().database, msg)
+		case UpdateRowMsg:
+			if m.writingRow {
+				return m, nil
+			}
+			m.writingRow = true
+			return m, updateRowCmd(m.focusedGrid().database, msg)
+		default:
+			var cmd tea.Cmd
+			m.rowDetail, cmd = m.rowDetail.Update(msg)
+			return m, cmd
+		}
+	}
+
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.calcPaneSizes()
+		m.resizeGrids()
+		return m, nil
+
+	case tea.KeyMsg:
+		m.note = ""
+		if m.txPrompt {
+			m.txPrompt = false
+			switch msg.String() {
+			case "c":
+				return m, m.endTx(true)
+			case "r":
+				return m, m.endTx(false)
+			}
+			return m, nil
+		}
+		if m.tableList.renaming && m.focused == paneList {
+			var cmd tea.Cmd
+			m.tableList, cmd = m.tableList.Update(msg)
+			return m, cmd
+		}
+		if msg.Paste && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			m.openPaste(string(msg.Runes))
+			return m, nil
+		}
+		if m.focused != paneList && m.dataLoaded && !m.inputActive() && m.focusedGrid().vimClaims(msg) {
+			grid := m.focusedGrid()
+			var cmd tea.Cmd
+			*grid, cmd = grid.Update(msg)
+			return m, cmd
+		}
+		if key.Matches(msg, Keys.SwitchTab) && m.zoomed {
+			// Zoomed: the table list is hidden, so only swap between grids.
+			if m.showPinned {
+				if m.focused == panePinned {
+					m.focused = paneData
+				} else {
+					m.focused = panePinned
+				}
+				m.resizeGrids()
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Zoom) && m.dataLoaded && !m.inputActive() {
+			m.zoomed = !m.zoomed
+			if m.zoomed && m.focused == paneList {
+				m.focused = paneData
+			}
+			m.calcPaneSizes()
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.SwitchTab) {
+			switch {
+			case m.focused == paneList:
+				m.focused = paneData
+			case m.focused == paneData && m.showPinned:
+				m.focused = panePinned
+			default:
+				m.focused = paneList
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.NewTab) && m.focused == paneList && m.loaded &&
+			m.tableList.list.FilterState() != list.Filtering {
+			if item, ok := m.tableList.list.SelectedItem().(TableItem); ok {
+				m.focused = paneData
+				if i := m.findTab(item.Name); i >= 0 {
+					m.switchTab(i)
+					return m, nil
+				}
+				return m, inNewTab(m.loadTableCmd(item.Name))
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.NextTab, Keys.PrevTab) && len(m.tabs) > 1 && !m.inputActive() {
+			step := 1
+			if key.Matches(msg, Keys.PrevTab) {
+				step = len(m.tabs) - 1
+			}
+			m.switchTab((m.activeTab + step) % len(m.tabs))
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.CloseTab) && len(m.tabs) > 1 && !m.inputActive() {
+			m.closeTab()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Pin) && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			if m.showPinned {
+				// Unpin: whichever grid is focused stays as the main grid.
+				m.tableData = *m.focusedGrid()
+				m.showPinned = false
+			} else {
+				m.pinned = m.tableData
+				m.pinned.id = m.newGridID()
+				m.showPinned = true
+			}
+			m.focused = paneData
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.FocusRight) && m.focused == paneList && m.loaded {
+			if m.tableList.list.FilterState() != list.Filtering {
+				m.focused = paneData
+				item, ok := m.tableList.list.SelectedItem().(TableItem)
+				if ok && (!m.dataLoaded || m.tableData.tableName != item.Name) {
+					return m, m.showTable(item.Name)
+				}
+			}
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.SortTables) && m.focused == paneList && m.loaded && !m.inputActive() {
+			next := m.tableList.sort.next()
+			if m.tableList.measured(next) {
+				return m, m.tableList.setSort(next)
+			}
+			var known map[string]int
+			if m.schemaFresh() {
+				known = maps.Clone(m.schema.Counts)
+			}
+			m.note = "measuring tables by " + next.String() + "…"
+			return m, tableMeasuresCmd(m.db, next, m.tableList.entries, known)
+		}
+
+		if key.Matches(msg, Keys.InternalTables) && m.loaded && !m.inputActive() {
+			show := !m.tableList.internal
+			m.note = "hiding internal tables"
+			if show {
+				m.note = "showing internal tables"
+			}
+			return m, m.tableList.setInternal(show)
+		}
+
+		if key.Matches(msg, Keys.FocusLeft) && m.focused != paneList && !m.zoomed {
+			m.focused = paneList
+			return m, nil
+		}
+
+		if msg.Type == tea.KeyEsc {
+			if m.inputActive() {
+				break // let the list or grid handle esc to cancel its input
+			}
+			if cmd, ok := m.closeDatabase(); ok {
+				return m, cmd
+			}
+			m.loaded = false
+			m.dataLoaded = false
+			m.showPinned = false
+			m.zoomed = false
+			m.tabs = nil
+			m.activeTab = 0
+			m.showPathInput = true
+			m.filePicker = NewFilePickerModel(dbOptions(m.cfg))
+			m.filePicker.width = m.width
+			m.filePicker.height = m.height
+			return m, m.filePicker.Init()
+		}
+
+		if key.Matches(msg, Keys.OpenDatabase) && m.loaded && !m.inputActive() {
+			m.showPathInput = true
+			m.filePicker = NewFilePickerModel(dbOptions(m.cfg))
+			m.filePicker.backOut = true
+			m.filePicker.width = m.width
+			m.filePicker.height = m.height
+			return m, m.filePicker.Init()
+		}
+
+		if key.Matches(msg, Keys.SwitchDatabase) && len(m.sessions) > 0 && !m.inputActive() {
+			return m, m.nextSession()
+		}
+
+		if key.Matches(msg, Keys.Quit) {
+			if m.inputActive() {
+				break
+			}
+			m.saveSplit()
+			return m, tea.Quit
+		}
+
+		if key.Matches(msg, Keys.ToggleSidebar) {
+			m.sidebarHidden = !m.sidebarHidden
+			if m.sidebarHidden {
+				m.focused = paneData
+			}
+			m.calcPaneSizes()
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.GrowSidebar, Keys.ShrinkSidebar) && !m.sidebarHidden && !m.zoomed {
+			step := splitStep
+			if key.Matches(msg, Keys.ShrinkSidebar) {
+				step = -splitStep
+			}
+			return m, m.resizeSidebar(step)
+		}
+
+		if key.Matches(msg, Keys.Refresh) && m.dataLoaded {
+			if db.IsSSH(m.dbPath) && m.cfg.SSHSyncOnRefresh && m.txn == nil && !m.syncing {
+				// Stop the watch connection, which would keep reading the
+				// old copy.
+				m.stopWatching()
+				m.syncing = true
+				m.note = "copying " + db.BaseName(m.dbPath) + " from the host…"
+				return m, syncSSHCmd(m.db, m.dbPath)
+			}
+			grid := m.focusedGrid()
+			if grid.static {
+				switch {
+				case grid.sourceQuery != "":
+					return m, grid.rerunCmd()
+				case grid == &m.pinned:
+					return m, nil
+				}
+				return m, loadSchemaObjectsCmd(m.db)
+			}
+			return m, grid.refreshCmd()
+		}
+
+		if key.Matches(msg, Keys.InsertRow) && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			grid := m.focusedGrid()
+			if grid.static {
+				return m, nil
+			}
+			ir, cmd := NewInsertRowModel(grid.database, grid.tableName, grid.columns, m.width, m.height)
+			m.insertRow = ir
+			m.showInsert = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.PasteRows) && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			text, err := readClipboard()
+			if err != nil {
+				m.note = ErrorStyle.Render(err.Error())
+				return m, nil
+			}
+			m.openPaste(text)
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.AlterTable) && m.loaded && !m.inputActive() {
+			table := m.targetTable()
+			if table == "" {
+				return m, nil
+			}
+			columns, err := m.tableColumns(table)
+			if err != nil {
+				m.note = ErrorStyle.Render(err.Error())
+				return m, nil
+			}
+			m.alterTable = NewAlterTableModel(m.db, table, columns, m.width, m.height)
+			m.showAlterTable = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.RenameTable) && m.focused == paneList && m.loaded && !m.inputActive() {
+			return m, m.tableList.startRename()
+		}
+
+		if key.Matches(msg, Keys.CreateIndex) && m.loaded && !m.inputActive() {
+			table := m.targetTable()
+			if table == "" {
+				return m, nil
+			}
+			columns, err := m.tableColumns(table)
+			if err != nil {
+				m.note = ErrorStyle.Render(err.Error())
+				return m, nil
+			}
+			var suggest string
+			if g := m.tableData; m.dataLoaded && g.tableName == table && g.fActive {
+				suggest = g.fCol
+			}
+			m.createIndex = NewCreateIndexModel(m.db, table, columns, suggest, m.width, m.height)
+			m.showCreateIndex = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.ViewDefinition) && m.loaded && !m.inputActive() {
+			view := m.targetView()
+			if view == "" {
+				return m, nil
+			}
+			query, err := db.ViewDefinition(context.Background(), m.db, view)
+			if err != nil {
+				m.note = ErrorStyle.Render(err.Error())
+				return m, nil
+			}
+			m.viewDef = NewViewDefModel(view, query, m.width, m.height)
+			m.showViewDef = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Indexes) && m.loaded && !m.inputActive() {
+			table := m.targetTable()
+			if table == "" {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.indexes, cmd = NewIndexesModel(m.db, table, m.width, m.height)
+			m.showIndexes = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.EmptyTable) && m.loaded && !m.inputActive() {
+			table := m.targetTable()
+			if table == "" {
+				return m, nil
+			}
+			e, cmd := NewEmptyTableModel(m.db, table, m.width)
+			m.emptyTable = e
+			m.showEmptyTable = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.ExportAll) && m.loaded && !m.inputActive() {
+			dir := strings.TrimSuffix(db.BaseName(m.dbPath), filepath.Ext(db.BaseName(m.dbPath))) + "-export"
+			var filter *exportFilter
+			if grid := m.focusedGrid(); m.focused != paneList && m.dataLoaded && grid.fActive && !grid.static {
+				query, args := db.FilterQuery(grid.tableName, grid.fCol, grid.fQuery, grid.fMode, grid.rowOrder())
+				filter = &exportFilter{database: grid.database, table: grid.tableName, query: query, args: args, rows: grid.fTotalRows}
+			}
+			e, cmd := NewExportModel(m.db, m.dbPath, m.tables, filter, dir, m.width, m.height)
+			m.export = e
+			m.showExport = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.SchemaObjects) && m.loaded && !m.inputActive() {
+			m.focused = paneData
+			if i := m.findTab(schemaObjectsName); i >= 0 && i != m.activeTab {
+				m.switchTab(i)
+				return m, nil
+			}
+			return m, loadSchemaObjectsCmd(m.db)
+		}
+
+		if key.Matches(msg, Keys.ViewLink) && m.loaded && !m.inputActive() {
+			var current string
+			if grid := m.focusedGrid(); m.dataLoaded && !grid.static {
+				current = viewLinkFor(*grid).String()
+			}
+			var cmd tea.Cmd
+			m.viewLinkPopup, cmd = NewViewLinkModel(current, m.width)
+			m.showViewLink = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.Pragmas) && m.loaded && !m.inputActive() {
+			m.pragmas = NewPragmaModel(m.db, m.width)
+			m.showPragmas = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Follow) && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			return m, m.toggleTail()
+		}
+
+		if key.Matches(msg, Keys.Live) && m.loaded && !m.inputActive() {
+			m.live = !m.live
+			if m.live {
+				return m, m.startLive()
+			}
+			m.stopLive()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Maintenance) && m.loaded && !m.inputActive() {
+			m.maintenance = NewMaintenanceModel(m.db, m.dbPath, m.lastTableName, m.width, m.height)
+			m.showMaintenance = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.ColumnStats) && m.focused != paneList && m.dataLoaded && !m.focusedGrid().static && !m.inputActive() {
+			grid := m.focusedGrid()
+			cs, err := NewColumnStatsModel(grid.database, grid.tableName, m.width, m.height)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.columnStats = cs
+			m.showColumnStats = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Timestamps) && m.loaded && !m.inputActive() {
+			formatTimestamps = !formatTimestamps
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.RowNumbers) && m.loaded && !m.inputActive() {
+			showRowNumbers = !showRowNumbers
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.RowIDs) && m.loaded && !m.inputActive() {
+			showRowIDs = !showRowIDs
+			m.resizeGrids()
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.JSONPaths) && m.focused != paneList && m.dataLoaded && !m.focusedGrid().static && !m.inputActive() {
+			grid := m.focusedGrid()
+			jp, err := NewJSONPathsModel(grid.database, grid.tableName, m.width, m.height)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.jsonPaths = jp
+			m.showJSONPaths = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.DataDiff) && m.focused != paneList && m.dataLoaded && !m.focusedGrid().static && !m.inputActive() {
+			// Another open database is the likeliest copy to compare with.
+			var other string
+			if len(m.sessions) > 0 {
+				other = m.sessions[len(m.sessions)-1].dbPath
+			}
+			grid := m.focusedGrid()
+			var cmd tea.Cmd
+			m.dataDiff, cmd = NewDataDiffModel(grid.database, grid.tableName, other, m.width, m.height)
+			m.showDataDiff = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.Transaction) && m.loaded && !m.inputActive() {
+			if m.txn == nil {
+				m.beginTx()
+				return m, m.txChangesCmd()
+			}
+			m.txPrompt = true
+			return m, m.txChangesCmd()
+		}
+
+		if key.Matches(msg, Keys.Undo) && m.loaded && !m.inputActive() {
+			return m, m.undo()
+		}
+
+		if key.Matches(msg, Keys.Bookmarks) && m.loaded && !m.inputActive() {
+			if m.bookmarks == nil {
+				m.bookmarks = loadBookmarks(m.dbPath)
+			}
+			m.bookmarkList = NewBookmarksModel(m.bookmarks, m.width, m.height)
+			m.showBookmarks = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.Relationships) && m.loaded && !m.inputActive() {
+			r, err := NewRelationsModel(m.db, m.tables, m.width, m.height)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.relations = r
+			m.showRelations = true
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.SpaceUsage) && m.loaded && !m.inputActive() {
+			var cmd tea.Cmd
+			m.spaceUsage, cmd = NewSpaceUsageModel(m.db, m.width, m.height)
+			m.showSpaceUsage = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.Cancel) && m.cancelLoads() {
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.SaveResults) && m.focused != paneList && m.dataLoaded && m.focusedGrid().sourceQuery != "" && !m.inputActive() {
+			grid := m.focusedGrid()
+			// Saving runs the query again, which must not write twice.
+			if !grid.rerunnable() {
+				m.note = ErrorStyle.Render("only the results of a single SELECT that changed no rows can be saved, since saving runs the query again")
+				return m, nil
+			}
+			query, err := grid.resultQuery()
+			if err != nil {
+				m.note = ErrorStyle.Render(err.Error())
+				return m, nil
+			}
+			sr, cmd := NewSaveResultsModel(grid.database, query, grid.sourceArgs, grid.sourceTimeout, m.width)
+			m.saveResults = sr
+			m.showSaveResults = true
+			return m, cmd
+		}
+
+		if key.Matches(msg, Keys.CopyMarkdown) && m.focused != paneList && m.dataLoaded && !m.inputActive() {
+			// The rows shown: a query's results, or a table's current page.
+			grid := m.focusedGrid()
+			if err := copyToClipboard(markdownTable(grid.columns, grid.allRows)); err != nil {
+				m.note = ErrorStyle.Render("copy failed: " + err.Error())
+				return m, nil
+			}
+			rows := "rows"
+			if len(grid.allRows) == 1 {
+				rows = "row"
+			}
+			m.note = fmt.Sprintf("copied %s %s as a Markdown table", groupDigits(int64(len(grid.allRows))), rows)
+			return m, nil
+		}
+
+		if key.Matches(msg, Keys.DatabaseInfo) && m.loaded && !m.inputActive() {
+			return m, loadDBInfoCmd(m.db, m.dbPath, true)
+		}
+
+		if key.Matches(msg, Keys.OpenQuery) {
+			return m, m.openQuery("")
+		}
+
+	case tablesLoadedMsg:
+		if msg.entries == nil {
+			msg.entries = tableEntries(msg.tables)
+		}
+		internal := m.tableList.internal
+		m.tableList = NewTableListModel(msg.entries, m.leftWidth, m.paneHeight())
+		if internal {
+			m.tableList.setInternal(true)
+		}
+		m.loaded = true
+		m.tables = msg.tables
+		m.schema = msg.schema
+		m.dbInfo = nil
+		watchCmd := tea.Batch(m.startWatching(), loadDBInfoCmd(m.db, m.dbPath, false))
+		if item, ok := m.tableList.list.SelectedItem().(TableItem); ok {
+			return m, tea.Batch(watchCmd, m.loadTableCmd(item.Name))
+		}
+		return m, watchCmd
+
+	case TableRenameMsg:
+		return m, renameTableCmd(m.db, msg.From, msg.To)
+
+	case tableAlteredMsg:
+		return m, m.tableAltered(msg)
+
+	case alterFailedMsg:
+		m.note = ErrorStyle.Render(msg.err.Error())
+		return m, nil
+
+	case schemaReloadedMsg:
+		if msg.database != m.db {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.note = ErrorStyle.Render(msg.err.Error())
+			return m, nil
+		}
+		internal := m.tableList.internal
+		m.tableList = NewTableListModel(msg.entries, m.leftWidth, m.paneHeight())
+		if internal {
+			m.tableList.setInternal(true)
+		}
+		m.tableList.selectName(msg.table)
+		m.tables = msg.tables
+		m.schema = msg.schema
+		info := loadDBInfoCmd(m.db, m.dbPath, false)
+		if msg.table != "" && (!m.dataLoaded || !m.tableData.static && m.tableData.tableName == msg.table) {
+			return m, tea.Batch(m.loadTableCmd(msg.table), info)
+		}
+		return m, info
+
+	case schemaObjectsMsg:
+		if msg.database != m.db {
+			return m, nil
+		}
+		m.tableData = newSchemaObjectsGrid(msg.columns, msg.rows, m.rightWidth, m.dataHeight(), m.db)
+		m.tableData.id = m.newGridID()
+		m.dataLoaded = true
+		return m, nil
+
+	case dbInfoMsg:
+		if msg.database != m.db {
+			return m, nil
+		}
+		if msg.err != nil {
+			if msg.show {
+				m.err = msg.err
+			}
+			return m, nil
+		}
+		m.dbInfo = &msg.info
+		if msg.show {
+			m.dbInfoView = NewDatabaseInfoModel(m.dbPath, msg.info, m.width)
+			m.showDBInfo = true
+		}
+		return m, nil
+
+	case tableDataLoadedMsg:
+		if msg.database != m.db {
+			return m, nil // loaded for a database that has since been switched away from
+		}
+		if msg.newTab && m.dataLoaded {
+			m.openTab()
+		} else {
+			m.tableData.cancelLoads() // the grid being replaced no longer needs them
+		}
+		if msg.view {
+			m.tableData = newStaticGrid(msg.tableName, msg.columns, msg.rows, m.rightWidth, m.dataHeight(), m.db)
+			m.tableData.sourceQuery = msg.query
+			m.tableData.sourceTimeout = m.cfg.QueryTimeout
+			m.tableData.pageRows = msg.pageSize
+			m.tableData.paged = true
+			m.tableData.staticRows = nil
+			m.tableData.pageSize = msg.pageSize
+			m.tableData.hasMore = msg.hasMore
+			m.tableData.totalRows = unknownTotal
+			m.tableData.id = m.newGridID()
+			m.dataLoaded = true
+			return m, tea.Batch(m.resetDataVersion(), m.tableData.countCmd())
+		}
+		m.tableData = NewTableDataModel(
+			msg.tableName, msg.columns, msg.rows, msg.rowIDs,
+			m.rightWidth, m.dataHeight(), m.db,
+			msg.page, msg.pageSize, msg.totalRows,
+		)
+		m.tableData.id = m.newGridID()
+		m.tableData.hasMore = msg.hasMore
+		if m.filterHistory == nil {
+			m.filterHistory = newFilterHistory(m.dbPath, m.cfg)
+		}
+		m.tableData.history = m.filterHistory
+		if m.bookmarks == nil {
+			m.bookmarks = loadBookmarks(m.dbPath)
+		}
+		m.tableData.bookmarks = m.bookmarks
+		if m.columnWidths == nil {
+			m.columnWidths = columnWidths{}
+		}
+		m.tableData.setWidths(m.columnWidths)
+		m.tableData.loadRowKey()
+		m.dataLoaded = true
+		m.lastTableName = msg.tableName
+		var countCmd tea.Cmd
+		if msg.estimated || msg.totalRows == unknownTotal {
+			m.tableData.estimated = msg.estimated
+			countCmd = m.tableData.countCmd()
+		} else {
+			m.cacheTableMeta(msg.tableName, msg.columns, msg.totalRows)
+		}
+		var restoreCmd tea.Cmd
+		if m.pendingView != nil && m.pendingView.Table == msg.tableName {
+			restoreCmd = m.tableData.restoreView(*m.pendingView)
+			m.pendingView = nil
+		}
+		if m.pendingBookmark != nil && m.pendingBookmark.Table == msg.tableName {
+			restoreCmd = m.tableData.keyJumpCmd(m.pendingBookmark.Key)
+			m.pendingBookmark = nil
+		}
+		return m, tea.Batch(m.resetDataVersion(), countCmd, restoreCmd)
+
+	case resultRerunMsg:
+		var countCmd tea.Cmd
+		switch msg.gridID {
+		case m.tableData.id:
+			countCmd = m.tableData.applyRerun(msg)
+		case m.pinned.id:
+			countCmd = m.pinned.applyRerun(msg)
+		default:
+			countCmd = m.applyBackgroundRerun(msg)
+		}
+		return m, tea.Batch(m.resetDataVersion(), countCmd)
+
+	case TableSelectedMsg:
+		return m, m.showTable(msg.Name)
+
+	case RowSelectedMsg:
+		var database *sql.DB
+		if msg.Editable {
+			database = m.focusedGrid().database
+		}
+		m.rowDetail = NewRowDetailModel(database, msg.Columns, msg.Values, msg.Timestamps, msg.TableName, msg.RowID, msg.Editable, m.width, m.height)
+		m.showDetail = true
+		return m, nil
+
+	case errMsg:
+		m.err = msg.err
+		return m, nil
+
+	case noteMsg:
+		m.note = ErrorStyle.Render(msg.err.Error())
+		return m, nil
+
+	case sshSyncedMsg:
+		return m, m.sshSynced(msg)
+
+	case txChangesMsg:
+		if msg.txn == m.txn {
+			m.txn.changes = msg.changes
+		}
+		return m, nil
+	}
+
+	switch m.focused {
+	case paneList:
+		if m.loaded {
+			var cmd tea.Cmd
+			m.tableList, cmd = m.tableList.Update(msg)
+			return m, cmd
+		}
+	case paneData:
+		if m.dataLoaded {
+			var cmd tea.Cmd
+			m.tableData, cmd = m.tableData.Update(msg)
+			return m, cmd
+		}
+	case panePinned:
+		if m.showPinned {
+			var cmd tea.Cmd
+			m.pinned, cmd = m.pinned.Update(msg)
+			return m, cmd
+		}
+	}
+
+	return m, nil
 }
 
 func (m Model) View() string {
+	if m.showWhatsNew {
+		return m.placePopup(m.whatsNew.View())
+	}
 	if m.showPathInput {
 		return m.filePicker.View()
 	}
@@ -479,54 +1829,168 @@ func (m Model) View() string {
 		)
 	}
 
-	leftStyle, rightStyle := UnfocusedPaneStyle, UnfocusedPaneStyle
-	if m.focused == paneList {
+	leftStyle, rightStyle, pinnedStyle := UnfocusedPaneStyle, UnfocusedPaneStyle, UnfocusedPaneStyle
+	switch m.focused {
+	case paneList:
 		leftStyle = FocusedPaneStyle
-	} else {
+	case paneData:
 		rightStyle = FocusedPaneStyle
+	case panePinned:
+		pinnedStyle = FocusedPaneStyle
 	}
 
 	// Build the status bar first so we know how many lines it needs.
 	hints := []helpItem{
-		{"←→/tab", "navigate"},
-		{"enter", "detail"},
-		{"f", "filter"},
-		{"[/]", "page"},
-		{"ctrl+e", "query"},
-		{"ctrl+r", "refresh"},
-		{"ctrl+\\", "sidebar"},
+		{Keys.FocusLeft.Help().Key + Keys.FocusRight.Help().Key + "/" + Keys.SwitchTab.Help().Key, "navigate"},
+		{Keys.Select.Help().Key, "detail"},
+		{Keys.Filter.Help().Key, "filter"},
+		{Keys.Search.Help().Key + " " + Keys.NextMatch.Help().Key + "/" + Keys.PrevMatch.Help().Key, "search page"},
+		{Keys.Sort.Help().Key + "/" + Keys.ThenSort.Help().Key, "sort/then by"},
+		{Keys.ResizeColumns.Help().Key, "resize columns"},
+		{Keys.WrapRow.Help().Key, "wrap row"},
+		{Keys.PrevPage.Help().Key + "/" + Keys.NextPage.Help().Key, "page"},
+		{Keys.GoToPage.Help().Key, "go to page"},
+		{Keys.GoToRow.Help().Key, "go to row"},
+		{Keys.Bookmark.Help().Key, "bookmark"},
+		{Keys.Bookmarks.Help().Key, "bookmarks"},
+		{Keys.OpenQuery.Help().Key, "query"},
+		{Keys.InsertRow.Help().Key, "add row"},
+		{Keys.Undo.Help().Key, "undo"},
+		{Keys.Transaction.Help().Key, "transaction"},
+		{Keys.Pin.Help().Key, "pin"},
+		{Keys.NewTab.Help().Key, "new tab"},
+		{Keys.ExportAll.Help().Key, "export all"},
+		{Keys.OpenDatabase.Help().Key, "open db"},
+		{Keys.DatabaseInfo.Help().Key, "db info"},
+		{Keys.SpaceUsage.Help().Key, "space usage"},
+		{Keys.Pragmas.Help().Key, "pragmas"},
+		{Keys.Maintenance.Help().Key, "maintenance"},
+		{Keys.ColumnStats.Help().Key, "column stats"},
+		{Keys.JSONPaths.Help().Key, "json paths"},
+		{Keys.DataDiff.Help().Key, "data diff"},
+		{Keys.Timestamps.Help().Key, "timestamps"},
+		{Keys.RowNumbers.Help().Key, "row numbers"},
+		{Keys.RowIDs.Help().Key, "rowids"},
+		{Keys.SaveResults.Help().Key, "save results"},
+		{Keys.ViewLink.Help().Key, "view link"},
+		{Keys.SchemaObjects.Help().Key, "schema"},
+		{Keys.Relationships.Help().Key, "relationships"},
+		{Keys.Refresh.Help().Key, "refresh"},
+		{Keys.Live.Help().Key, "live"},
+		{Keys.Follow.Help().Key, "follow"},
+		{Keys.ToggleSidebar.Help().Key, "sidebar"},
+		{Keys.SortTables.Help().Key, "sort tables"},
+		{Keys.InternalTables.Help().Key, "internal tables"},
+		{Keys.AlterTable.Help().Key, "alter table"},
+		{Keys.EmptyTable.Help().Key, "empty table"},
+		{Keys.RenameTable.Help().Key, "rename table"},
+		{Keys.CreateIndex.Help().Key, "new index"},
+		{Keys.Indexes.Help().Key, "indexes"},
+		{Keys.ViewDefinition.Help().Key, "view sql"},
+		{Keys.PasteRows.Help().Key, "paste rows"},
+		{Keys.CopyMarkdown.Help().Key, "copy as markdown"},
+		{Keys.Zoom.Help().Key, "zoom"},
 		{"esc", "back"},
-		{"q", "quit"},
+		{Keys.Quit.Help().Key, "quit"},
+	}
+	if len(m.sessions) > 0 {
+		hints = slices.Insert(hints, len(hints)-2, helpItem{Keys.SwitchDatabase.Help().Key, "switch db"})
 	}
+	if Keys.FirstPage.Enabled() {
+		hints = slices.Insert(hints, 8,
+			helpItem{Keys.FirstPage.Help().Key + "/" + Keys.LastPage.Help().Key, "first/last page"},
+			helpItem{Keys.HalfPageUp.Help().Key + "/" + Keys.HalfPageDown.Help().Key, "half page"})
+	}
+	// Actions whose keys vim_keys took are left out until rebound.
+	hints = slices.DeleteFunc(hints, func(h helpItem) bool { return h.key == "" })
 	var info string
 	if m.dataLoaded {
-		info = m.tableData.StatusText()
+		info = m.focusedGrid().StatusText()
+	}
+	if m.dbInfo != nil {
+		info = infoSummary(m.dbPath, *m.dbInfo) + " · " + info
+	} else if label := m.dbLabel(); label != "" {
+		info = label + " · " + info
+	}
+	busy := m.busyText()
+	if m.loads.busy() || m.dataLoaded && m.focusedGrid().busy() {
+		if busy == "" {
+			busy = "loading"
+		}
+		busy += ", " + Keys.Cancel.Help().Key + " to cancel"
+	}
+	if busy != "" {
+		info += " · " + busy
+	}
+	if m.note != "" {
+		info += " · " + m.note
+	} else if len(m.edits) > 0 {
+		info += " · " + Keys.Undo.Help().Key + " to undo " + m.edits[len(m.edits)-1].edit.String()
+	}
+	if m.live {
+		info += " · ● live"
+	} else if m.dbChanged {
+		info += " · changed externally, " + Keys.Refresh.Help().Key + " to refresh"
+	}
+	if m.cfg.ShowClipped && m.dataLoaded && m.focused != paneList {
+		// As much as fits, leaving the key hints a third of the bar.
+		if v := m.focusedGrid().clippedText(); v != "" {
+			if room := (m.width-4)*2/3 - lipgloss.Width(info) - 5; room >= 10 {
+				info += " · " + runewidth.Truncate(v, room, "…")
+			}
+		}
 	}
 	status := m.renderStatusBar(info, hints)
+	if m.txn != nil {
+		status = m.txBar() + "\n" + status
+	}
 	statusLines := strings.Count(status, "\n") + 1
 
 	// 3 = top margin (1) + bottom margin (1) + status bar base (1 line already counted in statusLines adjustment)
 	contentH := max(m.height-3-statusLines, 3) - 2
 
-	rightClip := lipgloss.NewStyle().MaxHeight(contentH).MaxWidth(m.rightWidth - 2)
+	// With a pinned grid the right column holds two bordered boxes, so the
+	// data box loses the pinned box's height (content + 2 border lines).
+	dataContentH := contentH
+	var pinnedPanel string
+	if m.pinnedVisible() {
+		pinnedContentH := (contentH+2)/2 - 2
+		dataContentH = contentH - pinnedContentH - 2
+		pinnedClip := lipgloss.NewStyle().MaxHeight(pinnedContentH).MaxWidth(m.rightWidth - 2)
+		pinnedPanel = pinnedStyle.
+			Width(m.rightWidth - 2).
+			Height(pinnedContentH).
+			Render(pinnedClip.Render(m.pinned.View()))
+	}
+
+	rightClip := lipgloss.NewStyle().MaxHeight(dataContentH).MaxWidth(m.rightWidth - 2)
 
 	var rightContent string
-	if m.dataLoaded {
+	if m.zoomed {
+		rightContent = m.focusedGrid().View()
+		rightStyle = FocusedPaneStyle
+	} else if m.dataLoaded {
 		rightContent = m.tableData.View()
 	} else {
 		rightContent = lipgloss.Place(
-			m.rightWidth-2, contentH,
+			m.rightWidth-2, dataContentH,
 			lipgloss.Center, lipgloss.Center,
 			StatusBarStyle.Render("← Select a table"),
 		)
 	}
+	if m.showTabBar() {
+		rightContent = m.renderTabBar() + "\n" + rightContent
+	}
 	rightPanel := rightStyle.
 		Width(m.rightWidth - 2).
-		Height(contentH).
+		Height(dataContentH).
 		Render(rightClip.Render(rightContent))
+	if m.pinnedVisible() {
+		rightPanel = lipgloss.JoinVertical(lipgloss.Left, pinnedPanel, rightPanel)
+	}
 
 	var split string
-	if m.sidebarHidden {
+	if m.sidebarHidden || m.zoomed {
 		split = rightPanel
 	} else {
 		leftClip := lipgloss.NewStyle().MaxHeight(contentH).MaxWidth(m.leftWidth - 2)
@@ -542,36 +2006,393 @@ func (m Model) View() string {
 	)
 
 	if m.showDetail {
-		popup := m.rowDetail.View()
-		return lipgloss.Place(
-			m.width, m.height,
-			lipgloss.Center, lipgloss.Center,
-			popup,
-		)
+		return m.placePopup(m.rowDetail.View())
+	}
+	if m.showInsert {
+		return m.placePopup(m.insertRow.View())
+	}
+	if m.showPasteRows {
+		return m.placePopup(m.pasteRows.View())
+	}
+	if m.showExport {
+		return m.placePopup(m.export.View())
+	}
+	if m.showDBInfo {
+		return m.placePopup(m.dbInfoView.View())
+	}
+	if m.showPragmas {
+		return m.placePopup(m.pragmas.View())
+	}
+	if m.showMaintenance {
+		return m.placePopup(m.maintenance.View())
+	}
+	if m.showColumnStats {
+		return m.placePopup(m.columnStats.View())
+	}
+	if m.showSaveResults {
+		return m.placePopup(m.saveResults.View())
+	}
+	if m.showRelations {
+		return m.placePopup(m.relations.View())
+	}
+	if m.showSpaceUsage {
+		return m.placePopup(m.spaceUsage.View())
+	}
+	if m.showJSONPaths {
+		return m.placePopup(m.jsonPaths.View())
+	}
+	if m.showDataDiff {
+		return m.placePopup(m.dataDiff.View())
+	}
+	if m.showAlterTable {
+		return m.placePopup(m.alterTable.View())
+	}
+	if m.showEmptyTable {
+		return m.placePopup(m.emptyTable.View())
+	}
+	if m.showCreateIndex {
+		return m.placePopup(m.createIndex.View())
+	}
+	if m.showIndexes {
+		return m.placePopup(m.indexes.View())
+	}
+	if m.showViewDef {
+		return m.placePopup(m.viewDef.View())
+	}
+	if m.showViewLink {
+		return m.placePopup(m.viewLinkPopup.View())
+	}
+	if m.showBookmarks {
+		return m.placePopup(m.bookmarkList.View())
 	}
 	if m.showQuery {
-		popup := m.queryInput.View()
-		return lipgloss.Place(
-			m.width, m.height,
-			lipgloss.Center, lipgloss.Center,
-			popup,
-		)
+		return m.placePopup(m.queryInput.View())
 	}
 
 	return base
 }
 
-func loadTableDataCmd(database *sql.DB, tableName string, pageSize int) tea.Cmd {
-	return func() tea.Msg {
-		total, err := db.CountRows(database, tableName)
+// startWatching reserves the data_version connection for the newly opened
+// database and kicks off the polling loop.
+func (m *Model) startWatching() tea.Cmd {
+	m.stopWatching()
+	conn, err := db.OpenWatchConn(context.Background(), m.db)
+	if err != nil {
+		return nil
+	}
+	m.watchConn = conn
+	m.watchGen++
+	m.viewVersion = -1
+	m.dbChanged = false
+	var liveCmd tea.Cmd
+	if m.live {
+		liveCmd = m.startLive()
+	}
+	return tea.Batch(readDataVersionCmd(conn, m.watchGen), dataVersionTickCmd(m.watchGen), liveCmd)
+}
+
+// stopWatching releases the data_version connection and retires its ticks,
+// along with the live-mode file watcher.
+func (m *Model) stopWatching() {
+	m.stopLive()
+	if m.watchConn != nil {
+		m.watchConn.Close()
+		m.watchConn = nil
+	}
+	m.watchGen++
+	m.dbChanged = false
+}
+
+// resetDataVersion marks the current view as fresh: the next poll becomes
+// the new baseline and the change indicator is cleared.
+func (m *Model) resetDataVersion() tea.Cmd {
+	m.viewVersion = -1
+	m.dbChanged = false
+	return readDataVersionCmd(m.watchConn, m.watchGen)
+}
+
+// schemaFresh reports whether the schema cache still describes the file on
+// disk, i.e. nothing has written to it since the cache was taken.
+func (m Model) schemaFresh() bool {
+	if m.schema == nil {
+		return false
+	}
+	key, err := state.FileKeyFor(m.dbPath)
+	return err == nil && key == m.schema.Key
+}
+
+// openPaste opens the paste popup on text, rows pasted into the focused
+// grid's table.
+func (m *Model) openPaste(text string) {
+	grid := m.focusedGrid()
+	if grid.static {
+		m.note = ErrorStyle.Render("pasted rows go into a table, not a view or query result")
+		return
+	}
+	pr, err := NewPasteRowsModel(grid.database, grid.tableName, grid.columns, text, m.width, m.height)
+	if err != nil {
+		m.note = ErrorStyle.Render(err.Error())
+		return
+	}
+	m.pasteRows = pr
+	m.showPasteRows = true
+}
+
+// openQuery opens the query popup holding query, ready to run or edit.
+func (m *Model) openQuery(query string) tea.Cmd {
+	qi, cmd := NewQueryInputModel(m.db, m.cfg.ScanWarnRows, m.cfg.QueryTimeout, m.width, m.height)
+	qi.table = m.lastTableName
+	qi.paramValues = m.paramValues
+	qi.textarea.SetValue(query)
+	m.queryInput = qi
+	m.showQuery = true
+	return cmd
+}
+
+// loadTableCmd loads the first page of a table, reusing the cached row count
+// when the file is unchanged so big tables skip the COUNT(*). A table or
+// view still loading is no longer wanted.
+func (m Model) loadTableCmd(name string) tea.Cmd {
+	m.loads.cancel()
+	if m.tableList.isView(name) {
+		return loadViewCmd(m.loads, m.db, name, m.cfg.QueryTimeout, m.pageSize())
+	}
+	total := -1
+	if m.schemaFresh() {
+		if n, ok := m.schema.Counts[name]; ok {
+			total = n
+		}
+	}
+	return loadTableDataCmd(m.loads, m.db, name, m.pageSize(), total)
+}
+
+// cancelLoads stops the table or view loading and the focused grid's
+// loads and counts, reporting whether any were running.
+func (m *Model) cancelLoads() bool {
+	loading := m.loads.cancel()
+	if !m.dataLoaded {
+		return loading
+	}
+	return m.focusedGrid().cancelLoads() || loading
+}
+
+// tableColumns returns the column names of table, from the schema cache
+// when it has them and the file is unchanged. An open transaction may have
+// altered the table without the file showing it yet.
+func (m Model) tableColumns(table string) ([]string, error) {
+	if m.txn == nil && m.schemaFresh() {
+		if cols, ok := m.schema.Columns[table]; ok {
+			return cols, nil
+		}
+	}
+	return db.GetColumns(context.Background(), m.db, table)
+}
+
+// cacheTableMeta records a table's columns and row count in the schema cache.
+func (m *Model) cacheTableMeta(name string, columns []string, total int) {
+	if !m.schemaFresh() {
+		return
+	}
+	m.schema.Columns[name] = columns
+	if total == unknownTotal {
+		delete(m.schema.Counts, name)
+	} else {
+		m.schema.Counts[name] = total
+	}
+	_ = state.SaveSchemaCache(m.dbPath, m.schema)
+}
+
+// applyRowCount hands a background count to the grid it was run for,
+// caching the table's total once it is known.
+func (m *Model) applyRowCount(msg rowCountMsg) {
+	switch msg.gridID {
+	case m.tableData.id:
+		m.tableData.applyCount(msg)
+		if !msg.filtered && !msg.estimated && !m.tableData.counting && !m.tableData.static {
+			m.cacheTableMeta(m.tableData.tableName, m.tableData.columns, m.tableData.totalRows)
+		}
+	case m.pinned.id:
+		m.pinned.applyCount(msg)
+	default:
+		m.applyBackgroundCount(msg)
+	}
+}
+
+// updateBackground handles the messages of the timers, watches, and page
+// loads running behind the screen. They land whatever popup is open: one
+// dropped would stop its poll or watch for good.
+func (m Model) updateBackground(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case dataVersionTickMsg:
+		if msg.gen != m.watchGen {
+			return m, nil
+		}
+		return m, tea.Batch(readDataVersionCmd(m.watchConn, m.watchGen), dataVersionTickCmd(m.watchGen))
+
+	case tailTickMsg:
+		if !m.tailing() {
+			m.tailTicking = false
+			return m, nil
+		}
+		return m, tea.Batch(m.tailCmd(), tailTickCmd())
+
+	case fileChangedMsg:
+		if msg.gen != m.liveGen || m.fsWatcher == nil {
+			return m, nil
+		}
+		cmds := []tea.Cmd{waitForChangeCmd(m.fsWatcher, watchedNames(db.LocalPath(m.dbPath)), m.liveGen), m.resetDataVersion()}
+		if m.dataLoaded && !m.tableData.static {
+			cmds = append(cmds, m.tableData.reloadCmd())
+		}
+		return m, tea.Batch(cmds...)
+
+	case dataVersionMsg:
+		if msg.gen != m.watchGen {
+			return m, nil
+		}
+		if m.viewVersion < 0 {
+			m.viewVersion = msg.version
+		} else if msg.version != m.viewVersion {
+			m.dbChanged = true
+		}
+		return m, nil
+
+	case pageDataLoadedMsg:
+		switch msg.gridID {
+		case m.tableData.id:
+			m.tableData.applyPage(msg)
+		case m.pinned.id:
+			if !m.showPinned {
+				return m, nil
+			}
+			m.pinned.applyPage(msg)
+		default:
+			if !m.applyBackgroundPage(msg) {
+				return m, nil // grid was replaced while the page was loading
+			}
+		}
+		return m, m.resetDataVersion()
+
+	case tableMeasuresMsg:
+		if msg.database != m.db {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.note = msg.err.Error()
+			return m, nil
+		}
+		m.note = ""
+		m.tableList.setMeasures(msg.sort, msg.measures)
+		return m, m.tableList.setSort(msg.sort)
+	}
+	return m, nil
+}
+
+// placePopup centers a modal popup on the screen.
+func (m Model) placePopup(popup string) string {
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Center, lipgloss.Center,
+		popup,
+	)
+}
+
+// rowWrittenMsg carries the outcome of a delete or update asked for by the
+// detail popup.
+type rowWrittenMsg struct {
+	database *sql.DB
+	edit     db.Edit
+	deleted  bool
+	err      error
+}
+
+// deleteRowCmd deletes the row the detail popup shows, in the background.
+func deleteRowCmd(database *sql.DB, msg DeleteRowMsg) tea.Cmd {
+	return track("deleting row from "+msg.TableName, func() tea.Msg {
+		edit, err := db.DeleteRow(context.Background(), database, msg.TableName, msg.RowID)
+		return rowWrittenMsg{database: database, edit: edit, deleted: true, err: err}
+	})
+}
+
+// updateRowCmd writes the fields edited in the detail popup, in the
+// background.
+func updateRowCmd(database *sql.DB, msg UpdateRowMsg) tea.Cmd {
+	return track("updating row in "+msg.TableName, func() tea.Msg {
+		edit, err := db.UpdateRow(context.Background(), database, msg.TableName, msg.RowID, msg.Columns, msg.Values)
+		return rowWrittenMsg{database: database, edit: edit, err: err}
+	})
+}
+
+// rowWritten applies the outcome of deleteRowCmd or updateRowCmd: it
+// journals the edit and closes the popup, or keeps it open showing why an
+// update failed.
+func (m Model) rowWritten(msg rowWrittenMsg) (tea.Model, tea.Cmd) {
+	m.writingRow = false
+	if msg.database != m.db {
+		return m, nil // the database was closed meanwhile
+	}
+	if msg.err != nil {
+		if msg.deleted || !m.showDetail {
+			m.note = ErrorStyle.Render(msg.err.Error())
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.rowDetail, cmd = m.rowDetail.Update(rowUpdateFailedMsg{err: msg.err})
+		return m, cmd
+	}
+	m.journal(msg.database, msg.edit)
+	m.showDetail = false
+	reload := m.focusedGrid().reloadCmd()
+	if msg.deleted {
+		reload = m.focusedGrid().refreshCmd()
+	}
+	return m, tea.Batch(reload, m.txChangesCmd())
+}
+
+// loadViewCmd reads the first page of a view. A view has no rowids to page
+// by, so its pages are read with LIMIT and OFFSET, like a query result run
+// again.
+func loadViewCmd(loads *loadScope, database *sql.DB, name string, timeout time.Duration, pageSize int) tea.Cmd {
+	return track("reading "+name, loads.run(func(ctx context.Context) tea.Msg {
+		query := "SELECT * FROM " + db.QuoteTable(name)
+		cols, rows, _, err := db.ResultPage(ctx, database, query, nil, timeout, nil, nil, db.ResultFilter{}, pageSize+1, 0)
 		if err != nil {
-			return errMsg{err: err}
+			return noteMsg{err: err}
+		}
+		rows, _, hasMore := trimPage(rows, nil, pageSize)
+		return tableDataLoadedMsg{
+			database:  database,
+			tableName: name,
+			columns:   cols,
+			rows:      rows,
+			pageSize:  pageSize,
+			hasMore:   hasMore,
+			view:      true,
+			query:     query,
+		}
+	}))
+}
+
+// loadTableDataCmd loads the first page of a table. knownTotal is a row count
+// already known to be accurate, or -1 to estimate one from MAX(rowid) and
+// leave COUNT(*) to run in the background once the page is shown.
+func loadTableDataCmd(loads *loadScope, database *sql.DB, tableName string, pageSize, knownTotal int) tea.Cmd {
+	return track("loading "+tableName, loads.run(func(ctx context.Context) tea.Msg {
+		total, estimated := knownTotal, false
+		if total < 0 {
+			n, err := db.EstimateRows(ctx, database, tableName)
+			total, estimated = n, err == nil
+			if err != nil {
+				total = unknownTotal
+			}
 		}
-		cols, rowIDs, rows, err := db.GetRows(database, tableName, pageSize, 0)
+		cols, rowIDs, rows, err := db.GetRows(ctx, database, tableName, db.Order{}, pageSize+1, 0)
 		if err != nil {
 			return errMsg{err: err}
 		}
+		rows, rowIDs, hasMore := trimPage(rows, rowIDs, pageSize)
 		return tableDataLoadedMsg{
+			database:  database,
 			tableName: tableName,
 			columns:   cols,
 			rows:      rows,
@@ -579,6 +2400,8 @@ func loadTableDataCmd(database *sql.DB, tableName string, pageSize int) tea.Cmd
 			page:      0,
 			pageSize:  pageSize,
 			totalRows: total,
+			estimated: estimated,
+			hasMore:   hasMore,
 		}
-	}
+	}))
 }
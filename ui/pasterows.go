@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// rowsPastedMsg reports the rows the paste popup inserted.
+type rowsPastedMsg struct {
+	table string
+	edits []db.Edit
+}
+
+// pasteFailedMsg carries the error of an insert that was rolled back.
+type pasteFailedMsg struct {
+	err error
+}
+
+// PasteRowsModel is the popup inserting rows pasted from a spreadsheet, as
+// tab- or comma-separated lines, into a table. Each pasted column is mapped
+// onto a table column, by name when the first line is a header that names
+// them and by position otherwise, and the mapping can be changed before
+// the rows go in.
+type PasteRowsModel struct {
+	database *sql.DB
+	table    string
+	columns  []string
+	records  [][]string
+	fields   int // of the widest record
+	header   bool
+	mapping  []int // the table column each pasted one goes to, -1 for none
+	nulls    bool  // empty cells become NULL rather than ''
+	cursor   int
+	scroll   int
+	listLen  int
+	running  bool
+	loads    *loadScope // the insert runs under it, so esc can stop it
+	err      error
+	width    int
+}
+
+// NewPasteRowsModel parses text, the pasted lines, for inserting into
+// table.
+func NewPasteRowsModel(database *sql.DB, table string, columns []string, text string, termWidth, termHeight int) (PasteRowsModel, error) {
+	records, err := parsePastedRows(text)
+	if err != nil {
+		return PasteRowsModel{}, err
+	}
+	m := PasteRowsModel{
+		database: database,
+		table:    table,
+		columns:  columns,
+		records:  records,
+		nulls:    true,
+		loads:    newLoadScope(),
+		width:    max(termWidth*70/100, 50),
+		// Border, padding, title, summary, options, gaps, error, and help
+		// take 12 lines.
+		listLen: max(termHeight*70/100-12, 3),
+	}
+	for _, r := range records {
+		m.fields = max(m.fields, len(r))
+	}
+	m.header = m.namesColumns(records[0])
+	m.mapColumns()
+	return m, nil
+}
+
+// parsePastedRows splits pasted text into records: tab-separated if its
+// first line holds a tab, as spreadsheets copy cells, or CSV otherwise.
+func parsePastedRows(text string) ([][]string, error) {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("nothing pasted")
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	first, _, _ := strings.Cut(text, "\n")
+	if strings.Contains(first, "\t") {
+		r.Comma = '\t'
+		r.LazyQuotes = true
+	}
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("can't read the pasted rows: %v", err)
+	}
+	return records, nil
+}
+
+// namesColumns reports whether record, the first pasted line, is a header:
+// every cell in it names a table column.
+func (m PasteRowsModel) namesColumns(record []string) bool {
+	for _, cell := range record {
+		if m.columnIndex(cell) < 0 {
+			return false
+		}
+	}
+	return len(record) > 0
+}
+
+// columnIndex finds the table column named name, ignoring case, or -1.
+func (m PasteRowsModel) columnIndex(name string) int {
+	for i, c := range m.columns {
+		if strings.EqualFold(c, strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mapColumns maps the pasted columns onto the table's afresh: by the
+// header's names if there is one, else in order.
+func (m *PasteRowsModel) mapColumns() {
+	m.mapping = make([]int, m.fields)
+	for i := range m.mapping {
+		switch {
+		case m.header && i < len(m.records[0]):
+			m.mapping[i] = m.columnIndex(m.records[0][i])
+		case !m.header && i < len(m.columns):
+			m.mapping[i] = i
+		default:
+			m.mapping[i] = -1
+		}
+	}
+}
+
+// rows is the pasted records to insert, the header left out.
+func (m PasteRowsModel) rows() [][]string {
+	if m.header {
+		return m.records[1:]
+	}
+	return m.records
+}
+
+func (m PasteRowsModel) Update(msg tea.Msg) (PasteRowsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pasteFailedMsg:
+		m.running = false
+		m.err = msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			if msg.String() == "esc" {
+				m.loads.cancel()
+				m.running = false
+				m.err = errors.New("cancelled: no rows were inserted")
+			}
+			return m, nil
+		}
+		m.err = nil
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, m.fields-1)
+		case "right", "l":
+			// Cycle through the table's columns, then none.
+			if m.mapping[m.cursor]++; m.mapping[m.cursor] == len(m.columns) {
+				m.mapping[m.cursor] = -1
+			}
+		case "left", "h":
+			if m.mapping[m.cursor]--; m.mapping[m.cursor] < -1 {
+				m.mapping[m.cursor] = len(m.columns) - 1
+			}
+		case "H":
+			m.header = !m.header && len(m.records) > 1
+			m.mapColumns()
+		case "n":
+			m.nulls = !m.nulls
+		case "enter":
+			return m.insert()
+		}
+		m.scroll = scrollTo(m.cursor, m.scroll, m.listLen)
+	}
+	return m, nil
+}
+
+// insert puts the rows into the table in the background, in one
+// transaction.
+func (m PasteRowsModel) insert() (PasteRowsModel, tea.Cmd) {
+	var columns []string
+	var from []int
+	taken := map[int]int{}
+	for i, c := range m.mapping {
+		if c < 0 {
+			continue
+		}
+		if j, ok := taken[c]; ok {
+			m.err = fmt.Errorf("pasted columns %d and %d both go to %s", j+1, i+1, m.columns[c])
+			return m, nil
+		}
+		taken[c] = i
+		columns = append(columns, m.columns[c])
+		from = append(from, i)
+	}
+	rows := m.rows()
+	switch {
+	case len(columns) == 0:
+		m.err = errors.New("no pasted column goes to a table column")
+		return m, nil
+	case len(rows) == 0:
+		m.err = errors.New("no rows below the header")
+		return m, nil
+	}
+	values := make([][]any, len(rows))
+	for r, record := range rows {
+		values[r] = make([]any, len(from))
+		for i, f := range from {
+			var cell string
+			if f < len(record) {
+				cell = record[f]
+			}
+			if cell == "" && m.nulls {
+				continue // nil, for NULL
+			}
+			values[r][i] = cell
+		}
+	}
+	m.running = true
+	database, table := m.database, m.table
+	label := fmt.Sprintf("inserting %s rows into %s", groupDigits(int64(len(values))), table)
+	return m, track(label, m.loads.run(func(ctx context.Context) tea.Msg {
+		edits, err := db.InsertRows(ctx, database, table, columns, values)
+		if err != nil {
+			return pasteFailedMsg{err: err}
+		}
+		return rowsPastedMsg{table: table, edits: edits}
+	}))
+}
+
+func (m PasteRowsModel) View() string {
+	n := len(m.rows())
+	rows := "rows"
+	if n == 1 {
+		rows = "row"
+	}
+	summary := fmt.Sprintf("%s %s of %d columns pasted", groupDigits(int64(n)), rows, m.fields)
+	if m.header {
+		summary += ", below a header"
+	}
+
+	labels := make([]string, m.fields)
+	labelW := 0
+	for i := range labels {
+		labels[i] = fmt.Sprintf("column %d", i+1)
+		if m.header && i < len(m.records[0]) {
+			labels[i] = m.records[0][i]
+		}
+		labelW = min(max(labelW, len(labels[i])), 24)
+	}
+	targetW := len("(skip)")
+	for _, c := range m.columns {
+		targetW = max(targetW, len(c))
+	}
+	var b strings.Builder
+	end := min(m.scroll+m.listLen, m.fields)
+	for i := m.scroll; i < end; i++ {
+		target := "(skip)"
+		if c := m.mapping[i]; c >= 0 {
+			target = m.columns[c]
+		}
+		var sample string
+		if rs := m.rows(); len(rs) > 0 && i < len(rs[0]) {
+			sample = truncateValue(rs[0][i], max(m.width-labelW-targetW-20, 8))
+		}
+		line := fmt.Sprintf("%-*s → %-*s  %s", labelW, truncateValue(labels[i], labelW), targetW, target, sample)
+		if i == m.cursor {
+			b.WriteString(TitleStyle.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(StatusBarStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	body := summary + "\n\n" + b.String() + "\n" +
+		check(m.header) + " first row is a header   " + check(m.nulls) + " empty cells as NULL"
+	if m.err != nil {
+		body += "\n\n" + ErrorStyle.Render("Error: "+m.err.Error())
+	}
+
+	help := fitHelp([]string{
+		"↑↓: select",
+		"←→: pick column",
+		"H: header",
+		"n: NULL for empty",
+		"enter: insert",
+		"esc: cancel",
+	}, m.width-6)
+	if m.running {
+		help = "inserting... | esc: cancel"
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(" Paste into "+m.table+" ") + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
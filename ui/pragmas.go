@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// PragmaModel is the popup listing PRAGMA values. Session-scoped ones can
+// be edited in place; persistent ones are shown for reference.
+type PragmaModel struct {
+	database *sql.DB
+	values   []db.PragmaValue
+	cursor   int
+	editing  bool
+	input    textinput.Model
+	err      string
+	width    int
+}
+
+func NewPragmaModel(database *sql.DB, termWidth int) PragmaModel {
+	ti := textinput.New()
+	ti.Prompt = "new value: "
+	m := PragmaModel{
+		database: database,
+		input:    ti,
+		width:    max(termWidth*60/100, 60),
+	}
+	m.reload()
+	return m
+}
+
+// reload re-reads every value, e.g. after one was changed.
+func (m *PragmaModel) reload() {
+	values, err := db.ReadPragmas(context.Background(), m.database)
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.values = values
+}
+
+func (m PragmaModel) Update(msg tea.Msg) (PragmaModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.editing {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if m.editing {
+		switch keyMsg.String() {
+		case "esc":
+			m.editing = false
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			p := m.values[m.cursor]
+			if err := db.SetPragma(m.database, p.Name, m.input.Value()); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.err = ""
+			m.editing = false
+			m.input.Blur()
+			m.reload()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		return m, func() tea.Msg { return CloseDetailMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+		m.err = ""
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.values)-1)
+		m.err = ""
+	case "enter":
+		if m.cursor < len(m.values) && m.values[m.cursor].Session {
+			m.editing = true
+			m.input.SetValue(m.values[m.cursor].Value)
+			m.input.CursorEnd()
+			return m, m.input.Focus()
+		}
+	default:
+		if key.Matches(keyMsg, Keys.Pragmas) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	return m, nil
+}
+
+func (m PragmaModel) View() string {
+	nameW, valueW := 0, 0
+	for _, v := range m.values {
+		nameW = max(nameW, len(v.Name))
+		valueW = max(valueW, len(v.Label()))
+	}
+
+	var b strings.Builder
+	for i, v := range m.values {
+		scope := "read-only"
+		if v.Session {
+			scope = "session"
+		}
+		line := fmt.Sprintf("%-*s  %-*s  %s", nameW, v.Name, valueW, v.Label(), scope)
+		if i == m.cursor {
+			b.WriteString(TitleStyle.Render("▸ "+line) + "\n")
+		} else {
+			b.WriteString(StatusBarStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	detail := " "
+	if m.cursor < len(m.values) {
+		p := m.values[m.cursor]
+		detail = p.Doc
+		if len(p.Choices) > 0 {
+			detail += " — " + strings.Join(p.Choices, ", ")
+		}
+	}
+
+	bottom := StatusBarStyle.Render("↑↓: select | enter: edit session value | esc: close")
+	if m.editing {
+		bottom = m.input.View() + "\n" + StatusBarStyle.Render("enter: apply | esc: cancel")
+	}
+	errLine := " "
+	if m.err != "" {
+		errLine = ErrorStyle.Render("Error: " + m.err)
+	}
+
+	title := TitleStyle.Render(" PRAGMAs ")
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(title + "\n\n" + b.String() + "\n" + StatusBarStyle.Render(detail) + "\n" + errLine + "\n" + bottom)
+}
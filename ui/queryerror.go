@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// updateFailure handles keys while the error of the last query is shown
+// in place of the textarea: esc or enter goes back to the query, with the
+// cursor where the error is.
+func (m QueryInputModel) updateFailure(msg tea.KeyMsg) (QueryInputModel, tea.Cmd) {
+	if msg.String() != "esc" && msg.String() != "enter" {
+		return m, nil
+	}
+	var qe *db.QueryError
+	// The query as run has its placeholders numbered, so the offsets only
+	// hold in the text as typed when it has none.
+	if errors.As(m.failure, &qe) && qe.Query == m.textarea.Value() {
+		if line, col := qe.Position(); line > 0 {
+			for m.textarea.Line() > line-1 {
+				m.textarea.CursorUp()
+			}
+			m.textarea.SetCursor(col - 1)
+		}
+	}
+	m.failure = nil
+	return m, m.textarea.Focus()
+}
+
+// failureView shows the error of the last query in full in place of the
+// textarea, and the query with the part the error is about highlighted.
+func (m QueryInputModel) failureView() string {
+	height := m.textarea.Height()
+	width := m.width - 6
+	lines := strings.Split(ErrorStyle.Width(width).Render(m.failure.Error()), "\n")
+	var qe *db.QueryError
+	if !errors.As(m.failure, &qe) || height-len(lines) < 3 {
+		return padLines(lines, height)
+	}
+	where := "The query:"
+	if line, col := qe.Position(); line > 0 {
+		where = fmt.Sprintf("At line %d, column %d:", line, col)
+	}
+	lines = append(lines, "", StatusBarStyle.Render(where))
+
+	rows, at := markedRows(qe.Query, qe.Start, qe.End, width)
+	room := height - len(lines)
+	from := max(min(at-room/2, len(rows)-room), 0)
+	return padLines(append(lines, rows[from:min(from+room, len(rows))]...), height)
+}
+
+// padLines joins lines, cut or padded to height so the popup keeps its
+// size.
+func padLines(lines []string, height int) string {
+	lines = lines[:min(len(lines), height)]
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markedRows wraps text to width columns, highlighting the bytes from start
+// to end, and says which row start is on.
+func markedRows(text string, start, end, width int) ([]string, int) {
+	mark := ErrorStyle.Reverse(true)
+	var (
+		rows     []string
+		row, run strings.Builder
+		marked   bool
+		w, at    int
+	)
+	flush := func() {
+		if marked {
+			row.WriteString(mark.Render(run.String()))
+		} else {
+			row.WriteString(run.String())
+		}
+		run.Reset()
+	}
+	newRow := func() {
+		flush()
+		rows = append(rows, row.String())
+		row.Reset()
+		w = 0
+	}
+	for i, r := range text {
+		if r == '\n' {
+			newRow()
+			continue
+		}
+		if r == '\t' {
+			r = ' '
+		}
+		rw := runewidth.RuneWidth(r)
+		if w+rw > width {
+			newRow()
+		}
+		if in := i >= start && i < end; in != marked {
+			flush()
+			marked = in
+		}
+		if i == start {
+			at = len(rows)
+		}
+		run.WriteRune(r)
+		w += rw
+	}
+	newRow()
+	return rows, at
+}
@@ -1,35 +1,159 @@
 package ui
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/markovic-nikola/sqlitui/config"
 	"github.com/markovic-nikola/sqlitui/db"
 )
 
 // QueryResultMsg is sent when the user successfully executes a query.
 // The parent model handles this to populate the right pane.
 type QueryResultMsg struct {
+	Query   string
+	Args    []any // bound to the query's parameters
 	Columns []string
 	Rows    [][]string
+	Stats   db.QueryStats
 }
 
+// queryDoneMsg carries the outcome of a query started from the popup. run
+// ties it to the run that started it, so a cancelled query reporting late
+// is dropped.
+type queryDoneMsg struct {
+	run     int
+	query   string
+	args    []any
+	columns []string
+	rows    [][]string
+	stats   db.QueryStats
+	err     error
+}
+
+// queryCheckedMsg carries what the guardrails found about a query before
+// it runs (see warnings), for the run that started the check. query is the
+// text as typed, and exec and args what runs.
+type queryCheckedMsg struct {
+	run     int
+	query   string
+	exec    string
+	args    []any
+	warning string
+}
+
+// QueryTimeoutMsg reports a query timeout set from the popup, which lasts
+// for the rest of the session.
+type QueryTimeoutMsg struct {
+	Timeout time.Duration
+}
+
+// editorDoneMsg carries the query back from the external editor.
+type editorDoneMsg struct {
+	query string
+	err   error
+}
+
+// viewSavedMsg reports the outcome of saving the query as a view.
+type viewSavedMsg struct {
+	name string
+	err  error
+}
+
+// confirmWrites makes the query popup count the rows its UPDATE and DELETE
+// statements would change and ask before running them. Installed from the
+// config by applyConfig.
+var confirmWrites bool
+
+// queryPrompt is a question the query popup asks below the textarea.
+type queryPrompt int
+
+const (
+	promptNone    queryPrompt = iota
+	promptTimeout             // a new query timeout (Keys.QueryTimeout)
+	promptOpen                // a SQL file to load (Keys.OpenFile)
+	promptSnippet             // the snippet to insert (Keys.Snippets), by name
+	promptParam               // the value of the query's next placeholder
+	promptView                // the name to save the query as a view under (Keys.SaveAsView)
+)
+
 // QueryInputModel is the SQL query popup component.
-// It presents a textarea for writing SQL and executes it on ctrl+r.
+// It presents a textarea for writing SQL and executes it on Keys.RunQuery.
+// The query runs in the background, so a runaway one can be cancelled.
 type QueryInputModel struct {
 	textarea textarea.Model
 	queryErr string
+	// failure is the error of the last query, shown in full in place of
+	// the textarea until dismissed.
+	failure  error
 	database *sql.DB
 	width    int
 	height   int
+
+	running  bool
+	checking bool // running the guardrails' checks rather than the query
+	runs     int  // queries and checks started, numbering queryDoneMsg and queryCheckedMsg
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+	started  time.Time
+
+	// timeout bounds each query (0 = none).
+	timeout time.Duration
+
+	lastFile string // the SQL file loaded last, offered again by Keys.OpenFile
+
+	// table is the grid's table, which snippets refer to as snippetTable.
+	table         string
+	snippets      []config.Snippet
+	snippetCursor int // among the snippets matching the filter
+	snippetScroll int
+
+	// prompt is the one-line question being asked, if any, answered in
+	// promptInput in place of the error line.
+	prompt      queryPrompt
+	promptInput textinput.Model
+
+	// Placeholders: params are those of paramsFor, the query being
+	// submitted, whose values are asked for in turn and collected in typed.
+	// argsFor is the query text args were typed for, kept until it runs so
+	// a warning doesn't ask again. paramValues is the value last typed for
+	// each name, offered next time; the parent model shares its map.
+	params      []db.Param
+	paramsFor   string
+	typed       []string
+	args        []any
+	argsFor     string
+	paramValues map[string]string
+
+	// Guardrails: scanWarnRows is the row threshold for the full-scan
+	// warning (0 = off), and confirmWrites counts the rows UPDATEs and
+	// DELETEs would change before running them. warned is the query text
+	// the current warning is about; running the same text again overrides
+	// the warning.
+	scanWarnRows int
+	warning      string
+	warned       string
 }
 
 // NewQueryInputModel creates the popup, sized ~70% wide x ~50% tall.
-// Returns a tea.Cmd for the textarea cursor blink.
-func NewQueryInputModel(database *sql.DB, termWidth, termHeight int) (QueryInputModel, tea.Cmd) {
+// scanWarnRows enables the full-scan warning for tables above that many
+// rows; timeout cancels queries that run longer. Returns a tea.Cmd for the
+// textarea cursor blink.
+func NewQueryInputModel(database *sql.DB, scanWarnRows int, timeout time.Duration, termWidth, termHeight int) (QueryInputModel, tea.Cmd) {
 	popupWidth := termWidth * 70 / 100
 	popupHeight := termHeight * 50 / 100
 	if popupWidth < 50 {
@@ -59,53 +183,506 @@ func NewQueryInputModel(database *sql.DB, termWidth, termHeight int) (QueryInput
 	cmd := ta.Focus()
 
 	return QueryInputModel{
-		textarea: ta,
-		database: database,
-		width:    popupWidth,
-		height:   popupHeight,
+		textarea:     ta,
+		database:     database,
+		width:        popupWidth,
+		height:       popupHeight,
+		scanWarnRows: scanWarnRows,
+		spinner:      newSpinner(),
+		timeout:      timeout,
+		promptInput:  textinput.New(),
+		paramValues:  map[string]string{},
 	}, cmd
 }
 
 func (m QueryInputModel) Update(msg tea.Msg) (QueryInputModel, tea.Cmd) {
 	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			return m, func() tea.Msg { return CloseDetailMsg{} }
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
 
-		case "ctrl+r":
-			query := m.textarea.Value()
-			if query == "" {
-				return m, nil
+	case queryDoneMsg:
+		if !m.running || msg.run != m.runs {
+			return m, nil
+		}
+		m.running = false
+		m.cancel = nil
+		if msg.err != nil {
+			m.queryErr = msg.err.Error()
+			m.failure = msg.err
+			m.textarea.Blur()
+			return m, nil
+		}
+		return m, func() tea.Msg {
+			return QueryResultMsg{Query: msg.query, Args: msg.args, Columns: msg.columns, Rows: msg.rows, Stats: msg.stats}
+		}
+
+	case queryCheckedMsg:
+		if !m.running || msg.run != m.runs {
+			return m, nil
+		}
+		m.running = false
+		m.checking = false
+		m.cancel = nil
+		if msg.warning != "" {
+			m.warning = msg.warning
+			m.warned = msg.query
+			return m, nil
+		}
+		m.warning = ""
+		m.argsFor = ""
+		return m.run(msg.exec, msg.args)
+
+	case viewSavedMsg:
+		// Saved, the parent closes the popup.
+		m.queryErr = msg.err.Error()
+		return m, nil
+
+	case editorDoneMsg:
+		if msg.err != nil {
+			m.queryErr = msg.err.Error()
+			return m, nil
+		}
+		m.queryErr = ""
+		m.textarea.SetValue(msg.query)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.running {
+			if msg.String() == "esc" || key.Matches(msg, Keys.Cancel) {
+				m.cancel()
+				m.cancel = nil
+				m.running = false
+				m.checking = false
+				m.queryErr = "query cancelled"
 			}
-			cols, rows, err := db.ExecQuery(m.database, query)
-			if err != nil {
+			return m, nil
+		}
+		if m.failure != nil {
+			return m.updateFailure(msg)
+		}
+		if m.prompt != promptNone {
+			return m.updatePrompt(msg)
+		}
+		if key.Matches(msg, Keys.ExternalEditor) {
+			return m, editQueryCmd(m.textarea.Value())
+		}
+		if key.Matches(msg, Keys.QueryTimeout) {
+			value := ""
+			if m.timeout > 0 {
+				value = m.timeout.String()
+			}
+			return m.ask(promptTimeout, "timeout: ", "e.g. 30s, 2m, or 0 for none", value)
+		}
+		if key.Matches(msg, Keys.OpenFile) {
+			return m.ask(promptOpen, "open: ", "path to a .sql file", m.lastFile)
+		}
+		if key.Matches(msg, Keys.Snippets) {
+			return m.openSnippets()
+		}
+		if key.Matches(msg, Keys.SaveAsView) {
+			if _, err := db.ViewQuery(m.textarea.Value()); err != nil {
 				m.queryErr = err.Error()
 				return m, nil
 			}
-			return m, func() tea.Msg {
-				return QueryResultMsg{Columns: cols, Rows: rows}
-			}
+			return m.ask(promptView, "view name: ", "saved as CREATE VIEW name AS <query>", "")
+		}
+		if key.Matches(msg, Keys.RunQuery) {
+			return m.submit(m.textarea.Value())
+		}
+		if msg.String() == "esc" {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
 		}
 	}
 
 	var cmd tea.Cmd
 	m.textarea, cmd = m.textarea.Update(msg)
+	if m.warning != "" && m.textarea.Value() != m.warned {
+		m.warning = "" // edited since the warning; check again on the next run
+		m.warned = ""
+	}
+	return m, cmd
+}
+
+// editQueryCmd suspends the TUI and opens query in $VISUAL or $EDITOR
+// (vi if neither is set), reading the file back once the editor exits.
+func editQueryCmd(query string) tea.Cmd {
+	f, err := os.CreateTemp("", "sqlitui-*.sql")
+	if err == nil {
+		_, err = f.WriteString(query)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		return func() tea.Msg { return editorDoneMsg{err: err} }
+	}
+	path := f.Name()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	// The variable may carry arguments, like "code --wait".
+	args := append(strings.Fields(editor), path)
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		defer os.Remove(path)
+		if err != nil {
+			return editorDoneMsg{err: fmt.Errorf("%s: %w", args[0], err)}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return editorDoneMsg{err: err}
+		}
+		return editorDoneMsg{query: strings.TrimRight(string(data), "\n")}
+	})
+}
+
+// ask moves the focus to a one-line prompt, starting from value.
+func (m QueryInputModel) ask(p queryPrompt, prompt, placeholder, value string) (QueryInputModel, tea.Cmd) {
+	m.prompt = p
+	m.queryErr = ""
+	m.promptInput.Prompt = prompt
+	m.promptInput.Placeholder = placeholder
+	m.promptInput.SetValue(value)
+	m.promptInput.CursorEnd()
+	m.textarea.Blur()
+	return m, m.promptInput.Focus()
+}
+
+// closePrompt hands the focus back to the textarea.
+func (m QueryInputModel) closePrompt() (QueryInputModel, tea.Cmd) {
+	m.prompt = promptNone
+	m.promptInput.Blur()
+	return m, m.textarea.Focus()
+}
+
+// updatePrompt handles keys while a prompt is open. esc keeps things as
+// they were. For a timeout, enter applies it; for a file, enter loads it
+// into the textarea and Keys.RunQuery loads and runs it.
+func (m QueryInputModel) updatePrompt(msg tea.KeyMsg) (QueryInputModel, tea.Cmd) {
+	if m.prompt == promptSnippet {
+		return m.updateSnippets(msg)
+	}
+	value := m.promptInput.Value()
+	switch {
+	case msg.String() == "esc":
+		return m.closePrompt()
+
+	case m.prompt == promptParam && (msg.String() == "enter" || key.Matches(msg, Keys.RunQuery)):
+		m.paramValues[m.params[len(m.typed)].Name] = value
+		m.typed = append(m.typed, value)
+		if len(m.typed) < len(m.params) {
+			return m.askParam()
+		}
+		m.args = db.BindParams(m.params, m.typed)
+		m.argsFor = m.paramsFor
+		m, cmd := m.closePrompt()
+		m, runCmd := m.submit(m.paramsFor)
+		return m, tea.Batch(cmd, runCmd)
+
+	case m.prompt == promptTimeout && msg.String() == "enter":
+		d, err := parseTimeout(value)
+		if err != nil {
+			m.queryErr = err.Error()
+			return m, nil
+		}
+		m.timeout = d
+		m.queryErr = ""
+		m, cmd := m.closePrompt()
+		return m, tea.Batch(cmd, func() tea.Msg { return QueryTimeoutMsg{Timeout: d} })
+
+	case m.prompt == promptView && msg.String() == "enter":
+		name := strings.TrimSpace(value)
+		if name == "" {
+			return m, nil
+		}
+		database, query := m.database, m.textarea.Value()
+		m, cmd := m.closePrompt()
+		return m, tea.Batch(cmd, func() tea.Msg {
+			return viewSavedMsg{name: name, err: db.CreateView(context.Background(), database, name, query)}
+		})
+
+	case m.prompt == promptOpen && (msg.String() == "enter" || key.Matches(msg, Keys.RunQuery)):
+		query, err := readSQLFile(value)
+		if err != nil {
+			m.queryErr = err.Error()
+			return m, nil
+		}
+		m.lastFile = value
+		m.queryErr = ""
+		m.warning, m.warned = "", ""
+		m.textarea.SetValue(query)
+		m, cmd := m.closePrompt()
+		if msg.String() == "enter" {
+			return m, cmd
+		}
+		m, runCmd := m.submit(query)
+		return m, tea.Batch(cmd, runCmd)
+	}
+	m.queryErr = ""
+	var cmd tea.Cmd
+	m.promptInput, cmd = m.promptInput.Update(msg)
 	return m, cmd
 }
 
+// readSQLFile reads the query in the file at path; a leading "~/" stands
+// for the home directory.
+func readSQLFile(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("no file given")
+	}
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// expandHome replaces a leading "~/" in path with the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// fitHelp joins help items with " | ", leaving out items before the last
+// (from the end) until the line fits in width.
+func fitHelp(items []string, width int) string {
+	line := strings.Join(items, " | ")
+	for len(items) > 2 && lipgloss.Width(line) > width {
+		items = append(items[:len(items)-2], items[len(items)-1])
+		line = strings.Join(items, " | ")
+	}
+	return line
+}
+
+// parseTimeout reads a query timeout: a Go duration like "30s", or 0, off,
+// or nothing for none.
+func parseTimeout(s string) (time.Duration, error) {
+	switch s = strings.TrimSpace(s); s {
+	case "", "0", "off":
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("timeout %q is not a duration like 30s or 2m", s)
+	}
+	return d, nil
+}
+
+// submit runs query, once the values of its placeholders are typed,
+// unless it would scan a large table or change rows and hasn't been warned
+// about yet.
+func (m QueryInputModel) submit(query string) (QueryInputModel, tea.Cmd) {
+	if query == "" {
+		return m, nil
+	}
+	exec, params := db.Params(query)
+	var args []any
+	if len(params) > 0 {
+		if query != m.argsFor {
+			m.params, m.paramsFor, m.typed = params, query, nil
+			return m.askParam()
+		}
+		args = m.args
+	}
+	if query != m.warned && (m.scanWarnRows > 0 || confirmWrites) {
+		return m.check(query, exec, args)
+	}
+	m.warning = ""
+	m.argsFor = ""
+	return m.run(exec, args)
+}
+
+// check runs the guardrails' checks on exec in the background, since
+// counting the rows a query would change can take as long as the query;
+// the query runs when queryCheckedMsg finds nothing to warn about. It can
+// be cancelled like the query.
+func (m QueryInputModel) check(query, exec string, args []any) (QueryInputModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.checking = true
+	m.started = time.Now()
+	m.queryErr = ""
+	m.runs++
+	run := m.runs
+	checked := func() tea.Msg {
+		defer cancel()
+		return queryCheckedMsg{run: run, query: query, exec: exec, args: args, warning: m.warnings(ctx, exec, args)}
+	}
+	return m, tea.Batch(track("checking query", checked), m.spinner.Tick)
+}
+
+// askParam asks for the value of the next placeholder without one.
+func (m QueryInputModel) askParam() (QueryInputModel, tea.Cmd) {
+	p := m.params[len(m.typed)]
+	prompt := fmt.Sprintf("%s (%d/%d): ", p.Name, len(m.typed)+1, len(m.params))
+	return m.ask(promptParam, prompt, "42, 'text', or NULL", m.paramValues[p.Name])
+}
+
+// run executes query with args in the background until it finishes or is
+// cancelled.
+func (m QueryInputModel) run(query string, args []any) (QueryInputModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.running = true
+	m.started = time.Now()
+	m.queryErr = ""
+	m.runs++
+	database, run, timeout := m.database, m.runs, m.timeout
+	exec := func() tea.Msg {
+		defer cancel()
+		cols, rows, stats, err := db.ExecQuery(ctx, database, query, timeout, args...)
+		return queryDoneMsg{run: run, query: query, args: args, columns: cols, rows: rows, stats: stats, err: err}
+	}
+	return m, tea.Batch(track("running query", exec), m.spinner.Tick)
+}
+
+// warnings joins what the guardrails have to say about query, or returns
+// "" when it can just run.
+func (m QueryInputModel) warnings(ctx context.Context, query string, args []any) string {
+	var parts []string
+	if m.scanWarnRows > 0 {
+		if w := m.scanWarning(ctx, query, args); w != "" {
+			parts = append(parts, w)
+		}
+	}
+	if confirmWrites {
+		if w := m.impactWarning(ctx, query, args); w != "" {
+			parts = append(parts, w)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	w := strings.Join(parts, "; ")
+	return strings.ToUpper(w[:1]) + w[1:] + " — " + Keys.RunQuery.Help().Key + " again to run anyway"
+}
+
+// scanWarning describes the large tables query would read in full, or
+// returns "" when the plan is fine or can't be determined — in which case
+// the query just runs and reports its own errors.
+func (m QueryInputModel) scanWarning(ctx context.Context, query string, args []any) string {
+	scans, err := db.FullScans(ctx, m.database, query, int64(m.scanWarnRows), args...)
+	if err != nil || len(scans) == 0 {
+		return ""
+	}
+	parts := make([]string, len(scans))
+	for i, s := range scans {
+		parts[i] = fmt.Sprintf("%s (~%d rows)", s.Table, s.Rows)
+	}
+	return "Full scan of " + strings.Join(parts, ", ")
+}
+
+// impactWarning tells how many rows the UPDATEs and DELETEs in query would
+// change, or returns "" when they change none. When they can't be counted it
+// says so, so the query still waits to be confirmed.
+func (m QueryInputModel) impactWarning(ctx context.Context, query string, args []any) string {
+	impacts, err := db.Impacts(ctx, m.database, query, args...)
+	if err != nil {
+		return "can't tell how many rows this changes (" + err.Error() + ")"
+	}
+	var parts []string
+	for _, im := range impacts {
+		rows := "rows"
+		if im.Rows == 1 {
+			rows = "row"
+		}
+		if im.Rows > 0 {
+			parts = append(parts, fmt.Sprintf("%s %s %s of %s", strings.ToLower(im.Verb), groupDigits(im.Rows), rows, im.Table))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "this will " + strings.Join(parts, ", ")
+}
+
+// groupDigits writes n with commas between groups of three digits.
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0 && s[i-1] != '-'; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
 func (m QueryInputModel) View() string {
 	title := TitleStyle.Render(" SQL Query ")
-	help := StatusBarStyle.Render("ctrl+r: run | esc: close")
+	timeout := "none"
+	if m.timeout > 0 {
+		timeout = m.timeout.String()
+		title += StatusBarStyle.Render(" timeout " + timeout)
+	}
+	help := StatusBarStyle.Render(fitHelp([]string{
+		Keys.RunQuery.Help().Key + ": run",
+		Keys.OpenFile.Help().Key + ": open",
+		Keys.Snippets.Help().Key + ": snippets",
+		Keys.SaveAsView.Help().Key + ": save as view",
+		Keys.ExternalEditor.Help().Key + ": editor",
+		Keys.QueryTimeout.Help().Key + ": timeout",
+		"esc: close",
+	}, m.width-6))
 
 	// Always reserve the error line to prevent layout jumps.
 	errLine := " "
-	if m.queryErr != "" {
+	if m.prompt != promptNone {
+		errLine = m.promptInput.View()
+		if m.queryErr != "" {
+			errLine += "  " + ErrorStyle.Render(m.queryErr)
+		}
+		switch m.prompt {
+		case promptTimeout:
+			help = StatusBarStyle.Render("enter: set | esc: keep " + timeout)
+		case promptSnippet:
+			help = StatusBarStyle.Render("↑↓: select | enter: insert | esc: back")
+		case promptView:
+			help = StatusBarStyle.Render("enter: create view | esc: cancel")
+		case promptParam:
+			next := "next"
+			if len(m.typed) == len(m.params)-1 {
+				next = "run"
+			}
+			help = StatusBarStyle.Render("enter: " + next + " | esc: cancel")
+		default:
+			help = StatusBarStyle.Render("enter: load | " + Keys.RunQuery.Help().Key + ": load and run | esc: cancel")
+		}
+	} else if m.running {
+		label := "running"
+		if m.checking {
+			label = "checking"
+		}
+		errLine = spinnerLine(m.spinner, label, m.started)
+		help = StatusBarStyle.Render("esc/" + Keys.Cancel.Help().Key + ": cancel")
+	} else if m.failure != nil {
+		help = StatusBarStyle.Render("esc/enter: back to the query")
+	} else if m.queryErr != "" {
 		errLine = ErrorStyle.Render("Error: " + m.queryErr)
+	} else if m.warning != "" {
+		errLine = ErrorStyle.Render("Warning: " + m.warning)
 	}
 
+	body := m.textarea.View()
+	if m.prompt == promptSnippet {
+		body = m.snippetsView()
+	} else if m.failure != nil {
+		body = m.failureView()
+	}
 	return PopupStyle.
 		Width(m.width - 2).
 		Height(m.height - 2).
-		Render(title + "\n\n" + m.textarea.View() + "\n" + errLine + "\n" + help)
+		Render(title + "\n\n" + body + "\n" + errLine + "\n" + help)
 }
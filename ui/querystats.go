@@ -0,0 +1,42 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxPlanSteps is how many query plan steps the status bar lists.
+const maxPlanSteps = 4
+
+// statsText sums up how the grid's query went for the status bar: "3.21ms
+// (was 41.5ms) · 5 changed · scan users, sort".
+func (m TableDataModel) statsText() string {
+	s := m.queryStats
+	text := roundElapsed(s.Elapsed).String()
+	if m.prevElapsed > 0 {
+		text += " (was " + roundElapsed(m.prevElapsed).String() + ")"
+	}
+	if s.Changes > 0 {
+		text += " · " + groupDigits(s.Changes) + " changed"
+	}
+	if len(s.Plan) > 0 {
+		steps := s.Plan
+		if len(steps) > maxPlanSteps {
+			steps = append(steps[:maxPlanSteps:maxPlanSteps], fmt.Sprintf("+%d more", len(s.Plan)-maxPlanSteps))
+		}
+		text += " · " + strings.Join(steps, ", ")
+	}
+	return text
+}
+
+// roundElapsed keeps about three digits of d.
+func roundElapsed(d time.Duration) time.Duration {
+	switch {
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond)
+	case d < time.Second:
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// relationLink is a foreign key as seen from one of its two tables:
+// outgoing when that table holds the key, incoming when it is referenced.
+type relationLink struct {
+	fk       db.ForeignKey
+	outgoing bool
+}
+
+// other is the table at the far end of the link.
+func (l relationLink) other() string {
+	if l.outgoing {
+		return l.fk.RefTable
+	}
+	return l.fk.Table
+}
+
+// RelationsModel is the popup summarizing which tables reference which:
+// pick a table on the left to see its foreign keys and the keys pointing
+// at it, and follow one to the table at its other end.
+type RelationsModel struct {
+	tables []string
+	fks    []db.ForeignKey
+	cursor int
+	scroll int
+
+	inLinks    bool // the cursor is in the selected table's links
+	linkCursor int
+
+	width   int
+	listLen int // lines visible at once
+}
+
+func NewRelationsModel(database *sql.DB, tables []string, termWidth, termHeight int) (RelationsModel, error) {
+	fks, err := db.ForeignKeys(context.Background(), database)
+	if err != nil {
+		return RelationsModel{}, err
+	}
+	return RelationsModel{
+		tables: tables,
+		fks:    fks,
+		width:  max(termWidth*70/100, 60),
+		// Border, padding, title, gap, and help take 8 lines.
+		listLen: max(termHeight*70/100-8, 5),
+	}, nil
+}
+
+// links lists the foreign keys of table, then those referencing it.
+func (m RelationsModel) links(table string) []relationLink {
+	var out, in []relationLink
+	for _, fk := range m.fks {
+		if fk.Table == table {
+			out = append(out, relationLink{fk: fk, outgoing: true})
+		}
+		if fk.RefTable == table {
+			in = append(in, relationLink{fk: fk})
+		}
+	}
+	return append(out, in...)
+}
+
+func (m RelationsModel) selected() string {
+	if len(m.tables) == 0 {
+		return ""
+	}
+	return m.tables[m.cursor]
+}
+
+func (m RelationsModel) Update(msg tea.Msg) (RelationsModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if len(m.tables) == 0 {
+		if keyMsg.String() == "esc" || key.Matches(keyMsg, Keys.Relationships) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+		return m, nil
+	}
+
+	if m.inLinks {
+		links := m.links(m.selected())
+		switch keyMsg.String() {
+		case "esc", "left", "tab":
+			m.inLinks = false
+		case "up", "k":
+			m.linkCursor = max(m.linkCursor-1, 0)
+		case "down", "j":
+			m.linkCursor = min(m.linkCursor+1, len(links)-1)
+		case "enter":
+			// Follow the link: its far table becomes the selection.
+			if i := slices.Index(m.tables, links[m.linkCursor].other()); i >= 0 {
+				m.cursor = i
+				m.inLinks = false
+				m.keepVisible()
+			}
+		case "o":
+			name := links[m.linkCursor].other()
+			return m, func() tea.Msg { return TableSelectedMsg{Name: name} }
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		return m, func() tea.Msg { return CloseDetailMsg{} }
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.tables)-1)
+	case "pgup":
+		m.cursor = max(m.cursor-m.listLen, 0)
+	case "pgdown":
+		m.cursor = min(m.cursor+m.listLen, len(m.tables)-1)
+	case "home":
+		m.cursor = 0
+	case "end":
+		m.cursor = len(m.tables) - 1
+	case "enter", "right", "tab":
+		if len(m.links(m.selected())) > 0 {
+			m.inLinks = true
+			m.linkCursor = 0
+		}
+	case "o":
+		name := m.selected()
+		return m, func() tea.Msg { return TableSelectedMsg{Name: name} }
+	default:
+		if key.Matches(keyMsg, Keys.Relationships) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	m.keepVisible()
+	return m, nil
+}
+
+// keepVisible scrolls the table list to the cursor.
+func (m *RelationsModel) keepVisible() {
+	if m.cursor < m.scroll {
+		m.scroll = m.cursor
+	} else if m.cursor >= m.scroll+m.listLen {
+		m.scroll = m.cursor - m.listLen + 1
+	}
+}
+
+func (m RelationsModel) View() string {
+	title := TitleStyle.Render(fmt.Sprintf(" Relationships (%d foreign keys) ", len(m.fks)))
+	if len(m.tables) == 0 {
+		return PopupStyle.Width(m.width - 2).Render(title + "\n\n" + "No tables.\n\n" + StatusBarStyle.Render("esc: close"))
+	}
+
+	// Table list, each name followed by its outgoing and incoming counts.
+	nameWidth := 0
+	for _, t := range m.tables {
+		nameWidth = max(nameWidth, len([]rune(t)))
+	}
+	nameWidth = min(nameWidth, (m.width-6)/3)
+	var list strings.Builder
+	end := min(m.scroll+m.listLen, len(m.tables))
+	for i := m.scroll; i < end; i++ {
+		t := m.tables[i]
+		out, in := 0, 0
+		for _, fk := range m.fks {
+			if fk.Table == t {
+				out++
+			}
+			if fk.RefTable == t {
+				in++
+			}
+		}
+		name := truncateValue(t, nameWidth)
+		name += strings.Repeat(" ", nameWidth-len([]rune(name)))
+		counts := "     "
+		if out+in > 0 {
+			counts = fmt.Sprintf("→%d ←%d", out, in)
+		}
+		switch {
+		case i == m.cursor && !m.inLinks:
+			list.WriteString(TitleStyle.Render("▸ "+name) + " " + StatusBarStyle.Render(counts))
+		case i == m.cursor:
+			list.WriteString(PopupLabelStyle.Render("▸ "+name) + " " + StatusBarStyle.Render(counts))
+		default:
+			list.WriteString("  " + name + " " + StatusBarStyle.Render(counts))
+		}
+		if i < end-1 {
+			list.WriteString("\n")
+		}
+	}
+
+	left := list.String()
+	detailWidth := max(m.width-6-lipgloss.Width(left)-3, 20)
+	right := m.detailView(detailWidth)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left, "   ", right)
+
+	help := "↑↓: table | enter: links | o: open table | esc: close"
+	if m.inLinks {
+		help = "↑↓: link | enter: go to table | o: open table | esc: back"
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(title + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+// detailView draws the selected table's links: the tables it references,
+// then the ones referencing it, scrolled to keep the link cursor in view.
+func (m RelationsModel) detailView(width int) string {
+	table := m.selected()
+	links := m.links(table)
+
+	lines := []string{TitleStyle.Render(truncateValue(table, width))}
+	cursorLine := 0
+	section := func(label string, outgoing bool) {
+		lines = append(lines, PopupLabelStyle.Render(label))
+		n := 0
+		for i, l := range links {
+			if l.outgoing != outgoing {
+				continue
+			}
+			n++
+			line := truncateValue(formatRelationLink(l), width-2)
+			if m.inLinks && i == m.linkCursor {
+				cursorLine = len(lines)
+				line = TitleStyle.Render("▸ " + line)
+			} else {
+				line = "  " + line
+			}
+			lines = append(lines, line)
+		}
+		if n == 0 {
+			lines = append(lines, StatusBarStyle.Render("  none"))
+		}
+	}
+	section("references", true)
+	section("referenced by", false)
+
+	start := max(0, min(cursorLine-m.listLen+1, len(lines)-m.listLen))
+	return strings.Join(lines[start:min(start+m.listLen, len(lines))], "\n")
+}
+
+// formatRelationLink describes l as "from → to": "user_id → users(id)" for
+// a table's own key, "orders(user_id) → id" for one referencing it, with
+// any declared ON DELETE / ON UPDATE actions after it.
+func formatRelationLink(l relationLink) string {
+	to := strings.Join(l.fk.To, ", ")
+	if to == "" {
+		to = "primary key"
+	}
+	var s string
+	if l.outgoing {
+		s = strings.Join(l.fk.From, ", ") + " → " + l.fk.RefTable + "(" + to + ")"
+	} else {
+		s = l.fk.Table + "(" + strings.Join(l.fk.From, ", ") + ") → " + to
+	}
+	if l.fk.OnDelete != "NO ACTION" {
+		s += " · on delete " + strings.ToLower(l.fk.OnDelete)
+	}
+	if l.fk.OnUpdate != "NO ACTION" {
+		s += " · on update " + strings.ToLower(l.fk.OnUpdate)
+	}
+	return s
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// resultRerunMsg carries a page of a query result or view run again by
+// rerunCmd, for the grid gridID.
+type resultRerunMsg struct {
+	gridID  int
+	columns []string
+	rows    [][]string
+	stats   db.QueryStats
+	page    int
+	hasMore bool
+}
+
+// rerunnable reports whether the grid's rows came from a query that can
+// run again without doing anything but reading: a single SELECT that
+// changed no rows when it ran.
+func (m TableDataModel) rerunnable() bool {
+	if m.sourceQuery == "" || m.queryStats != nil && m.queryStats.Changes > 0 {
+		return false
+	}
+	_, err := db.ViewQuery(m.sourceQuery)
+	return err == nil
+}
+
+// resultQuery is the SQL giving the grid's rows in the order shown: its
+// source query, sorted by the columns picked with Keys.Sort.
+func (m TableDataModel) resultQuery() (string, error) {
+	return db.SortedQuery(m.sourceQuery, m.columns, m.sort)
+}
+
+// resultFilter is the filter keeping the rows of the grid's source query
+// that match query in the filter column; with query "" it keeps them all.
+func (m TableDataModel) resultFilter(query string) db.ResultFilter {
+	return db.ResultFilter{Column: slices.Index(m.columns, m.fCol), Query: query, Mode: m.fMode}
+}
+
+// rerunCmd runs the grid's source query again, with the same arguments and
+// timeout, for the page shown, sorted and filtered as the grid is. From
+// then on the grid is paged, reading its rows from the query a page at a
+// time. It runs with the grid's loads, so Keys.Cancel stops it like a
+// query run from the SQL popup.
+func (m TableDataModel) rerunCmd() tea.Cmd {
+	if !m.rerunnable() {
+		return noteCmd(errors.New("only a single SELECT that changed no rows runs again; run the query from the SQL popup"))
+	}
+	m.loads.cancel() // a run still going is superseded
+	database, id, query, args, timeout := m.database, m.id, m.sourceQuery, m.sourceArgs, m.sourceTimeout
+	columns, sort, pageSize := m.columns, m.sort, max(m.pageRows, 1)
+	var f db.ResultFilter
+	if m.fActive {
+		f = m.resultFilter(m.fQuery)
+	}
+	page := 0
+	if m.paged {
+		page = m.page
+	}
+	return track("running query", m.loads.run(func(ctx context.Context) tea.Msg {
+		cols, rows, stats, err := db.ResultPage(ctx, database, query, args, timeout, columns, sort, f, pageSize+1, page*pageSize)
+		if err != nil {
+			return noteMsg{err: err}
+		}
+		rows, _, hasMore := trimPage(rows, nil, pageSize)
+		return resultRerunMsg{gridID: id, columns: cols, rows: rows, stats: stats, page: page, hasMore: hasMore}
+	}))
+}
+
+// applyRerun installs the page of a query run again in place of the old
+// rows, keeping the filter, the sort, and the cursor's row position, and
+// counts the rows again.
+func (m *TableDataModel) applyRerun(msg resultRerunMsg) tea.Cmd {
+	cursor := m.table.Cursor()
+	m.columns = msg.columns
+	m.sort = slices.DeleteFunc(slices.Clone(m.sort), func(k db.SortKey) bool { return m.sortColumn(k) < 0 })
+	m.paged = true
+	m.staticRows = nil
+	m.allRows = msg.rows
+	m.allRowIDs = nil
+	m.page = msg.page
+	m.pageSize = max(m.pageRows, 1)
+	m.hasMore = msg.hasMore
+	m.fFiltered = m.fActive
+	m.uncounted = false
+	if m.queryStats != nil {
+		m.prevElapsed = m.queryStats.Elapsed
+		m.queryStats = &msg.stats
+	}
+	// The query may now have other columns, or other values to fit.
+	m.refitColumns()
+	m.setTableRows(m.allRows)
+	m.table.SetCursor(min(cursor, max(len(m.allRows)-1, 0)))
+	m.totalRows = unknownTotal
+	cmds := []tea.Cmd{m.countCmd()}
+	if m.fActive {
+		cmds = append(cmds, m.filterCountCmd(m.fQuery))
+	}
+	return tea.Batch(cmds...)
+}
+
+// resultPage reads a page of a paged grid's source query, sorted as the
+// grid is and filtered by query in the filter column unless it is "".
+func (m TableDataModel) resultPage(query string, page int) func(ctx context.Context) (pageDataLoadedMsg, error) {
+	database, source, args, timeout := m.database, m.sourceQuery, m.sourceArgs, m.sourceTimeout
+	columns, sort, f, pageSize := m.columns, m.sort, m.resultFilter(query), m.pageSize
+	return func(ctx context.Context) (pageDataLoadedMsg, error) {
+		_, rows, _, err := db.ResultPage(ctx, database, source, args, timeout, columns, sort, f, pageSize+1, page*pageSize)
+		if err != nil {
+			return pageDataLoadedMsg{}, err
+		}
+		rows, _, hasMore := trimPage(rows, nil, pageSize)
+		return pageDataLoadedMsg{rows: rows, page: page, pageSize: pageSize, hasMore: hasMore, filtered: query != ""}, nil
+	}
+}
+
+// resultPageCmd loads a page of a paged grid, filtered by query unless it
+// is "".
+func (m TableDataModel) resultPageCmd(query string, page int, cursorEnd bool) tea.Cmd {
+	load, id := m.resultPage(query, page), m.id
+	return track(fmt.Sprintf("loading page %d", page+1), m.loads.run(func(ctx context.Context) tea.Msg {
+		msg, err := load(ctx)
+		if err != nil {
+			return noteMsg{err: err}
+		}
+		msg.gridID, msg.cursorEnd = id, cursorEnd
+		return msg
+	}))
+}
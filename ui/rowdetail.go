@@ -1,13 +1,20 @@
 package ui
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/markovic-nikola/sqlitui/db"
 )
 
 // CloseDetailMsg is sent when the user dismisses the row detail popup.
@@ -20,8 +27,32 @@ type DeleteRowMsg struct {
 	RowID     int64
 }
 
+// UpdateRowMsg asks the parent to write the fields edited in the detail
+// popup back to the row. Values holds a string per changed column, or nil
+// for NULL.
+type UpdateRowMsg struct {
+	TableName string
+	RowID     int64
+	Columns   []string
+	Values    []any
+}
+
+// rowUpdateFailedMsg reports an UpdateRowMsg the database rejected, so the
+// popup can stay open in edit mode with the error shown.
+type rowUpdateFailedMsg struct {
+	err error
+}
+
+// fieldEdit is the pending new value of one field.
+type fieldEdit struct {
+	value string
+	null  bool
+}
+
 // RowDetailModel displays a single row's data as a vertical key-value list
-// inside a scrollable viewport. This is the "popup" component.
+// inside a scrollable viewport. This is the "popup" component. Rows from a
+// table (not query results) can be edited in place: the fields are stepped
+// through with tab and all changes are saved in one UPDATE.
 type RowDetailModel struct {
 	viewport    viewport.Model
 	width       int
@@ -29,11 +60,41 @@ type RowDetailModel struct {
 	tableName   string
 	rowID       int64
 	deleteArmed bool // true after first del press; second confirms.
+
+	database *sql.DB // nil for query results, whose BLOBs can't be re-read
+	columns  []string
+	values   []string
+	editable bool
+	// partial holds the fields still cut short because reading them in
+	// full failed, by column index; saving one would write the cut value.
+	partial  map[int]error
+	hex      bool                 // the viewport shows a hex dump of the selected BLOB
+	previews map[int]imagePreview // image BLOBs by field, drawn in place of their size
+
+	// blob is the selected BLOB while its save path is asked for in
+	// savePath; nil otherwise.
+	blob     []byte
+	savePath textinput.Model
+	raw      bool // show JSON and timestamp values as stored rather than formatted
+	hasJSON  bool // some value is a JSON object or array
+	// timestamps marks the columns whose values are shown as dates unless
+	// raw; hasTimestamp is set when there is such a value.
+	timestamps   []timestampKind
+	hasTimestamp bool
+
+	editing bool
+	field   int               // index of the selected field
+	notice  string            // confirmation of the last copy
+	edits   map[int]fieldEdit // changed fields by column index
+	input   textarea.Model
+	null    bool // the field being edited is set to NULL
+	editErr string
 }
 
 // NewRowDetailModel creates the popup. It renders column:value pairs
-// with aligned colons so the values line up neatly.
-func NewRowDetailModel(columns, values []string, tableName string, rowID int64, termWidth, termHeight int) RowDetailModel {
+// with aligned colons so the values line up neatly; columns marked in
+// timestamps show their values as dates.
+func NewRowDetailModel(database *sql.DB, columns, values []string, timestamps []timestampKind, tableName string, rowID int64, editable bool, termWidth, termHeight int) RowDetailModel {
 	// Size the popup to ~60% of terminal width, ~70% of terminal height.
 	popupWidth := termWidth * 60 / 100
 	popupHeight := termHeight * 70 / 100
@@ -50,51 +111,403 @@ func NewRowDetailModel(columns, values []string, tableName string, rowID int64,
 	contentWidth := popupWidth - 6
 	contentHeight := popupHeight - 4 - 3
 
-	// Find the longest column name for alignment.
-	maxLabel := 0
-	for _, col := range columns {
+	ta := textarea.New()
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0
+	ta.FocusedStyle.Base = lipgloss.NewStyle()
+	ta.BlurredStyle.Base = lipgloss.NewStyle()
+	// enter saves the row, so newlines move to alt+enter.
+	ta.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("alt+enter", "ctrl+j"))
+	ta.SetWidth(contentWidth)
+	ta.SetHeight(rowEditHeight)
+
+	m := RowDetailModel{
+		viewport:   viewport.New(contentWidth, contentHeight),
+		width:      popupWidth,
+		height:     popupHeight,
+		tableName:  tableName,
+		rowID:      rowID,
+		database:   database,
+		columns:    columns,
+		values:     values,
+		timestamps: timestamps,
+		editable:   editable && len(columns) > 0,
+		input:      ta,
+		savePath:   textinput.New(),
+	}
+	m.savePath.Prompt = "save to: "
+	m.savePath.Width = contentWidth - len(m.savePath.Prompt) - 1
+	m.loadFullValues()
+	m.loadPreviews()
+	for _, v := range m.values {
+		if isJSON(v) {
+			m.hasJSON = true
+			break
+		}
+	}
+	for i, k := range timestamps {
+		if k != notTimestamp && i < len(m.values) && k.format(m.values[i]) != m.values[i] {
+			m.hasTimestamp = true
+			break
+		}
+	}
+	m.render()
+	return m
+}
+
+// loadFullValues reads in full the values the grid had cut short, so they
+// are shown, copied, and edited whole. Query results have no row to read
+// them from and keep the cut values. A value that can't be read stays cut,
+// the error shown, and can't be edited.
+func (m *RowDetailModel) loadFullValues() {
+	if m.database == nil {
+		return
+	}
+	cloned := false
+	for i, v := range m.values {
+		if i >= len(m.columns) || !db.IsTruncated(v) {
+			continue
+		}
+		full, err := db.ReadValue(context.Background(), m.database, m.tableName, m.columns[i], m.rowID)
+		if err != nil {
+			if m.partial == nil {
+				m.partial = map[int]error{}
+			}
+			m.partial[i] = err
+			m.notice = ErrorStyle.Render(fmt.Sprintf("reading %s in full: %v", m.columns[i], err))
+			continue
+		}
+		// The values are the grid's row, which stays cut.
+		if !cloned {
+			m.values = slices.Clone(m.values)
+			cloned = true
+		}
+		m.values[i] = full
+	}
+}
+
+// loadPreviews reads the BLOBs small enough to preview and keeps the ones
+// that are images the terminal can draw.
+func (m *RowDetailModel) loadPreviews() {
+	if imageProtocol == graphicsNone || m.database == nil {
+		return
+	}
+	_, valueWidth := m.labelWidths()
+	for i, v := range m.values {
+		if size, ok := db.ParseBlobLabel(v); !ok || size > imagePreviewMaxBytes || i >= len(m.columns) {
+			continue
+		}
+		data, err := db.ReadBlob(context.Background(), m.database, m.tableName, m.columns[i], m.rowID)
+		if err != nil {
+			continue
+		}
+		if p, ok := newImagePreview(data, valueWidth); ok {
+			if m.previews == nil {
+				m.previews = map[int]imagePreview{}
+			}
+			m.previews[i] = p
+		}
+	}
+}
+
+// labelWidths returns the width of the longest column name, which the
+// labels are padded to, and the width left for values beside them.
+func (m RowDetailModel) labelWidths() (maxLabel, valueWidth int) {
+	for _, col := range m.columns {
 		if len(col) > maxLabel {
 			maxLabel = len(col)
 		}
 	}
+	// Marker (2) and " : " (3) around the label.
+	return maxLabel, max(m.viewport.Width-maxLabel-5, 10)
+}
+
+// rowEditHeight is the number of lines given to the field editor.
+const rowEditHeight = 3
+
+// render lays out the fields in the viewport. The selected field is marked
+// with ▸; while editing, changed fields show their pending value.
+func (m *RowDetailModel) render() {
+	maxLabel, valueWidth := m.labelWidths()
 
-	// Build the key-value content.
 	var b strings.Builder
-	for i, col := range columns {
+	fieldLine := 0
+	for i, col := range m.columns {
 		val := ""
-		if i < len(values) {
-			val = values[i]
+		if i < len(m.values) {
+			val = m.values[i]
+		}
+		if e, ok := m.edits[i]; ok {
+			val = e.value
+			if e.null {
+				val = "NULL"
+			}
 		}
 		// Left-pad column names so the colons align.
-		label := PopupLabelStyle.Render(fmt.Sprintf("%*s", maxLabel, col))
-		prefix := label + " : "
-		indentWidth := lipgloss.Width(prefix)
-		valueWidth := contentWidth - indentWidth
-		if valueWidth < 10 {
-			valueWidth = 10
+		label := fmt.Sprintf("%*s", maxLabel, col)
+		marker := "  "
+		if i == m.field {
+			marker = "▸ "
+			fieldLine = strings.Count(b.String(), "\n")
 		}
+		prefix := marker + PopupLabelStyle.Render(label) + " : "
+		if _, ok := m.edits[i]; ok {
+			prefix = marker + ErrorStyle.Render(label) + " * "
+		}
+		indentWidth := lipgloss.Width(prefix)
 
 		wrapped := wrapText(val, valueWidth)
+		if p, ok := m.previews[i]; ok {
+			wrapped = append([]string{p.caption}, p.lines...)
+		} else if !m.raw && isJSON(val) {
+			wrapped = formatJSON(val, valueWidth)
+		} else if _, edited := m.edits[i]; !m.raw && !edited && i < len(m.timestamps) && m.timestamps[i] != notTimestamp {
+			wrapped = wrapText(m.timestamps[i].format(val), valueWidth)
+		}
 		b.WriteString(prefix + wrapped[0] + "\n")
 		indent := strings.Repeat(" ", indentWidth)
 		for _, line := range wrapped[1:] {
 			b.WriteString(indent + line + "\n")
 		}
 	}
+	m.viewport.SetContent(b.String())
 
-	vp := viewport.New(contentWidth, contentHeight)
-	vp.SetContent(b.String())
+	// Keep the selected field in view.
+	if fieldLine < m.viewport.YOffset || fieldLine >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(fieldLine)
+	}
+}
+
+// startEdit switches to edit mode on the selected field.
+func (m *RowDetailModel) startEdit() tea.Cmd {
+	m.editing = true
+	m.edits = map[int]fieldEdit{}
+	m.editErr = ""
+	// Leave room for the editor and its separator line.
+	m.viewport.Height -= rowEditHeight + 1
+	return m.editField(m.field)
+}
 
-	return RowDetailModel{
-		viewport:  vp,
-		width:     popupWidth,
-		height:    popupHeight,
-		tableName: tableName,
-		rowID:     rowID,
+// stopEdit leaves edit mode, discarding unsaved changes.
+func (m *RowDetailModel) stopEdit() {
+	m.editing = false
+	m.edits = nil
+	m.editErr = ""
+	m.input.Blur()
+	m.viewport.Height += rowEditHeight + 1
+	m.render()
+}
+
+// selectField moves the selection to the next field, or the previous one
+// when back is set, wrapping around at either end.
+func (m *RowDetailModel) selectField(back bool) {
+	if len(m.columns) == 0 {
+		return
+	}
+	if back {
+		m.field = (m.field - 1 + len(m.columns)) % len(m.columns)
+	} else {
+		m.field = (m.field + 1) % len(m.columns)
 	}
+	m.render()
+}
+
+// copyField puts the selected field's full value on the clipboard.
+func (m *RowDetailModel) copyField() {
+	val := ""
+	if m.field < len(m.values) {
+		val = m.values[m.field]
+	}
+	if err := copyToClipboard(val); err != nil {
+		m.notice = ErrorStyle.Render("copy failed: " + err.Error())
+		return
+	}
+	m.notice = TitleStyle.Render(fmt.Sprintf("copied %s (%s)", m.columns[m.field], formatBytes(int64(len(val)))))
+}
+
+// isBlob reports whether field i holds a BLOB, shown by its size only.
+func (m RowDetailModel) isBlob(i int) bool {
+	if i >= len(m.values) {
+		return false
+	}
+	_, ok := db.ParseBlobLabel(m.values[i])
+	return ok
+}
+
+// fixed reports whether field i can't be edited here: a BLOB, or a value
+// that couldn't be read in full.
+func (m RowDetailModel) fixed(i int) bool {
+	return m.isBlob(i) || m.partial[i] != nil
+}
+
+// showHex replaces the field list with a hex dump of the selected BLOB.
+func (m *RowDetailModel) showHex() {
+	data, err := db.ReadBlob(context.Background(), m.database, m.tableName, m.columns[m.field], m.rowID)
+	if err != nil {
+		m.notice = ErrorStyle.Render(err.Error())
+		return
+	}
+	m.hex = true
+	m.viewport.SetContent(strings.Join(hexDump(data, m.viewport.Width), "\n"))
+	m.viewport.GotoTop()
+}
+
+// askSavePath reads the selected BLOB and prompts for the file to write
+// it to, suggesting a name in the current directory.
+func (m *RowDetailModel) askSavePath() tea.Cmd {
+	data, err := db.ReadBlob(context.Background(), m.database, m.tableName, m.columns[m.field], m.rowID)
+	if err != nil {
+		m.notice = ErrorStyle.Render(err.Error())
+		return nil
+	}
+	if data == nil {
+		data = []byte{}
+	}
+	m.blob = data
+	m.savePath.SetValue(blobFileName(m.tableName, m.columns[m.field], m.rowID, data))
+	m.savePath.CursorEnd()
+	return m.savePath.Focus()
+}
+
+// updateSave handles the save path prompt: enter writes the BLOB, esc
+// gives up.
+func (m RowDetailModel) updateSave(msg tea.Msg) (RowDetailModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.blob = nil
+			m.savePath.Blur()
+			return m, nil
+		case "enter":
+			path := strings.TrimSpace(m.savePath.Value())
+			if path == "" {
+				return m, nil
+			}
+			if err := writeNewFile(path, m.blob); err != nil {
+				m.notice = ErrorStyle.Render(err.Error())
+			} else {
+				m.notice = TitleStyle.Render(fmt.Sprintf("wrote %s to %s", formatBytes(int64(len(m.blob))), path))
+			}
+			m.blob = nil
+			m.savePath.Blur()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.savePath, cmd = m.savePath.Update(msg)
+	return m, cmd
+}
+
+// editField loads field i into the editor. BLOBs can't be typed in, nor
+// values that couldn't be read in full, so their editor stays empty and
+// ignores input.
+func (m *RowDetailModel) editField(i int) tea.Cmd {
+	m.field = i
+	if m.fixed(i) {
+		m.null = false
+		m.input.SetValue("")
+		m.input.Placeholder = "BLOB — not editable here"
+		if err := m.partial[i]; err != nil {
+			m.input.Placeholder = "not read in full (" + err.Error() + ") — not editable here"
+		}
+		m.render()
+		return nil
+	}
+	val, null := "", false
+	if i < len(m.values) {
+		val = m.values[i]
+		null = val == "NULL"
+	}
+	if e, ok := m.edits[i]; ok {
+		val, null = e.value, e.null
+	}
+	if null {
+		val = ""
+	}
+	m.null = null
+	m.input.SetValue(val)
+	m.updatePlaceholder()
+	m.render()
+	return m.input.Focus()
+}
+
+// updatePlaceholder shows NULL in the empty editor of a NULL field.
+func (m *RowDetailModel) updatePlaceholder() {
+	m.input.Placeholder = ""
+	if m.null {
+		m.input.Placeholder = "NULL"
+	}
+}
+
+// commitField records the editor's value for the current field when it
+// differs from the row's.
+func (m *RowDetailModel) commitField() {
+	if m.fixed(m.field) {
+		return
+	}
+	orig := ""
+	if m.field < len(m.values) {
+		orig = m.values[m.field]
+	}
+	e := fieldEdit{value: m.input.Value(), null: m.null}
+	if e.null && m.input.Value() != "" {
+		// Typing into a NULL field gives it a value.
+		e.null = false
+	}
+	origNull := orig == "NULL"
+	if (e.null && origNull) || (!e.null && !origNull && e.value == orig) {
+		delete(m.edits, m.field)
+		return
+	}
+	m.edits[m.field] = e
+}
+
+// saveEdits builds the UpdateRowMsg for the changed fields, or leaves edit
+// mode when nothing changed.
+func (m *RowDetailModel) saveEdits() tea.Cmd {
+	m.commitField()
+	if len(m.edits) == 0 {
+		m.stopEdit()
+		return nil
+	}
+	msg := UpdateRowMsg{TableName: m.tableName, RowID: m.rowID}
+	for i, col := range m.columns {
+		e, ok := m.edits[i]
+		if !ok {
+			continue
+		}
+		msg.Columns = append(msg.Columns, col)
+		if e.null {
+			msg.Values = append(msg.Values, nil)
+		} else {
+			msg.Values = append(msg.Values, e.value)
+		}
+	}
+	return func() tea.Msg { return msg }
 }
 
 func (m RowDetailModel) Update(msg tea.Msg) (RowDetailModel, tea.Cmd) {
+	if failed, ok := msg.(rowUpdateFailedMsg); ok {
+		m.editErr = failed.err.Error()
+		return m, nil
+	}
+	if m.editing {
+		return m.updateEdit(msg)
+	}
+	if m.blob != nil {
+		return m.updateSave(msg)
+	}
+	if m.hex {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && (keyMsg.String() == "esc" || key.Matches(keyMsg, Keys.HexView)) {
+			m.hex = false
+			m.render()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	}
+
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		if key.Matches(keyMsg, Keys.DeleteRow) {
 			if m.deleteArmed {
@@ -104,10 +517,39 @@ func (m RowDetailModel) Update(msg tea.Msg) (RowDetailModel, tea.Cmd) {
 			m.deleteArmed = true
 			return m, nil
 		}
+		m.notice = ""
+		if m.editable && key.Matches(keyMsg, Keys.EditRow) {
+			m.deleteArmed = false
+			return m, m.startEdit()
+		}
+		if (m.hasJSON || m.hasTimestamp) && key.Matches(keyMsg, Keys.RawView) {
+			m.deleteArmed = false
+			m.raw = !m.raw
+			m.render()
+			return m, nil
+		}
+		if m.database != nil && m.isBlob(m.field) && key.Matches(keyMsg, Keys.HexView) {
+			m.deleteArmed = false
+			m.showHex()
+			return m, nil
+		}
+		if m.database != nil && m.isBlob(m.field) && key.Matches(keyMsg, Keys.SaveBlob) {
+			m.deleteArmed = false
+			return m, m.askSavePath()
+		}
+		if key.Matches(keyMsg, Keys.CopyField) && len(m.columns) > 0 {
+			m.deleteArmed = false
+			m.copyField()
+			return m, nil
+		}
 
 		switch keyMsg.String() {
 		case "esc", "enter":
 			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "tab", "shift+tab":
+			m.deleteArmed = false
+			m.selectField(keyMsg.String() == "shift+tab")
+			return m, nil
 		}
 
 		// Any other key disarms the delete confirmation.
@@ -120,15 +562,87 @@ func (m RowDetailModel) Update(msg tea.Msg) (RowDetailModel, tea.Cmd) {
 	return m, cmd
 }
 
+// updateEdit handles keys while a field is being edited: tab and shift+tab
+// move between fields, enter saves every change, esc discards them.
+func (m RowDetailModel) updateEdit(msg tea.Msg) (RowDetailModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.stopEdit()
+			return m, nil
+		case "enter":
+			return m, m.saveEdits()
+		case "tab", "shift+tab":
+			m.commitField()
+			m.selectField(keyMsg.String() == "shift+tab")
+			return m, m.editField(m.field)
+		case "ctrl+n":
+			if m.fixed(m.field) {
+				return m, nil
+			}
+			m.null = !m.null
+			if m.null {
+				m.input.SetValue("")
+			}
+			m.updatePlaceholder()
+			return m, nil
+		}
+	}
+
+	if m.fixed(m.field) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.editErr = ""
+	return m, cmd
+}
+
 // View renders the viewport content inside the popup border.
 func (m RowDetailModel) View() string {
 	title := TitleStyle.Render(" Row Detail ")
+	if m.hex {
+		title = TitleStyle.Render(" Row Detail — " + m.columns[m.field] + " ")
+	}
 	content := m.viewport.View()
 	var help string
-	if m.deleteArmed {
-		help = ErrorStyle.Render("press del again to confirm | any other key cancels")
-	} else {
-		help = StatusBarStyle.Render("↑↓: scroll | esc/enter: close | del: delete")
+	switch {
+	case m.editing && m.editErr != "":
+		help = ErrorStyle.Render(m.editErr)
+	case m.editing:
+		help = StatusBarStyle.Render("tab/shift+tab: field | enter: save | ctrl+n: NULL | esc: cancel")
+	case m.blob != nil:
+		help = m.savePath.View()
+	case m.hex:
+		help = StatusBarStyle.Render("↑↓: scroll | esc/" + Keys.HexView.Help().Key + ": back to fields")
+	case m.notice != "":
+		help = m.notice
+	case m.deleteArmed:
+		help = ErrorStyle.Render("press " + Keys.DeleteRow.Help().Key + " again to confirm | any other key cancels")
+	default:
+		// A selected BLOB trades the copy and edit hints, which don't
+		// apply to it, for its own.
+		hints := "tab: field"
+		if m.database != nil && m.isBlob(m.field) {
+			hints += " | " + Keys.HexView.Help().Key + ": hex | " + Keys.SaveBlob.Help().Key + ": save"
+		} else {
+			hints += " | " + Keys.CopyField.Help().Key + ": copy"
+			if m.editable {
+				hints += " | " + Keys.EditRow.Help().Key + ": edit"
+			}
+		}
+		if m.hasJSON || m.hasTimestamp {
+			view := "raw"
+			if m.raw {
+				view = "formatted"
+			}
+			hints += " | " + Keys.RawView.Help().Key + ": " + view
+		}
+		help = StatusBarStyle.Render(hints + " | " + Keys.DeleteRow.Help().Key + ": delete | esc: close")
+	}
+	if m.editing {
+		label := PopupLabelStyle.Render(m.columns[m.field])
+		content += "\n" + label + "\n" + m.input.View()
 	}
 
 	return PopupStyle.
@@ -138,9 +652,16 @@ func (m RowDetailModel) View() string {
 }
 
 // wrapText breaks text into lines that fit within maxWidth visible characters.
-// It splits on spaces when possible, hard-breaking mid-word only when a single
-// word exceeds maxWidth.
+// Existing line breaks are kept. It splits on spaces when possible,
+// hard-breaking mid-word only when a single word exceeds maxWidth.
 func wrapText(text string, maxWidth int) []string {
+	if strings.Contains(text, "\n") {
+		var lines []string
+		for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+			lines = append(lines, wrapText(line, maxWidth)...)
+		}
+		return lines
+	}
 	if maxWidth <= 0 || lipgloss.Width(text) <= maxWidth {
 		return []string{text}
 	}
@@ -171,7 +692,9 @@ func wrapText(text string, maxWidth int) []string {
 		}
 		runes := []rune(line)
 		for len(runes) > 0 {
-			end := len(runes)
+			// A rune is at least one column wide, so no more than
+			// maxWidth of them fit.
+			end := min(len(runes), maxWidth)
 			for end > 0 && lipgloss.Width(string(runes[:end])) > maxWidth {
 				end--
 			}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// Columns the grid can show ahead of the data, installed from the config
+// by applyConfig and flipped with their keys.
+var (
+	// showRowNumbers numbers each row by its position in the whole table
+	// (or filter results), not just the page. Keys.RowNumbers.
+	showRowNumbers bool
+	// showRowIDs shows each row's rowid, the value edits and deletes
+	// address it by. Keys.RowIDs.
+	showRowIDs bool
+)
+
+// leadingColumns are the columns drawn ahead of the data for the current
+// page: row numbers and rowids, each while switched on. Grids without
+// rowids, such as query results, get no rowid column.
+func (m TableDataModel) leadingColumns() []table.Column {
+	var cols []table.Column
+	if showRowNumbers {
+		last := m.page*m.pageSize + max(len(m.allRows), 1)
+		cols = append(cols, table.Column{Title: "#", Width: len(strconv.Itoa(last))})
+	}
+	if showRowIDs && len(m.allRowIDs) > 0 {
+		w := len("rowid")
+		for _, id := range m.allRowIDs {
+			w = max(w, len(strconv.FormatInt(id, 10)))
+		}
+		cols = append(cols, table.Column{Title: "rowid", Width: w})
+	}
+	return cols
+}
+
+// leadingCells are the values of the leading columns for the n-th row of
+// the page.
+func (m TableDataModel) leadingCells(n int) []string {
+	cells := make([]string, 0, len(m.leading))
+	for _, c := range m.leading {
+		switch c.Title {
+		case "#":
+			cells = append(cells, strconv.Itoa(m.page*m.pageSize+n+1))
+		case "rowid":
+			cells = append(cells, strconv.FormatInt(m.allRowIDs[n], 10))
+		}
+	}
+	return cells
+}
+
+// leadingSpace is how much of the grid's width cols take, cell padding
+// included.
+func leadingSpace(cols []table.Column) int {
+	w := 0
+	for _, c := range cols {
+		w += c.Width + 2
+	}
+	return w
+}
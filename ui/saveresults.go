@@ -0,0 +1,221 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// saveProgressMsg reports how many result rows have been written.
+type saveProgressMsg struct {
+	rows int
+}
+
+// saveFinishedMsg is sent once saving the results ends, successfully or not.
+type saveFinishedMsg struct {
+	rows int
+	err  error
+}
+
+// SaveResultsModel is the popup writing a query's full result set to a
+// file. The grid holds only what was fetched, so the query runs again and
+// its rows are streamed to disk as they arrive, within the timeout it ran
+// with the first time.
+type SaveResultsModel struct {
+	database  *sql.DB
+	query     string
+	args      []any
+	timeout   time.Duration // 0 = none
+	pathInput textinput.Model
+	formatIdx int // index into db.Formats
+	phase     exportPhase
+	events    <-chan tea.Msg
+	cancel    context.CancelFunc
+	aborting  bool
+	rows      int // written so far
+	result    saveFinishedMsg
+	width     int
+}
+
+func NewSaveResultsModel(database *sql.DB, query string, args []any, timeout time.Duration, termWidth int) (SaveResultsModel, tea.Cmd) {
+	popupWidth := max(termWidth*60/100, 50)
+
+	ti := textinput.New()
+	ti.Prompt = "file: "
+	ti.SetValue("query-result." + string(db.Formats[0]))
+	ti.Width = popupWidth - 6 - len(ti.Prompt) - 1
+	cmd := ti.Focus()
+
+	return SaveResultsModel{
+		database:  database,
+		query:     query,
+		args:      args,
+		timeout:   timeout,
+		pathInput: ti,
+		width:     popupWidth,
+	}, cmd
+}
+
+func (m SaveResultsModel) Update(msg tea.Msg) (SaveResultsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case saveProgressMsg:
+		m.rows = msg.rows
+		return m, waitForExportEvent(m.events)
+
+	case saveFinishedMsg:
+		m.phase = exportDone
+		m.result = msg
+		m.cancel()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.phase {
+		case exportSetup:
+			switch msg.String() {
+			case "esc":
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			case "tab":
+				m.formatIdx = (m.formatIdx + 1) % len(db.Formats)
+				m.pathInput.SetValue(withFormatExt(m.pathInput.Value(), db.Formats[m.formatIdx]))
+				m.pathInput.CursorEnd()
+				return m, nil
+			case "enter":
+				return m.start()
+			}
+		case exportRunning:
+			if msg.String() == "esc" && !m.aborting {
+				m.aborting = true
+				m.cancel()
+			}
+			return m, nil
+		case exportDone:
+			switch msg.String() {
+			case "esc", "enter":
+				return m, func() tea.Msg { return CloseDetailMsg{} }
+			}
+			return m, nil
+		}
+	}
+
+	if m.phase == exportSetup {
+		var cmd tea.Cmd
+		m.pathInput, cmd = m.pathInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// withFormatExt swaps the extension of path for format's, when path ends
+// in another export format's extension.
+func withFormatExt(path string, format db.Format) string {
+	ext := filepath.Ext(path)
+	for _, f := range db.Formats {
+		if strings.EqualFold(ext, "."+string(f)) {
+			return strings.TrimSuffix(path, ext) + "." + string(format)
+		}
+	}
+	return path
+}
+
+// start creates the file, refusing to overwrite one, and launches the
+// query in the background.
+func (m SaveResultsModel) start() (SaveResultsModel, tea.Cmd) {
+	path := strings.TrimSpace(m.pathInput.Value())
+	if path == "" {
+		return m, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		m.phase = exportDone
+		m.result = saveFinishedMsg{err: err}
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan tea.Msg, 16)
+	go saveResults(ctx, m.database, m.query, m.args, m.timeout, db.Formats[m.formatIdx], f, events)
+
+	m.events = events
+	m.cancel = cancel
+	m.phase = exportRunning
+	m.pathInput.Blur()
+	return m, waitForExportEvent(events)
+}
+
+// saveResults runs query with args and streams its rows into f, reporting progress
+// on events and finishing with saveFinishedMsg. A timeout above zero bounds
+// the whole run. An incomplete file is removed, so a path either holds the
+// whole result or nothing.
+func saveResults(ctx context.Context, database *sql.DB, query string, args []any, timeout time.Duration, format db.Format, f *os.File, events chan<- tea.Msg) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	n, err := db.ExportQuery(ctx, database, query, args, format, f, db.ExportOptions{}, func(rows int) {
+		events <- saveProgressMsg{rows: rows}
+	})
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("query timed out after %s", timeout)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	events <- saveFinishedMsg{rows: n, err: err}
+}
+
+func (m SaveResultsModel) View() string {
+	title := TitleStyle.Render(" Save query results ")
+
+	var body, help string
+	switch m.phase {
+	case exportSetup:
+		var formats []string
+		for i, f := range db.Formats {
+			if i == m.formatIdx {
+				formats = append(formats, TitleStyle.Render("["+string(f)+"]"))
+			} else {
+				formats = append(formats, StatusBarStyle.Render(" "+string(f)+" "))
+			}
+		}
+		body = "Runs the query again and writes every row.\n\n" +
+			m.pathInput.View() + "\n" +
+			"format: " + strings.Join(formats, " ")
+		help = "enter: save | tab: format | esc: close"
+
+	case exportRunning:
+		body = fmt.Sprintf("%d rows written", m.rows)
+		help = "saving... | esc: abort"
+		if m.aborting {
+			help = "aborting..."
+		}
+
+	case exportDone:
+		switch {
+		case errors.Is(m.result.err, context.Canceled):
+			body = "Aborted; the partial file was removed."
+		case m.result.err != nil:
+			body = ErrorStyle.Render("Error: " + m.result.err.Error())
+		default:
+			body = fmt.Sprintf("Wrote %d rows to %s.", m.result.rows, m.pathInput.Value())
+		}
+		help = "esc/enter: close"
+	}
+
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(title + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
@@ -0,0 +1,237 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// schemaObjectsMsg carries the rows of sqlite_master for the schema view.
+type schemaObjectsMsg struct {
+	database *sql.DB
+	columns  []string
+	rows     [][]string
+}
+
+func loadSchemaObjectsCmd(database *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		cols, rows, err := db.SchemaObjects(ctx, database)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		fks, err := db.ForeignKeys(ctx, database)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		cols, rows = withForeignKeys(cols, rows, fks)
+		return schemaObjectsMsg{database: database, columns: cols, rows: rows}
+	}
+}
+
+// withForeignKeys adds a foreign_keys column before sql, listing each
+// table's keys one per line as the relationships popup shows them.
+func withForeignKeys(columns []string, rows [][]string, fks []db.ForeignKey) ([]string, [][]string) {
+	typeCol, nameCol, sqlCol := slices.Index(columns, "type"), slices.Index(columns, "name"), slices.Index(columns, "sql")
+	if typeCol < 0 || nameCol < 0 || sqlCol < 0 {
+		return columns, rows
+	}
+	byTable := make(map[string][]string)
+	for _, fk := range fks {
+		byTable[fk.Table] = append(byTable[fk.Table], formatRelationLink(relationLink{fk: fk, outgoing: true}))
+	}
+	columns = slices.Insert(columns, sqlCol, "foreign_keys")
+	for i, r := range rows {
+		keys := ""
+		if r[typeCol] == "table" {
+			keys = strings.Join(byTable[r[nameCol]], "\n")
+		}
+		rows[i] = slices.Insert(r, sqlCol, keys)
+	}
+	return columns, rows
+}
+
+// newSchemaObjectsGrid shows sqlite_master as an in-memory grid, so the
+// usual filter narrows it by type or name. The sql column is laid out by
+// formatSQL: the grid flattens it to one line, the row detail shows it in
+// full.
+func newSchemaObjectsGrid(columns []string, rows [][]string, width, height int, database *sql.DB) TableDataModel {
+	if c := slices.Index(columns, "sql"); c >= 0 {
+		for _, r := range rows {
+			if r[c] != "NULL" {
+				r[c] = formatSQL(r[c])
+			}
+		}
+	}
+	return newStaticGrid(schemaObjectsName, columns, rows, width, height, database)
+}
+
+// sqlToken is a lexical unit of a SQL statement. space and newline record
+// the whitespace that preceded it in the original text.
+type sqlToken struct {
+	text    string
+	space   bool
+	newline bool
+}
+
+// sqlTokens splits a statement into words, punctuation, quoted strings or
+// identifiers, and comments — just enough structure to re-indent it.
+func sqlTokens(src string) []sqlToken {
+	var toks []sqlToken
+	space, newline := false, false
+	for i := 0; i < len(src); {
+		c := src[i]
+		start := i
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			newline = newline || c == '\n'
+			i++
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			i++
+			for i < len(src) {
+				if src[i] == c {
+					if i+1 < len(src) && src[i+1] == c { // doubled quote escapes itself
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+		case c == '[':
+			if end := strings.IndexByte(src[i:], ']'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "--"):
+			if end := strings.IndexByte(src[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(src)
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			if end := strings.Index(src[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(src)
+			}
+		case strings.IndexByte("(),;", c) >= 0:
+			i++
+		default:
+			for i < len(src) && strings.IndexByte(" \t\n\r(),;'\"`[", src[i]) < 0 {
+				i++
+			}
+		}
+		toks = append(toks, sqlToken{text: src[start:i], space: space, newline: newline})
+		space, newline = false, false
+	}
+	return toks
+}
+
+// clauseKeywords start a new line in views, triggers, and index definitions.
+var clauseKeywords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "ORDER": true,
+	"HAVING": true, "LIMIT": true, "UNION": true, "EXCEPT": true, "INTERSECT": true,
+	"JOIN": true, "LEFT": true, "RIGHT": true, "FULL": true, "INNER": true,
+	"CROSS": true, "NATURAL": true, "VALUES": true, "SET": true,
+}
+
+// joinPrefixes are keywords that may precede JOIN on the same line.
+var joinPrefixes = map[string]bool{
+	"LEFT": true, "RIGHT": true, "FULL": true, "INNER": true, "CROSS": true,
+	"NATURAL": true, "OUTER": true,
+}
+
+// formatSQL lays out a CREATE statement for reading: one column or
+// constraint per line in a table definition, and the main clauses of
+// views and triggers on their own lines. Only whitespace changes; quoted
+// text and comments are kept as they were.
+func formatSQL(src string) string {
+	toks := sqlTokens(src)
+	var b strings.Builder
+
+	isTable := false // a CREATE ... TABLE whose column list gets expanded
+	for _, t := range toks {
+		up := strings.ToUpper(t.text)
+		if up == "TABLE" {
+			isTable = true
+		}
+		if up == "AS" {
+			isTable = false // CREATE TABLE ... AS SELECT has no column list
+			break
+		}
+		if up == "(" {
+			break
+		}
+	}
+
+	depth := 0
+	expand := 0   // depth whose items go one per line; 0 = none
+	body := 0     // indent for statements inside a trigger's BEGIN ... END
+	newline := -1 // indent for the next token's line break; -1 = none
+	prev := ""
+	for i, t := range toks {
+		up := strings.ToUpper(t.text)
+		lineStart := newline
+		newline = -1
+
+		switch {
+		case up == "(":
+			depth++
+			if isTable && expand == 0 && depth == 1 {
+				expand = depth
+				newline = 1
+			}
+		case up == ")":
+			if depth == expand && expand > 0 {
+				lineStart = 0
+				expand = -1 // done; never expand again
+			}
+			depth--
+		case up == "," && depth == expand && expand > 0:
+			newline = 1
+		case up == "BEGIN" && depth == 0:
+			body = 1
+			newline = body
+		case up == ";" && body > 0:
+			newline = body
+		case up == "END" && depth == 0 && body > 0:
+			body = 0
+			lineStart = 0
+		case depth == 0 && !isTable && i > 0 && clauseKeywords[up] && lineStart < 0:
+			if !(up == "JOIN" && joinPrefixes[strings.ToUpper(prev)]) {
+				lineStart = body
+			}
+		}
+		if strings.HasPrefix(t.text, "--") {
+			// A line comment runs to the end of the line. One that trailed
+			// code in the original stays on that code's line.
+			if !t.newline {
+				lineStart = -1
+			}
+			newline = body
+			if depth == expand && expand > 0 {
+				newline = 1
+			}
+		}
+
+		switch {
+		case lineStart >= 0 && i > 0:
+			b.WriteString("\n" + strings.Repeat("  ", lineStart))
+		case t.space && i > 0:
+			b.WriteByte(' ')
+		}
+		b.WriteString(t.text)
+		prev = t.text
+	}
+	return b.String()
+}
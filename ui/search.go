@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// startSearch opens the prompt for text to find on the page. Unlike the
+// filter it reads nothing from the database: it marks the cells of the
+// rows loaded that hold the text, in any case, and moves the cursor
+// between them.
+func (m *TableDataModel) startSearch() tea.Cmd {
+	m.search = ""
+	m.searchFrom = m.table.Cursor()
+	return m.startJump(jumpSearch)
+}
+
+// updateSearch handles keys while the search text is typed: the cursor
+// goes to the first match from where it was as the text changes, and ↑
+// and ↓ step through the others. enter keeps the search marked; esc drops
+// it and puts the cursor back.
+func (m TableDataModel) updateSearch(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closeJump()
+		m.search = ""
+		m.table.SetCursor(m.searchFrom)
+		return m, nil
+	case "enter":
+		m.closeJump()
+		if m.search != "" && m.matchingRows() == 0 {
+			return m, noteCmd(fmt.Errorf("%q isn't on this page", m.search))
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		return m.nextMatch(1)
+	case "up", "ctrl+p":
+		return m.nextMatch(-1)
+	}
+	var cmd tea.Cmd
+	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	if text := m.jumpInput.Value(); text != m.search {
+		m.search = text
+		m.table.SetCursor(m.searchFrom)
+		if row, ok := m.findMatch(m.searchFrom, 1, true); ok {
+			m.table.SetCursor(row)
+		}
+	}
+	return m, cmd
+}
+
+// findMatch returns the first row of the page from row on, down (step 1)
+// or up (step -1) and around, with a cell matching the search; row itself
+// is looked at first if from is set, else last.
+func (m TableDataModel) findMatch(row, step int, from bool) (int, bool) {
+	n := len(m.allRows)
+	if m.search == "" || n == 0 {
+		return 0, false
+	}
+	first := 1
+	if from {
+		first = 0
+	}
+	for i := first; i < first+n; i++ {
+		if r := ((row+step*i)%n + n) % n; m.matchedCells(r) != nil {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// nextMatch moves the cursor to the next row with a match after it,
+// down (step 1) or up (step -1).
+func (m TableDataModel) nextMatch(step int) (TableDataModel, tea.Cmd) {
+	if m.search == "" {
+		return m, nil
+	}
+	row, ok := m.findMatch(m.table.Cursor(), step, false)
+	if !ok {
+		return m, noteCmd(fmt.Errorf("%q isn't on this page", m.search))
+	}
+	m.table.SetCursor(row)
+	return m, nil
+}
+
+// matchedCells says which cells of the page's row r, as the table widget
+// holds them, show a value holding the search text, or returns nil if
+// none does. The leading row number and rowid columns aren't searched.
+func (m TableDataModel) matchedCells(r int) []bool {
+	if m.search == "" || r < 0 || r >= len(m.allRows) {
+		return nil
+	}
+	needle := strings.ToLower(m.search)
+	values := m.allRows[r]
+	var marked []bool
+	for i, v := range values[:min(m.displayCols, len(values))] {
+		if !strings.Contains(strings.ToLower(v), needle) {
+			continue
+		}
+		if marked == nil {
+			marked = make([]bool, len(m.leading)+m.displayCols+1)
+		}
+		marked[len(m.leading)+i] = true
+	}
+	return marked
+}
+
+// matchingRows counts the rows of the page with a match.
+func (m TableDataModel) matchingRows() int {
+	n := 0
+	for r := range m.allRows {
+		if m.matchedCells(r) != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// searchStatus tells, next to the search prompt, how many rows match.
+func (m TableDataModel) searchStatus() string {
+	if m.search == "" {
+		return ""
+	}
+	switch n := m.matchingRows(); n {
+	case 0:
+		return "no match on this page"
+	case 1:
+		return "1 matching row"
+	default:
+		return fmt.Sprintf("%d matching rows", n)
+	}
+}
+
+// cellMarks is what is marked in a row of the table widget: the cells
+// holding the search text, and the parts of the filter column's value the
+// filter matched.
+type cellMarks struct {
+	cells []bool   // by column, as matchedCells says
+	col   int      // the filter column's cell, or -1
+	spans [][2]int // byte ranges of its value
+}
+
+// rowMarks returns the marks of the page's row r, and whether it has any.
+func (m TableDataModel) rowMarks(r int) (cellMarks, bool) {
+	marks := cellMarks{cells: m.matchedCells(r), col: m.filterCell()}
+	if rows := m.table.Rows(); marks.col >= 0 && r < len(rows) && marks.col < len(rows[r]) {
+		marks.spans = m.filterSpans(rows[r][marks.col])
+	}
+	return marks, marks.cells != nil || marks.spans != nil
+}
+
+// marking reports whether the grid marks anything: the search, or the
+// filter's matches.
+func (m TableDataModel) marking() bool {
+	return m.search != "" || m.filterCell() >= 0
+}
+
+// markMatches redraws the rows of the table widget's view with the cells
+// holding the search text and the parts the filter matched marked. The
+// selected row is on line of the body (see cursorLine).
+func (m TableDataModel) markMatches(view string, line int) string {
+	lines := strings.Split(view, "\n")
+	if line < 0 || len(lines) <= headerLines {
+		return view
+	}
+	body := lines[headerLines:]
+	rows, cols, styles := m.table.Rows(), m.table.Columns(), tableStyles()
+	for k := range body {
+		r := m.table.Cursor() - line + k
+		if r < 0 || r >= len(rows) {
+			continue
+		}
+		if marks, ok := m.rowMarks(r); ok {
+			body[k] = renderMarkedRow(rows[r], cols, styles, marks, r == m.table.Cursor())
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderMarkedRow draws a row as the table widget does, with the marks set
+// in MatchStyle. Each cell is styled on its own, the selected row's too,
+// so the marks don't end its colors.
+func renderMarkedRow(row table.Row, cols []table.Column, styles table.Styles, marks cellMarks, selected bool) string {
+	s := make([]string, 0, len(cols))
+	for i, value := range row {
+		if i >= len(cols) || cols[i].Width <= 0 {
+			continue
+		}
+		base, spans := marks.cellStyle(i, selected, styles)
+		text := runewidth.Truncate(value, cols[i].Width, "…")
+		s = append(s, styles.Cell.Inherit(base).Render(markSpans(text, clipSpans(spans, 0, shownBytes(value, text)), base, cols[i].Width)))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, s...)
+}
+
+// cellStyle returns the style of the row's cell i, and the spans of its
+// value to mark in it: a cell holding the search text is marked whole.
+func (c cellMarks) cellStyle(i int, selected bool, styles table.Styles) (lipgloss.Style, [][2]int) {
+	switch {
+	case i < len(c.cells) && c.cells[i]:
+		return MatchStyle, nil
+	case i != c.col:
+		c.spans = nil
+	}
+	if selected {
+		return styles.Selected, c.spans
+	}
+	return lipgloss.NewStyle(), c.spans
+}
+
+// shownBytes is how many bytes of value text, value cut to a column, still
+// shows before the "…".
+func shownBytes(value, text string) int {
+	if text == value {
+		return len(value)
+	}
+	return len(strings.TrimSuffix(text, "…"))
+}
+
+// markSpans draws text in base, padded to width, with its spans in
+// MatchStyle. Each part is styled on its own, so the marks don't end
+// base's colors.
+func markSpans(text string, spans [][2]int, base lipgloss.Style, width int) string {
+	if len(spans) == 0 {
+		return lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true).Render(text)
+	}
+	pad := strings.Repeat(" ", max(width-runewidth.StringWidth(text), 0))
+	var b strings.Builder
+	at := 0
+	for _, s := range spans {
+		if s[0] < at {
+			continue
+		}
+		if at < s[0] {
+			b.WriteString(base.Render(text[at:s[0]]))
+		}
+		b.WriteString(MatchStyle.Render(text[s[0]:s[1]]))
+		at = s[1]
+	}
+	b.WriteString(base.Render(text[at:] + pad))
+	return b.String()
+}
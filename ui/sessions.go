@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
+)
+
+// dbSession is everything in the Model that belongs to one open database.
+// The active database lives directly in the Model's fields; the others are
+// parked in Model.sessions until the user switches back to them. The
+// pinned grid is not part of a session, so a table pinned from one file
+// stays on screen for comparison while another file is browsed.
+type dbSession struct {
+	db            *sql.DB
+	dbPath        string
+	schema        *state.SchemaCache
+	tables        []string
+	tableList     TableListModel
+	tableData     TableDataModel
+	dataLoaded    bool
+	lastTableName string
+	tabs          []TableDataModel
+	activeTab     int
+	dbInfo        *db.Info
+	filterHistory *filterHistory
+	bookmarks     *bookmarks
+	columnWidths  columnWidths
+	txn           *transaction
+}
+
+// parkSession captures the active database's state and detaches its
+// change watcher.
+func (m *Model) parkSession() dbSession {
+	m.stopWatching()
+	return dbSession{
+		db:            m.db,
+		dbPath:        m.dbPath,
+		schema:        m.schema,
+		tables:        m.tables,
+		tableList:     m.tableList,
+		tableData:     m.tableData,
+		dataLoaded:    m.dataLoaded,
+		lastTableName: m.lastTableName,
+		tabs:          m.tabs,
+		activeTab:     m.activeTab,
+		dbInfo:        m.dbInfo,
+		filterHistory: m.filterHistory,
+		bookmarks:     m.bookmarks,
+		columnWidths:  m.columnWidths,
+		txn:           m.txn,
+	}
+}
+
+// restoreSession makes s the active database again.
+func (m *Model) restoreSession(s dbSession) tea.Cmd {
+	m.db = s.db
+	m.dbPath = s.dbPath
+	m.schema = s.schema
+	m.tables = s.tables
+	m.tableList = s.tableList
+	m.tableData = s.tableData
+	m.dataLoaded = s.dataLoaded
+	m.lastTableName = s.lastTableName
+	m.tabs = s.tabs
+	m.activeTab = s.activeTab
+	m.dbInfo = s.dbInfo
+	m.filterHistory = s.filterHistory
+	m.bookmarks = s.bookmarks
+	m.columnWidths = s.columnWidths
+	m.txn = s.txn
+	m.txPrompt = false
+	m.loaded = true
+	if m.focused == paneData && !m.dataLoaded {
+		m.focused = paneList
+	}
+	// The terminal may have been resized while the session was parked.
+	m.calcPaneSizes()
+	m.tableList.SetSize(m.leftWidth, m.paneHeight())
+	m.resizeGrids()
+	return m.startWatching()
+}
+
+// openDatabase makes a freshly opened database the active one, parking the
+// current database when the picker was opened on top of it. A file that is
+// already open is switched to instead of being opened twice.
+func (m *Model) openDatabase(msg dbOpenedMsg) tea.Cmd {
+	if m.loaded {
+		for i, s := range m.sessions {
+			if s.dbPath == msg.path {
+				db.Close(msg.db)
+				m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+				m.sessions = append(m.sessions, m.parkSession())
+				return m.restoreSession(s)
+			}
+		}
+		if m.dbPath == msg.path {
+			db.Close(msg.db)
+			return nil
+		}
+		m.sessions = append(m.sessions, m.parkSession())
+	}
+	m.db = msg.db
+	m.dbPath = msg.path
+	m.loaded = false
+	m.dataLoaded = false
+	m.lastTableName = ""
+	m.filterHistory = nil
+	m.bookmarks = nil
+	m.columnWidths = nil
+	m.txn = nil
+	m.txPrompt = false
+	m.tabs = nil
+	m.activeTab = 0
+	m.focused = paneList
+	m.calcPaneSizes()
+	return func() tea.Msg {
+		return tablesLoadedMsg{tables: msg.tables, entries: msg.entries, schema: msg.schema}
+	}
+}
+
+// nextSession rotates to the database opened after the active one.
+func (m *Model) nextSession() tea.Cmd {
+	next := m.sessions[0]
+	m.sessions = append(m.sessions[1:], m.parkSession())
+	return m.restoreSession(next)
+}
+
+// closeDatabase closes the active database. It returns false when that was
+// the last one open, leaving the caller to show the file picker.
+func (m *Model) closeDatabase() (tea.Cmd, bool) {
+	m.stopWatching()
+	if m.showPinned && m.pinned.database == m.db {
+		m.showPinned = false
+		m.zoomed = false
+		if m.focused == panePinned {
+			m.focused = paneData
+		}
+	}
+	if m.db != nil {
+		if m.txn != nil {
+			// Closing would roll it back anyway.
+			_ = db.Rollback(context.Background(), m.db)
+			m.txn = nil
+			m.txPrompt = false
+		}
+		m.forgetEdits(m.db)
+		db.Close(m.db)
+		db.RemoveSSHCopy(m.dbPath)
+		m.db = nil
+	}
+	if len(m.sessions) == 0 {
+		return nil, false
+	}
+	next := m.sessions[0]
+	m.sessions = m.sessions[1:]
+	return m.restoreSession(next), true
+}
+
+// dbLabel names the active database in the status bar when more than one
+// is open.
+func (m Model) dbLabel() string {
+	if len(m.sessions) == 0 {
+		return ""
+	}
+	return db.BaseName(m.dbPath)
+}
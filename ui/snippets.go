@@ -0,0 +1,143 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/config"
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// snippetTable stands for the table open in the grid in a snippet's SQL.
+const snippetTable = "{table}"
+
+// builtinSnippets are the snippets every user has; a user snippet of the
+// same name takes the place of one.
+var builtinSnippets = []config.Snippet{
+	{Name: "top rows", SQL: "SELECT * FROM {table} LIMIT 10"},
+	{Name: "newest rows", SQL: "SELECT * FROM {table} ORDER BY rowid DESC LIMIT 10"},
+	{Name: "random sample", SQL: "SELECT * FROM {table} ORDER BY random() LIMIT 10"},
+	{Name: "find duplicates", SQL: "SELECT col, COUNT(*) AS copies\nFROM {table}\nGROUP BY col\nHAVING COUNT(*) > 1\nORDER BY copies DESC"},
+	{Name: "table sizes", SQL: "SELECT name, SUM(pgsize) AS bytes, COUNT(*) AS pages\nFROM dbstat\nGROUP BY name\nORDER BY bytes DESC"},
+	{Name: "index usage", SQL: "SELECT m.tbl_name AS \"table\", m.name AS \"index\", group_concat(ii.name, ', ') AS columns, il.\"unique\", il.origin\n" +
+		"FROM sqlite_master m, pragma_index_list(m.tbl_name) il, pragma_index_info(il.name) ii\n" +
+		"WHERE m.type = 'index' AND il.name = m.name\nGROUP BY m.name\nORDER BY 1, 2"},
+	{Name: "tables without indexes", SQL: "SELECT name FROM sqlite_master t\nWHERE type = 'table' AND NOT EXISTS (SELECT 1 FROM sqlite_master i WHERE i.type = 'index' AND i.tbl_name = t.name)\nORDER BY name"},
+	{Name: "query plan", SQL: "EXPLAIN QUERY PLAN SELECT * FROM {table} WHERE "},
+	{Name: "foreign key check", SQL: "PRAGMA foreign_key_check"},
+	{Name: "integrity check", SQL: "PRAGMA integrity_check"},
+}
+
+// loadSnippets lists the built-in snippets followed by the user's, which
+// replace built-ins of the same name.
+func loadSnippets() ([]config.Snippet, error) {
+	snippets := slices.Clone(builtinSnippets)
+	user, err := config.LoadSnippets()
+	for _, s := range user {
+		if i := slices.IndexFunc(snippets, func(b config.Snippet) bool { return b.Name == s.Name }); i >= 0 {
+			snippets[i] = s
+		} else {
+			snippets = append(snippets, s)
+		}
+	}
+	return snippets, err
+}
+
+// openSnippets asks for the snippet to insert, filtering the list by name
+// as it is typed.
+func (m QueryInputModel) openSnippets() (QueryInputModel, tea.Cmd) {
+	snippets, err := loadSnippets()
+	m.snippets = snippets
+	m.snippetCursor, m.snippetScroll = 0, 0
+	m, cmd := m.ask(promptSnippet, "snippet: ", "type to filter", "")
+	if err != nil {
+		m.queryErr = err.Error()
+	}
+	return m, cmd
+}
+
+// matchingSnippets are the snippets whose name holds the filter text.
+func (m QueryInputModel) matchingSnippets() []config.Snippet {
+	filter := strings.ToLower(strings.TrimSpace(m.promptInput.Value()))
+	var out []config.Snippet
+	for _, s := range m.snippets {
+		if strings.Contains(strings.ToLower(s.Name), filter) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// updateSnippets handles keys while the snippet picker is open: enter
+// inserts the selected snippet at the cursor, esc goes back to the query.
+func (m QueryInputModel) updateSnippets(msg tea.KeyMsg) (QueryInputModel, tea.Cmd) {
+	matches := m.matchingSnippets()
+	switch msg.String() {
+	case "esc":
+		return m.closePrompt()
+	case "up":
+		m.snippetCursor = max(m.snippetCursor-1, 0)
+	case "down":
+		m.snippetCursor = max(min(m.snippetCursor+1, len(matches)-1), 0)
+	case "enter":
+		if len(matches) == 0 {
+			return m, nil
+		}
+		m.warning, m.warned = "", ""
+		m.queryErr = ""
+		m.textarea.InsertString(m.expandSnippet(matches[m.snippetCursor].SQL))
+		return m.closePrompt()
+	default:
+		var cmd tea.Cmd
+		m.promptInput, cmd = m.promptInput.Update(msg)
+		m.snippetCursor, m.snippetScroll = 0, 0
+		return m, cmd
+	}
+	m.snippetScroll = scrollTo(m.snippetCursor, m.snippetScroll, m.textarea.Height())
+	return m, nil
+}
+
+// expandSnippet puts the grid's table in place of snippetTable.
+func (m QueryInputModel) expandSnippet(sql string) string {
+	table := "my_table"
+	if m.table != "" {
+		table = db.QuoteTable(m.table)
+	}
+	return strings.ReplaceAll(sql, snippetTable, table)
+}
+
+// snippetsView lists the matching snippets in place of the textarea, each
+// with the start of its SQL.
+func (m QueryInputModel) snippetsView() string {
+	matches := m.matchingSnippets()
+	height := m.textarea.Height()
+	width := m.width - 6
+	lines := make([]string, 0, height)
+	if len(matches) == 0 {
+		lines = append(lines, StatusBarStyle.Render("No snippet matches."))
+	}
+	nameWidth := 0
+	for _, s := range matches {
+		nameWidth = max(nameWidth, len([]rune(s.Name)))
+	}
+	nameWidth = min(nameWidth, width/3)
+	end := min(m.snippetScroll+height, len(matches))
+	for i := m.snippetScroll; i < end; i++ {
+		s := matches[i]
+		name := truncateValue(s.Name, nameWidth)
+		name += strings.Repeat(" ", nameWidth-len([]rune(name)))
+		sql := StatusBarStyle.Render(truncateValue(strings.Join(strings.Fields(m.expandSnippet(s.SQL)), " "), max(width-nameWidth-4, 5)))
+		if i == m.snippetCursor {
+			lines = append(lines, fmt.Sprintf("%s  %s", TitleStyle.Render("▸ "+name), sql))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s  %s", name, sql))
+		}
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines, "\n")
+}
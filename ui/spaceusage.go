@@ -0,0 +1,205 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// spaceUsageMsg carries the result of measuring the database with dbstat.
+type spaceUsageMsg struct {
+	usage   db.SpaceUsage
+	elapsed time.Duration
+	err     error
+}
+
+// SpaceUsageModel is the popup showing how much of the file each table
+// and index takes up, largest first, to find what makes a database big.
+// dbstat reads the whole file, so it is measured in the background.
+type SpaceUsageModel struct {
+	running bool
+	cancel  context.CancelFunc
+	spinner spinner.Model
+	started time.Time
+
+	result  *spaceUsageMsg
+	byTable bool // sum each table with its indexes rather than list both
+	scroll  int
+	width   int
+	listLen int // object rows visible at once
+}
+
+func NewSpaceUsageModel(database *sql.DB, termWidth, termHeight int) (SpaceUsageModel, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := SpaceUsageModel{
+		running: true,
+		cancel:  cancel,
+		spinner: newSpinner(),
+		started: time.Now(),
+		width:   max(termWidth*70/100, 60),
+		// Border, padding, title, summary, header, gaps, and help take 11 lines.
+		listLen: max(termHeight*70/100-11, 3),
+	}
+	started := m.started
+	run := func() tea.Msg {
+		usage, err := db.SpaceUsageStats(ctx, database, "")
+		return spaceUsageMsg{usage: usage, elapsed: time.Since(started), err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+func (m SpaceUsageModel) Update(msg tea.Msg) (SpaceUsageModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case spinner.TickMsg:
+		if !m.running {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case spaceUsageMsg:
+		if !m.running {
+			return m, nil
+		}
+		m.running = false
+		m.cancel()
+		m.result = &msg
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "esc" || key.Matches(msg, Keys.SpaceUsage) {
+			if m.running {
+				m.cancel()
+			}
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+		if m.result == nil || m.result.err != nil {
+			return m, nil
+		}
+		n := len(m.objects())
+		switch msg.String() {
+		case "up", "k":
+			m.scroll = max(m.scroll-1, 0)
+		case "down", "j":
+			m.scroll = min(m.scroll+1, max(n-m.listLen, 0))
+		case "pgup":
+			m.scroll = max(m.scroll-m.listLen, 0)
+		case "pgdown":
+			m.scroll = min(m.scroll+m.listLen, max(n-m.listLen, 0))
+		case "tab":
+			m.byTable = !m.byTable
+			m.scroll = 0
+		}
+	}
+	return m, nil
+}
+
+// objects is what the list shows: every table and index, or with byTable
+// each table's indexes added into it.
+func (m SpaceUsageModel) objects() []db.ObjectSize {
+	if !m.byTable {
+		return m.result.usage.Objects
+	}
+	var out []db.ObjectSize
+	index := make(map[string]int)
+	for _, o := range m.result.usage.Objects {
+		i, ok := index[o.Table]
+		if !ok {
+			i = len(out)
+			index[o.Table] = i
+			out = append(out, db.ObjectSize{Name: o.Table, Type: "table", Table: o.Table})
+		}
+		out[i].Pages += o.Pages
+		out[i].Bytes += o.Bytes
+		out[i].Unused += o.Unused
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Bytes > out[b].Bytes })
+	return out
+}
+
+func (m SpaceUsageModel) View() string {
+	var title, body, help string
+	switch {
+	case m.running:
+		title = " Space usage "
+		body = spinnerLine(m.spinner, "reading every page", m.started)
+		help = "esc: cancel"
+	case m.result.err != nil:
+		title = " Space usage "
+		body = ErrorStyle.Render("Error: " + m.result.err.Error())
+		help = "esc: close"
+	default:
+		title = fmt.Sprintf(" Space usage (%s) ", m.result.elapsed.Round(time.Millisecond))
+		body = m.usageView()
+		help = "↑↓: scroll | tab: by table | esc: close"
+		if m.byTable {
+			help = "↑↓: scroll | tab: tables and indexes | esc: close"
+		}
+	}
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(title) + "\n\n" + body + "\n\n" + StatusBarStyle.Render(help))
+}
+
+// usageView is the file summary followed by one line per object: its size,
+// share of the file, free space inside its pages, and a bar to compare.
+func (m SpaceUsageModel) usageView() string {
+	u := m.result.usage
+	fileBytes := u.PageCount * u.PageSize
+	summary := fmt.Sprintf("%s in %d pages of %s", formatBytes(fileBytes), u.PageCount, formatBytes(u.PageSize))
+	if u.FreePages > 0 {
+		summary += fmt.Sprintf(", %s free (VACUUM reclaims it)", formatBytes(u.FreePages*u.PageSize))
+	}
+
+	objects := m.objects()
+	if len(objects) == 0 {
+		return summary
+	}
+	width := m.width - 6
+	nameWidth := 4
+	for _, o := range objects {
+		nameWidth = max(nameWidth, len([]rune(objectLabel(o))))
+	}
+	nameWidth = min(nameWidth, width/2)
+	// The size, share, and unused columns and the gaps around them take 30.
+	barWidth := max(width-nameWidth-30, 1)
+
+	var b strings.Builder
+	b.WriteString(summary + "\n\n")
+	b.WriteString(PopupLabelStyle.Render(fmt.Sprintf("%-*s %10s %6s %10s", nameWidth, "name", "size", "share", "unused")) + "\n")
+	end := min(m.scroll+m.listLen, len(objects))
+	for _, o := range objects[m.scroll:end] {
+		name := truncateValue(objectLabel(o), nameWidth)
+		share := 0.0
+		if fileBytes > 0 {
+			share = float64(o.Bytes) * 100 / float64(fileBytes)
+		}
+		bar := int(o.Bytes * int64(barWidth) / max(objects[0].Bytes, 1))
+		fmt.Fprintf(&b, "%s%s %10s %5.1f%% %10s %s\n",
+			name, strings.Repeat(" ", nameWidth-len([]rune(name))),
+			formatBytes(o.Bytes), share, formatBytes(o.Unused), TitleStyle.Render(strings.Repeat("█", max(bar, 1))))
+	}
+	if len(objects) > m.listLen {
+		b.WriteString(StatusBarStyle.Render(fmt.Sprintf("%d-%d of %d", m.scroll+1, end, len(objects))))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// objectLabel names an object in the list, saying which table an index
+// belongs to.
+func objectLabel(o db.ObjectSize) string {
+	if o.Type == "index" {
+		return o.Name + " (" + o.Table + ")"
+	}
+	return o.Name
+}
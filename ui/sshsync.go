@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// sshSyncedMsg reports that the database at an scp:// URL was copied from
+// its host again, or why it wasn't.
+type sshSyncedMsg struct {
+	database *sql.DB
+	err      error
+}
+
+// syncSSHCmd copies the database at path from its host again, over the
+// copy database reads, and has database read the new one.
+func syncSSHCmd(database *sql.DB, path string) tea.Cmd {
+	return func() tea.Msg {
+		if _, err := db.SyncSSH(context.Background(), path); err != nil {
+			return sshSyncedMsg{database: database, err: err}
+		}
+		db.Reconnect(database)
+		return sshSyncedMsg{database: database}
+	}
+}
+
+// sshSynced re-reads the grids of the database once it has been copied
+// again, as refreshing it would. A failed copy leaves the earlier one open.
+func (m *Model) sshSynced(msg sshSyncedMsg) tea.Cmd {
+	m.syncing = false
+	if msg.database != m.db {
+		return nil
+	}
+	watchCmd := m.startWatching()
+	if msg.err != nil {
+		m.note = msg.err.Error()
+		return watchCmd
+	}
+	m.note = "copied " + db.BaseName(m.dbPath) + " from the host"
+	return tea.Batch(watchCmd, loadDBInfoCmd(m.db, m.dbPath, false), m.refreshGrids(m.db, ""))
+}
@@ -1,72 +1,150 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
 
 // All styles live here — one place to change the look of the entire app.
 // lipgloss works like CSS: you build styles by chaining methods, and
 // they're immutable (each method returns a new style).
+//
+// The colors come from the active Theme; applyTheme rebuilds every style
+// when the theme changes, so the rest of the UI just uses these variables.
 
 var (
 	AppStyle = lipgloss.NewStyle().Margin(1, 2)
 
+	TitleStyle     lipgloss.Style
+	StatusBarStyle lipgloss.Style
+
+	// StatusBarInfoStyle is for the left section showing table name and page info.
+	StatusBarInfoStyle lipgloss.Style
+
+	// StatusBarKeyStyle highlights the key binding name (e.g. "f", "enter").
+	StatusBarKeyStyle lipgloss.Style
+
+	// StatusBarDescStyle is for the key description (e.g. "filter", "detail").
+	StatusBarDescStyle lipgloss.Style
+
+	// StatusBarBgStyle is the base background for the full status bar.
+	StatusBarBgStyle lipgloss.Style
+
+	ErrorStyle lipgloss.Style
+
+	// FocusedPaneStyle has a bright border — applied to the active panel.
+	// Width/Height are set dynamically at render time via .Width()/.Height().
+	FocusedPaneStyle lipgloss.Style
+
+	// UnfocusedPaneStyle has a dim border — applied to the inactive panel.
+	UnfocusedPaneStyle lipgloss.Style
+
+	// PopupStyle wraps the row detail modal. Bright border + background
+	// so it visually "floats" above the split pane behind it.
+	PopupStyle lipgloss.Style
+
+	// PopupLabelStyle is for the column names in the key-value list.
+	PopupLabelStyle lipgloss.Style
+
+	// TabStyle and ActiveTabStyle label the open tables above the data grid.
+	TabStyle       lipgloss.Style
+	ActiveTabStyle lipgloss.Style
+
+	// MatchStyle marks the grid cells holding the text searched for, and
+	// the parts of values a filter matched.
+	MatchStyle lipgloss.Style
+
+	Logo string
+
+	// activeTheme is the theme the styles above were built from.
+	activeTheme Theme
+)
+
+func init() {
+	applyTheme(Themes[defaultTheme])
+}
+
+// applyTheme rebuilds every package-level style from t.
+func applyTheme(t Theme) {
+	activeTheme = t
+
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("205"))
+		Bold(true).
+		Foreground(t.Accent)
 
 	StatusBarStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+		Foreground(t.Muted)
 
-	// StatusBarInfoStyle is for the left section showing table name and page info.
 	StatusBarInfoStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("205")).
-				Background(lipgloss.Color("236")).
-				Bold(true).
-				Padding(0, 1)
+		Foreground(t.Accent).
+		Background(t.StatusInfoBg).
+		Bold(true).
+		Padding(0, 1)
 
-	// StatusBarKeyStyle highlights the key binding name (e.g. "f", "enter").
 	StatusBarKeyStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("252")).
-				Background(lipgloss.Color("235"))
+		Foreground(t.StatusKeyFg).
+		Background(t.StatusBarBg)
 
-	// StatusBarDescStyle is for the key description (e.g. "filter", "detail").
 	StatusBarDescStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("242")).
-				Background(lipgloss.Color("235"))
+		Foreground(t.StatusDescFg).
+		Background(t.StatusBarBg)
 
-	// StatusBarBgStyle is the base background for the full status bar.
 	StatusBarBgStyle = lipgloss.NewStyle().
-				Background(lipgloss.Color("235"))
+		Background(t.StatusBarBg)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+		Foreground(t.Error).
+		Bold(true)
 
-	// FocusedPaneStyle has a bright border — applied to the active panel.
-	// Width/Height are set dynamically at render time via .Width()/.Height().
 	FocusedPaneStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("62"))
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.FocusBorder)
 
-	// UnfocusedPaneStyle has a dim border — applied to the inactive panel.
 	UnfocusedPaneStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("240"))
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.UnfocusBorder)
 
-	// PopupStyle wraps the row detail modal. Bright border + background
-	// so it visually "floats" above the split pane behind it.
 	PopupStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("205")).
-			Padding(1, 2)
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.Accent).
+		Padding(1, 2)
 
-	// PopupLabelStyle is for the column names in the key-value list.
 	PopupLabelStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("63"))
+		Bold(true).
+		Foreground(t.Label)
+
+	TabStyle = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		Padding(0, 1)
+
+	ActiveTabStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true).
+		Underline(true).
+		Padding(0, 1)
+
+	MatchStyle = lipgloss.NewStyle().
+		Foreground(t.MatchFg).
+		Background(t.MatchBg)
 
 	Logo = TitleStyle.Render(
 		" ▄▄▄▄  ▄▄▄  ▄▄    ▄▄ ▄▄▄▄▄▄ ▄▄ ▄▄ ▄▄ \n" +
 			"███▄▄ ██▀██ ██    ██   ██   ██ ██ ██ \n" +
 			"▄▄██▀ ▀███▀ ██▄▄▄ ██   ██   ▀███▀ ██ \n" +
 			"         ▀▀                          ")
-)
+}
+
+// tableStyles returns the bubbles/table styles for the data grid.
+func tableStyles() table.Styles {
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(activeTheme.UnfocusBorder).
+		BorderBottom(true).
+		Bold(true)
+	s.Selected = s.Selected.
+		Foreground(activeTheme.SelectedFg).
+		Background(activeTheme.SelectedBg).
+		Bold(false)
+	return s
+}
@@ -1,57 +1,162 @@
 package ui
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 
 	"github.com/markovic-nikola/sqlitui/db"
+	"github.com/markovic-nikola/sqlitui/state"
 )
 
 // RowSelectedMsg is sent when the user presses enter on a row.
 // Carries column names + that row's values so the popup can display them,
 // plus the table name and rowid so destructive actions can target the row.
+// Editable is false for query results, which have no row to write back to.
+// Timestamps marks the columns shown as dates, if any.
 type RowSelectedMsg struct {
-	Columns   []string
-	Values    []string
-	TableName string
-	RowID     int64
+	Columns    []string
+	Values     []string
+	TableName  string
+	RowID      int64
+	Editable   bool
+	Timestamps []timestampKind
 }
 
 // filterState tracks the two-step filter flow.
 type filterState int
 
 const (
-	filterOff     filterState = iota // normal table mode
-	filterPickCol                    // picking a column
-	filterInput                      // typing a value
+	filterOff       filterState = iota // normal table mode
+	filterPickCol                      // picking a column
+	filterInput                        // typing a value
+	filterPickValue                    // picking one of the column's distinct values
 )
 
 // pageDataLoadedMsg carries the result of loading a specific page.
+// gridID identifies the TableDataModel that requested it, since more than
+// one grid can be on screen at a time.
 type pageDataLoadedMsg struct {
-	rows      [][]string
-	rowIDs    []int64
-	page      int
-	pageSize  int
-	totalRows int
-	cursorEnd bool // when true, place cursor at the last row
+	gridID     int
+	rows       [][]string
+	rowIDs     []int64
+	page       int
+	pageSize   int
+	hasMore    bool     // another page follows
+	snippets   []string // FTS5 match snippets for the filter column, one per row
+	filtered   bool     // the rows matching a filter
+	cursorEnd  bool     // when true, place cursor at the last row
+	keepCursor bool     // a reload of the same page: leave the cursor where it was
+	cursorRow  int      // otherwise, place cursor on this row
 }
 
+// rowCountMsg carries a row count run in the background by countCmd or
+// filterCountCmd. gen ties it to the request, so a count superseded by a
+// newer one (a refresh, another filter) is dropped.
+type rowCountMsg struct {
+	gridID    int
+	gen       int
+	filtered  bool // the count of filter matches rather than of the table
+	total     int
+	estimated bool // total is an estimate from MAX(rowid)
+	cancelled bool // stopped with Keys.Cancel, rather than failed
+}
+
+// unknownTotal stands in for a row count that isn't known: not counted
+// yet, or COUNT(*) failed — some virtual tables reject it and some views
+// take too long. Paging doesn't depend on it, since every page reads one
+// row past its end to see whether more follow.
+const unknownTotal = -1
+
+// countTimeout bounds how long a background COUNT(*) may run before the
+// grid gives up on knowing the total.
+const countTimeout = 30 * time.Second
+
+// countRows counts a table's rows, or only those matching the filter when
+// fCol is set, returning unknownTotal if the count fails, times out, or is
+// cancelled through ctx.
+func countRows(ctx context.Context, database *sql.DB, tableName, fCol, fQuery string, mode db.MatchMode) int {
+	var total int
+	var err error
+	if fCol != "" {
+		total, err = db.CountFilteredRows(ctx, database, tableName, fCol, fQuery, mode)
+	} else {
+		total, err = db.CountRows(ctx, database, tableName)
+	}
+	if err != nil {
+		return unknownTotal
+	}
+	return total
+}
+
+// rowCounter counts the grid's rows as countRows does: those of its
+// source query for a paged grid.
+func (m TableDataModel) rowCounter() func(ctx context.Context, fCol, fQuery string, mode db.MatchMode) int {
+	database, tableName := m.database, m.tableName
+	if !m.paged {
+		return func(ctx context.Context, fCol, fQuery string, mode db.MatchMode) int {
+			return countRows(ctx, database, tableName, fCol, fQuery, mode)
+		}
+	}
+	query, args, columns := m.sourceQuery, m.sourceArgs, m.columns
+	return func(ctx context.Context, fCol, fQuery string, mode db.MatchMode) int {
+		f := db.ResultFilter{Column: slices.Index(columns, fCol), Query: fQuery, Mode: mode}
+		total, err := db.CountResult(ctx, database, query, args, columns, f)
+		if err != nil {
+			return unknownTotal
+		}
+		return total
+	}
+}
+
+// trimPage drops the probe row fetched past the page, reporting whether
+// there was one. rowIDs is nil for rows without them.
+func trimPage(rows [][]string, rowIDs []int64, pageSize int) ([][]string, []int64, bool) {
+	if len(rows) <= pageSize {
+		return rows, rowIDs, false
+	}
+	if rowIDs != nil {
+		rowIDs = rowIDs[:pageSize]
+	}
+	return rows[:pageSize], rowIDs, true
+}
+
+// Names shown for in-memory grids that have no backing table.
+const (
+	queryResultName   = "query result"
+	schemaObjectsName = "schema objects"
+)
+
 const (
-	minColWidth     = 10 // minimum width for any data column
-	maxColWidth     = 40 // maximum width for any data column
 	colPadding      = 3  // padding added to measured content width
 	indicatorColLen = 12 // reserved width for the "+ N cols" indicator column
 )
 
+// Column width limits. Variables rather than constants because the user
+// config can override them at startup (see applyConfig).
+var (
+	minColWidth = 10 // minimum width for any data column
+	maxColWidth = 40 // maximum width for any data column
+)
+
 // TableDataModel wraps bubbles/table.Model to display rows from a DB table.
 // It also stores the raw data so we can pass it to the popup on selection.
 type TableDataModel struct {
+	id          int // assigned by the parent; routes async page loads back to this grid
 	table       table.Model
 	tableName   string
 	columns     []string   // all columns from the DB
@@ -62,21 +167,112 @@ type TableDataModel struct {
 	width       int
 	height      int
 
+	// In-memory grids (query results, schema objects) hold every row up
+	// front and filter them locally instead of querying a table.
+	// sourceQuery is the SQL a query result or view came from, sourceArgs
+	// the values bound to its parameters, and sourceTimeout the timeout it
+	// ran with, so it can run again (see rerunCmd). A paged one reads its
+	// rows from sourceQuery a page of pageRows at a time instead, sorted
+	// and filtered there: a view's, or a query result's once run again.
+	static        bool
+	staticRows    [][]string
+	sourceQuery   string
+	sourceArgs    []any
+	sourceTimeout time.Duration
+	paged         bool
+	pageRows      int
+	// queryStats tells how the query went, and prevElapsed how long the
+	// query result it replaced took (0 if none), to compare the two.
+	queryStats  *db.QueryStats
+	prevElapsed time.Duration
+
 	// Pagination state.
-	page      int // current page (0-indexed)
-	pageSize  int // rows per page
-	totalRows int // total rows in table (from COUNT(*))
+	page      int  // current page (0-indexed)
+	pageSize  int  // rows per page
+	totalRows int  // total rows in table (from COUNT(*)), or unknownTotal
+	estimated bool // totalRows is a MAX(rowid) estimate until the count arrives
+	uncounted bool // COUNT(*) failed for this table; don't retry it on every page
+	hasMore   bool // another page follows this one
+
+	// Background counts: the generation of the latest request for the
+	// table and filter counts, whether it is still running, and how to
+	// stop it.
+	countGen, fCountGen       int
+	counting, fCounting       bool
+	countCancel, fCountCancel context.CancelFunc
+
+	// loads is what page and filter loads run under, so they can be
+	// cancelled too.
+	loads *loadScope
+
+	// leading are the row number and rowid columns shown ahead of the
+	// data, when switched on.
+	leading []table.Column
+
+	// wrap draws the selected row over as many lines as its values need.
+	wrap bool
+
+	// jump asks, in jumpInput, for a page or row to go to, or text to
+	// search the page for.
+	jump      jumpKind
+	jumpInput textinput.Model
+
+	// pendingKeys are the keys pressed so far of a vim key sequence, like
+	// the first g of "g g".
+	pendingKeys []string
+
+	// search is the text searched for on the page (Keys.Search), whose
+	// cells are marked, and searchFrom the row the cursor was on when the
+	// search prompt opened, which matches are looked for from as it is
+	// typed.
+	search     string
+	searchFrom int
+
+	// tail lists the newest rows first and re-reads them periodically,
+	// like tail -f on a log table. Page 0 is the newest page.
+	tail        bool
+	tailCounted time.Time // when the tail last counted the rows in full
+
+	// sort lists the columns the rows are sorted by, in turn; with none
+	// they come in the table's order.
+	sort []db.SortKey
+
+	// widths holds the widths columns were resized to (see columnWidths);
+	// resizing says a column is picked for it, resizeCol which.
+	widths    columnWidths
+	resizing  bool
+	resizeCol int
 
 	// Filter state.
 	fState     filterState
-	fColIndex  int             // highlighted column in the picker
+	fPick      columnPick      // what the column picker is open for
+	fColIndex  int             // highlighted entry in fColMatch
 	fColScroll int             // scroll offset for column picker
+	fColSearch string          // incremental search text in the picker
+	fColMatch  []int           // indices into columns matching fColSearch
+	colTypes   []string        // declared column types, loaded when the picker first opens
 	fCol       string          // selected column name
+	fValues    []db.ValueCount // the column's distinct values, when few enough to pick from
+	fValSearch string          // incremental search text in the value picker
+	fValMatch  []int           // indices into fValues matching fValSearch
+	fValIndex  int             // highlighted entry in fValMatch
+	fValScroll int             // scroll offset for the value picker
 	fInput     textinput.Model // value input
 	fActive    bool            // true when a confirmed filter is applied
 	fQuery     string          // the confirmed filter text
+	fMode      db.MatchMode    // substring, fuzzy, or FTS5 match, toggled while typing
+	fts        bool            // the table is an FTS5 index
+	keyCol     int             // column rows are bookmarked by (see loadRowKey)
+	keyChecked bool            // keyCol has been looked up
+	bookmarks  *bookmarks      // rows marked on this database; nil for none
+	ftsChecked bool            // fts has been looked up, when a filter column is first picked
+	snippets   []string        // FTS5 snippets shown in the filter column in place of allRows' values
 	fTotalRows int             // total count of filtered rows
 	fPrevPage  int             // page before filter was opened
+	history    *filterHistory  // filters confirmed on this database; nil for none
+	histPos    int             // filter recalled from history while typing, 1 = newest; 0 = none
+	histDraft  state.Filter    // what was typed before recalling
+	fFiltered  bool            // allRows holds filter results rather than the unfiltered page
 }
 
 func NewTableDataModel(name string, columns []string, rows [][]string, rowIDs []int64, width, height int, database *sql.DB, page, pageSize, totalRows int) TableDataModel {
@@ -85,54 +281,59 @@ func NewTableDataModel(name string, columns []string, rows [][]string, rowIDs []
 	// bubbles/table with WithHeight(N) outputs N+1 lines.
 	// We need N+1 <= height-2, so N = height-3.
 	tableHeight := height - 3
-	displayCols, colWidths := fitColumns(columns, rows, innerWidth)
+	m := TableDataModel{
+		tableName: name,
+		columns:   columns,
+		allRows:   rows,
+		allRowIDs: rowIDs,
+		database:  database,
+		width:     width,
+		height:    height,
+		page:      page,
+		pageSize:  pageSize,
+		totalRows: totalRows,
+		loads:     newLoadScope(),
+	}
+	m.leading = m.leadingColumns()
+	displayCols, colWidths := fitColumns(columns, rows, innerWidth-leadingSpace(m.leading), nil)
 
-	tableCols := buildTableColumns(columns, displayCols, colWidths, len(columns))
+	tableCols := buildTableColumns(columns, displayCols, colWidths, len(columns), m.leading)
 
 	t := table.New(
 		table.WithColumns(tableCols),
-		table.WithRows(truncateRows(rows, displayCols, displayCols < len(columns))),
 		table.WithFocused(true),
 		table.WithHeight(tableHeight),
 	)
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		BorderBottom(true).
-		Bold(true)
-	s.Selected = s.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
-		Bold(false)
-	t.SetStyles(s)
+	t.SetStyles(tableStyles())
 
 	ti := textinput.New()
-	ti.Placeholder = "filter..."
+	ti.Placeholder = "filter... (" + Keys.FuzzyFilter.Help().Key + ": fuzzy/regexp)"
 	ti.Width = innerWidth - 3
 	// Disable suggestion keybinds to avoid up/down conflicts with the table.
 	ti.KeyMap.NextSuggestion = key.NewBinding()
 	ti.KeyMap.PrevSuggestion = key.NewBinding()
 
-	return TableDataModel{
-		table:       t,
-		tableName:   name,
-		columns:     columns,
-		displayCols: displayCols,
-		allRows:     rows,
-		allRowIDs:   rowIDs,
-		database:    database,
-		width:       width,
-		height:      height,
-		page:        page,
-		pageSize:    pageSize,
-		totalRows:   totalRows,
-		fInput:      ti,
-	}
+	m.table = t
+	m.displayCols = displayCols
+	m.fInput = ti
+	m.jumpInput = newJumpInput()
+	m.setTableRows(rows)
+	return m
+}
+
+// newStaticGrid builds a grid over rows that are already fully loaded, such
+// as a query result. name is shown in place of a table name.
+func newStaticGrid(name string, columns []string, rows [][]string, width, height int, database *sql.DB) TableDataModel {
+	m := NewTableDataModel(name, columns, rows, nil, width, height, database, 0, len(rows), len(rows))
+	m.static = true
+	m.staticRows = rows
+	return m
 }
 
 // pickerVisibleCount returns how many column names are visible in the picker.
+// It is based on the full column count rather than the current matches so the
+// table height doesn't jump around while typing a search.
 func (m TableDataModel) pickerVisibleCount() int {
 	maxVisible := (m.height - 3) / 2
 	if maxVisible < 3 {
@@ -144,103 +345,379 @@ func (m TableDataModel) pickerVisibleCount() int {
 	return maxVisible
 }
 
-func (m TableDataModel) totalPages() int {
-	total := m.totalRows
+// currentTotal is the row count paging works against: the filtered count
+// while a filter is applied, else the table's.
+func (m TableDataModel) currentTotal() int {
 	if m.fActive {
-		total = m.fTotalRows
+		return m.fTotalRows
+	}
+	return m.totalRows
+}
+
+func (m TableDataModel) totalPages() int {
+	total := m.currentTotal()
+	if total == unknownTotal {
+		return m.page + 1
 	}
 	if total <= 0 {
 		return 1
 	}
-	return (total + m.pageSize - 1) / m.pageSize
+	// An estimate can fall short of the page already reached.
+	return max((total+m.pageSize-1)/m.pageSize, m.page+1)
 }
 
 func (m TableDataModel) hasNextPage() bool {
-	return m.page < m.totalPages()-1
+	return m.hasMore
+}
+
+// estimating reports whether the total shown is an estimate.
+func (m TableDataModel) estimating() bool {
+	return m.estimated && !m.fActive
 }
 
 func (m TableDataModel) hasPrevPage() bool {
 	return m.page > 0
 }
 
-func loadPageCmd(database *sql.DB, tableName string, page, pageSize int, cursorEnd bool) tea.Cmd {
-	return func() tea.Msg {
+// loadPageCmd loads a page of a table, reading one row past it to learn
+// whether another page follows. The total is counted separately.
+func loadPageCmd(loads *loadScope, database *sql.DB, gridID int, tableName string, order db.Order, page, pageSize int, cursorEnd bool) tea.Cmd {
+	return track(fmt.Sprintf("loading page %d", page+1), loads.run(func(ctx context.Context) tea.Msg {
 		offset := page * pageSize
-		_, rowIDs, rows, err := db.GetRows(database, tableName, pageSize, offset)
-		if err != nil {
-			return errMsg{err: err}
-		}
-		total, err := db.CountRows(database, tableName)
+		_, rowIDs, rows, err := db.GetRows(ctx, database, tableName, order, pageSize+1, offset)
 		if err != nil {
 			return errMsg{err: err}
 		}
+		rows, rowIDs, hasMore := trimPage(rows, rowIDs, pageSize)
 		return pageDataLoadedMsg{
+			gridID:    gridID,
 			rows:      rows,
 			rowIDs:    rowIDs,
 			page:      page,
 			pageSize:  pageSize,
-			totalRows: total,
+			hasMore:   hasMore,
 			cursorEnd: cursorEnd,
 		}
-	}
+	}))
 }
 
-func loadFilteredPageCmd(database *sql.DB, tableName, fCol, fQuery string, page, pageSize int, cursorEnd bool) tea.Cmd {
-	return func() tea.Msg {
-		offset := page * pageSize
-		_, rowIDs, rows, err := db.FilterColumn(database, tableName, fCol, fQuery, pageSize, offset)
-		if err != nil {
-			return errMsg{err: err}
-		}
-		total, err := db.CountFilteredRows(database, tableName, fCol, fQuery)
+func loadFilteredPageCmd(loads *loadScope, database *sql.DB, gridID int, tableName, fCol, fQuery string, mode db.MatchMode, order db.Order, page, pageSize int, cursorEnd bool) tea.Cmd {
+	return track("filtering "+tableName, loads.run(func(ctx context.Context) tea.Msg {
+		msg, err := filteredPage(ctx, database, tableName, fCol, fQuery, mode, order, page, pageSize)
 		if err != nil {
 			return errMsg{err: err}
 		}
-		return pageDataLoadedMsg{
-			rows:      rows,
-			rowIDs:    rowIDs,
-			page:      page,
-			pageSize:  pageSize,
-			totalRows: total,
-			cursorEnd: cursorEnd,
-		}
+		msg.gridID, msg.cursorEnd = gridID, cursorEnd
+		return msg
+	}))
+}
+
+// filteredPage reads a page of the rows matching a filter, one row past
+// it to learn whether another page follows.
+func filteredPage(ctx context.Context, database *sql.DB, tableName, fCol, fQuery string, mode db.MatchMode, order db.Order, page, pageSize int) (pageDataLoadedMsg, error) {
+	rowIDs, rows, snippets, err := filterRows(ctx, database, tableName, fCol, fQuery, mode, order, pageSize+1, page*pageSize)
+	if err != nil {
+		return pageDataLoadedMsg{}, err
 	}
+	rows, rowIDs, hasMore := trimPage(rows, rowIDs, pageSize)
+	return pageDataLoadedMsg{
+		rows:     rows,
+		rowIDs:   rowIDs,
+		page:     page,
+		pageSize: pageSize,
+		hasMore:  hasMore,
+		snippets: snippets[:min(len(snippets), len(rows))],
+		filtered: true,
+	}, nil
 }
 
-func (m TableDataModel) nextPageCmd() tea.Cmd {
+// filterRows runs a filter query, through FilterFTS for MatchFTS so the
+// rows come with snippets of their matches.
+func filterRows(ctx context.Context, database *sql.DB, tableName, fCol, fQuery string, mode db.MatchMode, order db.Order, limit, offset int) ([]int64, [][]string, []string, error) {
+	if mode == db.MatchFTS {
+		return db.FilterFTS(ctx, database, tableName, fCol, fQuery, order, limit, offset)
+	}
+	_, rowIDs, rows, err := db.FilterColumn(ctx, database, tableName, fCol, fQuery, mode, order, limit, offset)
+	return rowIDs, rows, nil, err
+}
+
+// pageCmd loads the given page, honoring the active filter.
+func (m TableDataModel) pageCmd(page int, cursorEnd bool) tea.Cmd {
+	if m.paged {
+		query := ""
+		if m.fActive {
+			query = m.fQuery
+		}
+		return m.resultPageCmd(query, page, cursorEnd)
+	}
 	if m.fActive {
-		return loadFilteredPageCmd(m.database, m.tableName, m.fCol, m.fQuery, m.page+1, m.pageSize, false)
+		return loadFilteredPageCmd(m.loads, m.database, m.id, m.tableName, m.fCol, m.fQuery, m.fMode, m.rowOrder(), page, m.pageSize, cursorEnd)
 	}
-	return loadPageCmd(m.database, m.tableName, m.page+1, m.pageSize, false)
+	return loadPageCmd(m.loads, m.database, m.id, m.tableName, m.rowOrder(), page, m.pageSize, cursorEnd)
+}
+
+// rowOrder is the order the grid lists a table's rows in.
+func (m TableDataModel) rowOrder() db.Order {
+	return db.Order{NewestFirst: m.tail, By: m.sort}
+}
+
+func (m TableDataModel) nextPageCmd() tea.Cmd {
+	return m.pageCmd(m.page+1, false)
 }
 
 func (m TableDataModel) prevPageCmd() tea.Cmd {
+	return m.pageCmd(m.page-1, true)
+}
+
+// refreshCmd re-reads the current page and recounts the rows, since the
+// table may have changed.
+func (m *TableDataModel) refreshCmd() tea.Cmd {
+	cmds := []tea.Cmd{m.pageCmd(m.page, false), m.countCmd()}
 	if m.fActive {
-		return loadFilteredPageCmd(m.database, m.tableName, m.fCol, m.fQuery, m.page-1, m.pageSize, true)
+		cmds = append(cmds, m.filterCountCmd(m.fQuery))
 	}
-	return loadPageCmd(m.database, m.tableName, m.page-1, m.pageSize, true)
+	return tea.Batch(cmds...)
 }
 
-func (m TableDataModel) refreshCmd() tea.Cmd {
-	if m.fActive {
-		return loadFilteredPageCmd(m.database, m.tableName, m.fCol, m.fQuery, m.page, m.pageSize, false)
+// reloadCmd re-reads the current page in place, keeping the cursor on the
+// same row position — for reloads the user didn't ask for. These can come
+// every second, so a recount only starts once the previous one is done.
+func (m *TableDataModel) reloadCmd() tea.Cmd {
+	cmds := []tea.Cmd{m.reloadPageCmd()}
+	if !m.counting {
+		cmds = append(cmds, m.countCmd())
+	}
+	if m.fActive && !m.fCounting {
+		cmds = append(cmds, m.filterCountCmd(m.fQuery))
+	}
+	return tea.Batch(cmds...)
+}
+
+// reloadPageCmd re-reads the current page, keeping the cursor where it is.
+func (m TableDataModel) reloadPageCmd() tea.Cmd {
+	load := m.pageCmd(m.page, false)
+	return func() tea.Msg {
+		msg := load()
+		if page, ok := msg.(pageDataLoadedMsg); ok {
+			page.keepCursor = true
+			return page
+		}
+		return msg
+	}
+}
+
+// estimateCmd estimates the table's rows from MAX(rowid) in the background,
+// unless a count is running. A table without rowids keeps its count.
+func (m *TableDataModel) estimateCmd() tea.Cmd {
+	if m.inMemory() || m.paged || m.counting {
+		return nil
+	}
+	m.countGen++
+	msg := rowCountMsg{gridID: m.id, gen: m.countGen, estimated: true}
+	database, table := m.database, m.tableName
+	return func() tea.Msg {
+		n, err := db.EstimateRows(context.Background(), database, table)
+		if err != nil {
+			return nil
+		}
+		msg.total = n
+		return msg
+	}
+}
+
+// countCmd counts the table's rows in the background, superseding a count
+// still running. Tables that failed to count aren't retried.
+func (m *TableDataModel) countCmd() tea.Cmd {
+	if m.inMemory() || m.uncounted {
+		return nil
+	}
+	if m.countCancel != nil {
+		m.countCancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	m.countGen++
+	m.counting = true
+	m.countCancel = cancel
+	msg := rowCountMsg{gridID: m.id, gen: m.countGen}
+	count := m.rowCounter()
+	return track("counting "+m.tableName, func() tea.Msg {
+		defer cancel()
+		msg.total = count(ctx, "", "", db.MatchSubstring)
+		msg.cancelled = errors.Is(ctx.Err(), context.Canceled)
+		return msg
+	})
+}
+
+// filterCountCmd counts the rows matching query in the filter column in
+// the background. Until it arrives the filtered total is unknown.
+func (m *TableDataModel) filterCountCmd(query string) tea.Cmd {
+	if m.inMemory() {
+		return nil
+	}
+	m.fTotalRows = unknownTotal
+	if m.uncounted {
+		return nil
+	}
+	if m.fCountCancel != nil {
+		m.fCountCancel()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), countTimeout)
+	m.fCountGen++
+	m.fCounting = true
+	m.fCountCancel = cancel
+	msg := rowCountMsg{gridID: m.id, gen: m.fCountGen, filtered: true}
+	count, fCol, mode := m.rowCounter(), m.fCol, m.fMode
+	return track("counting matches", func() tea.Msg {
+		defer cancel()
+		msg.total = count(ctx, fCol, query, mode)
+		return msg
+	})
+}
+
+// applyCount installs a background count, unless a newer one has been
+// asked for since.
+func (m *TableDataModel) applyCount(msg rowCountMsg) {
+	if msg.filtered {
+		if msg.gen == m.fCountGen {
+			m.fTotalRows = msg.total
+			m.fCounting = false
+			m.fCountCancel = nil
+		}
+		return
+	}
+	if msg.gen != m.countGen {
+		return
+	}
+	m.totalRows = msg.total
+	m.estimated = msg.estimated
+	m.uncounted = msg.total == unknownTotal && !msg.cancelled
+	m.counting = false
+	m.countCancel = nil
+}
+
+// cancelCounts stops the background counts still running, reporting
+// whether there were any. A cancelled count arrives as unknownTotal, so
+// the table pages without a total until a refresh counts it again.
+func (m *TableDataModel) cancelCounts() bool {
+	running := m.counting || m.fCounting
+	if m.countCancel != nil {
+		m.countCancel()
+	}
+	if m.fCountCancel != nil {
+		m.fCountCancel()
+	}
+	return running
+}
+
+// busy reports whether the grid has loads or counts running, which
+// Keys.Cancel stops.
+func (m TableDataModel) busy() bool {
+	return m.counting || m.fCounting || m.loads.busy()
+}
+
+// cancelLoads stops the grid's page and filter loads and its counts,
+// reporting whether any were running.
+func (m *TableDataModel) cancelLoads() bool {
+	pages := m.loads.cancel()
+	return m.cancelCounts() || pages
+}
+
+// applyPage installs a page loaded by pageCmd.
+func (m *TableDataModel) applyPage(msg pageDataLoadedMsg) {
+	m.allRows = msg.rows
+	m.allRowIDs = msg.rowIDs
+	m.snippets = msg.snippets
+	m.fFiltered = msg.filtered
+	m.page = msg.page
+	m.hasMore = msg.hasMore
+	cursor := m.table.Cursor()
+	m.setTableRows(msg.rows)
+	switch {
+	case msg.keepCursor:
+		m.table.SetCursor(min(cursor, max(len(msg.rows)-1, 0)))
+	case msg.cursorEnd && len(msg.rows) > 0:
+		m.table.SetCursor(len(msg.rows) - 1)
+		m.table.GotoBottom()
+	default:
+		m.table.SetCursor(min(msg.cursorRow, max(len(msg.rows)-1, 0)))
 	}
-	return loadPageCmd(m.database, m.tableName, m.page, m.pageSize, false)
 }
 
 func (m *TableDataModel) SetSize(width, height int) {
 	m.width = width
 	m.height = height
-	innerWidth := width - 2
 
-	displayCols, colWidths := fitColumns(m.columns, m.allRows, innerWidth)
+	cursor := m.table.Cursor()
+	m.refitColumns()
+	m.setTableRows(m.allRows)
+	m.table.SetCursor(max(cursor, 0))
+	m.table.SetHeight(m.tableHeight())
+	m.fInput.Width = width - 2 - 3
+}
+
+// refitColumns works out again which columns fit the grid's width, and how
+// wide each is, from the current rows.
+func (m *TableDataModel) refitColumns() {
+	m.leading = m.leadingColumns()
+	innerWidth := m.width - 2 - leadingSpace(m.leading)
+	titles := m.columnTitles()
+	displayCols, colWidths := fitColumns(titles, m.withTimestamps(m.allRows), innerWidth, m.setWidthsOf())
 	m.displayCols = displayCols
 	// Clear rows before SetColumns so the intermediate re-render can't index a row cell beyond the new columns.
 	m.table.SetRows(nil)
-	m.table.SetColumns(buildTableColumns(m.columns, displayCols, colWidths, len(m.columns)))
-	m.table.SetRows(truncateRows(m.allRows, m.displayCols, m.hasHiddenCols()))
-	m.table.SetHeight(m.tableHeight())
-	m.fInput.Width = innerWidth - 3
+	m.table.SetColumns(buildTableColumns(titles, displayCols, colWidths, len(m.columns), m.leading))
+}
+
+// setTableRows hands rows to the table widget with each visible column,
+// header included, aligned for the kind of values it holds, after the
+// leading columns. rows must be the grid's current rows.
+func (m *TableDataModel) setTableRows(rows [][]string) {
+	// Another page can need wider leading columns.
+	if !slices.Equal(m.leadingColumns(), m.leading) {
+		m.refitColumns()
+	}
+	rows = m.withTimestamps(rows)
+	aligns := columnAlignments(m.tableName, m.columns, rows, m.displayCols)
+	cols := m.table.Columns()
+	first := len(m.leading)
+	for i, c := range m.leading {
+		cols[i].Title = alignCell(c.Title, c.Width, lipgloss.Right)
+	}
+	for i, pos := range aligns {
+		title := fitTitle(m.columns[i], m.sortMarker(i), cols[first+i].Width)
+		cols[first+i].Title = alignCell(title, cols[first+i].Width, pos)
+	}
+	m.table.SetColumns(cols)
+
+	out := truncateRows(m.withSnippets(rows), m.displayCols, m.hasHiddenCols())
+	for n, r := range out {
+		for i, pos := range aligns {
+			r[i] = alignCell(r[i], cols[first+i].Width, pos)
+		}
+		lead := m.leadingCells(n)
+		for i, c := range m.leading {
+			lead[i] = alignCell(lead[i], c.Width, lipgloss.Right)
+		}
+		out[n] = slices.Insert(r, 0, lead...)
+	}
+	m.table.SetRows(out)
+}
+
+// withSnippets shows each row's FTS5 snippet, when there is one, in place of
+// its filter column. The rows themselves keep the full value.
+func (m TableDataModel) withSnippets(rows [][]string) [][]string {
+	col := slices.Index(m.columns, m.fCol)
+	if len(m.snippets) != len(rows) || col < 0 {
+		return rows
+	}
+	out := make([][]string, len(rows))
+	for i, r := range rows {
+		out[i] = slices.Clone(r)
+		if col < len(r) {
+			out[i][col] = m.snippets[i]
+		}
+	}
+	return out
 }
 
 func (m TableDataModel) hasHiddenCols() bool {
@@ -252,9 +729,14 @@ func (m TableDataModel) tableHeight() int {
 	h := m.height - 3
 	switch m.fState {
 	case filterPickCol:
-		h -= m.pickerVisibleCount()
+		h -= m.pickerVisibleCount() + 1 // +1 for the search line
 	case filterInput:
 		h--
+	case filterPickValue:
+		h -= m.valuePickerVisibleCount() + 1 // +1 for the search line
+	}
+	if m.jump != jumpOff || m.resizing {
+		h--
 	}
 	if h < 3 {
 		h = 3
@@ -265,11 +747,19 @@ func (m TableDataModel) tableHeight() int {
 func (m TableDataModel) Update(msg tea.Msg) (TableDataModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.resizing {
+			return m.updateResize(msg)
+		}
+		if m.jump != jumpOff {
+			return m.updateJump(msg)
+		}
 		switch m.fState {
 		case filterPickCol:
 			return m.updatePickCol(msg)
 		case filterInput:
 			return m.updateFilterInput(msg)
+		case filterPickValue:
+			return m.updatePickValue(msg)
 		default:
 			return m.updateNormal(msg)
 		}
@@ -281,12 +771,28 @@ func (m TableDataModel) Update(msg tea.Msg) (TableDataModel, tea.Cmd) {
 }
 
 func (m TableDataModel) updateNormal(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
-	if msg.String() == "f" {
-		m.fState = filterPickCol
-		m.fColIndex = 0
-		m.fColScroll = 0
+	if m, cmd, ok := m.updateVim(msg); ok {
+		return m, cmd
+	}
+
+	if key.Matches(msg, Keys.Filter) {
+		m.fPick = pickFilterCol
 		m.fPrevPage = m.page
-		m.table.SetHeight(m.tableHeight())
+		m.openColumnPicker()
+		return m, nil
+	}
+
+	if key.Matches(msg, Keys.ResizeColumns) {
+		m.startResize()
+		return m, nil
+	}
+
+	if key.Matches(msg, Keys.Sort, Keys.ThenSort) {
+		return m, m.startSort(key.Matches(msg, Keys.ThenSort))
+	}
+
+	if key.Matches(msg, Keys.WrapRow) {
+		m.wrap = !m.wrap
 		return m, nil
 	}
 
@@ -294,6 +800,18 @@ func (m TableDataModel) updateNormal(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
 		return m, m.nextPageCmd()
 	}
 
+	if key.Matches(msg, Keys.GoToPage) && !m.inMemory() {
+		return m, m.startJump(jumpPage)
+	}
+
+	if key.Matches(msg, Keys.GoToRow) {
+		return m, m.startJump(jumpRow)
+	}
+
+	if key.Matches(msg, Keys.Bookmark) && !m.static {
+		return m, m.toggleBookmark()
+	}
+
 	if key.Matches(msg, Keys.PrevPage) && m.hasPrevPage() {
 		return m, m.prevPageCmd()
 	}
@@ -319,10 +837,12 @@ func (m TableDataModel) updateNormal(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
 			}
 			return m, func() tea.Msg {
 				return RowSelectedMsg{
-					Columns:   m.columns,
-					Values:    m.allRows[cursor],
-					TableName: m.tableName,
-					RowID:     rowID,
+					Columns:    m.columns,
+					Values:     m.allRows[cursor],
+					TableName:  m.tableName,
+					RowID:      rowID,
+					Editable:   !m.static,
+					Timestamps: m.timestampKinds(),
 				}
 			}
 		}
@@ -333,103 +853,351 @@ func (m TableDataModel) updateNormal(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
 	return m, cmd
 }
 
+// openColumnPicker shows the column picker, for what fPick says.
+func (m *TableDataModel) openColumnPicker() {
+	m.fState = filterPickCol
+	m.fColIndex = 0
+	m.fColScroll = 0
+	m.fColSearch = ""
+	m.updateColumnMatches()
+	m.loadColumnTypes()
+	m.table.SetHeight(m.tableHeight())
+}
+
 func (m TableDataModel) updatePickCol(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
+	visible := m.pickerVisibleCount()
+
 	switch msg.String() {
 	case "esc":
-		m.fState = filterOff
-		m.fActive = false
-		m.fQuery = ""
-		m.fTotalRows = 0
-		m.page = m.fPrevPage
-		m.table.SetRows(truncateRows(m.allRows, m.displayCols, m.hasHiddenCols()))
-		m.table.SetCursor(0)
-		m.table.SetHeight(m.tableHeight())
+		if m.fPick != pickFilterCol {
+			m.fState = filterOff
+			m.table.SetHeight(m.tableHeight())
+			return m, nil
+		}
+		return m, m.clearFilter()
+
+	case "up", "ctrl+p":
+		m.moveColumnCursor(-1)
 		return m, nil
 
-	case "up", "k":
-		if m.fColIndex > 0 {
-			m.fColIndex--
-			if m.fColIndex < m.fColScroll {
-				m.fColScroll = m.fColIndex
-			}
-		}
+	case "down", "ctrl+n":
+		m.moveColumnCursor(1)
 		return m, nil
 
-	case "down", "j":
-		if m.fColIndex < len(m.columns)-1 {
-			m.fColIndex++
-			visible := m.pickerVisibleCount()
-			if m.fColIndex >= m.fColScroll+visible {
-				m.fColScroll = m.fColIndex - visible + 1
-			}
+	case "pgup":
+		m.moveColumnCursor(-visible)
+		return m, nil
+
+	case "pgdown":
+		m.moveColumnCursor(visible)
+		return m, nil
+
+	case "home":
+		m.moveColumnCursor(-len(m.fColMatch))
+		return m, nil
+
+	case "end":
+		m.moveColumnCursor(len(m.fColMatch))
+		return m, nil
+
+	case "backspace":
+		if m.fColSearch != "" {
+			r := []rune(m.fColSearch)
+			m.fColSearch = string(r[:len(r)-1])
+			m.updateColumnMatches()
 		}
 		return m, nil
 
 	case "enter":
-		m.fCol = m.columns[m.fColIndex]
-		m.fState = filterInput
-		m.fInput.Prompt = m.fCol + ": "
-		m.fInput.Reset()
-		m.table.SetHeight(m.tableHeight())
-		cmd := m.fInput.Focus()
-		return m, cmd
+		if len(m.fColMatch) == 0 {
+			return m, nil
+		}
+		if m.fPick != pickFilterCol {
+			return m, m.sortBy(m.fColMatch[m.fColIndex], m.fPick == pickThenSortCol)
+		}
+		m.fCol = m.columns[m.fColMatch[m.fColIndex]]
+		if m.loadDistinctValues() {
+			m.fState = filterPickValue
+			m.table.SetHeight(m.tableHeight())
+			return m, nil
+		}
+		return m, m.startFilterInput("")
+	}
+
+	// Any printable input narrows the column list.
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		m.fColSearch += string(msg.Runes)
+		m.updateColumnMatches()
 	}
 
 	return m, nil
 }
 
+// startFilterInput switches to typing the filter value, starting from
+// value.
+func (m *TableDataModel) startFilterInput(value string) tea.Cmd {
+	m.fState = filterInput
+	m.fMode = m.defaultMatchMode()
+	m.histPos = 0
+	m.fInput.Prompt = m.filterPrompt()
+	m.fInput.Placeholder = m.filterPlaceholder()
+	m.fInput.SetValue(value)
+	m.fInput.CursorEnd()
+	m.table.SetHeight(m.tableHeight())
+	cmd := m.fInput.Focus()
+	if value == "" {
+		return cmd
+	}
+	return tea.Batch(cmd, m.applyFilter())
+}
+
+// moveColumnCursor moves the picker highlight by delta entries, clamping to
+// the match list and keeping the highlight within the scroll window.
+func (m *TableDataModel) moveColumnCursor(delta int) {
+	if len(m.fColMatch) == 0 {
+		return
+	}
+	m.fColIndex = max(0, min(m.fColIndex+delta, len(m.fColMatch)-1))
+	visible := m.pickerVisibleCount()
+	if m.fColIndex < m.fColScroll {
+		m.fColScroll = m.fColIndex
+	}
+	if m.fColIndex >= m.fColScroll+visible {
+		m.fColScroll = m.fColIndex - visible + 1
+	}
+}
+
+// updateColumnMatches recomputes which columns match the picker search
+// (case-insensitive substring) and resets the highlight to the first one.
+func (m *TableDataModel) updateColumnMatches() {
+	needle := strings.ToLower(m.fColSearch)
+	var matches []int
+	for i, col := range m.columns {
+		if strings.Contains(strings.ToLower(col), needle) {
+			matches = append(matches, i)
+		}
+	}
+	m.fColMatch = matches
+	m.fColIndex = 0
+	m.fColScroll = 0
+}
+
+// loadColumnTypes fetches declared column types for the picker. In-memory
+// grids have no backing table, so they simply show no types.
+func (m *TableDataModel) loadColumnTypes() {
+	if m.colTypes != nil || m.database == nil || m.static {
+		return
+	}
+	info, err := db.GetColumnInfo(context.Background(), m.database, m.tableName)
+	if err != nil {
+		return
+	}
+	types := make([]string, len(m.columns))
+	byName := make(map[string]string, len(info))
+	for _, c := range info {
+		byName[c.Name] = c.Type
+	}
+	for i, col := range m.columns {
+		types[i] = byName[col]
+	}
+	m.colTypes = types
+}
+
 func (m TableDataModel) updateFilterInput(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		m.fInput.Blur()
 		m.fInput.Reset()
-		m.fState = filterOff
-		m.fActive = false
-		m.fQuery = ""
-		m.fTotalRows = 0
-		m.page = m.fPrevPage
-		m.table.SetRows(truncateRows(m.allRows, m.displayCols, m.hasHiddenCols()))
-		m.table.SetCursor(0)
-		m.table.SetHeight(m.tableHeight())
-		return m, nil
+		return m, m.clearFilter()
 
 	case "enter":
+		if m.fInput.Value() == "" {
+			m.fMode = m.defaultMatchMode()
+		}
 		m.fInput.Blur()
 		m.fActive = m.fInput.Value() != ""
 		m.fQuery = m.fInput.Value()
 		m.fState = filterOff
 		m.table.SetHeight(m.tableHeight())
+		m.recordFilter()
 		return m, nil
+
+	case "up":
+		return m, m.recallFilter(1)
+
+	case "down":
+		return m, m.recallFilter(-1)
+	}
+
+	if key.Matches(msg, Keys.FuzzyFilter) {
+		switch {
+		case m.fMode == db.MatchFTS:
+			m.fMode = db.MatchSubstring
+		case m.fMode == db.MatchSubstring:
+			m.fMode = db.MatchFuzzy
+		case m.fMode == db.MatchFuzzy:
+			m.fMode = db.MatchRegexp
+		case m.fts:
+			m.fMode = db.MatchFTS
+		default:
+			m.fMode = db.MatchSubstring
+		}
+		m.fInput.Prompt = m.filterPrompt()
+		return m, m.applyFilter()
 	}
 
 	var cmd tea.Cmd
 	m.fInput, cmd = m.fInput.Update(msg)
-	m.applyFilter()
-	return m, cmd
+	return m, tea.Batch(cmd, m.applyFilter())
+}
+
+// filterPrompt labels the filter input with its column, using "~" instead
+// of ":" while matching fuzzily, "=~" for regular expressions, and "MATCH"
+// for FTS5 queries.
+func (m TableDataModel) filterPrompt() string {
+	switch m.fMode {
+	case db.MatchFuzzy:
+		return m.fCol + " ~ "
+	case db.MatchFTS:
+		return m.fCol + " MATCH "
+	case db.MatchExact:
+		return m.fCol + " = "
+	case db.MatchRegexp:
+		return m.fCol + " =~ "
+	}
+	return m.fCol + ": "
+}
+
+// filterPlaceholder hints at the filter syntax: FTS5 query syntax for
+// full-text tables, the fuzzy toggle otherwise.
+func (m TableDataModel) filterPlaceholder() string {
+	if m.fts {
+		return `words, "a phrase", prefix*, AND/OR/NOT (` + Keys.FuzzyFilter.Help().Key + ": LIKE/fuzzy/regexp)"
+	}
+	return "filter... (" + Keys.FuzzyFilter.Help().Key + ": fuzzy/regexp)"
+}
+
+// defaultMatchMode is how a new filter matches: with the full-text index
+// on FTS5 tables, where a LIKE would scan every row, substrings elsewhere.
+// Whether the table is an FTS5 one is looked up the first time.
+func (m *TableDataModel) defaultMatchMode() db.MatchMode {
+	if !m.fts && !m.static && m.database != nil && !m.ftsChecked {
+		m.ftsChecked = true
+		m.fts, _ = db.IsFTS5(context.Background(), m.database, m.tableName)
+	}
+	if m.fts {
+		return db.MatchFTS
+	}
+	return db.MatchSubstring
 }
 
-// applyFilter queries the DB for rows matching the filter value in the selected column.
-func (m *TableDataModel) applyFilter() {
+// applyFilter queries the DB in the background for rows matching the
+// filter value in the selected column, superseding the loads still
+// running. In-memory grids are matched locally the same way
+// (case-insensitive substring).
+func (m *TableDataModel) applyFilter() tea.Cmd {
 	query := m.fInput.Value()
 	if query == "" {
-		m.table.SetRows(truncateRows(m.allRows, m.displayCols, m.hasHiddenCols()))
-		m.table.SetCursor(0)
 		m.fTotalRows = 0
-		return
+		return m.restoreUnfiltered()
 	}
-	_, _, rows, err := db.FilterColumn(m.database, m.tableName, m.fCol, query, m.pageSize, 0)
-	if err != nil {
-		m.table.SetRows(truncateRows(m.allRows, m.displayCols, m.hasHiddenCols()))
-		m.table.SetCursor(0)
-		return
+	if m.inMemory() {
+		m.filterStatic(query)
+		return nil
 	}
-	total, err := db.CountFilteredRows(m.database, m.tableName, m.fCol, query)
-	if err != nil {
-		total = len(rows)
+	m.loads.cancel()
+	// The page shown is reloaded if filtering stops, even before the
+	// first results arrive.
+	m.fFiltered = true
+	database, id, tableName, fCol, mode, order, pageSize := m.database, m.id, m.tableName, m.fCol, m.fMode, m.rowOrder(), m.pageSize
+	filtered := func(ctx context.Context) (pageDataLoadedMsg, error) {
+		return filteredPage(ctx, database, tableName, fCol, query, mode, order, 0, pageSize)
+	}
+	if m.paged {
+		filtered = m.resultPage(query, 0)
+	}
+	load := track("filtering "+tableName, m.loads.run(func(ctx context.Context) tea.Msg {
+		msg, err := filtered(ctx)
+		if err != nil {
+			// An unfinished FTS5 query ("foo AND") or regular expression
+			// ("a(b") doesn't parse; the last results stay until it does.
+			return nil
+		}
+		msg.gridID = id
+		return msg
+	}))
+	return tea.Batch(load, m.filterCountCmd(query))
+}
+
+// filterStatic keeps the in-memory rows whose filter column matches query.
+func (m *TableDataModel) filterStatic(query string) {
+	col := slices.Index(m.columns, m.fCol)
+	needle := strings.ToLower(query)
+	var re *regexp.Regexp
+	if m.fMode == db.MatchRegexp {
+		var err error
+		if re, err = regexp.Compile(query); err != nil {
+			return
+		}
 	}
-	m.fTotalRows = total
+	var rows [][]string
+	for _, r := range m.staticRows {
+		if col < 0 || col >= len(r) {
+			continue
+		}
+		if m.fMode == db.MatchFuzzy && db.FuzzyMatch(r[col], query) ||
+			m.fMode == db.MatchExact && r[col] == query ||
+			m.fMode == db.MatchRegexp && re.MatchString(r[col]) ||
+			m.fMode == db.MatchSubstring && strings.Contains(strings.ToLower(r[col]), needle) {
+			rows = append(rows, r)
+		}
+	}
+	m.fTotalRows = len(rows)
 	m.page = 0
-	m.table.SetRows(truncateRows(rows, m.displayCols, m.hasHiddenCols()))
+	m.setRows(rows, nil)
+	m.fFiltered = true
+}
+
+// clearFilter leaves filter mode and brings back the unfiltered rows.
+func (m *TableDataModel) clearFilter() tea.Cmd {
+	m.fState = filterOff
+	m.fMode = db.MatchSubstring
+	m.fActive = false
+	m.fQuery = ""
+	m.fTotalRows = 0
+	m.table.SetHeight(m.tableHeight())
+	return m.restoreUnfiltered()
+}
+
+// restoreUnfiltered replaces filter results with the page that was shown
+// before filtering — reloaded for tables, since filtering replaced it.
+func (m *TableDataModel) restoreUnfiltered() tea.Cmd {
+	if !m.fFiltered {
+		return nil
+	}
+	m.fFiltered = false
+	if m.inMemory() {
+		m.setRows(m.staticRows, nil)
+		return nil
+	}
+	m.loads.cancel() // the filter results still coming
+	if m.paged {
+		return m.resultPageCmd("", m.fPrevPage, false)
+	}
+	return loadPageCmd(m.loads, m.database, m.id, m.tableName, m.rowOrder(), m.fPrevPage, m.pageSize, false)
+}
+
+// inMemory reports whether the grid holds every row, paging and
+// filtering them itself.
+func (m TableDataModel) inMemory() bool {
+	return m.static && !m.paged
+}
+
+// setRows installs rows as the grid's current rows, cursor at the top.
+func (m *TableDataModel) setRows(rows [][]string, rowIDs []int64) {
+	m.allRows = rows
+	m.allRowIDs = rowIDs
+	m.setTableRows(rows)
 	m.table.SetCursor(0)
 }
 
@@ -442,58 +1210,142 @@ func (m TableDataModel) View() string {
 	}
 
 	tableView := m.table.View()
+	line := m.cursorLine(tableView)
+	if m.marking() {
+		tableView = m.markMatches(tableView, line)
+	}
+	tableView = m.markClipped(tableView, line)
+	if m.wrap {
+		tableView = m.wrapSelectedRow(tableView, line)
+	}
+	if m.resizing {
+		return m.markResizedHeader(tableView) + "\n" + m.resizeStatus()
+	}
+	if m.jump == jumpSearch {
+		return tableView + "\n" + m.jumpInput.View() + "  " + StatusBarStyle.Render(m.searchStatus())
+	}
+	if m.jump != jumpOff {
+		return tableView + "\n" + m.jumpInput.View()
+	}
 
 	switch m.fState {
 	case filterPickCol:
 		return tableView + "\n" + m.renderColumnPicker()
 	case filterInput:
 		return tableView + "\n" + m.fInput.View()
+	case filterPickValue:
+		return tableView + "\n" + m.renderValuePicker()
 	}
 	return tableView
 }
 
-// renderColumnPicker draws a simple selectable list of column names.
+// renderColumnPicker draws the search line followed by a scrollable list of
+// matching column names with their declared types.
 func (m TableDataModel) renderColumnPicker() string {
 	visible := m.pickerVisibleCount()
-	var s string
-	for i := m.fColScroll; i < m.fColScroll+visible && i < len(m.columns); i++ {
-		name := m.columns[i]
+
+	nameW := 0
+	for _, idx := range m.fColMatch {
+		nameW = max(nameW, utf8.RuneCountInString(m.columns[idx]+m.sortMarker(idx)))
+	}
+
+	search := StatusBarStyle.Render(fmt.Sprintf("%s: %s▏ (%d/%d)", m.fPick.label(), m.fColSearch, len(m.fColMatch), len(m.columns)))
+	lines := []string{search}
+	if len(m.fColMatch) == 0 {
+		lines = append(lines, StatusBarStyle.Render("  no matching columns"))
+	}
+	for i := m.fColScroll; i < m.fColScroll+visible && i < len(m.fColMatch); i++ {
+		idx := m.fColMatch[i]
+		name := fmt.Sprintf("%-*s", nameW, m.columns[idx]+m.sortMarker(idx))
+		var colType string
+		if idx < len(m.colTypes) && m.colTypes[idx] != "" {
+			colType = "  " + m.colTypes[idx]
+		}
 		if i == m.fColIndex {
-			s += TitleStyle.Render("▸ " + name)
+			lines = append(lines, TitleStyle.Render("▸ "+name)+StatusBarStyle.Render(colType))
 		} else {
-			s += StatusBarStyle.Render("  " + name)
-		}
-		if i < m.fColScroll+visible-1 && i < len(m.columns)-1 {
-			s += "\n"
+			lines = append(lines, StatusBarStyle.Render("  "+name+colType))
 		}
 	}
-	return s
+	return strings.Join(lines, "\n")
 }
 
 // StatusText returns info about the table for the parent's status bar.
 func (m TableDataModel) StatusText() string {
+	text := m.pagingText()
+	if m.queryStats != nil {
+		text += " · " + m.statsText()
+	}
+	if len(m.sort) > 0 {
+		text += " · " + m.sortText()
+	}
+	if m.tail {
+		text += " · following newest"
+	}
+	if m.bookmarked() {
+		text += " · ★ bookmarked"
+	}
+	return text
+}
+
+func (m TableDataModel) pagingText() string {
 	currentPage := m.page + 1
-	pages := m.totalPages()
 
+	results := "results"
+	switch m.fMode {
+	case db.MatchFuzzy:
+		results = "fuzzy results"
+	case db.MatchFTS:
+		results = "matches"
+	case db.MatchRegexp:
+		results = "regexp results"
+	}
 	if m.fActive {
-		return fmt.Sprintf("%s (page %d/%d, %d results for %s)", m.tableName, currentPage, pages, m.fTotalRows, m.fCol)
+		return fmt.Sprintf("%s (page %d/%s, %s %s for %s)", m.tableName, currentPage, m.pageCount(), m.rowCount(), results, m.fCol)
 	}
 
 	// During live filter typing, show result count without page info.
 	if m.fState != filterOff {
 		displayed := len(m.table.Rows())
-		return fmt.Sprintf("%s (%d results for %s)", m.tableName, displayed, m.fCol)
+		return fmt.Sprintf("%s (%d %s for %s)", m.tableName, displayed, results, m.fCol)
 	}
 
-	return fmt.Sprintf("%s (page %d/%d, %d rows)", m.tableName, currentPage, pages, m.totalRows)
+	return fmt.Sprintf("%s (page %d/%s, %s rows)", m.tableName, currentPage, m.pageCount(), m.rowCount())
+}
+
+// pageCount and rowCount format the totals for the status bar. Without a
+// known total both read "?" until the last page is reached, which pins
+// them down; an estimate is marked with "~".
+func (m TableDataModel) pageCount() string {
+	if m.currentTotal() == unknownTotal && m.hasMore {
+		return "?"
+	}
+	if m.estimating() && m.hasMore {
+		return "~" + strconv.Itoa(m.totalPages())
+	}
+	return strconv.Itoa(m.totalPages())
+}
+
+func (m TableDataModel) rowCount() string {
+	total := m.currentTotal()
+	if m.estimating() {
+		return "~" + strconv.Itoa(total)
+	}
+	if total != unknownTotal {
+		return strconv.Itoa(total)
+	}
+	if m.hasMore {
+		return "?"
+	}
+	return strconv.Itoa(m.page*m.pageSize + len(m.allRows))
 }
 
 // measureColWidth returns the ideal width for a column based on its header and data.
 func measureColWidth(colIndex int, header string, rows [][]string) int {
-	w := len(header)
+	w := runewidth.StringWidth(header)
 	for _, r := range rows {
-		if colIndex < len(r) && len(r[colIndex]) > w {
-			w = len(r[colIndex])
+		if colIndex < len(r) {
+			w = max(w, runewidth.StringWidth(r[colIndex]))
 		}
 	}
 	w += colPadding
@@ -507,8 +1359,10 @@ func measureColWidth(colIndex int, header string, rows [][]string) int {
 }
 
 // fitColumns determines how many columns fit within the available width and
-// returns the number of display columns along with their widths.
-func fitColumns(columns []string, rows [][]string, innerWidth int) (int, []int) {
+// returns the number of display columns along with their widths. A column
+// with a width in set above 0 takes that width instead of its measured one,
+// and none of the space left over.
+func fitColumns(columns []string, rows [][]string, innerWidth int, set []int) (int, []int) {
 	available := innerWidth - 2 // account for table border
 	if available < minColWidth {
 		available = minColWidth
@@ -519,6 +1373,9 @@ func fitColumns(columns []string, rows [][]string, innerWidth int) (int, []int)
 
 	for i, col := range columns {
 		w := measureColWidth(i, col, rows)
+		if i < len(set) && set[i] > 0 {
+			w = min(set[i], available)
+		}
 		remaining := len(columns) - i - 1
 
 		// If this isn't the last column, check if we need to reserve space for the indicator.
@@ -541,10 +1398,18 @@ func fitColumns(columns []string, rows [][]string, innerWidth int) (int, []int)
 	if hiddenCols > 0 {
 		leftover -= indicatorColLen
 	}
-	if leftover > 0 && displayCols > 0 {
-		extra := leftover / displayCols
+	fitted := 0
+	for i := range widths {
+		if i >= len(set) || set[i] <= 0 {
+			fitted++
+		}
+	}
+	if leftover > 0 && fitted > 0 {
+		extra := leftover / fitted
 		for i := range widths {
-			widths[i] += extra
+			if i >= len(set) || set[i] <= 0 {
+				widths[i] += extra
+			}
 		}
 	}
 
@@ -552,33 +1417,37 @@ func fitColumns(columns []string, rows [][]string, innerWidth int) (int, []int)
 }
 
 // buildTableColumns creates bubbles table column definitions from pre-computed widths.
-func buildTableColumns(columns []string, displayCols int, widths []int, totalCols int) []table.Column {
+// The leading columns go in front.
+func buildTableColumns(columns []string, displayCols int, widths []int, totalCols int, leading []table.Column) []table.Column {
 	hiddenCols := totalCols - displayCols
-	numCols := displayCols
-	if hiddenCols > 0 {
-		numCols++
-	}
-	cols := make([]table.Column, numCols)
+	cols := make([]table.Column, 0, len(leading)+displayCols+1)
+	cols = append(cols, leading...)
 	for i := range displayCols {
-		cols[i] = table.Column{Title: columns[i], Width: widths[i]}
+		cols = append(cols, table.Column{Title: columns[i], Width: widths[i]})
 	}
 	if hiddenCols > 0 {
-		cols[displayCols] = table.Column{
+		cols = append(cols, table.Column{
 			Title: fmt.Sprintf("+ %d cols", hiddenCols),
 			Width: indicatorColLen,
-		}
+		})
 	}
 	return cols
 }
 
 // truncateRows converts [][]string to []table.Row, keeping only the first maxCols values per row.
 // When hasExtra is true, an empty trailing cell is added to match the extra header column.
+// Multi-line values are flattened so each row stays one line tall. The
+// returned rows never share memory with rows, so callers may rewrite cells.
 func truncateRows(rows [][]string, maxCols int, hasExtra bool) []table.Row {
 	result := make([]table.Row, len(rows))
 	for i, r := range rows {
-		row := r
-		if len(r) > maxCols {
-			row = r[:maxCols]
+		n := min(len(r), maxCols)
+		row := make(table.Row, n, n+1)
+		for j, v := range r[:n] {
+			if strings.ContainsAny(v, "\n\r\t") {
+				v = strings.Join(strings.Fields(v), " ")
+			}
+			row[j] = v
 		}
 		if hasExtra {
 			row = append(row, "")
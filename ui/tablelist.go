@@ -2,22 +2,65 @@ package ui
 
 import (
 	"fmt"
+	"io"
+	"slices"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
 )
 
 // TableItem implements the list.Item interface from bubbles.
 // The list component needs items that can provide a title, description,
 // and a filter value (used for the built-in fuzzy search).
 type TableItem struct {
-	Name string
+	Name   string
+	Kind   db.TableKind
+	detail string // the row count or size the list is sorted by
 }
 
-func (t TableItem) Title() string       { return t.Name }
+func (t TableItem) Title() string {
+	if t.detail != "" {
+		return t.Name + " · " + t.detail
+	}
+	return t.Name
+}
 func (t TableItem) Description() string { return "" }
 func (t TableItem) FilterValue() string { return t.Name }
 
+// sectionItem heads the entries of one kind in the list. Selecting it folds
+// them away, or back out.
+type sectionItem struct {
+	kind      db.TableKind
+	count     int
+	collapsed bool
+}
+
+func (s sectionItem) Title() string {
+	marker := "▾"
+	if s.collapsed {
+		marker = "▸"
+	}
+	return fmt.Sprintf("%s %s (%d)", marker, sectionNames[s.kind], s.count)
+}
+func (s sectionItem) Description() string { return "" }
+func (s sectionItem) FilterValue() string { return "" } // filtering lists only entries
+
+// sectionOrder is the order of the list's sections, each named in
+// sectionNames.
+var sectionOrder = []db.TableKind{db.KindTable, db.KindView, db.KindVirtual, db.KindSystem}
+
+var sectionNames = map[db.TableKind]string{
+	db.KindTable:   "Tables",
+	db.KindView:    "Views",
+	db.KindVirtual: "Virtual tables",
+	db.KindSystem:  "System",
+}
+
 // TableSelectedMsg is sent when the user presses enter on a table.
 // This is how the table list communicates upward to the parent model —
 // through messages, not direct function calls.
@@ -25,20 +68,51 @@ type TableSelectedMsg struct {
 	Name string
 }
 
+// TableRenameMsg is sent when the user renames a table in the list.
+type TableRenameMsg struct {
+	From string
+	To   string
+}
+
+// renameDelegate draws the list's items like the default delegate, but the
+// selected one as the input renaming it while a rename is under way.
+type renameDelegate struct {
+	list.DefaultDelegate
+	editing string // the rename input's view, "" when not renaming
+}
+
+func (d renameDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if d.editing != "" && index == m.Index() {
+		fmt.Fprint(w, d.Styles.SelectedTitle.Render(d.editing))
+		return
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
 // TableListModel wraps bubbles/list.Model. This is the component
 // composition pattern: our model contains a child model and delegates
 // messages to it.
 type TableListModel struct {
-	list list.Model
+	list      list.Model
+	entries   []db.ListEntry
+	collapsed map[db.TableKind]bool
+	sort      tableSort
+	measures  map[tableSort]map[string]tableMeasure // by sort, once measured
+	internal  bool                                  // list SQLite's own tables too
+
+	delegate list.DefaultDelegate
+	renaming bool // the selected table's name is being edited
+	rename   textinput.Model
 }
 
-// NewTableListModel creates the table list from a slice of table names.
-func NewTableListModel(tables []string, width, height int) TableListModel {
-	// Convert []string to []list.Item — the list component works with
-	// its own Item interface, so we wrap our data.
-	items := make([]list.Item, len(tables))
-	for i, t := range tables {
-		items[i] = TableItem{Name: t}
+// NewTableListModel creates the table list from the database's tables and
+// views. A database holding only plain tables lists them as they are;
+// otherwise they are grouped by kind under collapsible section headers.
+// SQLite's own tables are left out until setInternal shows them.
+func NewTableListModel(entries []db.ListEntry, width, height int) TableListModel {
+	m := TableListModel{
+		entries:   entries,
+		collapsed: map[db.TableKind]bool{},
 	}
 
 	// Parent passes the pane border-box dimensions.
@@ -50,8 +124,9 @@ func NewTableListModel(tables []string, width, height int) TableListModel {
 	listDelegate.SetHeight(1)  // 1 line per item (no description line)
 	listDelegate.SetSpacing(0) // no blank line between items
 	listDelegate.ShowDescription = false
-	l := list.New(items, listDelegate, contentW, contentH)
-	l.Title = fmt.Sprintf("Tables (%d)", len(tables))
+	m.delegate = listDelegate
+	l := list.New(m.items(), renameDelegate{DefaultDelegate: listDelegate}, contentW, contentH)
+	l.Title = m.title()
 	l.SetShowStatusBar(false) // count is in the title now
 	l.SetFilteringEnabled(true)
 	l.SetShowHelp(false) // parent's status bar shows keybindings
@@ -60,7 +135,244 @@ func NewTableListModel(tables []string, width, height int) TableListModel {
 	l.KeyMap.NextPage.SetEnabled(false)
 	l.KeyMap.PrevPage.SetEnabled(false)
 
-	return TableListModel{list: l}
+	m.list = l
+	// Start on the first entry rather than its section's header.
+	for i, item := range l.Items() {
+		if _, ok := item.(TableItem); ok {
+			m.list.Select(i)
+			break
+		}
+	}
+	return m
+}
+
+// tableEntries lists tables of no particular kind, for a database whose
+// views and kinds of tables couldn't be read.
+func tableEntries(tables []string) []db.ListEntry {
+	entries := make([]db.ListEntry, len(tables))
+	for i, t := range tables {
+		entries[i] = db.ListEntry{Name: t}
+	}
+	return entries
+}
+
+// title heads the list with how many entries it has and what it is
+// sorted by.
+func (m TableListModel) title() string {
+	n := len(m.visible())
+	title := fmt.Sprintf("Tables (%d)", n)
+	if m.grouped() {
+		title = fmt.Sprintf("Schema (%d)", n)
+	}
+	if m.sort != sortByName {
+		title += " by " + m.sort.String()
+	}
+	return title
+}
+
+// measured reports whether the entries have been measured for sort.
+func (m TableListModel) measured(sort tableSort) bool {
+	return sort == sortByName || m.measures[sort] != nil
+}
+
+// setMeasures keeps the entries' measures for sort.
+func (m *TableListModel) setMeasures(sort tableSort, measures map[string]tableMeasure) {
+	if m.measures == nil {
+		m.measures = map[tableSort]map[string]tableMeasure{}
+	}
+	m.measures[sort] = measures
+}
+
+// setSort orders the list by sort, which must be measured, keeping the
+// selection on the same entry.
+func (m *TableListModel) setSort(sort tableSort) tea.Cmd {
+	m.sort = sort
+	return m.relist()
+}
+
+// setInternal shows or hides SQLite's own tables, like sqlite_master and
+// sqlite_sequence, in the System section.
+func (m *TableListModel) setInternal(show bool) tea.Cmd {
+	m.internal = show
+	return m.relist()
+}
+
+// relist rebuilds the list's items after its order or contents changed,
+// keeping the selection on the same entry while it is still listed.
+func (m *TableListModel) relist() tea.Cmd {
+	var selected string
+	if item, ok := m.list.SelectedItem().(TableItem); ok {
+		selected = item.Name
+	}
+	m.list.Title = m.title()
+	cmd := m.list.SetItems(m.items())
+	m.selectName(selected)
+	return cmd
+}
+
+// selectName moves the selection to the entry named name, if it is listed.
+func (m *TableListModel) selectName(name string) {
+	for i, item := range m.list.Items() {
+		if t, ok := item.(TableItem); ok && t.Name == name {
+			m.list.Select(i)
+			return
+		}
+	}
+}
+
+// visible returns the entries the list shows: all of them once internal
+// tables are shown, else all but the System section's.
+func (m TableListModel) visible() []db.ListEntry {
+	if m.internal {
+		return m.entries
+	}
+	var entries []db.ListEntry
+	for _, e := range m.entries {
+		if e.Kind != db.KindSystem {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// sorted returns the entries in the list's order: by name, or by their
+// measure, largest first, with the unmeasured last.
+func (m TableListModel) sorted() []TableItem {
+	entries := m.visible()
+	items := make([]TableItem, len(entries))
+	measures := m.measures[m.sort]
+	for i, e := range entries {
+		items[i] = TableItem{Name: e.Name, Kind: e.Kind}
+		if m.sort != sortByName {
+			items[i].detail = measures[e.Name].label
+		}
+	}
+	if m.sort == sortByName {
+		return items
+	}
+	slices.SortStableFunc(items, func(a, b TableItem) int {
+		ma, oka := measures[a.Name]
+		mb, okb := measures[b.Name]
+		switch {
+		case oka != okb:
+			if oka {
+				return -1
+			}
+			return 1
+		case ma.value > mb.value:
+			return -1
+		case ma.value < mb.value:
+			return 1
+		}
+		return 0
+	})
+	return items
+}
+
+// grouped reports whether the list has entries of more than plain tables,
+// and so shows sections.
+func (m TableListModel) grouped() bool {
+	for _, e := range m.visible() {
+		if e.Kind != db.KindTable {
+			return true
+		}
+	}
+	return false
+}
+
+// items builds the list's items: the entries, under a header per section
+// when grouped, leaving out those of collapsed sections.
+func (m TableListModel) items() []list.Item {
+	var items []list.Item
+	sorted := m.sorted()
+	if !m.grouped() {
+		for _, t := range sorted {
+			items = append(items, t)
+		}
+		return items
+	}
+	for _, kind := range sectionOrder {
+		var section []list.Item
+		for _, t := range sorted {
+			if t.Kind == kind {
+				section = append(section, t)
+			}
+		}
+		if len(section) == 0 {
+			continue
+		}
+		items = append(items, sectionItem{kind: kind, count: len(section), collapsed: m.collapsed[kind]})
+		if !m.collapsed[kind] {
+			items = append(items, section...)
+		}
+	}
+	return items
+}
+
+// isView reports whether name is one of the list's views, which are read
+// with a query rather than paged like tables.
+func (m TableListModel) isView(name string) bool {
+	for _, e := range m.entries {
+		if e.Name == name {
+			return e.Kind == db.KindView
+		}
+	}
+	return false
+}
+
+// startRename edits the selected table's name in place.
+func (m *TableListModel) startRename() tea.Cmd {
+	item, ok := m.list.SelectedItem().(TableItem)
+	if !ok || item.Kind == db.KindView {
+		return nil
+	}
+	_, name := db.SplitTable(item.Name)
+	m.rename = textinput.New()
+	m.rename.Prompt = ""
+	m.rename.Width = m.list.Width() - 4
+	m.rename.SetValue(name)
+	m.rename.CursorEnd()
+	m.renaming = true
+	cmd := m.rename.Focus()
+	m.setEditing()
+	return cmd
+}
+
+// stopRename puts the selected table's name back in place of the input.
+func (m *TableListModel) stopRename() {
+	m.renaming = false
+	m.rename.Blur()
+	m.list.SetDelegate(renameDelegate{DefaultDelegate: m.delegate})
+}
+
+// setEditing has the delegate draw the rename input as it now is.
+func (m *TableListModel) setEditing() {
+	m.list.SetDelegate(renameDelegate{DefaultDelegate: m.delegate, editing: m.rename.View()})
+}
+
+// updateRename handles a message while a rename is under way: enter asks
+// for it with a TableRenameMsg, esc drops it.
+func (m TableListModel) updateRename(msg tea.Msg) (TableListModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			m.stopRename()
+			return m, nil
+		case "enter":
+			item, _ := m.list.SelectedItem().(TableItem)
+			_, name := db.SplitTable(item.Name)
+			to := strings.TrimSpace(m.rename.Value())
+			m.stopRename()
+			if to == "" || to == name {
+				return m, nil
+			}
+			return m, func() tea.Msg { return TableRenameMsg{From: item.Name, To: to} }
+		}
+	}
+	var cmd tea.Cmd
+	m.rename, cmd = m.rename.Update(msg)
+	m.setEditing()
+	return m, cmd
 }
 
 // SetSize updates the list dimensions. Called when the terminal resizes.
@@ -72,13 +384,23 @@ func (m *TableListModel) SetSize(width, height int) {
 // Notice the return type is (TableListModel, tea.Cmd) — not (tea.Model, tea.Cmd).
 // Sub-models don't need to satisfy the tea.Model interface; only the root does.
 func (m TableListModel) Update(msg tea.Msg) (TableListModel, tea.Cmd) {
+	if m.renaming {
+		return m.updateRename(msg)
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Don't intercept keys when the list is filtering (user is typing)
 		if m.list.FilterState() == list.Filtering {
 			break
 		}
-		if msg.String() == "enter" {
+		if key.Matches(msg, Keys.Select) {
+			if section, ok := m.list.SelectedItem().(sectionItem); ok {
+				m.collapsed[section.kind] = !section.collapsed
+				i := m.list.Index()
+				cmd := m.list.SetItems(m.items())
+				m.list.Select(i)
+				return m, cmd
+			}
 			item, ok := m.list.SelectedItem().(TableItem)
 			if ok {
 				return m, func() tea.Msg {
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// tableSort is what the table list is ordered by.
+type tableSort int
+
+const (
+	sortByName tableSort = iota
+	sortByRows
+	sortBySize
+)
+
+func (s tableSort) next() tableSort { return (s + 1) % 3 }
+
+func (s tableSort) String() string {
+	switch s {
+	case sortByRows:
+		return "rows"
+	case sortBySize:
+		return "size"
+	}
+	return "name"
+}
+
+// tableMeasure is a table's row count or size, and how the list shows it.
+type tableMeasure struct {
+	value int64
+	label string
+}
+
+// tableMeasuresMsg carries the row counts or sizes of a database's tables
+// for sorting the table list by.
+type tableMeasuresMsg struct {
+	database *sql.DB
+	sort     tableSort
+	measures map[string]tableMeasure
+	err      error
+}
+
+// tableMeasuresCmd measures the tables for sort. Row counts are the cached
+// ones in known, else estimates from the largest rowid, marked with ~;
+// tables with no rowid to estimate from go unmeasured. Sizes come from
+// dbstat, taking a table's indexes with it.
+func tableMeasuresCmd(database *sql.DB, sort tableSort, entries []db.ListEntry, known map[string]int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		measures := map[string]tableMeasure{}
+		if sort == sortBySize {
+			// Attached databases are files of their own, measured one by one.
+			schemas := []string{""}
+			for _, a := range db.Attached(database) {
+				schemas = append(schemas, a.Name)
+			}
+			for _, schema := range schemas {
+				usage, err := db.SpaceUsageStats(ctx, database, schema)
+				if err != nil {
+					return tableMeasuresMsg{database: database, sort: sort, err: err}
+				}
+				for _, o := range usage.Objects {
+					m := measures[o.Table]
+					m.value += o.Bytes
+					m.label = formatBytes(m.value)
+					measures[o.Table] = m
+				}
+			}
+			return tableMeasuresMsg{database: database, sort: sort, measures: measures}
+		}
+		for _, e := range entries {
+			if e.Kind == db.KindView {
+				continue
+			}
+			if n, ok := known[e.Name]; ok {
+				measures[e.Name] = tableMeasure{int64(n), groupDigits(int64(n))}
+				continue
+			}
+			if n, err := db.EstimateRows(ctx, database, e.Name); err == nil {
+				measures[e.Name] = tableMeasure{int64(n), "~" + groupDigits(int64(n))}
+			}
+		}
+		return tableMeasuresMsg{database: database, sort: sort, measures: measures}
+	}
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Open tables live in Model.tabs. The active tab's entry is stale while it
+// is active — m.tableData is the live copy — and is written back when the
+// user switches away, so every tab keeps its own page, cursor and filter.
+
+// inNewTab marks the first page loaded by cmd to open in a new tab instead
+// of replacing the active one.
+func inNewTab(cmd tea.Cmd) tea.Cmd {
+	return func() tea.Msg { return newTabMsg(cmd()) }
+}
+
+func newTabMsg(msg tea.Msg) tea.Msg {
+	switch msg := msg.(type) {
+	case tableDataLoadedMsg:
+		msg.newTab = true
+		return msg
+	case tea.BatchMsg:
+		// A tracked load comes back as a batch still to run.
+		cmds := make(tea.BatchMsg, 0, len(msg))
+		for _, cmd := range msg {
+			if cmd != nil {
+				cmds = append(cmds, func() tea.Msg { return newTabMsg(cmd()) })
+			}
+		}
+		return cmds
+	}
+	return msg
+}
+
+// showTabBar reports whether the tab strip is drawn above the main grid.
+func (m Model) showTabBar() bool {
+	return len(m.tabs) > 1 && !(m.zoomed && m.focused == panePinned)
+}
+
+// openTab stashes the active grid and appends an empty tab for the caller
+// to fill in m.tableData.
+func (m *Model) openTab() {
+	if len(m.tabs) == 0 {
+		m.tabs = make([]TableDataModel, 1)
+	}
+	m.tabs[m.activeTab] = m.tableData
+	m.tabs = append(m.tabs, TableDataModel{})
+	m.activeTab = len(m.tabs) - 1
+}
+
+// switchTab makes tab i the live grid.
+func (m *Model) switchTab(i int) {
+	if i == m.activeTab || i < 0 || i >= len(m.tabs) {
+		return
+	}
+	m.tabs[m.activeTab] = m.tableData
+	m.activeTab = i
+	m.tableData = m.tabs[i]
+	// The terminal may have been resized while this tab was in the background.
+	m.tableData.SetSize(m.rightWidth, m.dataHeight())
+}
+
+// closeTab drops the active tab and activates its right-hand neighbour, or
+// the new last tab. The final tab cannot be closed.
+func (m *Model) closeTab() {
+	if len(m.tabs) < 2 {
+		return
+	}
+	m.tabs = append(m.tabs[:m.activeTab], m.tabs[m.activeTab+1:]...)
+	m.activeTab = min(m.activeTab, len(m.tabs)-1)
+	m.tableData = m.tabs[m.activeTab]
+	m.tableData.SetSize(m.rightWidth, m.dataHeight())
+}
+
+// findTab returns the index of the tab showing table name, or -1.
+func (m Model) findTab(name string) int {
+	for i := range m.tabs {
+		grid := m.tabs[i]
+		if i == m.activeTab {
+			grid = m.tableData
+		}
+		if grid.tableName == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// showTable brings table name into view: it switches to the tab already
+// showing it, or loads it into the active tab.
+func (m *Model) showTable(name string) tea.Cmd {
+	if i := m.findTab(name); i >= 0 {
+		m.switchTab(i)
+		return nil
+	}
+	return m.loadTableCmd(name)
+}
+
+// applyBackgroundPage routes a page load to a tab that is not active.
+func (m *Model) applyBackgroundPage(msg pageDataLoadedMsg) bool {
+	for i := range m.tabs {
+		if i != m.activeTab && m.tabs[i].id == msg.gridID {
+			m.tabs[i].applyPage(msg)
+			return true
+		}
+	}
+	return false
+}
+
+// applyBackgroundRerun routes the rows of a query run again to the
+// background tab it was run for, if that tab is still open.
+func (m *Model) applyBackgroundRerun(msg resultRerunMsg) tea.Cmd {
+	for i := range m.tabs {
+		if i != m.activeTab && m.tabs[i].id == msg.gridID {
+			return m.tabs[i].applyRerun(msg)
+		}
+	}
+	return nil
+}
+
+// applyBackgroundCount routes a row count to the background tab it was
+// run for, if that tab is still open.
+func (m *Model) applyBackgroundCount(msg rowCountMsg) {
+	for i := range m.tabs {
+		if i != m.activeTab && m.tabs[i].id == msg.gridID {
+			m.tabs[i].applyCount(msg)
+			return
+		}
+	}
+}
+
+// renderTabBar draws one label per open tab, highlighting the active one.
+func (m Model) renderTabBar() string {
+	labels := make([]string, len(m.tabs))
+	for i := range m.tabs {
+		if i == m.activeTab {
+			labels[i] = ActiveTabStyle.Render(m.tableData.tableName)
+		} else {
+			labels[i] = TabStyle.Render(m.tabs[i].tableName)
+		}
+	}
+	return strings.Join(labels, TabStyle.Render("│"))
+}
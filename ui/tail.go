@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tailInterval is how often grids in tail mode re-read their newest rows.
+const tailInterval = time.Second
+
+// tailCountInterval is how often they count their rows in full. COUNT(*)
+// reads the whole table, so in between the total follows MAX(rowid).
+const tailCountInterval = time.Minute
+
+// tailTickMsg triggers a re-read of every grid in tail mode.
+type tailTickMsg struct{}
+
+func tailTickCmd() tea.Cmd {
+	return tea.Tick(tailInterval, func(time.Time) tea.Msg { return tailTickMsg{} })
+}
+
+// toggleTail switches the focused grid in or out of tail mode, reloading
+// it from its first page in the new order. The tick loop starts with the
+// first tailing grid and stops once none is left.
+func (m *Model) toggleTail() tea.Cmd {
+	grid := m.focusedGrid()
+	if grid.static {
+		return nil
+	}
+	grid.tail = !grid.tail
+	cmd := grid.pageCmd(0, false)
+	if grid.tail && !m.tailTicking {
+		m.tailTicking = true
+		cmd = tea.Batch(cmd, tailTickCmd())
+	}
+	return cmd
+}
+
+// tailing reports whether any grid of the open database is in tail mode.
+func (m Model) tailing() bool {
+	if m.tableData.tail || (m.showPinned && m.pinned.tail) {
+		return true
+	}
+	for i, t := range m.tabs {
+		if i != m.activeTab && t.tail {
+			return true
+		}
+	}
+	return false
+}
+
+// tailCmd reloads the visible grids that are tailing and showing their
+// newest page. A grid whose filter is being edited is left alone.
+func (m *Model) tailCmd() tea.Cmd {
+	var cmds []tea.Cmd
+	for _, grid := range []*TableDataModel{&m.tableData, &m.pinned} {
+		if grid == &m.pinned && !m.showPinned {
+			continue
+		}
+		if grid.tail && grid.page == 0 && grid.fState == filterOff {
+			cmds = append(cmds, grid.tailReloadCmd())
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// tailReloadCmd re-reads a tailing grid's newest page. Its rows, and a
+// filter's matches, are counted again once a tailCountInterval; in between
+// only the table's total is estimated.
+func (m *TableDataModel) tailReloadCmd() tea.Cmd {
+	if time.Since(m.tailCounted) >= tailCountInterval {
+		m.tailCounted = time.Now()
+		return m.reloadCmd()
+	}
+	return tea.Batch(m.reloadPageCmd(), m.estimateCmd())
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names every color the UI uses. Styles in styles.go are built from
+// the active theme by applyTheme, so components never reference raw color
+// codes themselves.
+type Theme struct {
+	Accent        lipgloss.TerminalColor // titles, highlighted entries, popup border
+	Muted         lipgloss.TerminalColor // secondary text and hints
+	Label         lipgloss.TerminalColor // column labels in popups
+	Error         lipgloss.TerminalColor
+	FocusBorder   lipgloss.TerminalColor // border of the pane with focus
+	UnfocusBorder lipgloss.TerminalColor // border of other panes and the grid header rule
+	StatusBarBg   lipgloss.TerminalColor // status bar background
+	StatusInfoBg  lipgloss.TerminalColor // background of the status bar info section
+	StatusKeyFg   lipgloss.TerminalColor // key names in the status bar
+	StatusDescFg  lipgloss.TerminalColor // key descriptions in the status bar
+	SelectedFg    lipgloss.TerminalColor // cursor row in the grid
+	SelectedBg    lipgloss.TerminalColor
+	MatchFg       lipgloss.TerminalColor // grid cells holding the text searched for
+	MatchBg       lipgloss.TerminalColor
+}
+
+// Themes are the built-in themes, selectable by name via config or --theme.
+// "auto" pairs the dark and light palettes with lipgloss.AdaptiveColor, so
+// lipgloss picks whichever suits the detected terminal background.
+var Themes = map[string]Theme{
+	"auto": {
+		Accent:        lipgloss.AdaptiveColor{Light: "162", Dark: "205"},
+		Muted:         lipgloss.AdaptiveColor{Light: "244", Dark: "241"},
+		Label:         lipgloss.AdaptiveColor{Light: "25", Dark: "63"},
+		Error:         lipgloss.AdaptiveColor{Light: "160", Dark: "196"},
+		FocusBorder:   lipgloss.AdaptiveColor{Light: "62", Dark: "62"},
+		UnfocusBorder: lipgloss.AdaptiveColor{Light: "250", Dark: "240"},
+		StatusBarBg:   lipgloss.AdaptiveColor{Light: "254", Dark: "235"},
+		StatusInfoBg:  lipgloss.AdaptiveColor{Light: "253", Dark: "236"},
+		StatusKeyFg:   lipgloss.AdaptiveColor{Light: "235", Dark: "252"},
+		StatusDescFg:  lipgloss.AdaptiveColor{Light: "242", Dark: "242"},
+		SelectedFg:    lipgloss.AdaptiveColor{Light: "231", Dark: "229"},
+		SelectedBg:    lipgloss.AdaptiveColor{Light: "62", Dark: "57"},
+		MatchFg:       lipgloss.AdaptiveColor{Light: "16", Dark: "16"},
+		MatchBg:       lipgloss.AdaptiveColor{Light: "220", Dark: "214"},
+	},
+	"dark": {
+		Accent:        lipgloss.Color("205"),
+		Muted:         lipgloss.Color("241"),
+		Label:         lipgloss.Color("63"),
+		Error:         lipgloss.Color("196"),
+		FocusBorder:   lipgloss.Color("62"),
+		UnfocusBorder: lipgloss.Color("240"),
+		StatusBarBg:   lipgloss.Color("235"),
+		StatusInfoBg:  lipgloss.Color("236"),
+		StatusKeyFg:   lipgloss.Color("252"),
+		StatusDescFg:  lipgloss.Color("242"),
+		SelectedFg:    lipgloss.Color("229"),
+		SelectedBg:    lipgloss.Color("57"),
+		MatchFg:       lipgloss.Color("16"),
+		MatchBg:       lipgloss.Color("214"),
+	},
+	"light": {
+		Accent:        lipgloss.Color("162"),
+		Muted:         lipgloss.Color("244"),
+		Label:         lipgloss.Color("25"),
+		Error:         lipgloss.Color("160"),
+		FocusBorder:   lipgloss.Color("62"),
+		UnfocusBorder: lipgloss.Color("250"),
+		StatusBarBg:   lipgloss.Color("254"),
+		StatusInfoBg:  lipgloss.Color("253"),
+		StatusKeyFg:   lipgloss.Color("235"),
+		StatusDescFg:  lipgloss.Color("242"),
+		SelectedFg:    lipgloss.Color("231"),
+		SelectedBg:    lipgloss.Color("62"),
+		MatchFg:       lipgloss.Color("16"),
+		MatchBg:       lipgloss.Color("220"),
+	},
+	"solarized": {
+		Accent:        lipgloss.Color("#d33682"), // magenta
+		Muted:         lipgloss.Color("#586e75"), // base01
+		Label:         lipgloss.Color("#268bd2"), // blue
+		Error:         lipgloss.Color("#dc322f"), // red
+		FocusBorder:   lipgloss.Color("#2aa198"), // cyan
+		UnfocusBorder: lipgloss.Color("#073642"), // base02
+		StatusBarBg:   lipgloss.Color("#073642"),
+		StatusInfoBg:  lipgloss.Color("#002b36"), // base03
+		StatusKeyFg:   lipgloss.Color("#93a1a1"), // base1
+		StatusDescFg:  lipgloss.Color("#657b83"), // base00
+		SelectedFg:    lipgloss.Color("#fdf6e3"), // base3
+		SelectedBg:    lipgloss.Color("#268bd2"),
+		MatchFg:       lipgloss.Color("#002b36"), // base03
+		MatchBg:       lipgloss.Color("#b58900"), // yellow
+	},
+}
+
+// defaultTheme is used when the config doesn't name one.
+const defaultTheme = "auto"
+
+// lookupTheme returns the named built-in theme; an empty name selects the default.
+func lookupTheme(name string) (Theme, error) {
+	if name == "" {
+		name = defaultTheme
+	}
+	t, ok := Themes[name]
+	if !ok {
+		names := make([]string, 0, len(Themes))
+		for n := range Themes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	return t, nil
+}
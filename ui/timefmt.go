@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Display settings for timestamps, installed from the config by applyConfig.
+var (
+	displayLocation = time.Local  // timezone used when showing times
+	weekStart       = time.Monday // first day of the week for "this week"
+)
+
+// parseWeekday accepts full or three-letter English day names.
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown week_start %q (expected a day name like monday or sun)", s)
+}
+
+// startOfWeek returns midnight on the first day of t's week, honoring weekStart.
+func startOfWeek(t time.Time) time.Time {
+	days := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	y, m, d := t.AddDate(0, 0, -days).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// humanizeTime formats t in the display timezone, relative to now: just the
+// clock for today, weekday and clock within the current week, and the full
+// date otherwise.
+func humanizeTime(t, now time.Time) string {
+	t = t.In(displayLocation)
+	now = now.In(displayLocation)
+
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := now.Date()
+	switch {
+	case y1 == y2 && m1 == m2 && d1 == d2:
+		return t.Format("15:04")
+	case !t.Before(startOfWeek(now)) && t.Before(now):
+		return t.Format("Mon 15:04")
+	default:
+		return t.Format("2006-01-02 15:04")
+	}
+}
@@ -0,0 +1,145 @@
+package ui
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formatTimestamps shows the values of timestamp-like columns as dates in
+// the grid and row detail. Installed from the config by applyConfig and
+// flipped with Keys.Timestamps.
+var formatTimestamps bool
+
+// timestampKind is how a column's numbers encode points in time.
+type timestampKind int
+
+const (
+	notTimestamp timestampKind = iota
+	unixSeconds
+	unixMillis
+	julianDay // REAL days since noon, November 24, 4714 BC, as julianday() returns
+)
+
+// Values between 2000 and 2100 are taken for timestamps; most integer ids,
+// counts, and prices fall outside that range in every unit.
+var (
+	timestampFrom = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestampTo   = time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// julianDayUnixEpoch is the Julian day number of 1970-01-01 00:00 UTC.
+const julianDayUnixEpoch = 2440587.5
+
+// detectTimestamps classifies each of the first n columns by its non-NULL
+// values: unix seconds or milliseconds if every one is an integer in the
+// range for that unit, Julian days if every one is a REAL in range.
+func detectTimestamps(rows [][]string, n int) []timestampKind {
+	kinds := make([]timestampKind, n)
+	for i := range n {
+		kinds[i] = detectTimestamp(i, rows)
+	}
+	return kinds
+}
+
+func detectTimestamp(i int, rows [][]string) timestampKind {
+	candidates := []timestampKind{unixSeconds, unixMillis, julianDay}
+	seen := false
+	for _, r := range rows {
+		if i >= len(r) || r[i] == "NULL" || r[i] == "" {
+			continue
+		}
+		seen = true
+		candidates = slices.DeleteFunc(candidates, func(k timestampKind) bool {
+			_, ok := k.parse(r[i])
+			return !ok
+		})
+		if len(candidates) == 0 {
+			return notTimestamp
+		}
+	}
+	if !seen {
+		return notTimestamp
+	}
+	return candidates[0]
+}
+
+// parse reads s as a timestamp of kind k, reporting whether it is one in
+// the accepted range.
+func (k timestampKind) parse(s string) (time.Time, bool) {
+	var t time.Time
+	switch k {
+	case unixSeconds, unixMillis:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		if k == unixSeconds {
+			t = time.Unix(n, 0)
+		} else {
+			t = time.UnixMilli(n)
+		}
+	case julianDay:
+		// A julianday() result always has a fraction part; integers are
+		// more likely counts than dates.
+		if !strings.ContainsAny(s, ".eE") {
+			return time.Time{}, false
+		}
+		d, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(d) || math.IsInf(d, 0) {
+			return time.Time{}, false
+		}
+		t = time.UnixMilli(int64(math.Round((d - julianDayUnixEpoch) * 86400 * 1000)))
+	default:
+		return time.Time{}, false
+	}
+	return t, !t.Before(timestampFrom) && t.Before(timestampTo)
+}
+
+// format shows s as a date and time in the display timezone, or returns
+// it unchanged if it isn't a timestamp of kind k.
+func (k timestampKind) format(s string) string {
+	t, ok := k.parse(s)
+	if !ok {
+		return s
+	}
+	t = t.In(displayLocation)
+	if k == unixMillis || t.Nanosecond() != 0 {
+		return t.Format("2006-01-02 15:04:05.000")
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// timestampKinds classifies the grid's columns by its current rows, or is
+// nil while formatTimestamps is off.
+func (m TableDataModel) timestampKinds() []timestampKind {
+	if !formatTimestamps {
+		return nil
+	}
+	return detectTimestamps(m.allRows, len(m.columns))
+}
+
+// withTimestamps returns rows with the values of timestamp columns
+// formatted, when formatTimestamps is on. The rows themselves keep the
+// stored values.
+func (m TableDataModel) withTimestamps(rows [][]string) [][]string {
+	if !formatTimestamps {
+		return rows
+	}
+	kinds := detectTimestamps(rows, len(m.columns))
+	if !slices.ContainsFunc(kinds, func(k timestampKind) bool { return k != notTimestamp }) {
+		return rows
+	}
+	out := make([][]string, len(rows))
+	for i, r := range rows {
+		out[i] = slices.Clone(r)
+		for c, k := range kinds {
+			if k != notTimestamp && c < len(r) {
+				out[i][c] = k.format(r[c])
+			}
+		}
+	}
+	return out
+}
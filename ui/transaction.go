@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// transaction is an explicit transaction open on the active database:
+// every edit and query runs in it until it is committed or rolled back.
+type transaction struct {
+	started time.Time
+	base    int64 // total_changes() when it began
+	changes int64 // rows inserted, updated, or deleted in it, as last counted
+}
+
+// txChangesMsg carries a fresh count of the rows changed in txn.
+type txChangesMsg struct {
+	txn     *transaction
+	changes int64
+}
+
+// txEndedMsg reports how committing or rolling back txn went.
+type txEndedMsg struct {
+	txn    *transaction
+	commit bool
+	err    error
+}
+
+// beginTx opens a transaction on the active database.
+func (m *Model) beginTx() {
+	ctx := context.Background()
+	if err := db.Begin(ctx, m.db); err != nil {
+		m.note = ErrorStyle.Render(err.Error())
+		return
+	}
+	base, _ := db.TotalChanges(ctx, m.db)
+	m.txn = &transaction{started: time.Now(), base: base}
+	m.note = "transaction started"
+}
+
+// endTx commits or rolls back the open transaction in the background: a
+// COMMIT waits for the statements already running in it.
+func (m *Model) endTx(commit bool) tea.Cmd {
+	txn, database := m.txn, m.db
+	end, label := db.Rollback, "rolling back"
+	if commit {
+		end, label = db.Commit, "committing"
+	}
+	return track(label, func() tea.Msg {
+		return txEndedMsg{txn: txn, commit: commit, err: end(context.Background(), database)}
+	})
+}
+
+// txEnded applies the outcome of endTx. A rollback drops the journal's
+// edits made in the transaction and re-reads the grids, which showed them.
+func (m *Model) txEnded(msg txEndedMsg) tea.Cmd {
+	if msg.txn != m.txn {
+		return nil
+	}
+	if msg.err != nil && !errors.Is(msg.err, db.ErrNoTransaction) {
+		// Still open: a COMMIT can fail on another process's lock.
+		m.note = ErrorStyle.Render(msg.err.Error())
+		return nil
+	}
+	m.txn = nil
+	done := "rolled back"
+	if msg.commit {
+		done = "committed"
+	}
+	switch {
+	case msg.err != nil:
+		m.note = ErrorStyle.Render(msg.err.Error())
+	case msg.txn.changes == 1:
+		m.note = done + " 1 row change"
+	default:
+		m.note = fmt.Sprintf("%s %d row changes", done, msg.txn.changes)
+	}
+	if msg.commit {
+		return m.startWatching()
+	}
+	m.dropTxEdits(msg.txn)
+	return tea.Batch(m.refreshGrids(m.db, ""), m.startWatching())
+}
+
+// txLost leaves the transaction SQLite ended without the transaction bar,
+// saying why: rolled back after a statement in it failed, or ended by a
+// COMMIT or ROLLBACK from the query popup. Either way its edits can't be
+// undone as they were, and the grids are read again.
+func (m *Model) txLost(why error) tea.Cmd {
+	txn := m.txn
+	m.txn = nil
+	m.txPrompt = false
+	m.dropTxEdits(txn)
+	if errors.Is(why, db.ErrNoTransaction) {
+		why = errors.New("a statement run in the transaction ended it")
+	}
+	m.note = ErrorStyle.Render(why.Error())
+	return tea.Batch(m.refreshGrids(m.db, ""), m.startWatching())
+}
+
+// dropTxEdits drops the journal's edits made in txn.
+func (m *Model) dropTxEdits(txn *transaction) {
+	kept := m.edits[:0]
+	for _, j := range m.edits {
+		if j.txn != txn {
+			kept = append(kept, j)
+		}
+	}
+	m.edits = kept
+}
+
+// txChangesCmd counts the rows changed in the open transaction, if any.
+func (m *Model) txChangesCmd() tea.Cmd {
+	txn, database := m.txn, m.db
+	if txn == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		n, err := db.TotalChanges(context.Background(), database)
+		if err != nil {
+			return nil
+		}
+		return txChangesMsg{txn: txn, changes: n - txn.base}
+	}
+}
+
+// txBar is the line above the status bar while a transaction is open,
+// offering to commit or roll it back.
+func (m Model) txBar() string {
+	info := fmt.Sprintf("● transaction since %s · %d row changes", m.txn.started.Format("15:04:05"), m.txn.changes)
+	items := []helpItem{{Keys.Transaction.Help().Key, "commit or roll back"}}
+	if m.txPrompt {
+		info = fmt.Sprintf("end the transaction with %d row changes?", m.txn.changes)
+		items = []helpItem{{"c", "commit"}, {"r", "roll back"}, {"esc", "keep open"}}
+	}
+	return m.renderStatusBar(info, items)
+}
+
+// refreshGrids re-reads the main and pinned grids when they show table
+// (any table, if empty) of database.
+func (m *Model) refreshGrids(database *sql.DB, table string) tea.Cmd {
+	var cmds []tea.Cmd
+	if m.dataLoaded && !m.tableData.static && m.tableData.database == database && (table == "" || m.tableData.tableName == table) {
+		cmds = append(cmds, m.tableData.refreshCmd())
+	}
+	if m.showPinned && !m.pinned.static && m.pinned.database == database && (table == "" || m.pinned.tableName == table) {
+		cmds = append(cmds, m.pinned.refreshCmd())
+	}
+	return tea.Batch(cmds...)
+}
@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"context"
+	"database/sql"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// maxJournal is how many edits the undo journal keeps, dropping the oldest.
+const maxJournal = 100
+
+// journalEntry is a row edit made through the UI, with the database it was
+// made to (the pinned grid may show another open file) and the transaction
+// it was made in, if any.
+type journalEntry struct {
+	database *sql.DB
+	edit     db.Edit
+	txn      *transaction
+}
+
+// journal records an edit for Keys.Undo.
+func (m *Model) journal(database *sql.DB, e db.Edit) {
+	j := journalEntry{database: database, edit: e}
+	if database == m.db {
+		j.txn = m.txn
+	}
+	m.edits = append(m.edits, j)
+	if len(m.edits) > maxJournal {
+		m.edits = m.edits[len(m.edits)-maxJournal:]
+	}
+	m.note = ""
+}
+
+// forgetEdits drops the journal's edits to database, which is closing.
+func (m *Model) forgetEdits(database *sql.DB) {
+	kept := m.edits[:0]
+	for _, j := range m.edits {
+		if j.database != database {
+			kept = append(kept, j)
+		}
+	}
+	m.edits = kept
+}
+
+// forgetTableEdits drops the journal's edits to table, whose definition
+// changed under them.
+func (m *Model) forgetTableEdits(database *sql.DB, table string) {
+	kept := m.edits[:0]
+	for _, j := range m.edits {
+		if j.database != database || j.edit.Table != table {
+			kept = append(kept, j)
+		}
+	}
+	m.edits = kept
+}
+
+// undo reverts the latest edit in the journal and refreshes the grids
+// showing its table. The outcome is noted in the status bar until the next
+// key.
+func (m *Model) undo() tea.Cmd {
+	if len(m.edits) == 0 {
+		m.note = "nothing to undo"
+		return nil
+	}
+	j := m.edits[len(m.edits)-1]
+	m.edits = m.edits[:len(m.edits)-1]
+	if err := db.UndoEdit(context.Background(), j.database, j.edit); err != nil {
+		m.note = ErrorStyle.Render(err.Error())
+		return nil
+	}
+	m.note = "undid " + j.edit.String()
+	return tea.Batch(m.refreshGrids(j.database, j.edit.Table), m.txChangesCmd())
+}
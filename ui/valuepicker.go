@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+const (
+	// maxPickValues is the most distinct values a column may have for the
+	// filter to offer them as a list; columns with more are typed into.
+	maxPickValues = 100
+	// distinctTimeout bounds the GROUP BY finding them, which scans the
+	// table unless the column is indexed. A huge table falls back to
+	// typing rather than stalling the filter.
+	distinctTimeout = 500 * time.Millisecond
+)
+
+// loadDistinctValues looks up the filter column's distinct values,
+// reporting whether there are few enough to pick from.
+func (m *TableDataModel) loadDistinctValues() bool {
+	m.fValues = nil
+	if m.paged {
+		return false // the page holds too few of them to tell
+	}
+	col := slices.Index(m.columns, m.fCol)
+	if m.static {
+		counts := make(map[string]int64)
+		for _, r := range m.staticRows {
+			if col < len(r) && r[col] != "NULL" {
+				if _, seen := counts[r[col]]; !seen && len(counts) == maxPickValues {
+					return false
+				}
+				counts[r[col]]++
+			}
+		}
+		for v, n := range counts {
+			m.fValues = append(m.fValues, db.ValueCount{Value: v, Count: n})
+		}
+		slices.SortFunc(m.fValues, func(a, b db.ValueCount) int {
+			if a.Count != b.Count {
+				return int(b.Count - a.Count)
+			}
+			return strings.Compare(a.Value, b.Value)
+		})
+	} else {
+		if m.database == nil {
+			return false
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), distinctTimeout)
+		defer cancel()
+		values, err := db.DistinctValues(ctx, m.database, m.tableName, m.fCol, maxPickValues+1)
+		if err != nil || len(values) > maxPickValues {
+			return false
+		}
+		m.fValues = values
+	}
+	if len(m.fValues) == 0 {
+		return false
+	}
+	m.fValSearch = ""
+	m.updateValueMatches()
+	return true
+}
+
+func (m TableDataModel) updatePickValue(msg tea.KeyMsg) (TableDataModel, tea.Cmd) {
+	visible := m.valuePickerVisibleCount()
+
+	switch msg.String() {
+	case "esc":
+		return m, m.clearFilter()
+
+	case "up", "ctrl+p":
+		m.moveValueCursor(-1)
+		return m, nil
+
+	case "down", "ctrl+n":
+		m.moveValueCursor(1)
+		return m, nil
+
+	case "pgup":
+		m.moveValueCursor(-visible)
+		return m, nil
+
+	case "pgdown":
+		m.moveValueCursor(visible)
+		return m, nil
+
+	case "home":
+		m.moveValueCursor(-len(m.fValMatch))
+		return m, nil
+
+	case "end":
+		m.moveValueCursor(len(m.fValMatch))
+		return m, nil
+
+	case "backspace":
+		if m.fValSearch != "" {
+			r := []rune(m.fValSearch)
+			m.fValSearch = string(r[:len(r)-1])
+			m.updateValueMatches()
+		}
+		return m, nil
+
+	case "tab":
+		// Type the value instead, starting from the search text.
+		return m, m.startFilterInput(m.fValSearch)
+
+	case "enter":
+		if len(m.fValMatch) == 0 {
+			return m, nil
+		}
+		value := m.fValues[m.fValMatch[m.fValIndex]].Value
+		m.fMode = db.MatchExact
+		m.fInput.Prompt = m.filterPrompt()
+		m.fInput.SetValue(value)
+		cmd := m.applyFilter()
+		m.fActive = true
+		m.fQuery = value
+		m.fState = filterOff
+		m.table.SetHeight(m.tableHeight())
+		m.recordFilter()
+		return m, cmd
+	}
+
+	// Any printable input narrows the value list.
+	if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+		m.fValSearch += string(msg.Runes)
+		m.updateValueMatches()
+	}
+	return m, nil
+}
+
+// valuePickerVisibleCount is how many values the picker shows at once,
+// sized like the column picker.
+func (m TableDataModel) valuePickerVisibleCount() int {
+	return min(len(m.fValues), max((m.height-3)/2, 3))
+}
+
+// moveValueCursor moves the value picker highlight by delta entries,
+// keeping it within the scroll window.
+func (m *TableDataModel) moveValueCursor(delta int) {
+	if len(m.fValMatch) == 0 {
+		return
+	}
+	m.fValIndex = max(0, min(m.fValIndex+delta, len(m.fValMatch)-1))
+	visible := m.valuePickerVisibleCount()
+	if m.fValIndex < m.fValScroll {
+		m.fValScroll = m.fValIndex
+	}
+	if m.fValIndex >= m.fValScroll+visible {
+		m.fValScroll = m.fValIndex - visible + 1
+	}
+}
+
+// updateValueMatches recomputes which values contain the picker search,
+// ignoring case, and resets the highlight to the first one.
+func (m *TableDataModel) updateValueMatches() {
+	needle := strings.ToLower(m.fValSearch)
+	var matches []int
+	for i, v := range m.fValues {
+		if strings.Contains(strings.ToLower(v.Value), needle) {
+			matches = append(matches, i)
+		}
+	}
+	m.fValMatch = matches
+	m.fValIndex = 0
+	m.fValScroll = 0
+}
+
+// renderValuePicker draws the search line followed by a scrollable list of
+// the matching values, each with how many rows hold it.
+func (m TableDataModel) renderValuePicker() string {
+	visible := m.valuePickerVisibleCount()
+	valueW := 0
+	for _, i := range m.fValMatch {
+		valueW = max(valueW, len([]rune(truncateValue(m.fValues[i].Value, maxColWidth))))
+	}
+
+	search := StatusBarStyle.Render(fmt.Sprintf("%s = %s▏ (%d/%d) · tab: type a value", m.fCol, m.fValSearch, len(m.fValMatch), len(m.fValues)))
+	lines := []string{search}
+	if len(m.fValMatch) == 0 {
+		lines = append(lines, StatusBarStyle.Render("  no matching values"))
+	}
+	for i := m.fValScroll; i < m.fValScroll+visible && i < len(m.fValMatch); i++ {
+		v := m.fValues[m.fValMatch[i]]
+		value := truncateValue(v.Value, maxColWidth)
+		value += strings.Repeat(" ", valueW-len([]rune(value)))
+		count := "  " + strconv.FormatInt(v.Count, 10)
+		if i == m.fValIndex {
+			lines = append(lines, TitleStyle.Render("▸ "+value)+StatusBarStyle.Render(count))
+		} else {
+			lines = append(lines, StatusBarStyle.Render("  "+value+count))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
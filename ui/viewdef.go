@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ViewDefModel is the popup showing the SELECT a view is defined by, laid
+// out a clause per line. It can be copied, or taken to the query popup to
+// run or rework, and saved from there as a view again.
+type ViewDefModel struct {
+	view     string
+	query    string
+	viewport viewport.Model
+	notice   string // the outcome of a copy
+	width    int
+	height   int
+}
+
+func NewViewDefModel(view, query string, termWidth, termHeight int) ViewDefModel {
+	m := ViewDefModel{
+		view:   view,
+		query:  query,
+		width:  max(termWidth*60/100, 50),
+		height: max(termHeight*60/100, 12),
+	}
+	// Border, padding, title, gaps, notice, and help take 8 lines; the
+	// viewport shrinks to fit short definitions.
+	lines := wrapText(formatSQL(query), m.width-6)
+	m.viewport = viewport.New(m.width-6, min(len(lines), m.height-8))
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+	return m
+}
+
+func (m ViewDefModel) Update(msg tea.Msg) (ViewDefModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		m.notice = ""
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "enter":
+			query := m.query
+			return m, func() tea.Msg { return editQueryMsg{query: query} }
+		case "y":
+			if err := copyToClipboard(m.query); err != nil {
+				m.notice = ErrorStyle.Render("copy failed: " + err.Error())
+			} else {
+				m.notice = TitleStyle.Render("copied the definition of " + m.view)
+			}
+			return m, nil
+		}
+		if key.Matches(msg, Keys.ViewDefinition) {
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m ViewDefModel) View() string {
+	notice := " "
+	if m.notice != "" {
+		notice = m.notice
+	}
+	help := StatusBarStyle.Render("↑↓: scroll | enter: edit as a query | y: copy | esc: close")
+	return PopupStyle.
+		Width(m.width - 2).
+		Render(TitleStyle.Render(" View "+m.view+" ") + "\n\n" + m.viewport.View() + "\n\n" + notice + "\n" + help)
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// viewLinkScheme prefixes every view link so a pasted string is easy to
+// recognize.
+const viewLinkScheme = "sqlitui://view?"
+
+// viewLink describes what a grid shows — table, filter, sort, and
+// position — as a string a teammate can paste to land on the same view of
+// the same file. The position is the first row's offset rather than a page
+// number, since page sizes follow each terminal's height, and counts in
+// the sort order, so the sort comes along.
+type viewLink struct {
+	Table       string
+	FilterCol   string
+	FilterQuery string
+	FilterMode  db.MatchMode
+	Sort        []db.SortKey
+	Offset      int
+}
+
+func (v viewLink) String() string {
+	q := url.Values{}
+	q.Set("table", v.Table)
+	if v.FilterCol != "" {
+		q.Set("col", v.FilterCol)
+		q.Set("q", v.FilterQuery)
+		if v.FilterMode != db.MatchSubstring {
+			q.Set("match", v.FilterMode.String())
+		}
+	}
+	// One sort per column, in turn, as "asc:name" or "desc:name": the
+	// direction first, since a column's name can hold anything.
+	for _, k := range v.Sort {
+		dir := "asc:"
+		if k.Desc {
+			dir = "desc:"
+		}
+		q.Add("sort", dir+k.Column)
+	}
+	if v.Offset > 0 {
+		q.Set("row", strconv.Itoa(v.Offset))
+	}
+	return viewLinkScheme + q.Encode()
+}
+
+func parseViewLink(s string) (viewLink, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(s), viewLinkScheme)
+	if !ok {
+		return viewLink{}, fmt.Errorf("not a view link (expected %s...)", viewLinkScheme)
+	}
+	q, err := url.ParseQuery(rest)
+	if err != nil {
+		return viewLink{}, err
+	}
+	v := viewLink{Table: q.Get("table"), FilterCol: q.Get("col"), FilterQuery: q.Get("q")}
+	v.FilterMode = db.ParseMatchMode(q.Get("match"))
+	if v.Table == "" {
+		return viewLink{}, fmt.Errorf("view link has no table")
+	}
+	for _, s := range q["sort"] {
+		dir, col, ok := strings.Cut(s, ":")
+		if !ok || col == "" || dir != "asc" && dir != "desc" {
+			return viewLink{}, fmt.Errorf("invalid sort %q in view link", s)
+		}
+		v.Sort = append(v.Sort, db.SortKey{Column: col, Desc: dir == "desc"})
+	}
+	if r := q.Get("row"); r != "" {
+		if v.Offset, err = strconv.Atoi(r); err != nil || v.Offset < 0 {
+			return viewLink{}, fmt.Errorf("invalid row %q in view link", r)
+		}
+	}
+	return v, nil
+}
+
+// viewLinkFor describes grid, which must show a real table.
+func viewLinkFor(grid TableDataModel) viewLink {
+	v := viewLink{Table: grid.tableName, Sort: grid.sort, Offset: grid.page * grid.pageSize}
+	if grid.fActive {
+		v.FilterCol = grid.fCol
+		v.FilterQuery = grid.fQuery
+		v.FilterMode = grid.fMode
+	}
+	return v
+}
+
+// restoreView applies a link's filter, sort, and position to a freshly
+// loaded grid of the same table. The sort comes first, since the position
+// counts in its order; columns the table no longer has are left out.
+func (m *TableDataModel) restoreView(v viewLink) tea.Cmd {
+	page := 0
+	if m.pageSize > 0 {
+		page = v.Offset / m.pageSize
+	}
+	var sort []db.SortKey
+	for _, k := range v.Sort {
+		if slices.Contains(m.columns, k.Column) && !slices.ContainsFunc(sort, func(s db.SortKey) bool { return s.Column == k.Column }) {
+			sort = append(sort, k)
+		}
+	}
+	if len(sort) > 0 {
+		m.sort = sort
+		// The markers change the header's width.
+		m.refitColumns()
+		m.setTableRows(m.allRows)
+	}
+	if v.FilterCol != "" && slices.Contains(m.columns, v.FilterCol) {
+		m.fCol = v.FilterCol
+		m.fQuery = v.FilterQuery
+		m.fMode = v.FilterMode
+		if m.fMode == db.MatchFTS {
+			m.fts, m.ftsChecked = true, true
+		}
+		m.fActive = true
+		m.fInput.Prompt = m.filterPrompt()
+		m.fInput.SetValue(v.FilterQuery)
+	}
+	if page == 0 && !m.fActive && len(m.sort) == 0 {
+		return nil
+	}
+	return m.pageCmd(page, false)
+}
+
+// openViewMsg asks the parent to open the view a pasted link describes.
+type openViewMsg struct {
+	link viewLink
+}
+
+// ViewLinkModel is the popup showing the current view's link, with an
+// input to paste someone else's.
+type ViewLinkModel struct {
+	current string // "" when the focused grid isn't a table
+	input   textinput.Model
+	err     string
+	width   int
+}
+
+func NewViewLinkModel(current string, termWidth int) (ViewLinkModel, tea.Cmd) {
+	width := max(termWidth*70/100, 50)
+	ti := textinput.New()
+	ti.Prompt = "open: "
+	ti.Placeholder = "paste a view link"
+	ti.Width = width - 14
+	cmd := ti.Focus()
+	return ViewLinkModel{current: current, input: ti, width: width}, cmd
+}
+
+// SetError shows why the pasted link couldn't be opened.
+func (m *ViewLinkModel) SetError(err error) {
+	m.err = err.Error()
+}
+
+func (m ViewLinkModel) Update(msg tea.Msg) (ViewLinkModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		case "enter":
+			link, err := parseViewLink(m.input.Value())
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			return m, func() tea.Msg { return openViewMsg{link: link} }
+		}
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m ViewLinkModel) View() string {
+	current := StatusBarStyle.Render("Query results and schema views have no link.")
+	if m.current != "" {
+		current = m.current
+	}
+	errLine := " "
+	if m.err != "" {
+		errLine = ErrorStyle.Render("Error: " + m.err)
+	}
+	title := TitleStyle.Render(" View Link ")
+	help := StatusBarStyle.Render("enter: open pasted link | esc: close")
+	body := PopupLabelStyle.Render("This view") + "\n" +
+		strings.Join(wrapText(current, m.width-6), "\n") + "\n\n" +
+		m.input.View() + "\n" + errLine + "\n" + help
+	return PopupStyle.Width(m.width - 2).Render(title + "\n\n" + body)
+}
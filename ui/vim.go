@@ -0,0 +1,250 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// vimBindings are the data pane's vim set, switched on by vim_keys.
+func (k *KeyMap) vimBindings() []*key.Binding {
+	return []*key.Binding{&k.FirstPage, &k.LastPage, &k.HalfPageDown, &k.HalfPageUp}
+}
+
+// gridBindings are the data pane's own keys, which it takes ahead of the
+// parent's: the vim set and the page search.
+func (k *KeyMap) gridBindings() []*key.Binding {
+	return append(k.vimBindings(), &k.Search, &k.NextMatch, &k.PrevMatch)
+}
+
+// setVimKeys switches the vim set on or off, moving the search's next and
+// previous match to n and N while it is on. The other actions' keys the
+// data pane would take first with it on are dropped, like go to row's G,
+// leaving an action with no key of its own until the config gives it one.
+// Bindings from the config are applied afterwards, so they win.
+func (k *KeyMap) setVimKeys(on bool) {
+	for _, b := range k.vimBindings() {
+		b.SetEnabled(on)
+	}
+	next, prev := "ctrl+n", "ctrl+p"
+	if on {
+		next, prev = "n", "N"
+	}
+	k.NextMatch.SetKeys(next)
+	k.NextMatch.SetHelp(next, k.NextMatch.Help().Desc)
+	k.PrevMatch.SetKeys(prev)
+	k.PrevMatch.SetHelp(prev, k.PrevMatch.Help().Desc)
+	if !on {
+		return
+	}
+	for _, b := range k.actions() {
+		if slices.Contains(k.gridBindings(), b) {
+			continue
+		}
+		keys := slices.DeleteFunc(slices.Clone(b.Keys()), func(key string) bool { return k.gridClash(key) != "" })
+		if len(keys) < len(b.Keys()) {
+			help := ""
+			if len(keys) > 0 {
+				help = displayKey(keys[0])
+			}
+			b.SetKeys(keys...)
+			b.SetHelp(help, b.Help().Desc)
+		}
+	}
+}
+
+// gridClash returns the key of the grid's own, enabled, that key would
+// clash with in the data pane, or "": the same keys, or one beginning the
+// other, as g begins gg.
+func (k *KeyMap) gridClash(key string) string {
+	for _, b := range k.gridBindings() {
+		if !b.Enabled() {
+			continue
+		}
+		for _, own := range b.Keys() {
+			if keysClash(key, own) {
+				return own
+			}
+		}
+	}
+	return ""
+}
+
+// keysClash reports whether a and b are the same keys, or one begins the
+// other.
+func keysClash(a, b string) bool {
+	sa, sb := keySeq(a), keySeq(b)
+	n := min(len(sa), len(sb))
+	return slices.Equal(sa[:n], sb[:n])
+}
+
+// checkClashes returns an error for a key the config gives an action that
+// clashes with one of the grid's own in the data pane, where only the
+// grid's would ever run.
+func (k *KeyMap) checkClashes(names []string) error {
+	actions := k.actions()
+	grid := k.gridBindings()
+	for _, name := range names {
+		b := actions[name]
+		if !slices.Contains(grid, b) {
+			for _, key := range b.Keys() {
+				if own := k.gridClash(key); own != "" {
+					return fmt.Errorf("key action %q: %s clashes with the data pane's %s", name, key, own)
+				}
+			}
+			continue
+		}
+		if !b.Enabled() {
+			continue
+		}
+		for other, ob := range actions {
+			if slices.Contains(grid, ob) {
+				continue
+			}
+			for _, key := range ob.Keys() {
+				for _, own := range b.Keys() {
+					if keysClash(key, own) {
+						return fmt.Errorf("key action %q: %s clashes with %s of %q", name, own, key, other)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// keySeq splits a binding's key into the keys pressed in turn: "g g" is
+// g twice. A lone space is the space bar.
+func keySeq(k string) []string {
+	if strings.TrimSpace(k) == "" {
+		return []string{k}
+	}
+	return strings.Fields(k)
+}
+
+// vimMatches reports whether seq, the keys pressed in turn, is one of b's.
+func vimMatches(b *key.Binding, seq []string) bool {
+	if !b.Enabled() {
+		return false
+	}
+	for _, k := range b.Keys() {
+		if strings.Join(keySeq(k), " ") == strings.Join(seq, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// vimStarts reports whether seq begins a longer key of the grid's own.
+func vimStarts(seq []string) bool {
+	for _, b := range Keys.gridBindings() {
+		if !b.Enabled() {
+			continue
+		}
+		for _, k := range b.Keys() {
+			if ks := keySeq(k); len(ks) > len(seq) && strings.Join(ks[:len(seq)], " ") == strings.Join(seq, " ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// vimClaims reports whether the grid takes msg, ahead of the parent's
+// bindings on the same key: it is one of the grid's own keys, or the next
+// of a sequence already begun, or a column is being resized.
+func (m TableDataModel) vimClaims(msg tea.KeyMsg) bool {
+	if m.pendingKeys != nil || m.resizing {
+		return true
+	}
+	seq := []string{msg.String()}
+	if vimStarts(seq) {
+		return true
+	}
+	for _, b := range Keys.gridBindings() {
+		if vimMatches(b, seq) {
+			return true
+		}
+	}
+	return false
+}
+
+// updateVim runs the binding of the grid's own that msg completes, if
+// any. A key that begins a sequence waits for the next; one that doesn't
+// continue it drops both, as in vim.
+func (m TableDataModel) updateVim(msg tea.KeyMsg) (TableDataModel, tea.Cmd, bool) {
+	pending := m.pendingKeys != nil
+	seq := append(m.pendingKeys, msg.String())
+	m.pendingKeys = nil
+	switch {
+	case vimMatches(&Keys.FirstPage, seq):
+		return m.firstRow(), m.firstPageCmd(), true
+	case vimMatches(&Keys.LastPage, seq):
+		m, cmd := m.lastPage()
+		return m, cmd, true
+	case vimMatches(&Keys.HalfPageDown, seq):
+		m, cmd := m.halfPage(1)
+		return m, cmd, true
+	case vimMatches(&Keys.HalfPageUp, seq):
+		m, cmd := m.halfPage(-1)
+		return m, cmd, true
+	case vimMatches(&Keys.Search, seq):
+		return m, m.startSearch(), true
+	case vimMatches(&Keys.NextMatch, seq):
+		m, cmd := m.nextMatch(1)
+		return m, cmd, true
+	case vimMatches(&Keys.PrevMatch, seq):
+		m, cmd := m.nextMatch(-1)
+		return m, cmd, true
+	case vimStarts(seq):
+		m.pendingKeys = seq
+		return m, nil, true
+	}
+	return m, nil, pending
+}
+
+// firstRow puts the cursor on the page's first row.
+func (m TableDataModel) firstRow() TableDataModel {
+	m.table.SetCursor(0)
+	return m
+}
+
+// firstPageCmd loads the first page, unless it is the one shown.
+func (m TableDataModel) firstPageCmd() tea.Cmd {
+	if m.inMemory() || m.page == 0 {
+		return nil
+	}
+	return m.pageCmd(0, false)
+}
+
+// lastPage puts the cursor on the last row, loading the last page first.
+// Until the rows are counted, that is the last page known.
+func (m TableDataModel) lastPage() (TableDataModel, tea.Cmd) {
+	if last := m.totalPages() - 1; !m.inMemory() && last != m.page {
+		return m, m.pageCmd(last, true)
+	}
+	m.table.SetCursor(max(len(m.allRows)-1, 0))
+	return m, nil
+}
+
+// halfPage moves the cursor half the grid's height down (dir 1) or up
+// (dir -1), onto the next or previous page past the ends of this one.
+func (m TableDataModel) halfPage(dir int) (TableDataModel, tea.Cmd) {
+	half := max(m.table.Height()/2, 1)
+	target := m.table.Cursor() + dir*half
+	switch {
+	case target >= len(m.allRows) && m.hasNextPage() && !m.inMemory():
+		load := m.nextPageCmd()
+		row := target - len(m.allRows)
+		return m, func() tea.Msg { return withCursorRow(load(), row) }
+	case target < 0 && m.hasPrevPage() && !m.inMemory():
+		load := m.pageCmd(m.page-1, false)
+		row := max(m.pageSize+target, 0)
+		return m, func() tea.Msg { return withCursorRow(load(), row) }
+	}
+	m.table.SetCursor(min(max(target, 0), max(len(m.allRows)-1, 0)))
+	return m, nil
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/markovic-nikola/sqlitui/db"
+)
+
+// fileSettleDelay is how long the file must stay quiet after a change
+// before the view reloads, so a burst of writes costs a single reload.
+const fileSettleDelay = 250 * time.Millisecond
+
+// fileChangedMsg reports that the database file changed on disk. gen ties
+// it to the watcher that saw it, so events from a retired watcher are
+// dropped.
+type fileChangedMsg struct {
+	gen int
+}
+
+// watchedNames are the files whose changes mean the data changed: the
+// database itself and its rollback journal or WAL. The -shm index is left
+// out since readers touch it too.
+func watchedNames(dbPath string) map[string]bool {
+	abs, err := filepath.Abs(dbPath)
+	if err != nil {
+		abs = dbPath
+	}
+	return map[string]bool{abs: true, abs + "-wal": true, abs + "-journal": true}
+}
+
+// startLive watches the open database's directory — the -wal file comes
+// and goes, so watching it directly would miss it — and returns the command
+// waiting for the first change.
+func (m *Model) startLive() tea.Cmd {
+	m.stopLive()
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		m.live = false
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(db.LocalPath(m.dbPath)))
+	if err == nil {
+		err = w.Add(dir)
+	}
+	if err != nil {
+		w.Close()
+		m.live = false
+		return nil
+	}
+	m.fsWatcher = w
+	m.liveGen++
+	return waitForChangeCmd(w, watchedNames(db.LocalPath(m.dbPath)), m.liveGen)
+}
+
+// stopLive closes the file watcher, which also ends its waiting command.
+func (m *Model) stopLive() {
+	if m.fsWatcher != nil {
+		m.fsWatcher.Close()
+		m.fsWatcher = nil
+	}
+	m.liveGen++
+}
+
+// waitForChangeCmd blocks until one of names is written, created, or
+// removed, then waits for the file to settle before reporting it. It
+// returns nil once the watcher is closed.
+func waitForChangeCmd(w *fsnotify.Watcher, names map[string]bool, gen int) tea.Cmd {
+	return func() tea.Msg {
+		// A write can come with a chmod in the same event; a chmod alone
+		// changes nothing to reload.
+		relevant := func(ev fsnotify.Event) bool {
+			return names[filepath.Clean(ev.Name)] &&
+				(ev.Has(fsnotify.Write) || ev.Has(fsnotify.Create) || ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename))
+		}
+	wait:
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return nil
+				}
+				if relevant(ev) {
+					break wait
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return nil
+				}
+			}
+		}
+
+		settle := time.NewTimer(fileSettleDelay)
+		defer settle.Stop()
+		for {
+			select {
+			case ev, ok := <-w.Events:
+				if !ok {
+					return nil
+				}
+				if relevant(ev) {
+					settle.Reset(fileSettleDelay)
+				}
+			case _, ok := <-w.Errors:
+				if !ok {
+					return nil
+				}
+			case <-settle.C:
+				return fileChangedMsg{gen: gen}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// WhatsNewModel is the popup shown on the first start after an update,
+// listing the release notes since the previously run version.
+type WhatsNewModel struct {
+	version  string
+	notes    string // Markdown sections from the embedded changelog
+	viewport viewport.Model
+	width    int
+	height   int
+}
+
+// WithWhatsNew returns m set to greet the user with the release notes for
+// version, shown until dismissed.
+func (m Model) WithWhatsNew(version, notes string) Model {
+	m.whatsNew = WhatsNewModel{version: version, notes: notes}
+	m.whatsNew.SetSize(m.width, m.height)
+	m.showWhatsNew = true
+	return m
+}
+
+// SetSize fits the popup to the terminal and re-wraps the notes.
+func (m *WhatsNewModel) SetSize(termWidth, termHeight int) {
+	m.width = max(termWidth*60/100, 50)
+	m.height = max(termHeight*70/100, 12)
+	// Border (2) + padding (4) horizontally; border, padding, title, gap,
+	// and help vertically.
+	contentWidth := m.width - 6
+	m.viewport = viewport.New(contentWidth, m.height-7)
+	m.viewport.SetContent(renderNotes(m.notes, contentWidth))
+}
+
+func (m WhatsNewModel) Update(msg tea.Msg) (WhatsNewModel, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "enter", "q":
+			return m, func() tea.Msg { return CloseDetailMsg{} }
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m WhatsNewModel) View() string {
+	title := TitleStyle.Render(" What's new in sqlitui " + m.version + " ")
+	help := StatusBarStyle.Render("↑↓: scroll | esc/enter: close")
+	return PopupStyle.
+		Width(m.width - 2).
+		Height(m.height - 2).
+		Render(title + "\n\n" + m.viewport.View() + "\n" + help)
+}
+
+// renderNotes lays out changelog Markdown for the terminal: release
+// headings become titles, list items wrap under their bullet, and
+// `code` spans (mostly key names) are highlighted.
+func renderNotes(notes string, width int) string {
+	var b strings.Builder
+	for _, line := range strings.Split(notes, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			b.WriteString(TitleStyle.Render(heading) + "\n")
+			continue
+		}
+		indent := ""
+		if strings.HasPrefix(line, "- ") {
+			indent = "  "
+		}
+		for i, l := range wrapText(line, width-len(indent)) {
+			if i > 0 {
+				l = indent + l
+			}
+			b.WriteString(highlightCode(l) + "\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// highlightCode renders `code` spans in the key style, dropping the
+// backticks. An unmatched backtick is left as is.
+func highlightCode(line string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(line, '`')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(line[start+1:], '`')
+		if end < 0 {
+			break
+		}
+		b.WriteString(line[:start])
+		b.WriteString(StatusBarKeyStyle.Render(line[start+1 : start+1+end]))
+		line = line[start+end+2:]
+	}
+	b.WriteString(line)
+	return b.String()
+}
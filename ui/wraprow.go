@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// headerLines are the lines of the table widget's view the header takes:
+// the titles and their bottom border.
+const headerLines = 2
+
+// wrapSelectedRow takes the table widget's view and redraws the selected
+// row, on line of the body (see cursorLine), with each cell wrapped over
+// as many lines as its value needs, so long values can be read without
+// opening the row. The rows below it move down, or those above it up when
+// it is near the bottom; the view keeps its height.
+func (m TableDataModel) wrapSelectedRow(view string, line int) string {
+	rows := m.table.Rows()
+	cursor := m.table.Cursor()
+	lines := strings.Split(view, "\n")
+	if cursor < 0 || cursor >= len(rows) || line < 0 || len(lines) <= headerLines {
+		return view
+	}
+	header, body := lines[:headerLines], lines[headerLines:]
+
+	styles := tableStyles()
+	cols := m.table.Columns()
+
+	// Wrap every cell to its column and stack the lines of each. Each cell
+	// is styled on its own, so the marks don't end the selected row's
+	// colors, and as tall as the row, so no gap is left unstyled.
+	marks, _ := m.rowMarks(cursor)
+	var values []string
+	var bases []lipgloss.Style
+	var widths []int
+	height := 1
+	for i, value := range rows[cursor] {
+		if i >= len(cols) || cols[i].Width <= 0 {
+			continue
+		}
+		base, spans := marks.cellStyle(i, true, styles)
+		wrapped := wrapCell(value, spans, base, cols[i].Width)
+		height = max(height, len(wrapped))
+		values = append(values, strings.Join(wrapped, "\n"))
+		bases = append(bases, base)
+		widths = append(widths, cols[i].Width)
+	}
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = styles.Cell.Inherit(bases[i]).Render(lipgloss.NewStyle().Width(widths[i]).Height(height).Render(v))
+	}
+	row := lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+	height = min(height, len(body))
+	expanded := strings.Split(row, "\n")[:height]
+
+	out := append(append(append([]string{}, body[:line]...), expanded...), body[line+1:]...)
+	start := max(0, line+height-len(body))
+	out = out[start : start+len(body)]
+	return strings.Join(append(header, out...), "\n")
+}
+
+// wrapCell wraps value to width, cutting what still doesn't fit, with its
+// spans marked on the lines they fall on.
+func wrapCell(value string, spans [][2]int, base lipgloss.Style, width int) []string {
+	lines := wrapText(strings.TrimSpace(value), width)
+	at := len(value) - len(strings.TrimLeftFunc(value, unicode.IsSpace))
+	for j, l := range lines {
+		text := runewidth.Truncate(l, width, "…")
+		// The lines are value's words in order; find where this one is
+		// to tell which spans are on it.
+		var on [][2]int
+		if k := strings.Index(value[at:], l); k >= 0 {
+			on = clipSpans(spans, at+k, shownBytes(l, text))
+			at += k + len(l)
+		}
+		lines[j] = markSpans(text, on, base, width)
+	}
+	return lines
+}
+
+// cursorLine returns the line of the table body, below the header, the
+// selected row is on, or -1 if it can't be told. view must be the table
+// widget's own, before any row is redrawn.
+func (m TableDataModel) cursorLine(view string) int {
+	lines := strings.Split(view, "\n")
+	if m.table.Cursor() < 0 || len(lines) <= headerLines {
+		return -1
+	}
+	body := lines[headerLines:]
+	rows, cols, styles := m.table.Rows(), m.table.Columns(), tableStyles()
+	for k := range body {
+		if m.bodyShowsCursorAt(body, k, rows, cols, styles) {
+			return k
+		}
+	}
+	return -1
+}
+
+// bodyShowsCursorAt reports whether the table body has the selected row on
+// line k: every line must then be the row it would be, drawn the way the
+// table widget draws it.
+func (m TableDataModel) bodyShowsCursorAt(body []string, k int, rows []table.Row, cols []table.Column, styles table.Styles) bool {
+	cursor := m.table.Cursor()
+	for j, got := range body {
+		r := cursor - k + j
+		// The viewport pads its lines with spaces.
+		got = strings.TrimRight(got, " ")
+		if r < 0 || r >= len(rows) {
+			if got != "" {
+				return false
+			}
+			continue
+		}
+		want := renderTableRow(rows[r], cols, styles)
+		if r == cursor {
+			want = styles.Selected.Render(want)
+		}
+		if got != strings.TrimRight(want, " ") {
+			return false
+		}
+	}
+	return true
+}
+
+// renderTableRow draws a row as the table widget does.
+func renderTableRow(row table.Row, cols []table.Column, styles table.Styles) string {
+	s := make([]string, 0, len(cols))
+	for i, value := range row {
+		if i >= len(cols) || cols[i].Width <= 0 {
+			continue
+		}
+		style := lipgloss.NewStyle().Width(cols[i].Width).MaxWidth(cols[i].Width).Inline(true)
+		s = append(s, styles.Cell.Render(style.Render(runewidth.Truncate(value, cols[i].Width, "…"))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, s...)
+}
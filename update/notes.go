@@ -0,0 +1,61 @@
+package update
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ReleaseNotes returns the sections of changelog for releases newer than
+// seen and no newer than current, newest first. changelog is Markdown with
+// one "## vX.Y.Z" heading per release; other headings are skipped. When
+// seen is empty (a first run) only current's own section is returned, and
+// unparseable versions such as "dev" yield nothing.
+func ReleaseNotes(changelog, seen, current string) string {
+	cur, err := coreVersion(current)
+	if err != nil {
+		return ""
+	}
+	var last *semver.Version
+	if seen != "" {
+		if last, err = coreVersion(seen); err != nil {
+			return ""
+		}
+		if !last.LessThan(cur) {
+			return ""
+		}
+	}
+
+	var out []string
+	keep := false
+	for _, line := range strings.Split(changelog, "\n") {
+		if heading, ok := strings.CutPrefix(line, "## "); ok {
+			v, err := semver.NewVersion(strings.TrimSpace(heading))
+			switch {
+			case err != nil:
+				keep = false
+			case last == nil:
+				keep = v.Equal(cur)
+			default:
+				keep = v.GreaterThan(last) && !v.GreaterThan(cur)
+			}
+		} else if strings.HasPrefix(line, "# ") {
+			keep = false
+		}
+		if keep {
+			out = append(out, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// coreVersion parses v down to major.minor.patch. Local builds are named by
+// git describe (v0.3.0-2-gabc123-dirty), which semver would read as a
+// pre-release of v0.3.0 rather than a build after it.
+func coreVersion(v string) (*semver.Version, error) {
+	parsed, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, err
+	}
+	return semver.New(parsed.Major(), parsed.Minor(), parsed.Patch(), "", ""), nil
+}